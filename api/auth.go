@@ -0,0 +1,419 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/featureform/auth"
+	help "github.com/featureform/helpers"
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/metadata/proto"
+	srv "github.com/featureform/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+var methodResourceTypes = map[string]auth.ResourceType{
+	"GetUsers":                auth.UserResource,
+	"ListUsers":               auth.UserResource,
+	"GetProviders":            auth.ProviderResource,
+	"ListProviders":           auth.ProviderResource,
+	"GetSources":              auth.SourceResource,
+	"GetSourceVariants":       auth.SourceResource,
+	"ListSources":             auth.SourceResource,
+	"GetEntities":             auth.EntityResource,
+	"ListEntities":            auth.EntityResource,
+	"GetFeatures":             auth.FeatureResource,
+	"GetFeatureVariants":      auth.FeatureResource,
+	"ListFeatures":            auth.FeatureResource,
+	"GetFeatureGroups":        auth.FeatureGroupResource,
+	"GetFeatureGroupVariants": auth.FeatureGroupResource,
+	"ListFeatureGroups":       auth.FeatureGroupResource,
+	"GetLabels":               auth.LabelResource,
+	"GetLabelVariants":        auth.LabelResource,
+	"ListLabels":              auth.LabelResource,
+	"GetTrainingSets":         auth.TrainingSetResource,
+	"GetTrainingSetVariants":  auth.TrainingSetResource,
+	"ListTrainingSets":        auth.TrainingSetResource,
+	"GetModels":               auth.ModelResource,
+	"GetModelVariants":        auth.ModelResource,
+	"ListModels":              auth.ModelResource,
+}
+
+var protoResourceTypes = map[pb.ResourceType]auth.ResourceType{
+	pb.ResourceType_FEATURE:               auth.FeatureResource,
+	pb.ResourceType_FEATURE_VARIANT:       auth.FeatureResource,
+	pb.ResourceType_LABEL:                 auth.LabelResource,
+	pb.ResourceType_LABEL_VARIANT:         auth.LabelResource,
+	pb.ResourceType_TRAINING_SET:          auth.TrainingSetResource,
+	pb.ResourceType_TRAINING_SET_VARIANT:  auth.TrainingSetResource,
+	pb.ResourceType_SOURCE:                auth.SourceResource,
+	pb.ResourceType_SOURCE_VARIANT:        auth.SourceResource,
+	pb.ResourceType_PROVIDER:              auth.ProviderResource,
+	pb.ResourceType_ENTITY:                auth.EntityResource,
+	pb.ResourceType_MODEL:                 auth.ModelResource,
+	pb.ResourceType_USER:                  auth.UserResource,
+	pb.ResourceType_FEATURE_GROUP:         auth.FeatureGroupResource,
+	pb.ResourceType_FEATURE_GROUP_VARIANT: auth.FeatureGroupResource,
+	pb.ResourceType_MODEL_VARIANT:         auth.ModelResource,
+}
+
+func containsResourceType(types []pb.ResourceType, t pb.ResourceType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+// apiRequestResources is the auth.RequestPolicy of the API server. Create,
+// delete and archive calls need write permission on the resource they change
+// and everything else needs read permission on the resources it returns.
+func apiRequestResources(fullMethod string, msg interface{}) (auth.Permission, []auth.Resource, error) {
+	method := path.Base(fullMethod)
+	permission := auth.Read
+	if strings.HasPrefix(method, "Create") || method == "RequestScheduleChange" ||
+		method == "DeleteResource" || method == "ArchiveResource" || method == "SetLifecycle" ||
+		method == "SetTags" || method == "SetNotifications" {
+		permission = auth.Write
+	}
+	single := func(resType auth.ResourceType, name string) (auth.Permission, []auth.Resource, error) {
+		return permission, []auth.Resource{{Type: resType, Name: name}}, nil
+	}
+	switch req := msg.(type) {
+	case *pb.User:
+		return single(auth.UserResource, req.Name)
+	case *pb.NotificationsRequest:
+		return single(auth.UserResource, req.User)
+	case *pb.Provider:
+		return single(auth.ProviderResource, req.Name)
+	case *pb.SourceVariant:
+		return single(auth.SourceResource, req.Name)
+	case *pb.Entity:
+		return single(auth.EntityResource, req.Name)
+	case *pb.FeatureVariant:
+		return single(auth.FeatureResource, req.Name)
+	case *pb.FeatureGroupVariant:
+		return single(auth.FeatureGroupResource, req.Name)
+	case *pb.LabelVariant:
+		return single(auth.LabelResource, req.Name)
+	case *pb.TrainingSetVariant:
+		return single(auth.TrainingSetResource, req.Name)
+	case *pb.Model:
+		return single(auth.ModelResource, req.Name)
+	case *pb.ModelVariant:
+		return single(auth.ModelResource, req.Name)
+	case *pb.ScheduleChangeRequest, *pb.DeleteResourceRequest, *pb.ArchiveResourceRequest, *pb.LineageRequest,
+		*pb.SetLifecycleRequest, *pb.SetTagsRequest:
+		resourceID := req.(interface{ GetResourceId() *pb.ResourceID }).GetResourceId()
+		resType, has := protoResourceTypes[resourceID.GetResourceType()]
+		if !has {
+			return permission, nil, fmt.Errorf("unknown resource type: %v", resourceID.GetResourceType())
+		}
+		return single(resType, resourceID.GetResource().GetName())
+	case *pb.ResourceID:
+		resType, has := protoResourceTypes[req.GetResourceType()]
+		if !has {
+			return permission, nil, fmt.Errorf("unknown resource type: %v", req.GetResourceType())
+		}
+		return single(resType, req.GetResource().GetName())
+	case *pb.AuditEventsRequest, *pb.IntegrityRequest, *pb.ResourceQuery:
+		// Audit events and query results can be about any resource, and
+		// integrity checks look at all of them. Repairs can change any of them too.
+		if check, ok := req.(*pb.IntegrityRequest); ok && check.Repair {
+			permission = auth.Write
+		}
+		resources := make([]auth.Resource, 0)
+		seen := make(map[auth.ResourceType]bool)
+		for _, resType := range protoResourceTypes {
+			if !seen[resType] {
+				seen[resType] = true
+				resources = append(resources, auth.Resource{Type: resType})
+			}
+		}
+		return permission, resources, nil
+	case *pb.WatchRequest:
+		// Watches without a selection see changes to every type.
+		types := make([]auth.ResourceType, 0)
+		seen := make(map[auth.ResourceType]bool)
+		for protoType, resType := range protoResourceTypes {
+			if len(req.ResourceTypes) > 0 && !containsResourceType(req.ResourceTypes, protoType) {
+				continue
+			}
+			if !seen[resType] {
+				seen[resType] = true
+				types = append(types, resType)
+			}
+		}
+		names := req.Names
+		if len(names) == 0 {
+			names = []string{""}
+		}
+		resources := make([]auth.Resource, 0, len(types)*len(names))
+		for _, resType := range types {
+			for _, name := range names {
+				resources = append(resources, auth.Resource{Type: resType, Name: name})
+			}
+		}
+		return permission, resources, nil
+	case *pb.PlanRequest:
+		// Plans don't change anything, but they show how resources differ.
+		resources := make([]auth.Resource, len(req.Definitions))
+		for i, def := range req.Definitions {
+			resources[i] = definitionResource(def)
+		}
+		return permission, resources, nil
+	case *pb.Name, *pb.NameVariant, *pb.Empty, *pb.ListRequest:
+		resType, has := methodResourceTypes[method]
+		if !has {
+			return permission, nil, fmt.Errorf("unknown method: %s", fullMethod)
+		}
+		name := ""
+		if named, ok := req.(interface{ GetName() string }); ok {
+			name = named.GetName()
+		}
+		return single(resType, name)
+	case *srv.FeatureServeRequest, *srv.FeatureServeAsOfRequest:
+		features := req.(interface{ GetFeatures() []*srv.FeatureID }).GetFeatures()
+		groups := req.(interface{ GetFeatureGroups() []*srv.FeatureID }).GetFeatureGroups()
+		resources := make([]auth.Resource, 0, len(features)+len(groups))
+		for _, feature := range features {
+			resources = append(resources, auth.Resource{Type: auth.FeatureResource, Name: feature.GetName()})
+		}
+		for _, group := range groups {
+			resources = append(resources, auth.Resource{Type: auth.FeatureGroupResource, Name: group.GetName()})
+		}
+		// Serving a model variant with no features serves the ones it was
+		// trained with, so the model is checked too.
+		if served, ok := req.(*srv.FeatureServeRequest); ok && served.GetModel().GetVersion() != "" {
+			resources = append(resources, auth.Resource{Type: auth.ModelResource, Name: served.GetModel().GetName()})
+		}
+		return permission, resources, nil
+	case *srv.NearestRequest:
+		return single(auth.FeatureResource, req.GetId().GetName())
+	case *srv.TrainingDataRequest:
+		return single(auth.TrainingSetResource, req.GetId().GetName())
+	case *srv.TrainingDataColumnsRequest:
+		return single(auth.TrainingSetResource, req.GetId().GetName())
+	case *srv.SourceDataRequest:
+		return single(auth.SourceResource, req.GetId().GetName())
+	case *srv.SourceColumnRequest:
+		return single(auth.SourceResource, req.GetId().GetName())
+	default:
+		// Any other service, such as reflection, only requires authentication.
+		return permission, nil, nil
+	}
+}
+
+// servedFeatureResources returns the features that the feature groups and
+// model variant in a serving request serve, which need read permission as
+// much as the features requested by name.
+func servedFeatureResources(ctx context.Context, client *metadata.Client, msg interface{}) ([]auth.Resource, error) {
+	served, ok := msg.(interface{ GetFeatureGroups() []*srv.FeatureID })
+	if !ok {
+		return nil, nil
+	}
+	resources := make([]auth.Resource, 0)
+	if req, ok := msg.(*srv.FeatureServeRequest); ok && req.GetModel().GetVersion() != "" {
+		model := req.GetModel()
+		id := metadata.NameVariant{Name: model.GetName(), Variant: model.GetVersion(), Namespace: model.GetNamespace()}
+		variant, err := client.GetModelVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, feature := range variant.Features() {
+			resources = append(resources, auth.Resource{Type: auth.FeatureResource, Name: feature.Name})
+		}
+	}
+	for _, group := range served.GetFeatureGroups() {
+		namespaced := client.InNamespace(group.GetNamespace())
+		id := metadata.NameVariant{Name: group.GetName(), Variant: group.GetVersion(), Namespace: group.GetNamespace()}
+		if id.Variant == "" {
+			fetched, err := namespaced.GetFeatureGroup(ctx, id.Name)
+			if err != nil {
+				return nil, err
+			}
+			id.Variant = fetched.DefaultVariant()
+		}
+		variant, err := client.GetFeatureGroupVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, feature := range variant.Features() {
+			resources = append(resources, auth.Resource{Type: auth.FeatureResource, Name: feature.Name})
+		}
+	}
+	return resources, nil
+}
+
+// requestResources is apiRequestResources, with the features that serving
+// requests serve through feature groups and model variants looked up too.
+func (serv *ApiServer) requestResources(fullMethod string, msg interface{}) (auth.Permission, []auth.Resource, error) {
+	permission, resources, err := apiRequestResources(fullMethod, msg)
+	if err != nil {
+		return permission, nil, err
+	}
+	served, err := servedFeatureResources(context.Background(), serv.metadata.client, msg)
+	if err != nil {
+		return permission, nil, err
+	}
+	return permission, append(resources, served...), nil
+}
+
+func definitionResource(def *pb.ResourceDefinition) auth.Resource {
+	switch casted := def.Resource.(type) {
+	case *pb.ResourceDefinition_User:
+		return auth.Resource{Type: auth.UserResource, Name: casted.User.Name}
+	case *pb.ResourceDefinition_Provider:
+		return auth.Resource{Type: auth.ProviderResource, Name: casted.Provider.Name}
+	case *pb.ResourceDefinition_Entity:
+		return auth.Resource{Type: auth.EntityResource, Name: casted.Entity.Name}
+	case *pb.ResourceDefinition_SourceVariant:
+		return auth.Resource{Type: auth.SourceResource, Name: casted.SourceVariant.Name}
+	case *pb.ResourceDefinition_FeatureVariant:
+		return auth.Resource{Type: auth.FeatureResource, Name: casted.FeatureVariant.Name}
+	case *pb.ResourceDefinition_FeatureGroupVariant:
+		return auth.Resource{Type: auth.FeatureGroupResource, Name: casted.FeatureGroupVariant.Name}
+	case *pb.ResourceDefinition_LabelVariant:
+		return auth.Resource{Type: auth.LabelResource, Name: casted.LabelVariant.Name}
+	case *pb.ResourceDefinition_TrainingSetVariant:
+		return auth.Resource{Type: auth.TrainingSetResource, Name: casted.TrainingSetVariant.Name}
+	case *pb.ResourceDefinition_Model:
+		return auth.Resource{Type: auth.ModelResource, Name: casted.Model.Name}
+	case *pb.ResourceDefinition_ModelVariant:
+		return auth.Resource{Type: auth.ModelResource, Name: casted.ModelVariant.Name}
+	default:
+		// Only rules that cover every type and name match an empty resource.
+		return auth.Resource{}
+	}
+}
+
+// metadataUserAuthenticator makes sure that every authenticated principal
+// exists as a metadata User, so the resources they create can be owned by
+// them.
+type metadataUserAuthenticator struct {
+	auth.Authenticator
+	metadata *MetadataServer
+	known    sync.Map
+}
+
+func (a *metadataUserAuthenticator) Authenticate(ctx context.Context) (auth.Principal, error) {
+	principal, err := a.Authenticator.Authenticate(ctx)
+	if err != nil {
+		return principal, err
+	}
+	if _, known := a.known.Load(principal.Name); known {
+		return principal, nil
+	}
+	a.metadata.Logger.Infow("Registering authenticated user", "user", principal.Name, "method", principal.Method)
+	if _, err := a.metadata.meta.CreateUser(ctx, &pb.User{Name: principal.Name}); err != nil {
+		return principal, fmt.Errorf("could not register user %s: %w", principal.Name, err)
+	}
+	a.known.Store(principal.Name, struct{}{})
+	return principal, nil
+}
+
+// forwardPrincipal tells the metadata server who is making a call, so that it
+// can be recorded in the audit log.
+func forwardPrincipal(ctx context.Context) context.Context {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return metadata.ContextWithUser(ctx, principal.Name)
+	}
+	return ctx
+}
+
+func forwardPrincipalUnary(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(forwardPrincipal(ctx), method, req, reply, cc, opts...)
+}
+
+func forwardPrincipalStream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(forwardPrincipal(ctx), desc, cc, method, opts...)
+}
+
+// claimOwnership makes the authenticated principal the owner of a resource
+// being created.
+func claimOwnership(ctx context.Context, owner *string) {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		*owner = principal.Name
+	}
+}
+
+// EnableAuth authenticates and authorizes every call to the server. It must be
+// called before the server starts.
+func (serv *ApiServer) EnableAuth(authenticator auth.Authenticator, authorizer auth.Authorizer) {
+	serv.auth = &auth.Interceptor{
+		Authenticator: &metadataUserAuthenticator{Authenticator: authenticator, metadata: &serv.metadata},
+		Authorizer:    authorizer,
+		Policy:        serv.requestResources,
+		Logger:        serv.Logger,
+	}
+}
+
+// EnableTLS serves over TLS, which is required for mTLS authentication.
+func (serv *ApiServer) EnableTLS(config *tls.Config) {
+	serv.tlsConfig = config
+}
+
+// configureAuth sets up authentication from the environment. With no
+// authenticators configured, the server accepts every call as before.
+func configureAuth(serv *ApiServer, logger *zap.SugaredLogger) error {
+	certFile := help.GetEnv("API_TLS_CERT_FILE", "")
+	clientCAFile := help.GetEnv("API_TLS_CLIENT_CA_FILE", "")
+	if certFile != "" {
+		config, err := auth.ServerTLSConfig(certFile, help.GetEnv("API_TLS_KEY_FILE", ""), clientCAFile)
+		if err != nil {
+			return err
+		}
+		serv.EnableTLS(config)
+	}
+	authenticators := auth.Chain{}
+	if clientCAFile != "" {
+		if certFile == "" {
+			return fmt.Errorf("API_TLS_CLIENT_CA_FILE requires API_TLS_CERT_FILE")
+		}
+		authenticators = append(authenticators, auth.MTLSAuthenticator{})
+	}
+	if keysFile := help.GetEnv("API_KEYS_FILE", ""); keysFile != "" {
+		keys, err := auth.LoadAPIKeyAuthenticator(keysFile)
+		if err != nil {
+			return err
+		}
+		authenticators = append(authenticators, keys)
+	}
+	secret := help.GetEnv("API_JWT_HMAC_SECRET", "")
+	publicKeyFile := help.GetEnv("API_JWT_PUBLIC_KEY_FILE", "")
+	if secret != "" || publicKeyFile != "" {
+		jwt, err := auth.NewJWTAuthenticator(auth.JWTConfig{
+			HMACSecret:       []byte(secret),
+			RSAPublicKeyFile: publicKeyFile,
+			Issuer:           help.GetEnv("API_JWT_ISSUER", ""),
+			Audience:         help.GetEnv("API_JWT_AUDIENCE", ""),
+			UserClaim:        help.GetEnv("API_JWT_USER_CLAIM", ""),
+		})
+		if err != nil {
+			return err
+		}
+		authenticators = append(authenticators, jwt)
+	}
+	if len(authenticators) == 0 {
+		logger.Warn("No authentication configured; the API accepts unauthenticated calls")
+		return nil
+	}
+	var authorizer auth.Authorizer = auth.AllowAll{}
+	if policyFile := help.GetEnv("API_AUTH_POLICY_FILE", ""); policyFile != "" {
+		policy, err := auth.LoadPolicyAuthorizer(policyFile)
+		if err != nil {
+			return err
+		}
+		authorizer = policy
+	} else {
+		logger.Warn("No authorization policy configured; every authenticated user has full access")
+	}
+	serv.EnableAuth(authenticators, authorizer)
+	return nil
+}
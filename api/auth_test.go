@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/featureform/auth"
+	"github.com/featureform/metadata"
+	srv "github.com/featureform/proto"
+	pt "github.com/featureform/provider/provider_type"
+	"go.uber.org/zap/zaptest"
+)
+
+// servingTestServer starts a metadata server with a "public" and a "secret"
+// feature, and returns an API server that reads from it.
+func servingTestServer(t *testing.T, defs ...metadata.ResourceDef) *ApiServer {
+	logger := zaptest.NewLogger(t).Sugar()
+	meta, err := metadata.NewMetadataServer(&metadata.Config{
+		Logger:          logger,
+		StorageProvider: metadata.LocalStorageProvider{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go meta.ServeOnListener(lis)
+	t.Cleanup(func() { meta.Stop() })
+	client, err := metadata.NewClient(lis.Addr().String(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create client: %s", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	all := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{Name: "mockOnline", Type: string(pt.RedisOnline)},
+		metadata.EntityDef{Name: "user"},
+		metadata.SourceDef{
+			Name:       "transactions",
+			Variant:    "default",
+			Owner:      "Featureform",
+			Provider:   "mockOnline",
+			Definition: metadata.PrimaryDataSource{Location: metadata.SQLTable{Name: "transactions"}},
+		},
+	}
+	for _, name := range []string{"public", "secret"} {
+		all = append(all, metadata.FeatureDef{
+			Name:     name,
+			Variant:  "default",
+			Provider: "mockOnline",
+			Entity:   "user",
+			Source:   metadata.NameVariant{Name: "transactions", Variant: "default"},
+			Owner:    "Featureform",
+			Location: metadata.ResourceVariantColumns{Entity: "user", Value: name, TS: "ts"},
+			Mode:     metadata.PRECOMPUTED,
+		})
+	}
+	if err := client.CreateAll(context.Background(), append(all, defs...)); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	serv := &ApiServer{Logger: logger}
+	serv.metadata.client = client
+	return serv
+}
+
+// authorizeRequest checks a request against a policy that lets alice read
+// the "group" feature group, the "public" feature and the given models.
+func authorizeRequest(t *testing.T, serv *ApiServer, msg interface{}, models ...string) error {
+	authorizer, err := auth.NewPolicyAuthorizer(auth.Policy{Rules: []auth.Rule{
+		{Users: []string{"alice"}, Permission: auth.Read, Types: []auth.ResourceType{auth.FeatureGroupResource}, Names: []string{"group"}},
+		{Users: []string{"alice"}, Permission: auth.Read, Types: []auth.ResourceType{auth.ModelResource}, Names: models},
+		{Users: []string{"alice"}, Permission: auth.Read, Types: []auth.ResourceType{auth.FeatureResource}, Names: []string{"public"}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create authorizer: %s", err)
+	}
+	permission, resources, err := serv.requestResources("/featureform.serving.proto.Feature/FeatureServe", msg)
+	if err != nil {
+		t.Fatalf("Failed to get request resources: %s", err)
+	}
+	for _, resource := range resources {
+		if err := authorizer.Authorize(auth.Principal{Name: "alice"}, permission, resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestServeFeatureGroupAuth(t *testing.T) {
+	serv := servingTestServer(t,
+		metadata.FeatureGroupDef{
+			Name:     "group",
+			Variant:  "public",
+			Owner:    "Featureform",
+			Entity:   "user",
+			Features: metadata.NameVariants{{Name: "public", Variant: "default"}},
+		},
+		metadata.FeatureGroupDef{
+			Name:     "group",
+			Variant:  "secret",
+			Owner:    "Featureform",
+			Entity:   "user",
+			Features: metadata.NameVariants{{Name: "public", Variant: "default"}, {Name: "secret", Variant: "default"}},
+		},
+	)
+	readable := &srv.FeatureServeRequest{FeatureGroups: []*srv.FeatureID{{Name: "group", Version: "public"}}}
+	if err := authorizeRequest(t, serv, readable); err != nil {
+		t.Fatalf("Expected a group of readable features to be allowed: %s", err)
+	}
+	// The group is readable, but one of its features isn't.
+	unreadable := &srv.FeatureServeRequest{FeatureGroups: []*srv.FeatureID{{Name: "group", Version: "secret"}}}
+	if err := authorizeRequest(t, serv, unreadable); err == nil {
+		t.Fatalf("Expected a group with an unreadable feature to be denied")
+	}
+}
+
+func TestServeModelVariantAuth(t *testing.T) {
+	trainingSet := func(variant string, features ...string) metadata.ResourceDef {
+		def := metadata.TrainingSetDef{
+			Name:     "training-set",
+			Variant:  variant,
+			Provider: "mockOnline",
+			Label:    metadata.NameVariant{Name: "label", Variant: "default"},
+			Owner:    "Featureform",
+		}
+		for _, feature := range features {
+			def.Features = append(def.Features, metadata.NameVariant{Name: feature, Variant: "default"})
+		}
+		return def
+	}
+	serv := servingTestServer(t,
+		metadata.LabelDef{
+			Name:     "label",
+			Variant:  "default",
+			Type:     "int64",
+			Provider: "mockOnline",
+			Entity:   "user",
+			Source:   metadata.NameVariant{Name: "transactions", Variant: "default"},
+			Owner:    "Featureform",
+			Location: metadata.ResourceVariantColumns{Entity: "user", Value: "label", TS: "ts"},
+		},
+		trainingSet("public", "public"),
+		trainingSet("secret", "public", "secret"),
+		metadata.ModelVariantDef{Name: "model", Variant: "public", TrainingSet: metadata.NameVariant{Name: "training-set", Variant: "public"}},
+		metadata.ModelVariantDef{Name: "model", Variant: "secret", TrainingSet: metadata.NameVariant{Name: "training-set", Variant: "secret"}},
+	)
+	readable := &srv.FeatureServeRequest{Model: &srv.Model{Name: "model", Version: "public"}}
+	if err := authorizeRequest(t, serv, readable, "model"); err != nil {
+		t.Fatalf("Expected a model trained on readable features to be allowed: %s", err)
+	}
+	// The model is readable, but one of the features it was trained with isn't.
+	unreadable := &srv.FeatureServeRequest{Model: &srv.Model{Name: "model", Version: "secret"}}
+	if err := authorizeRequest(t, serv, unreadable, "model"); err == nil {
+		t.Fatalf("Expected a model trained on an unreadable feature to be denied")
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/featureform/logging"
 	"io"
@@ -18,8 +19,10 @@ import (
 
 	"github.com/joho/godotenv"
 
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/featureform/auth"
 	help "github.com/featureform/helpers"
 	"github.com/featureform/metadata"
 	pb "github.com/featureform/metadata/proto"
@@ -35,6 +38,8 @@ type ApiServer struct {
 	listener   net.Listener
 	metadata   MetadataServer
 	online     OnlineServer
+	auth       *auth.Interceptor
+	tlsConfig  *tls.Config
 }
 
 type MetadataServer struct {
@@ -159,6 +164,64 @@ func (serv *MetadataServer) GetFeatureVariants(stream pb.Api_GetFeatureVariantsS
 	}
 }
 
+func (serv *MetadataServer) GetFeatureGroups(stream pb.Api_GetFeatureGroupsServer) error {
+	for {
+		name, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			serv.Logger.Errorf("Failed to read client request: %v", err)
+			return err
+		}
+		proxyStream, err := serv.meta.GetFeatureGroups(stream.Context())
+		if err != nil {
+			return err
+		}
+		sErr := proxyStream.Send(name)
+		if sErr != nil {
+			return sErr
+		}
+		res, err := proxyStream.Recv()
+		if err != nil {
+			return err
+		}
+		sendErr := stream.Send(res)
+		if sendErr != nil {
+			return sendErr
+		}
+	}
+}
+
+func (serv *MetadataServer) GetFeatureGroupVariants(stream pb.Api_GetFeatureGroupVariantsServer) error {
+	for {
+		nameVariant, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			serv.Logger.Errorf("Failed to read client request: %v", err)
+			return err
+		}
+		proxyStream, err := serv.meta.GetFeatureGroupVariants(stream.Context())
+		if err != nil {
+			return err
+		}
+		sErr := proxyStream.Send(nameVariant)
+		if sErr != nil {
+			return sErr
+		}
+		res, err := proxyStream.Recv()
+		if err != nil {
+			return err
+		}
+		sendErr := stream.Send(res)
+		if sendErr != nil {
+			return sendErr
+		}
+	}
+}
+
 func (serv *MetadataServer) GetLabels(stream pb.Api_GetLabelsServer) error {
 	for {
 		name, err := stream.Recv()
@@ -420,7 +483,36 @@ func (serv *MetadataServer) GetModels(stream pb.Api_GetModelsServer) error {
 	}
 }
 
-func (serv *MetadataServer) ListUsers(in *pb.Empty, stream pb.Api_ListUsersServer) error {
+func (serv *MetadataServer) GetModelVariants(stream pb.Api_GetModelVariantsServer) error {
+	for {
+		nameVariant, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			serv.Logger.Errorf("Failed to read client request: %v", err)
+			return err
+		}
+		proxyStream, err := serv.meta.GetModelVariants(stream.Context())
+		if err != nil {
+			return err
+		}
+		sErr := proxyStream.Send(nameVariant)
+		if sErr != nil {
+			return sErr
+		}
+		res, err := proxyStream.Recv()
+		if err != nil {
+			return err
+		}
+		sendErr := stream.Send(res)
+		if sendErr != nil {
+			return sendErr
+		}
+	}
+}
+
+func (serv *MetadataServer) ListUsers(in *pb.ListRequest, stream pb.Api_ListUsersServer) error {
 	proxyStream, err := serv.meta.ListUsers(stream.Context(), in)
 	if err != nil {
 		return err
@@ -428,6 +520,8 @@ func (serv *MetadataServer) ListUsers(in *pb.Empty, stream pb.Api_ListUsersServe
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -440,7 +534,7 @@ func (serv *MetadataServer) ListUsers(in *pb.Empty, stream pb.Api_ListUsersServe
 	}
 }
 
-func (serv *MetadataServer) ListFeatures(in *pb.Empty, stream pb.Api_ListFeaturesServer) error {
+func (serv *MetadataServer) ListFeatures(in *pb.ListRequest, stream pb.Api_ListFeaturesServer) error {
 	proxyStream, err := serv.meta.ListFeatures(stream.Context(), in)
 	if err != nil {
 		return err
@@ -448,6 +542,8 @@ func (serv *MetadataServer) ListFeatures(in *pb.Empty, stream pb.Api_ListFeature
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -460,7 +556,29 @@ func (serv *MetadataServer) ListFeatures(in *pb.Empty, stream pb.Api_ListFeature
 	}
 }
 
-func (serv *MetadataServer) ListLabels(in *pb.Empty, stream pb.Api_ListLabelsServer) error {
+func (serv *MetadataServer) ListFeatureGroups(in *pb.ListRequest, stream pb.Api_ListFeatureGroupsServer) error {
+	proxyStream, err := serv.meta.ListFeatureGroups(stream.Context(), in)
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := proxyStream.Recv()
+		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		sendErr := stream.Send(res)
+		if sendErr != nil {
+			return sendErr
+		}
+	}
+}
+
+func (serv *MetadataServer) ListLabels(in *pb.ListRequest, stream pb.Api_ListLabelsServer) error {
 	proxyStream, err := serv.meta.ListLabels(stream.Context(), in)
 	if err != nil {
 		return err
@@ -468,6 +586,8 @@ func (serv *MetadataServer) ListLabels(in *pb.Empty, stream pb.Api_ListLabelsSer
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -480,7 +600,7 @@ func (serv *MetadataServer) ListLabels(in *pb.Empty, stream pb.Api_ListLabelsSer
 	}
 }
 
-func (serv *MetadataServer) ListSources(in *pb.Empty, stream pb.Api_ListSourcesServer) error {
+func (serv *MetadataServer) ListSources(in *pb.ListRequest, stream pb.Api_ListSourcesServer) error {
 	proxyStream, err := serv.meta.ListSources(stream.Context(), in)
 	if err != nil {
 		return err
@@ -488,6 +608,8 @@ func (serv *MetadataServer) ListSources(in *pb.Empty, stream pb.Api_ListSourcesS
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -500,7 +622,7 @@ func (serv *MetadataServer) ListSources(in *pb.Empty, stream pb.Api_ListSourcesS
 	}
 }
 
-func (serv *MetadataServer) ListTrainingSets(in *pb.Empty, stream pb.Api_ListTrainingSetsServer) error {
+func (serv *MetadataServer) ListTrainingSets(in *pb.ListRequest, stream pb.Api_ListTrainingSetsServer) error {
 	proxyStream, err := serv.meta.ListTrainingSets(stream.Context(), in)
 	if err != nil {
 		return err
@@ -508,6 +630,8 @@ func (serv *MetadataServer) ListTrainingSets(in *pb.Empty, stream pb.Api_ListTra
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -520,7 +644,7 @@ func (serv *MetadataServer) ListTrainingSets(in *pb.Empty, stream pb.Api_ListTra
 	}
 }
 
-func (serv *MetadataServer) ListModels(in *pb.Empty, stream pb.Api_ListModelsServer) error {
+func (serv *MetadataServer) ListModels(in *pb.ListRequest, stream pb.Api_ListModelsServer) error {
 	proxyStream, err := serv.meta.ListModels(stream.Context(), in)
 	if err != nil {
 		return err
@@ -528,6 +652,8 @@ func (serv *MetadataServer) ListModels(in *pb.Empty, stream pb.Api_ListModelsSer
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -540,7 +666,7 @@ func (serv *MetadataServer) ListModels(in *pb.Empty, stream pb.Api_ListModelsSer
 	}
 }
 
-func (serv *MetadataServer) ListEntities(in *pb.Empty, stream pb.Api_ListEntitiesServer) error {
+func (serv *MetadataServer) ListEntities(in *pb.ListRequest, stream pb.Api_ListEntitiesServer) error {
 	proxyStream, err := serv.meta.ListEntities(stream.Context(), in)
 	if err != nil {
 		return err
@@ -548,6 +674,8 @@ func (serv *MetadataServer) ListEntities(in *pb.Empty, stream pb.Api_ListEntitie
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -560,7 +688,7 @@ func (serv *MetadataServer) ListEntities(in *pb.Empty, stream pb.Api_ListEntitie
 	}
 }
 
-func (serv *MetadataServer) ListProviders(in *pb.Empty, stream pb.Api_ListProvidersServer) error {
+func (serv *MetadataServer) ListProviders(in *pb.ListRequest, stream pb.Api_ListProvidersServer) error {
 	proxyStream, err := serv.meta.ListProviders(stream.Context(), in)
 	if err != nil {
 		return err
@@ -568,6 +696,8 @@ func (serv *MetadataServer) ListProviders(in *pb.Empty, stream pb.Api_ListProvid
 	for {
 		res, err := proxyStream.Recv()
 		if err == io.EOF {
+			// The next page token is a trailer.
+			stream.SetTrailer(proxyStream.Trailer())
 			return nil
 		}
 		if err != nil {
@@ -587,6 +717,14 @@ func (serv *MetadataServer) CreateProvider(ctx context.Context, provider *pb.Pro
 
 func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, source *pb.SourceVariant) (*pb.Empty, error) {
 	serv.Logger.Infow("Creating Source Variant", "name", source.Name, "variant", source.Variant)
+	claimOwnership(ctx, &source.Owner)
+	serv.setSQLTransformationSources(source)
+	return serv.meta.CreateSourceVariant(ctx, source)
+}
+
+// setSQLTransformationSources fills in the sources of a SQL transformation
+// from the {{ name.variant }} references in its query.
+func (serv *MetadataServer) setSQLTransformationSources(source *pb.SourceVariant) {
 	switch casted := source.Definition.(type) {
 	case *pb.SourceVariant_Transformation:
 		switch transformationType := casted.Transformation.Type.(type) {
@@ -607,7 +745,6 @@ func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, source *pb.
 			source.Definition.(*pb.SourceVariant_Transformation).Transformation.Type.(*pb.Transformation_SQLTransformation).SQLTransformation.Source = sources
 		}
 	}
-	return serv.meta.CreateSourceVariant(ctx, source)
 }
 
 func (serv *MetadataServer) CreateEntity(ctx context.Context, entity *pb.Entity) (*pb.Empty, error) {
@@ -620,16 +757,143 @@ func (serv *MetadataServer) RequestScheduleChange(ctx context.Context, req *pb.S
 	return serv.meta.RequestScheduleChange(ctx, req)
 }
 
+func (serv *MetadataServer) DeleteResource(ctx context.Context, req *pb.DeleteResourceRequest) (*pb.DeleteResourceResponse, error) {
+	serv.Logger.Infow("Deleting Resource", "resource", req.ResourceId, "force", req.Force, "cleanup", req.Cleanup)
+	return serv.meta.DeleteResource(ctx, req)
+}
+
+func (serv *MetadataServer) ArchiveResource(ctx context.Context, req *pb.ArchiveResourceRequest) (*pb.ArchiveResourceResponse, error) {
+	serv.Logger.Infow("Archiving Resource", "resource", req.ResourceId, "force", req.Force)
+	return serv.meta.ArchiveResource(ctx, req)
+}
+
+func (serv *MetadataServer) SetLifecycle(ctx context.Context, req *pb.SetLifecycleRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Setting Lifecycle", "resource", req.ResourceId, "lifecycle", req.Lifecycle)
+	return serv.meta.SetLifecycle(ctx, req)
+}
+
+func (serv *MetadataServer) SetTags(ctx context.Context, req *pb.SetTagsRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Setting Tags", "resource", req.ResourceId, "tags", req.Tags)
+	return serv.meta.SetTags(ctx, req)
+}
+
+func (serv *MetadataServer) CheckIntegrity(ctx context.Context, req *pb.IntegrityRequest) (*pb.IntegrityReport, error) {
+	serv.Logger.Infow("Checking Integrity", "repair", req.Repair, "check_jobs", req.CheckJobs)
+	return serv.meta.CheckIntegrity(ctx, req)
+}
+
+func (serv *MetadataServer) SetNotifications(ctx context.Context, req *pb.NotificationsRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Setting Notifications", "user", req.User)
+	return serv.meta.SetNotifications(ctx, req)
+}
+
+func (serv *MetadataServer) QueryResources(ctx context.Context, req *pb.ResourceQuery) (*pb.ResourceQueryResult, error) {
+	serv.Logger.Infow("Querying Resources", "query", req.Query)
+	return serv.meta.QueryResources(ctx, req)
+}
+
+func (serv *MetadataServer) GetResourceHistory(req *pb.ResourceID, stream pb.Api_GetResourceHistoryServer) error {
+	serv.Logger.Infow("Getting Resource History", "resource", req)
+	proxyStream, err := serv.meta.GetResourceHistory(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := proxyStream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+}
+
+func (serv *MetadataServer) ListAuditEvents(req *pb.AuditEventsRequest, stream pb.Api_ListAuditEventsServer) error {
+	serv.Logger.Infow("Listing Audit Events", "start", req.Start, "end", req.End)
+	proxyStream, err := serv.meta.ListAuditEvents(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := proxyStream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+}
+
+func (serv *MetadataServer) Watch(req *pb.WatchRequest, stream pb.Api_WatchServer) error {
+	serv.Logger.Infow("Watching Resources", "types", req.ResourceTypes, "names", req.Names, "start_revision", req.StartRevision)
+	proxyStream, err := serv.meta.Watch(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := proxyStream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+}
+
+func (serv *MetadataServer) Plan(ctx context.Context, req *pb.PlanRequest) (*pb.PlanResponse, error) {
+	serv.Logger.Infow("Planning Resources", "count", len(req.Definitions))
+	// Fill in the same fields as the create calls would.
+	for _, def := range req.Definitions {
+		switch casted := def.Resource.(type) {
+		case *pb.ResourceDefinition_SourceVariant:
+			claimOwnership(ctx, &casted.SourceVariant.Owner)
+			serv.setSQLTransformationSources(casted.SourceVariant)
+		case *pb.ResourceDefinition_FeatureVariant:
+			claimOwnership(ctx, &casted.FeatureVariant.Owner)
+		case *pb.ResourceDefinition_LabelVariant:
+			claimOwnership(ctx, &casted.LabelVariant.Owner)
+		case *pb.ResourceDefinition_TrainingSetVariant:
+			claimOwnership(ctx, &casted.TrainingSetVariant.Owner)
+		}
+	}
+	return serv.meta.Plan(ctx, req)
+}
+
+func (serv *MetadataServer) GetLineage(ctx context.Context, req *pb.LineageRequest) (*pb.Lineage, error) {
+	serv.Logger.Infow("Getting Lineage", "resource", req.ResourceId, "direction", req.Direction, "depth", req.Depth)
+	return serv.meta.GetLineage(ctx, req)
+}
+
 func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, feature *pb.FeatureVariant) (*pb.Empty, error) {
 	serv.Logger.Infow("Creating Feature Variant", "name", feature.Name, "variant", feature.Variant)
+	claimOwnership(ctx, &feature.Owner)
 	return serv.meta.CreateFeatureVariant(ctx, feature)
 }
 
+func (serv *MetadataServer) CreateFeatureGroupVariant(ctx context.Context, group *pb.FeatureGroupVariant) (*pb.Empty, error) {
+	serv.Logger.Infow("Creating Feature Group Variant", "name", group.Name, "variant", group.Variant)
+	claimOwnership(ctx, &group.Owner)
+	return serv.meta.CreateFeatureGroupVariant(ctx, group)
+}
+
 func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, label *pb.LabelVariant) (*pb.Empty, error) {
 	serv.Logger.Infow("Creating Label Variant", "name", label.Name, "variant", label.Variant)
+	claimOwnership(ctx, &label.Owner)
 	protoSource := label.Source
 	serv.Logger.Debugw("Finding label source", "name", protoSource.Name, "variant", protoSource.Variant)
-	source, err := serv.client.GetSourceVariant(ctx, metadata.NameVariant{protoSource.Name, protoSource.Variant})
+	source, err := serv.client.GetSourceVariant(ctx, metadata.NameVariant{Name: protoSource.Name, Variant: protoSource.Variant, Namespace: label.Namespace})
 	if err != nil {
 		serv.Logger.Errorw("Could not create label source variant", "error", err)
 		return nil, err
@@ -645,13 +909,20 @@ func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, label *pb.La
 
 func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, train *pb.TrainingSetVariant) (*pb.Empty, error) {
 	serv.Logger.Infow("Creating Training Set Variant", "name", train.Name, "variant", train.Variant)
+	claimOwnership(ctx, &train.Owner)
 	protoLabel := train.Label
-	label, err := serv.client.GetLabelVariant(ctx, metadata.NameVariant{protoLabel.Name, protoLabel.Variant})
+	label, err := serv.client.GetLabelVariant(ctx, metadata.NameVariant{Name: protoLabel.Name, Variant: protoLabel.Variant, Namespace: train.Namespace})
 	if err != nil {
 		return nil, err
 	}
 	for _, protoFeature := range train.Features {
-		_, err := serv.client.GetFeatureVariant(ctx, metadata.NameVariant{protoFeature.Name, protoFeature.Variant})
+		_, err := serv.client.GetFeatureVariant(ctx, metadata.NameVariant{Name: protoFeature.Name, Variant: protoFeature.Variant, Namespace: train.Namespace})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, protoGroup := range train.FeatureGroups {
+		_, err := serv.client.GetFeatureGroupVariant(ctx, metadata.NameVariant{Name: protoGroup.Name, Variant: protoGroup.Variant, Namespace: train.Namespace})
 		if err != nil {
 			return nil, err
 		}
@@ -665,11 +936,22 @@ func (serv *MetadataServer) CreateModel(ctx context.Context, model *pb.Model) (*
 	return serv.meta.CreateModel(ctx, model)
 }
 
+func (serv *MetadataServer) CreateModelVariant(ctx context.Context, model *pb.ModelVariant) (*pb.Empty, error) {
+	serv.Logger.Infow("Creating Model Variant", "name", model.Name, "variant", model.Variant)
+	claimOwnership(ctx, &model.Owner)
+	return serv.meta.CreateModelVariant(ctx, model)
+}
+
 func (serv *OnlineServer) FeatureServe(ctx context.Context, req *srv.FeatureServeRequest) (*srv.FeatureRow, error) {
 	serv.Logger.Infow("Serving Features", "request", req.String())
 	return serv.client.FeatureServe(ctx, req)
 }
 
+func (serv *OnlineServer) FeatureServeAsOf(ctx context.Context, req *srv.FeatureServeAsOfRequest) (*srv.FeatureRow, error) {
+	serv.Logger.Infow("Serving Features As Of", "request", req.String())
+	return serv.client.FeatureServeAsOf(ctx, req)
+}
+
 func (serv *OnlineServer) TrainingData(req *srv.TrainingDataRequest, stream srv.Feature_TrainingDataServer) error {
 	serv.Logger.Infow("Serving Training Data", "id", req.Id.String())
 	client, err := serv.client.TrainingData(context.Background(), req)
@@ -741,7 +1023,11 @@ func (serv *ApiServer) Serve() error {
 	opts := []grpc.DialOption{
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 	}
-	metaConn, err := grpc.Dial(serv.metadata.address, opts...)
+	metaOpts := append(opts,
+		grpc.WithChainUnaryInterceptor(forwardPrincipalUnary),
+		grpc.WithChainStreamInterceptor(forwardPrincipalStream),
+	)
+	metaConn, err := grpc.Dial(serv.metadata.address, metaOpts...)
 	if err != nil {
 		return fmt.Errorf("metdata connection: %w", err)
 	}
@@ -775,10 +1061,20 @@ func (serv *ApiServer) ServeOnListener(lis net.Listener) error {
 		grpc_logrus.WithLevels(customFunc),
 	}
 	grpc_logrus.ReplaceGrpcLogger(logrusEntry)
+	unary := []grpc.UnaryServerInterceptor{
+		grpc_logrus.UnaryServerInterceptor(logrusEntry, lorgusOpts...),
+	}
+	stream := []grpc.StreamServerInterceptor{}
+	if serv.auth != nil {
+		unary = append(unary, serv.auth.Unary())
+		stream = append(stream, serv.auth.Stream())
+	}
 	opt := []grpc.ServerOption{
-		grpc_middleware.WithUnaryServerChain(
-			grpc_logrus.UnaryServerInterceptor(logrusEntry, lorgusOpts...),
-		),
+		grpc_middleware.WithUnaryServerChain(unary...),
+		grpc_middleware.WithStreamServerChain(stream...),
+	}
+	if serv.tlsConfig != nil {
+		opt = append(opt, grpc.Creds(credentials.NewTLS(serv.tlsConfig)))
 	}
 	grpcServer := grpc.NewServer(opt...)
 	reflection.Register(grpcServer)
@@ -868,5 +1164,9 @@ func main() {
 		fmt.Println(err)
 		return
 	}
+	if err := configureAuth(serv, logger); err != nil {
+		fmt.Println(err)
+		return
+	}
 	fmt.Println(serv.Serve())
 }
@@ -0,0 +1,66 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const APIKeyHeader = "x-api-key"
+
+// APIKeyAuthenticator authenticates requests by a static key sent in the
+// x-api-key metadata.
+type APIKeyAuthenticator struct {
+	// Keys are stored hashed so they aren't kept in memory in plain text.
+	users map[[sha256.Size]byte]string
+}
+
+// NewAPIKeyAuthenticator creates an authenticator from a map of API keys to
+// the names of the users they belong to.
+func NewAPIKeyAuthenticator(keys map[string]string) (*APIKeyAuthenticator, error) {
+	users := make(map[[sha256.Size]byte]string, len(keys))
+	for key, user := range keys {
+		if key == "" || user == "" {
+			return nil, fmt.Errorf("API keys and their users cannot be empty")
+		}
+		users[sha256.Sum256([]byte(key))] = user
+	}
+	return &APIKeyAuthenticator{users: users}, nil
+}
+
+// LoadAPIKeyAuthenticator reads a JSON object of API keys to user names.
+func LoadAPIKeyAuthenticator(filename string) (*APIKeyAuthenticator, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read API keys: %w", err)
+	}
+	keys := make(map[string]string)
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, fmt.Errorf("parse API keys: %w", err)
+	}
+	return NewAPIKeyAuthenticator(keys)
+}
+
+func (auth *APIKeyAuthenticator) Authenticate(ctx context.Context) (Principal, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Principal{}, ErrNoCredentials
+	}
+	values := md.Get(APIKeyHeader)
+	if len(values) == 0 {
+		return Principal{}, ErrNoCredentials
+	}
+	user, has := auth.users[sha256.Sum256([]byte(values[0]))]
+	if !has {
+		return Principal{}, fmt.Errorf("invalid API key")
+	}
+	return Principal{Name: user, Method: "api_key"}, nil
+}
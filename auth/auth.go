@@ -0,0 +1,78 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package auth authenticates gRPC callers and authorizes their access to
+// Featureform resources.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Principal is an authenticated caller. Its Name is the name of the
+// Featureform user it acts as.
+type Principal struct {
+	Name string
+	// Method is the authenticator that identified the principal.
+	Method string
+}
+
+// ErrNoCredentials is returned by an Authenticator when the request doesn't
+// carry the kind of credentials it checks, as opposed to carrying invalid
+// ones.
+var ErrNoCredentials = errors.New("no credentials provided")
+
+type Authenticator interface {
+	Authenticate(ctx context.Context) (Principal, error)
+}
+
+// Chain tries each authenticator in order until one finds credentials on the
+// request. Invalid credentials fail immediately rather than falling through.
+type Chain []Authenticator
+
+func (chain Chain) Authenticate(ctx context.Context) (Principal, error) {
+	for _, authenticator := range chain {
+		principal, err := authenticator.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return Principal{}, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of an authenticated request.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// bearerToken returns the token in a request's "authorization: Bearer"
+// metadata.
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ErrNoCredentials
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", ErrNoCredentials
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return "", fmt.Errorf("unsupported authorization scheme")
+	}
+	return strings.TrimSpace(token), nil
+}
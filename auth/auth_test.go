@@ -0,0 +1,193 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func incomingContext(kv ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator(map[string]string{"secret": "alice"})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %s", err)
+	}
+	principal, err := authenticator.Authenticate(incomingContext(APIKeyHeader, "secret"))
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %s", err)
+	}
+	if principal.Name != "alice" {
+		t.Fatalf("Wrong principal: %v", principal)
+	}
+	if _, err := authenticator.Authenticate(incomingContext(APIKeyHeader, "wrong")); err == nil || errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("Expected invalid key error, got %v", err)
+	}
+	if _, err := authenticator.Authenticate(incomingContext()); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("Expected no credentials error, got %v", err)
+	}
+}
+
+func signedToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %s", err)
+	}
+	return token
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	authenticator, err := NewJWTAuthenticator(JWTConfig{HMACSecret: []byte("secret"), Issuer: "featureform"})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %s", err)
+	}
+	valid := signedToken(t, "secret", jwt.MapClaims{
+		"sub": "bob",
+		"iss": "featureform",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	principal, err := authenticator.Authenticate(incomingContext("authorization", "Bearer "+valid))
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %s", err)
+	}
+	if principal.Name != "bob" {
+		t.Fatalf("Wrong principal: %v", principal)
+	}
+	invalid := map[string]string{
+		"WrongSecret": signedToken(t, "other", jwt.MapClaims{"sub": "bob", "iss": "featureform"}),
+		"WrongIssuer": signedToken(t, "secret", jwt.MapClaims{"sub": "bob", "iss": "other"}),
+		"Expired":     signedToken(t, "secret", jwt.MapClaims{"sub": "bob", "iss": "featureform", "exp": time.Now().Add(-time.Hour).Unix()}),
+		"NoSubject":   signedToken(t, "secret", jwt.MapClaims{"iss": "featureform"}),
+	}
+	for name, token := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := authenticator.Authenticate(incomingContext("authorization", "Bearer "+token)); err == nil {
+				t.Fatalf("Authenticated invalid token")
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	keys, err := NewAPIKeyAuthenticator(map[string]string{"secret": "alice"})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %s", err)
+	}
+	jwtAuth, err := NewJWTAuthenticator(JWTConfig{HMACSecret: []byte("secret")})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %s", err)
+	}
+	chain := Chain{MTLSAuthenticator{}, keys, jwtAuth}
+	token := signedToken(t, "secret", jwt.MapClaims{"sub": "bob"})
+	principal, err := chain.Authenticate(incomingContext("authorization", "Bearer "+token))
+	if err != nil {
+		t.Fatalf("Failed to authenticate: %s", err)
+	}
+	if principal.Name != "bob" || principal.Method != "jwt" {
+		t.Fatalf("Wrong principal: %v", principal)
+	}
+	if _, err := chain.Authenticate(incomingContext()); !errors.Is(err, ErrNoCredentials) {
+		t.Fatalf("Expected no credentials error, got %v", err)
+	}
+}
+
+func TestPolicyAuthorizer(t *testing.T) {
+	authorizer, err := NewPolicyAuthorizer(Policy{
+		Admins: []string{"admin"},
+		Rules: []Rule{
+			{Users: []string{"*"}, Permission: Read, Types: []ResourceType{FeatureResource}, Names: []string{"*"}},
+			{Users: []string{"alice"}, Permission: Write, Types: []ResourceType{FeatureResource, SourceResource}, Names: []string{"alice_*"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create authorizer: %s", err)
+	}
+	type AuthorizeTest struct {
+		User       string
+		Permission Permission
+		Resource   Resource
+		Allowed    bool
+	}
+	tests := map[string]AuthorizeTest{
+		"ReadWildcard":     {"bob", Read, Resource{FeatureResource, "f1"}, true},
+		"ListWildcard":     {"bob", Read, Resource{FeatureResource, ""}, true},
+		"WriteDenied":      {"bob", Write, Resource{FeatureResource, "f1"}, false},
+		"OtherType":        {"bob", Read, Resource{SourceResource, "s1"}, false},
+		"WritePattern":     {"alice", Write, Resource{SourceResource, "alice_src"}, true},
+		"WriteImpliesRead": {"alice", Read, Resource{SourceResource, "alice_src"}, true},
+		"PatternNoList":    {"alice", Read, Resource{SourceResource, ""}, false},
+		"WriteOutside":     {"alice", Write, Resource{SourceResource, "src"}, false},
+		"Admin":            {"admin", Write, Resource{ProviderResource, "p"}, true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := authorizer.Authorize(Principal{Name: test.User}, test.Permission, test.Resource)
+			if test.Allowed && err != nil {
+				t.Fatalf("Expected access: %s", err)
+			}
+			if !test.Allowed && err == nil {
+				t.Fatalf("Expected access to be denied")
+			}
+		})
+	}
+	if _, err := NewPolicyAuthorizer(Policy{Rules: []Rule{{Permission: "admin"}}}); err == nil {
+		t.Fatalf("Created authorizer with an unknown permission")
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	keys, err := NewAPIKeyAuthenticator(map[string]string{"secret": "alice"})
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %s", err)
+	}
+	authorizer, err := NewPolicyAuthorizer(Policy{Rules: []Rule{
+		{Users: []string{"alice"}, Permission: Read, Types: []ResourceType{FeatureResource}, Names: []string{"f1"}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to create authorizer: %s", err)
+	}
+	interceptor := &Interceptor{
+		Authenticator: keys,
+		Authorizer:    authorizer,
+		Policy: func(method string, msg interface{}) (Permission, []Resource, error) {
+			return Read, []Resource{{Type: FeatureResource, Name: msg.(string)}}, nil
+		},
+		Logger: zap.NewNop().Sugar(),
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal, ok := PrincipalFromContext(ctx)
+		if !ok {
+			return nil, errors.New("no principal")
+		}
+		return principal.Name, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test/Get"}
+	unary := interceptor.Unary()
+	resp, err := unary(incomingContext(APIKeyHeader, "secret"), "f1", info, handler)
+	if err != nil {
+		t.Fatalf("Failed to call handler: %s", err)
+	}
+	if resp != "alice" {
+		t.Fatalf("Wrong principal in handler: %v", resp)
+	}
+	if _, err := unary(incomingContext(APIKeyHeader, "secret"), "f2", info, handler); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected permission denied, got %v", err)
+	}
+	if _, err := unary(incomingContext(), "f1", info, handler); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected unauthenticated, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+)
+
+type Permission string
+
+const (
+	Read Permission = "read"
+	// Write implies Read.
+	Write Permission = "write"
+)
+
+type ResourceType string
+
+const (
+	UserResource         ResourceType = "user"
+	ProviderResource     ResourceType = "provider"
+	SourceResource       ResourceType = "source"
+	EntityResource       ResourceType = "entity"
+	FeatureResource      ResourceType = "feature"
+	LabelResource        ResourceType = "label"
+	TrainingSetResource  ResourceType = "training_set"
+	ModelResource        ResourceType = "model"
+	FeatureGroupResource ResourceType = "feature_group"
+)
+
+// Resource is something a request reads or writes. Permissions apply to a
+// resource's name and so cover all of its variants. An empty Name refers to
+// every resource of the type, as when listing them.
+type Resource struct {
+	Type ResourceType
+	Name string
+}
+
+func (res Resource) String() string {
+	if res.Name == "" {
+		return fmt.Sprintf("all %s resources", res.Type)
+	}
+	return fmt.Sprintf("%s %s", res.Type, res.Name)
+}
+
+type Authorizer interface {
+	Authorize(principal Principal, permission Permission, resource Resource) error
+}
+
+// AllowAll authorizes every authenticated principal for everything.
+type AllowAll struct{}
+
+func (AllowAll) Authorize(Principal, Permission, Resource) error {
+	return nil
+}
+
+// Rule grants users a permission on resources. Users, Types and Names may
+// contain "*", and Names are matched as path.Match patterns.
+type Rule struct {
+	Users      []string       `json:"users"`
+	Permission Permission     `json:"permission"`
+	Types      []ResourceType `json:"types"`
+	Names      []string       `json:"names"`
+}
+
+func (rule Rule) check() error {
+	if rule.Permission != Read && rule.Permission != Write {
+		return fmt.Errorf("unknown permission %q", rule.Permission)
+	}
+	for _, name := range rule.Names {
+		if _, err := path.Match(name, ""); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (rule Rule) allows(principal Principal, permission Permission, resource Resource) bool {
+	if permission == Write && rule.Permission != Write {
+		return false
+	}
+	if !rule.matchesUser(principal.Name) || !rule.matchesType(resource.Type) {
+		return false
+	}
+	for _, name := range rule.Names {
+		if name == "*" {
+			return true
+		}
+		// Only a wildcard rule covers every resource of a type.
+		if resource.Name == "" {
+			continue
+		}
+		if matched, _ := path.Match(name, resource.Name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule Rule) matchesUser(name string) bool {
+	for _, user := range rule.Users {
+		if user == "*" || user == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule Rule) matchesType(resType ResourceType) bool {
+	for _, t := range rule.Types {
+		if t == "*" || t == resType {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy is a set of rules, plus admins who may do anything.
+type Policy struct {
+	Admins []string `json:"admins"`
+	Rules  []Rule   `json:"rules"`
+}
+
+// PolicyAuthorizer denies anything that its policy doesn't allow.
+type PolicyAuthorizer struct {
+	policy Policy
+	admins map[string]struct{}
+}
+
+func NewPolicyAuthorizer(policy Policy) (*PolicyAuthorizer, error) {
+	for i, rule := range policy.Rules {
+		if err := rule.check(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	admins := make(map[string]struct{}, len(policy.Admins))
+	for _, admin := range policy.Admins {
+		admins[admin] = struct{}{}
+	}
+	return &PolicyAuthorizer{policy: policy, admins: admins}, nil
+}
+
+// LoadPolicyAuthorizer reads a Policy from a JSON file.
+func LoadPolicyAuthorizer(filename string) (*PolicyAuthorizer, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read policy: %w", err)
+	}
+	policy := Policy{}
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return nil, fmt.Errorf("parse policy: %w", err)
+	}
+	return NewPolicyAuthorizer(policy)
+}
+
+func (auth *PolicyAuthorizer) Authorize(principal Principal, permission Permission, resource Resource) error {
+	if _, isAdmin := auth.admins[principal.Name]; isAdmin {
+		return nil
+	}
+	for _, rule := range auth.policy.Rules {
+		if rule.allows(principal, permission, resource) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not have %s permission on %s", principal.Name, permission, resource)
+}
@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestPolicy returns the permission and resources that a request message
+// to a method needs. Returning no resources only requires the caller to be
+// authenticated.
+type RequestPolicy func(fullMethod string, msg interface{}) (Permission, []Resource, error)
+
+// Interceptor authenticates every call and authorizes every request message,
+// including each message a client sends on a stream.
+type Interceptor struct {
+	Authenticator Authenticator
+	Authorizer    Authorizer
+	Policy        RequestPolicy
+	Logger        *zap.SugaredLogger
+}
+
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		principal, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		if err := i.authorize(principal, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(WithPrincipal(ctx, principal), req)
+	}
+}
+
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		principal, err := i.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{
+			ServerStream: stream,
+			ctx:          WithPrincipal(stream.Context(), principal),
+			interceptor:  i,
+			principal:    principal,
+			method:       info.FullMethod,
+		})
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context, method string) (Principal, error) {
+	principal, err := i.Authenticator.Authenticate(ctx)
+	if err != nil {
+		i.Logger.Infow("Unauthenticated request", "method", method, "error", err)
+		return Principal{}, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+	return principal, nil
+}
+
+func (i *Interceptor) authorize(principal Principal, method string, msg interface{}) error {
+	permission, resources, err := i.Policy(method, msg)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	for _, resource := range resources {
+		if err := i.Authorizer.Authorize(principal, permission, resource); err != nil {
+			i.Logger.Infow("Unauthorized request", "method", method, "user", principal.Name, "error", err)
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+	return nil
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx         context.Context
+	interceptor *Interceptor
+	principal   Principal
+	method      string
+}
+
+func (stream *authorizedStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *authorizedStream) RecvMsg(msg interface{}) error {
+	if err := stream.ServerStream.RecvMsg(msg); err != nil {
+		return err
+	}
+	return stream.interceptor.authorize(stream.principal, stream.method, msg)
+}
@@ -0,0 +1,93 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const defaultJWTUserClaim = "sub"
+
+type JWTConfig struct {
+	// Exactly one of HMACSecret and RSAPublicKeyFile must be set.
+	HMACSecret       []byte
+	RSAPublicKeyFile string
+	// Issuer and Audience are only checked if set.
+	Issuer   string
+	Audience string
+	// UserClaim is the claim holding the user's name; it defaults to "sub".
+	UserClaim string
+}
+
+// JWTAuthenticator authenticates requests by a signed JWT sent as an
+// "authorization: Bearer" token.
+type JWTAuthenticator struct {
+	key       interface{}
+	methods   []string
+	issuer    string
+	audience  string
+	userClaim string
+}
+
+func NewJWTAuthenticator(config JWTConfig) (*JWTAuthenticator, error) {
+	auth := &JWTAuthenticator{
+		issuer:    config.Issuer,
+		audience:  config.Audience,
+		userClaim: config.UserClaim,
+	}
+	if auth.userClaim == "" {
+		auth.userClaim = defaultJWTUserClaim
+	}
+	switch {
+	case len(config.HMACSecret) > 0 && config.RSAPublicKeyFile != "":
+		return nil, fmt.Errorf("only one of an HMAC secret and an RSA public key can be set")
+	case len(config.HMACSecret) > 0:
+		auth.key = config.HMACSecret
+		auth.methods = []string{"HS256", "HS384", "HS512"}
+	case config.RSAPublicKeyFile != "":
+		pem, err := os.ReadFile(config.RSAPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read JWT public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT public key: %w", err)
+		}
+		auth.key = key
+		auth.methods = []string{"RS256", "RS384", "RS512"}
+	default:
+		return nil, fmt.Errorf("an HMAC secret or an RSA public key is required")
+	}
+	return auth, nil
+}
+
+func (auth *JWTAuthenticator) Authenticate(ctx context.Context) (Principal, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return Principal{}, err
+	}
+	claims := jwt.MapClaims{}
+	keyFunc := func(*jwt.Token) (interface{}, error) {
+		return auth.key, nil
+	}
+	if _, err := jwt.ParseWithClaims(token, claims, keyFunc, jwt.WithValidMethods(auth.methods)); err != nil {
+		return Principal{}, fmt.Errorf("invalid token: %w", err)
+	}
+	if auth.issuer != "" && !claims.VerifyIssuer(auth.issuer, true) {
+		return Principal{}, fmt.Errorf("invalid token: wrong issuer")
+	}
+	if auth.audience != "" && !claims.VerifyAudience(auth.audience, true) {
+		return Principal{}, fmt.Errorf("invalid token: wrong audience")
+	}
+	name, ok := claims[auth.userClaim].(string)
+	if !ok || name == "" {
+		return Principal{}, fmt.Errorf("invalid token: missing %s claim", auth.userClaim)
+	}
+	return Principal{Name: name, Method: "jwt"}, nil
+}
@@ -12,7 +12,7 @@ def list_name(stub, resource_type):
     res = sorted(
         [
             received
-            for received in stub_list_functions[resource_type](metadata_pb2.Empty())
+            for received in stub_list_functions[resource_type](metadata_pb2.ListRequest())
         ],
         key=lambda x: x.name,
     )
@@ -27,7 +27,7 @@ def list_name_status(stub, resource_type):
     res = sorted(
         [
             received
-            for received in stub_list_functions[resource_type](metadata_pb2.Empty())
+            for received in stub_list_functions[resource_type](metadata_pb2.ListRequest())
         ],
         key=lambda x: x.name,
     )
@@ -42,7 +42,7 @@ def list_name_status_desc(stub, resource_type):
     res = sorted(
         [
             received
-            for received in stub_list_functions[resource_type](metadata_pb2.Empty())
+            for received in stub_list_functions[resource_type](metadata_pb2.ListRequest())
         ],
         key=lambda x: x.name,
     )
@@ -65,7 +65,7 @@ def list_name_variant_status(stub, resource_type):
     res = sorted(
         [
             received
-            for received in stub_list_functions[resource_type][0](metadata_pb2.Empty())
+            for received in stub_list_functions[resource_type][0](metadata_pb2.ListRequest())
         ],
         key=lambda x: x.name,
     )
@@ -99,7 +99,7 @@ def list_name_variant_status_desc(stub, resource_type):
     res = sorted(
         [
             received
-            for received in stub_list_functions[resource_type][0](metadata_pb2.Empty())
+            for received in stub_list_functions[resource_type][0](metadata_pb2.ListRequest())
         ],
         key=lambda x: x.name,
     )
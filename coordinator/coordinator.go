@@ -3,15 +3,14 @@ package coordinator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
 
 	db "github.com/jackc/pgx/v4"
-	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
-	"go.etcd.io/etcd/client/v3/concurrency"
 	"go.uber.org/zap"
 
 	cfg "github.com/featureform/config"
@@ -85,6 +84,7 @@ type Coordinator struct {
 	Logger     *zap.SugaredLogger
 	EtcdClient *clientv3.Client
 	KVClient   *clientv3.KV
+	Jobs       metadata.JobStore
 	Spawner    JobSpawner
 	Timeout    int
 }
@@ -178,10 +178,6 @@ type KubernetesJobSpawner struct {
 
 type MemoryJobSpawner struct{}
 
-func GetLockKey(jobKey string) string {
-	return fmt.Sprintf("LOCK_%s", jobKey)
-}
-
 func (k *KubernetesJobSpawner) GetJobRunner(jobName string, config runner.Config, resourceId metadata.ResourceID) (types.Runner, error) {
 	etcdConfig := &ETCDConfig{Endpoints: k.EtcdConfig.Endpoints, Username: k.EtcdConfig.Username, Password: k.EtcdConfig.Password}
 	serializedETCD, err := etcdConfig.Serialize()
@@ -226,11 +222,24 @@ func NewCoordinator(meta *metadata.Client, logger *zap.SugaredLogger, cli *clien
 		Logger:     logger,
 		EtcdClient: cli,
 		KVClient:   &kvc,
+		Jobs:       metadata.EtcdJobStore{Client: cli},
 		Spawner:    spawner,
 		Timeout:    600,
 	}, nil
 }
 
+// NewSQLCoordinator runs the jobs in SQL metadata storage, rather than etcd.
+func NewSQLCoordinator(meta *metadata.Client, logger *zap.SugaredLogger, storage metadata.SQLStorage, spawner JobSpawner) (*Coordinator, error) {
+	logger.Info("Creating new coordinator with SQL jobs")
+	return &Coordinator{
+		Metadata: meta,
+		Logger:   logger,
+		Jobs:     metadata.SQLJobStore{Connection: storage},
+		Spawner:  spawner,
+		Timeout:  600,
+	}, nil
+}
+
 const MAX_ATTEMPTS = 3
 
 func (c *Coordinator) checkError(err error, jobName string) {
@@ -248,88 +257,35 @@ func (c *Coordinator) checkError(err error, jobName string) {
 
 func (c *Coordinator) WatchForNewJobs() error {
 	c.Logger.Info("Watching for new jobs")
-	getResp, err := (*c.KVClient).Get(context.Background(), "JOB_", clientv3.WithPrefix())
-	if err != nil {
-		return fmt.Errorf("get existing etcd jobs: %v", err)
-	}
-	for _, kv := range getResp.Kvs {
-		go func(kv *mvccpb.KeyValue) {
-			err := c.ExecuteJob(string(kv.Key))
-			if err != nil {
-				c.checkError(err, string(kv.Key))
-			}
-		}(kv)
-	}
-	for {
-		rch := c.EtcdClient.Watch(context.Background(), "JOB_", clientv3.WithPrefix())
-		for wresp := range rch {
-			for _, ev := range wresp.Events {
-				if ev.Type == mvccpb.PUT {
-					go func(ev *clientv3.Event) {
-						err := c.ExecuteJob(string(ev.Kv.Key))
-						if err != nil {
-							c.checkError(err, string(ev.Kv.Key))
-						}
-					}(ev)
-				}
-
+	return c.Jobs.Watch(context.Background(), "JOB_", func(key, value string) {
+		go func() {
+			if err := c.ExecuteJob(key); err != nil {
+				c.checkError(err, key)
 			}
-		}
-	}
+		}()
+	})
 }
 
 func (c *Coordinator) WatchForUpdateEvents() error {
 	c.Logger.Info("Watching for new update events")
-	for {
-		rch := c.EtcdClient.Watch(context.Background(), "UPDATE_EVENT_", clientv3.WithPrefix())
-		for wresp := range rch {
-			for _, ev := range wresp.Events {
-				if ev.Type == 0 {
-					go func(ev *clientv3.Event) {
-						err := c.signalResourceUpdate(string(ev.Kv.Key), string(ev.Kv.Value))
-						if err != nil {
-							c.Logger.Errorw("Error executing update event catch: Polling search", "error", err)
-						}
-					}(ev)
-				}
-
+	return c.Jobs.Watch(context.Background(), "UPDATE_EVENT_", func(key, value string) {
+		go func() {
+			if err := c.signalResourceUpdate(key, value); err != nil {
+				c.Logger.Errorw("Error executing update event catch: Polling search", "error", err)
 			}
-		}
-	}
-	return nil
+		}()
+	})
 }
 
 func (c *Coordinator) WatchForScheduleChanges() error {
 	c.Logger.Info("Watching for new update events")
-	getResp, err := (*c.KVClient).Get(context.Background(), "SCHEDULEJOB_", clientv3.WithPrefix())
-	if err != nil {
-		return fmt.Errorf("fetch existing etcd schedule jobs: %v", err)
-	}
-	for _, kv := range getResp.Kvs {
-		go func(kv *mvccpb.KeyValue) {
-			err := c.changeJobSchedule(string(kv.Key), string(kv.Value))
-			if err != nil {
-				c.Logger.Errorw("Error executing job schedule change: Initial search", "error", err)
+	return c.Jobs.Watch(context.Background(), "SCHEDULEJOB_", func(key, value string) {
+		go func() {
+			if err := c.changeJobSchedule(key, value); err != nil {
+				c.Logger.Errorw("Error executing job schedule change", "error", err)
 			}
-		}(kv)
-	}
-	for {
-		rch := c.EtcdClient.Watch(context.Background(), "SCHEDULEJOB_", clientv3.WithPrefix())
-		for wresp := range rch {
-			for _, ev := range wresp.Events {
-				if ev.Type == 0 {
-					go func(ev *clientv3.Event) {
-						err := c.changeJobSchedule(string(ev.Kv.Key), string(ev.Kv.Value))
-						if err != nil {
-							c.Logger.Errorw("Error executing job schedule change: Polling search", "error", err)
-						}
-					}(ev)
-				}
-
-			}
-		}
-	}
-	return nil
+		}()
+	})
 }
 
 func (c *Coordinator) mapNameVariantsToTables(sources []metadata.NameVariant) (map[string]string, error) {
@@ -342,7 +298,7 @@ func (c *Coordinator) mapNameVariantsToTables(sources []metadata.NameVariant) (m
 		if source.Status() != metadata.READY {
 			return nil, fmt.Errorf("source in query not ready")
 		}
-		providerResourceID := provider.ResourceID{Name: source.Name(), Variant: source.Variant()}
+		providerResourceID := provider.ResourceID{Name: metadata.QualifiedName(source.Namespace(), source.Name()), Variant: source.Variant()}
 		var tableName string
 		sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
 		if err != nil {
@@ -395,7 +351,7 @@ func (c *Coordinator) verifyCompletionOfSources(sources []metadata.NameVariant)
 }
 
 func (c *Coordinator) runTransformationJob(transformationConfig provider.TransformationConfig, resID metadata.ResourceID, schedule string, sourceProvider *metadata.Provider) error {
-	transformation, err := c.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	transformation, err := c.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant, Namespace: resID.Namespace})
 	if err != nil {
 		return fmt.Errorf("get label variant: %v", err)
 	}
@@ -438,7 +394,7 @@ func (c *Coordinator) runTransformationJob(transformationConfig provider.Transfo
 	}
 	c.Logger.Debugw("Transformation Waiting For Completion")
 	if err := completionWatcher.Wait(); err != nil {
-		return fmt.Errorf("transformation failed to complete: %v", err)
+		return fmt.Errorf("transformation failed to complete: %w", RunnerFailedError{runner.CREATE_TRANSFORMATION, err})
 	}
 	c.Logger.Debugw("Transformation Setting Status")
 	if err := retryWithDelays("set status to ready", 5, time.Millisecond*10, func() error { return c.Metadata.SetStatus(context.Background(), resID, metadata.READY, "") }); err != nil {
@@ -500,7 +456,7 @@ func (c *Coordinator) runSQLTransformationJob(transformSource *metadata.SourceVa
 	}
 
 	c.Logger.Debugw("Created transformation query", "query", query)
-	providerResourceID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Transformation}
+	providerResourceID := provider.ResourceID{Name: metadata.QualifiedName(resID.Namespace, resID.Name), Variant: resID.Variant, Type: provider.Transformation}
 	transformationConfig := provider.TransformationConfig{
 		Type:          provider.SQLTransformation,
 		TargetTableID: providerResourceID,
@@ -538,7 +494,7 @@ func (c *Coordinator) runDFTransformationJob(transformSource *metadata.SourceVar
 	}
 
 	c.Logger.Debugw("Created transformation query")
-	providerResourceID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Transformation}
+	providerResourceID := provider.ResourceID{Name: metadata.QualifiedName(resID.Namespace, resID.Name), Variant: resID.Variant, Type: provider.Transformation}
 	transformationConfig := provider.TransformationConfig{
 		Type:          provider.DFTransformation,
 		TargetTableID: providerResourceID,
@@ -570,7 +526,7 @@ func getOrderedSourceMappings(sources []metadata.NameVariant, sourceMap map[stri
 
 func (c *Coordinator) runPrimaryTableJob(source *metadata.SourceVariant, resID metadata.ResourceID, offlineStore provider.OfflineStore, schedule string) error {
 	c.Logger.Info("Running primary table job on resource: ", resID)
-	providerResourceID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Primary}
+	providerResourceID := provider.ResourceID{Name: metadata.QualifiedName(resID.Namespace, resID.Name), Variant: resID.Variant, Type: provider.Primary}
 	if !source.IsPrimaryDataSQLTable() {
 		return fmt.Errorf("%s is not a primary table", source.Name())
 	}
@@ -589,7 +545,7 @@ func (c *Coordinator) runPrimaryTableJob(source *metadata.SourceVariant, resID m
 
 func (c *Coordinator) runRegisterSourceJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running register source job on resource: ", resID)
-	source, err := c.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	source, err := c.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant, Namespace: resID.Namespace})
 	if err != nil {
 		return fmt.Errorf("get source variant from metadata: %v", err)
 	}
@@ -624,7 +580,7 @@ func (c *Coordinator) runRegisterSourceJob(resID metadata.ResourceID, schedule s
 
 func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running label register job: ", resID)
-	label, err := c.Metadata.GetLabelVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	label, err := c.Metadata.GetLabelVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant, Namespace: resID.Namespace})
 	if err != nil {
 		return fmt.Errorf("get label variant: %v", err)
 	}
@@ -670,14 +626,14 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 	}(sourceStore)
 	var sourceTableName string
 	if source.IsSQLTransformation() || source.IsDFTransformation() {
-		sourceResourceID := provider.ResourceID{sourceNameVariant.Name, sourceNameVariant.Variant, provider.Transformation}
+		sourceResourceID := provider.ResourceID{Name: metadata.QualifiedName(sourceNameVariant.Namespace, sourceNameVariant.Name), Variant: sourceNameVariant.Variant, Type: provider.Transformation}
 		sourceTable, err := sourceStore.GetTransformationTable(sourceResourceID)
 		if err != nil {
 			return err
 		}
 		sourceTableName = sourceTable.GetName()
 	} else if source.IsPrimaryDataSQLTable() {
-		sourceResourceID := provider.ResourceID{sourceNameVariant.Name, sourceNameVariant.Variant, provider.Primary}
+		sourceResourceID := provider.ResourceID{Name: metadata.QualifiedName(sourceNameVariant.Namespace, sourceNameVariant.Name), Variant: sourceNameVariant.Variant, Type: provider.Primary}
 		sourceTable, err := sourceStore.GetPrimaryTable(sourceResourceID)
 		if err != nil {
 			return err
@@ -686,7 +642,7 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 	}
 
 	labelID := provider.ResourceID{
-		Name:    resID.Name,
+		Name:    metadata.QualifiedName(resID.Namespace, resID.Name),
 		Variant: resID.Variant,
 		Type:    provider.Label,
 	}
@@ -712,7 +668,7 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 
 func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running feature materialization job on resource: ", resID)
-	feature, err := c.Metadata.GetFeatureVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	feature, err := c.Metadata.GetFeatureVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant, Namespace: resID.Namespace})
 	if err != nil {
 		return fmt.Errorf("get feature variant from metadata: %v", err)
 	}
@@ -779,7 +735,7 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 		OfflineType:   pt.Type(sourceProvider.Type()),
 		OnlineConfig:  featureProvider.SerializedConfig(),
 		OfflineConfig: sourceProvider.SerializedConfig(),
-		ResourceID:    provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
+		ResourceID:    provider.ResourceID{Name: metadata.QualifiedName(resID.Namespace, resID.Name), Variant: resID.Variant, Type: provider.Feature},
 		VType:         provider.ValueTypeJSONWrapper{ValueType: vType},
 		Cloud:         runner.LocalMaterializeRunner,
 		IsUpdate:      false,
@@ -790,14 +746,14 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 	}
 	var sourceTableName string
 	if source.IsSQLTransformation() || source.IsDFTransformation() {
-		sourceResourceID := provider.ResourceID{sourceNameVariant.Name, sourceNameVariant.Variant, provider.Transformation}
+		sourceResourceID := provider.ResourceID{Name: metadata.QualifiedName(sourceNameVariant.Namespace, sourceNameVariant.Name), Variant: sourceNameVariant.Variant, Type: provider.Transformation}
 		sourceTable, err := sourceStore.GetTransformationTable(sourceResourceID)
 		if err != nil {
 			return err
 		}
 		sourceTableName = sourceTable.GetName()
 	} else if source.IsPrimaryDataSQLTable() {
-		sourceResourceID := provider.ResourceID{sourceNameVariant.Name, sourceNameVariant.Variant, provider.Primary}
+		sourceResourceID := provider.ResourceID{Name: metadata.QualifiedName(sourceNameVariant.Namespace, sourceNameVariant.Name), Variant: sourceNameVariant.Variant, Type: provider.Primary}
 		sourceTable, err := sourceStore.GetPrimaryTable(sourceResourceID)
 		if err != nil {
 			return err
@@ -806,7 +762,7 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 	}
 
 	featID := provider.ResourceID{
-		Name:    resID.Name,
+		Name:    metadata.QualifiedName(resID.Namespace, resID.Name),
 		Variant: resID.Variant,
 		Type:    provider.Feature,
 	}
@@ -835,7 +791,7 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 			return fmt.Errorf("failed to run job: %w", err)
 		}
 		if err := completionWatcher.Wait(); err != nil {
-			return fmt.Errorf("failed to complete job: %w", err)
+			return fmt.Errorf("failed to complete job: %w", RunnerFailedError{runner.MATERIALIZE, err})
 		}
 	}
 	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
@@ -847,7 +803,7 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 			OfflineType:   pt.Type(sourceProvider.Type()),
 			OnlineConfig:  featureProvider.SerializedConfig(),
 			OfflineConfig: sourceProvider.SerializedConfig(),
-			ResourceID:    provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
+			ResourceID:    provider.ResourceID{Name: metadata.QualifiedName(resID.Namespace, resID.Name), Variant: resID.Variant, Type: provider.Feature},
 			VType:         provider.ValueTypeJSONWrapper{ValueType: vType},
 			Cloud:         runner.LocalMaterializeRunner,
 			IsUpdate:      true,
@@ -876,7 +832,7 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 
 func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running training set job on resource: ", "name", resID.Name, "variant", resID.Variant)
-	ts, err := c.Metadata.GetTrainingSetVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	ts, err := c.Metadata.GetTrainingSetVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant, Namespace: resID.Namespace})
 	if err != nil {
 		return fmt.Errorf("fetch training set variant from metadata: %v", err)
 	}
@@ -912,7 +868,7 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 			c.Logger.Errorf("could not close offline store: %v", err)
 		}
 	}(store)
-	providerResID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.TrainingSet}
+	providerResID := provider.ResourceID{Name: metadata.QualifiedName(resID.Namespace, resID.Name), Variant: resID.Variant, Type: provider.TrainingSet}
 
 	if _, err := store.GetTrainingSet(providerResID); err == nil {
 		return fmt.Errorf("training set (%v) already exists: %v", resID, err)
@@ -920,7 +876,7 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	features := ts.Features()
 	featureList := make([]provider.ResourceID, len(features))
 	for i, feature := range features {
-		featureList[i] = provider.ResourceID{Name: feature.Name, Variant: feature.Variant, Type: provider.Feature}
+		featureList[i] = provider.ResourceID{Name: metadata.QualifiedName(feature.Namespace, feature.Name), Variant: feature.Variant, Type: provider.Feature}
 		featureResource, err := c.Metadata.GetFeatureVariant(context.Background(), feature)
 		if err != nil {
 			return fmt.Errorf("failed to get fetch dependent feature: %v", err)
@@ -930,7 +886,7 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 		if err != nil {
 			return fmt.Errorf("source of feature could not complete job: %v", err)
 		}
-		_, err = c.AwaitPendingFeature(metadata.NameVariant{feature.Name, feature.Variant})
+		_, err = c.AwaitPendingFeature(feature)
 		if err != nil {
 			return fmt.Errorf("feature could not complete job: %v", err)
 		}
@@ -940,7 +896,7 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	lagFeaturesList := make([]provider.LagFeatureDef, len(lagFeatures))
 	for i, lagFeature := range lagFeatures {
 		lagFeaturesList[i] = provider.LagFeatureDef{
-			FeatureName:    lagFeature.GetFeature(),
+			FeatureName:    metadata.QualifiedName(resID.Namespace, lagFeature.GetFeature()),
 			FeatureVariant: lagFeature.GetVariant(),
 			LagName:        lagFeature.GetName(),
 			LagDelta:       lagFeature.GetLag().AsDuration(), // see if need to convert it to time.Duration
@@ -956,13 +912,13 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	if err != nil {
 		return fmt.Errorf("source of label could not complete job: %v", err)
 	}
-	label, err = c.AwaitPendingLabel(metadata.NameVariant{label.Name(), label.Variant()})
+	label, err = c.AwaitPendingLabel(metadata.NameVariant{Name: label.Name(), Variant: label.Variant(), Namespace: label.Namespace()})
 	if err != nil {
 		return fmt.Errorf("label could not complete job: %v", err)
 	}
 	trainingSetDef := provider.TrainingSetDef{
 		ID:          providerResID,
-		Label:       provider.ResourceID{Name: label.Name(), Variant: label.Variant(), Type: provider.Label},
+		Label:       provider.ResourceID{Name: metadata.QualifiedName(label.Namespace(), label.Name()), Variant: label.Variant(), Type: provider.Label},
 		Features:    featureList,
 		LagFeatures: lagFeaturesList,
 	}
@@ -982,7 +938,7 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 		return fmt.Errorf("failed to start training set job: %v", err)
 	}
 	if err := completionWatcher.Wait(); err != nil {
-		return fmt.Errorf("training set job failed to complete: %v", err)
+		return fmt.Errorf("training set job failed to complete: %w", RunnerFailedError{runner.CREATE_TRAINING_SET, err})
 	}
 	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return fmt.Errorf("failed to set training set status: %v", err)
@@ -1016,62 +972,41 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	return nil
 }
 
-func (c *Coordinator) getJob(mtx *concurrency.Mutex, key string) (*metadata.CoordinatorJob, error) {
+func (c *Coordinator) getJob(lock metadata.JobLock, key string) (*metadata.CoordinatorJob, error) {
 	c.Logger.Debugf("Checking existence of job with key %s\n", key)
-	txn := (*c.KVClient).Txn(context.Background())
-	response, err := txn.If(mtx.IsOwner()).Then(clientv3.OpGet(key)).Commit()
+	value, found, err := lock.Get()
 	if err != nil {
-		return nil, fmt.Errorf("transaction did not succeed: %v", err)
-	}
-	isOwner := response.Succeeded //response.Succeeded sets true if transaction "if" statement true
-	if !isOwner {
-		return nil, fmt.Errorf("was not owner of lock")
+		return nil, err
 	}
-	responseData := response.Responses[0]
-	responseKVs := responseData.GetResponseRange().GetKvs()
-	if len(responseKVs) == 0 {
+	if !found {
 		return nil, JobDoesNotExistError{key: key}
 	}
-	responseValue := responseKVs[0].Value //Only single response for single key
 	job := &metadata.CoordinatorJob{}
-	if err := job.Deserialize(responseValue); err != nil {
+	if err := job.Deserialize([]byte(value)); err != nil {
 		return nil, fmt.Errorf("could not deserialize coordinator job: %v", err)
 	}
 	return job, nil
 }
 
-func (c *Coordinator) incrementJobAttempts(mtx *concurrency.Mutex, job *metadata.CoordinatorJob, jobKey string) error {
+func (c *Coordinator) incrementJobAttempts(lock metadata.JobLock, job *metadata.CoordinatorJob, jobKey string) error {
 	job.Attempts += 1
 	serializedJob, err := job.Serialize()
 	if err != nil {
 		return fmt.Errorf("could not serialize coordinator job. %v", err)
 	}
-	txn := (*c.KVClient).Txn(context.Background())
-	response, err := txn.If(mtx.IsOwner()).Then(clientv3.OpPut(jobKey, string(serializedJob))).Commit()
-	if err != nil {
+	if err := lock.Put(string(serializedJob)); err != nil {
 		return fmt.Errorf("could not set iterated coordinator job. %v", err)
 	}
-	isOwner := response.Succeeded //response.Succeeded sets true if transaction "if" statement true
-	if !isOwner {
-		return fmt.Errorf("was not owner of lock")
-	}
 	return nil
 }
 
-func (c *Coordinator) deleteJob(mtx *concurrency.Mutex, key string) error {
+func (c *Coordinator) deleteJob(lock metadata.JobLock, key string) error {
 	c.Logger.Info("Deleting job with key: ", key)
-	txn := (*c.KVClient).Txn(context.Background())
-	response, err := txn.If(mtx.IsOwner()).Then(clientv3.OpDelete(key)).Commit()
+	deleted, err := lock.Delete()
 	if err != nil {
 		return fmt.Errorf("delete job transaction failed: %v", err)
 	}
-	isOwner := response.Succeeded //response.Succeeded sets true if transaction "if" statement true
-	if !isOwner {
-		return fmt.Errorf("was not owner of lock")
-	}
-	responseData := response.Responses[0] //OpDelete always returns single response
-	numDeleted := responseData.GetResponseDeleteRange().Deleted
-	if numDeleted != 1 { //returns 0 if delete key did not exist
+	if !deleted {
 		return fmt.Errorf("job Already deleted")
 	}
 	c.Logger.Info("Succesfully deleted job with key: ", key)
@@ -1079,42 +1014,36 @@ func (c *Coordinator) deleteJob(mtx *concurrency.Mutex, key string) error {
 }
 
 func (c *Coordinator) hasJob(id metadata.ResourceID) (bool, error) {
-	getResp, err := (*c.KVClient).Get(context.Background(), metadata.GetJobKey(id), clientv3.WithPrefix())
+	has, err := c.Jobs.Has(metadata.GetJobKey(id))
 	if err != nil {
-		return false, fmt.Errorf("fetch jobs from etcd with prefix %s: %v", metadata.GetJobKey(id), err)
+		return false, fmt.Errorf("fetch jobs with prefix %s: %v", metadata.GetJobKey(id), err)
 	}
-	responseLength := len(getResp.Kvs)
-	if responseLength > 0 {
-		return true, nil
-	}
-	return false, nil
+	return has, nil
 }
 
-func (c *Coordinator) createJobLock(jobKey string, s *concurrency.Session) (*concurrency.Mutex, error) {
-	mtx := concurrency.NewMutex(s, GetLockKey(jobKey))
-	if err := mtx.Lock(context.Background()); err != nil {
+func (c *Coordinator) createJobLock(jobKey string) (metadata.JobLock, error) {
+	lock, err := c.Jobs.Lock(jobKey)
+	if err != nil {
 		c.Logger.Debugw("could not create job lock restarting.....", "error", err)
 		os.Exit(1)
 	}
-	return mtx, nil
+	return lock, nil
+}
+
+// unlockJob releases a job's lock once whatever held it is done.
+func (c *Coordinator) unlockJob(lock metadata.JobLock) {
+	if err := lock.Unlock(); err != nil {
+		c.Logger.Debugw("Error unlocking mutex:", "error", err)
+	}
 }
 
 func (c *Coordinator) ExecuteJob(jobKey string) error {
 	c.Logger.Info("Executing new job with key ", jobKey)
-	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
-	if err != nil {
-		return fmt.Errorf("new session: %v", err)
-	}
-	defer s.Close()
-	mtx, err := c.createJobLock(jobKey, s)
+	mtx, err := c.createJobLock(jobKey)
 	if err != nil {
 		return fmt.Errorf("job lock: %v", err)
 	}
-	defer func() {
-		if err := mtx.Unlock(context.Background()); err != nil {
-			c.Logger.Debugw("Error unlocking mutex:", "error", err)
-		}
-	}()
+	defer c.unlockJob(mtx)
 	job, err := c.getJob(mtx, jobKey)
 	if err != nil {
 		return err
@@ -1147,7 +1076,7 @@ func (c *Coordinator) ExecuteJob(jobKey string) error {
 		case ResourceAlreadyFailedError:
 			return err
 		default:
-			statusErr := c.Metadata.SetStatus(context.Background(), job.Resource, metadata.FAILED, err.Error())
+			statusErr := c.Metadata.SetFailed(context.Background(), job.Resource, jobFailure(job, err))
 			return fmt.Errorf("%s job failed: %w: %v", job.Resource.Type, err, statusErr)
 		}
 	}
@@ -1159,6 +1088,25 @@ func (c *Coordinator) ExecuteJob(jobKey string) error {
 	return nil
 }
 
+// jobFailure describes a failed attempt at a job, with the runner it failed
+// in and the runner's logs if they're known.
+func jobFailure(job *metadata.CoordinatorJob, err error) metadata.ResourceError {
+	failure := metadata.ResourceError{
+		Message: err.Error(),
+		Attempt: job.Attempts,
+		Failed:  time.Now(),
+	}
+	var runnerErr RunnerFailedError
+	if errors.As(err, &runnerErr) {
+		failure.Runner = runnerErr.runner
+	}
+	var jobErr kubernetes.JobFailedError
+	if errors.As(err, &jobErr) {
+		failure.Logs = jobErr.Logs
+	}
+	return failure
+}
+
 type ResourceUpdatedEvent struct {
 	ResourceID metadata.ResourceID
 	Completed  time.Time
@@ -1182,20 +1130,11 @@ func (c *ResourceUpdatedEvent) Deserialize(config Config) error {
 
 func (c *Coordinator) signalResourceUpdate(key string, value string) error {
 	c.Logger.Info("Updating metdata with latest resource update status and time", key)
-	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
-	if err != nil {
-		return fmt.Errorf("create new concurrency session for resource update job: %v", err)
-	}
-	defer s.Close()
-	mtx, err := c.createJobLock(key, s)
+	mtx, err := c.createJobLock(key)
 	if err != nil {
 		return fmt.Errorf("create lock on resource update job with key %s: %v", key, err)
 	}
-	defer func() {
-		if err := mtx.Unlock(context.Background()); err != nil {
-			c.Logger.Debugw("Error unlocking mutex:", "error", err)
-		}
-	}()
+	defer c.unlockJob(mtx)
 	resUpdatedEvent := &ResourceUpdatedEvent{}
 	if err := resUpdatedEvent.Deserialize(Config(value)); err != nil {
 		return fmt.Errorf("deserialize resource update event: %v", err)
@@ -1212,25 +1151,11 @@ func (c *Coordinator) signalResourceUpdate(key string, value string) error {
 
 func (c *Coordinator) changeJobSchedule(key string, value string) error {
 	c.Logger.Info("Updating schedule of currently made cronjob in kubernetes: ", key)
-	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
-	if err != nil {
-		return fmt.Errorf("create new concurrency session for resource update job: %v", err)
-	}
-	defer func(s *concurrency.Session) {
-		err := s.Close()
-		if err != nil {
-			c.Logger.Debugw("Error closing scheduling session", "error", err)
-		}
-	}(s)
-	mtx, err := c.createJobLock(key, s)
+	mtx, err := c.createJobLock(key)
 	if err != nil {
 		return fmt.Errorf("create lock on resource update job with key %s: %v", key, err)
 	}
-	defer func() {
-		if err := mtx.Unlock(context.Background()); err != nil {
-			c.Logger.Debugw("Error unlocking mutex:", "error", err)
-		}
-	}()
+	defer c.unlockJob(mtx)
 	coordinatorScheduleJob := &metadata.CoordinatorScheduleJob{}
 	if err := coordinatorScheduleJob.Deserialize(Config(value)); err != nil {
 		return fmt.Errorf("deserialize coordinator schedule job: %v", err)
@@ -1257,7 +1182,7 @@ func (c *Coordinator) changeJobSchedule(key string, value string) error {
 	}
 	c.Logger.Info("Successfully updated schedule for job in kubernetes with key: ", key)
 	if err := c.deleteJob(mtx, key); err != nil {
-		return fmt.Errorf("delete update schedule job in etcd: %v", err)
+		return fmt.Errorf("delete update schedule job: %v", err)
 	}
 	return nil
 }
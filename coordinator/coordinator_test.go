@@ -16,6 +16,7 @@ import (
 	"time"
 
 	help "github.com/featureform/helpers"
+	"github.com/featureform/kubernetes"
 	"github.com/google/uuid"
 
 	"github.com/featureform/metadata"
@@ -85,6 +86,22 @@ func TestRetryWithDelays(t *testing.T) {
 	}
 }
 
+func TestJobFailure(t *testing.T) {
+	job := &metadata.CoordinatorJob{Attempts: 2}
+	podErr := kubernetes.JobFailedError{Job: "job", Logs: "traceback"}
+	err := fmt.Errorf("failed to complete job: %w", RunnerFailedError{runner.MATERIALIZE, podErr})
+	failure := jobFailure(job, err)
+	if failure.Message != err.Error() || failure.Attempt != 2 || failure.Failed.IsZero() {
+		t.Fatalf("Expected the error and attempt to be recorded: %v", failure)
+	}
+	if failure.Runner != runner.MATERIALIZE || failure.Logs != "traceback" {
+		t.Fatalf("Expected the runner and its logs to be recorded: %v", failure)
+	}
+	if failure := jobFailure(job, errors.New("no runner")); failure.Runner != "" || failure.Logs != "" {
+		t.Fatalf("Expected no runner or logs: %v", failure)
+	}
+}
+
 func startServ(t *testing.T) (*metadata.MetadataServer, string) {
 	logger := zap.NewExample().Sugar()
 	storageProvider := metadata.EtcdStorageProvider{
@@ -180,7 +197,7 @@ func TestRunSQLJobError(t *testing.T) {
 			Definition: metadata.TransformationSource{
 				TransformationType: metadata.SQLTransformationType{
 					Query:   "{{ghost_source.}}",
-					Sources: []metadata.NameVariant{{"ghost_source", ""}},
+					Sources: []metadata.NameVariant{{Name: "ghost_source", Variant: ""}},
 				},
 			},
 			Schedule: "",
@@ -189,7 +206,7 @@ func TestRunSQLJobError(t *testing.T) {
 	if err := coord.Metadata.CreateAll(context.Background(), defs); err != nil {
 		t.Fatalf("could not create test metadata entries: %v", err)
 	}
-	transformSource, err := coord.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{sourceGhostDependency, ""})
+	transformSource, err := coord.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{Name: sourceGhostDependency, Variant: ""})
 	if err != nil {
 		t.Fatalf("could not fetch created source variant: %v", err)
 	}
@@ -205,7 +222,7 @@ func TestRunSQLJobError(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not get provider as offline store: %v", err)
 	}
-	sourceResourceID := metadata.ResourceID{sourceGhostDependency, "", metadata.SOURCE_VARIANT}
+	sourceResourceID := metadata.ResourceID{Name: sourceGhostDependency, Variant: "", Type: metadata.SOURCE_VARIANT}
 	if err := coord.runSQLTransformationJob(transformSource, sourceResourceID, offlineProvider, "", providerEntry); err == nil {
 		t.Fatalf("did not catch error trying to run primary table job with no source table set")
 	}
@@ -222,7 +239,7 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 		t.Fatalf("could not create new basic coordinator")
 	}
 	defer coord.Metadata.Close()
-	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{"ghost_resource", "", metadata.FEATURE_VARIANT}, ""); err == nil {
+	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{Name: "ghost_resource", Variant: "", Type: metadata.FEATURE_VARIANT}, ""); err == nil {
 		t.Fatalf("did not catch error when trying to materialize nonexistent feature")
 	}
 	liveAddr := fmt.Sprintf("%s:%s", redisHost, redisPort)
@@ -235,10 +252,10 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 	if err := materializeFeatureWithProvider(coord.Metadata, postgresConfig.Serialize(), redisConfig.Serialized(), featureName, sourceName, originalTableName, ""); err != nil {
 		t.Fatalf("could not create example feature, %v", err)
 	}
-	if err := coord.Metadata.SetStatus(context.Background(), metadata.ResourceID{featureName, "", metadata.FEATURE_VARIANT}, metadata.READY, ""); err != nil {
+	if err := coord.Metadata.SetStatus(context.Background(), metadata.ResourceID{Name: featureName, Variant: "", Type: metadata.FEATURE_VARIANT}, metadata.READY, ""); err != nil {
 		t.Fatalf("could not set feature to ready")
 	}
-	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{featureName, "", metadata.FEATURE_VARIANT}, ""); err == nil {
+	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{Name: featureName, Variant: "", Type: metadata.FEATURE_VARIANT}, ""); err == nil {
 		t.Fatalf("did not catch error when trying to materialize feature already set to ready")
 	}
 	providerName := createSafeUUID()
@@ -279,7 +296,7 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -299,7 +316,7 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 	if err := coord.Metadata.SetStatus(context.Background(), metadata.ResourceID{Name: sourceName, Variant: "", Type: metadata.SOURCE_VARIANT}, metadata.READY, ""); err != nil {
 		t.Fatalf("could not set source variant to ready")
 	}
-	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{featureName, "", metadata.FEATURE_VARIANT}, ""); err == nil {
+	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{Name: featureName, Variant: "", Type: metadata.FEATURE_VARIANT}, ""); err == nil {
 		t.Fatalf("did not trigger error trying to run job with nonexistent provider")
 	}
 	providerName = createSafeUUID()
@@ -340,7 +357,7 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -360,7 +377,7 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 	if err := coord.Metadata.SetStatus(context.Background(), metadata.ResourceID{Name: sourceName, Variant: "", Type: metadata.SOURCE_VARIANT}, metadata.READY, ""); err != nil {
 		t.Fatalf("could not set source variant to ready")
 	}
-	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{featureName, "", metadata.FEATURE_VARIANT}, ""); err == nil {
+	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{Name: featureName, Variant: "", Type: metadata.FEATURE_VARIANT}, ""); err == nil {
 		t.Fatalf("did not trigger error trying to use online store as offline store")
 	}
 	providerName = createSafeUUID()
@@ -410,7 +427,7 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -430,7 +447,7 @@ func TestFeatureMaterializeJobError(t *testing.T) {
 	if err := coord.Metadata.SetStatus(context.Background(), metadata.ResourceID{Name: sourceName, Variant: "", Type: metadata.SOURCE_VARIANT}, metadata.READY, ""); err != nil {
 		t.Fatalf("could not set source variant to ready")
 	}
-	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{featureName, "", metadata.FEATURE_VARIANT}, ""); err == nil {
+	if err := coord.runFeatureMaterializeJob(metadata.ResourceID{Name: featureName, Variant: "", Type: metadata.FEATURE_VARIANT}, ""); err == nil {
 		t.Fatalf("did not trigger error trying to get invalid feature provider")
 	}
 }
@@ -446,7 +463,7 @@ func TestTrainingSetJobError(t *testing.T) {
 		t.Fatalf("could not create new basic coordinator")
 	}
 	defer coord.Metadata.Close()
-	if err := coord.runTrainingSetJob(metadata.ResourceID{"ghost_training_set", "", metadata.TRAINING_SET_VARIANT}, ""); err == nil {
+	if err := coord.runTrainingSetJob(metadata.ResourceID{Name: "ghost_training_set", Variant: "", Type: metadata.TRAINING_SET_VARIANT}, ""); err == nil {
 		t.Fatalf("did not trigger error trying to run job for nonexistent training set")
 	}
 	providerName := createSafeUUID()
@@ -491,7 +508,7 @@ func TestTrainingSetJobError(t *testing.T) {
 			Variant:     "",
 			Description: "",
 			Type:        string(provider.Int),
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Entity:      entityName,
 			Owner:       userName,
 			Provider:    providerName,
@@ -504,7 +521,7 @@ func TestTrainingSetJobError(t *testing.T) {
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -523,15 +540,15 @@ func TestTrainingSetJobError(t *testing.T) {
 			Description: "",
 			Owner:       userName,
 			Provider:    providerName,
-			Label:       metadata.NameVariant{labelName, ""},
-			Features:    []metadata.NameVariant{{featureName, ""}},
+			Label:       metadata.NameVariant{Name: labelName, Variant: ""},
+			Features:    []metadata.NameVariant{{Name: featureName, Variant: ""}},
 			Schedule:    "",
 		},
 	}
 	if err := coord.Metadata.CreateAll(context.Background(), defs); err != nil {
 		t.Fatalf("could not create metadata entries: %v", err)
 	}
-	if err := coord.runTrainingSetJob(metadata.ResourceID{tsName, "", metadata.TRAINING_SET_VARIANT}, ""); err == nil {
+	if err := coord.runTrainingSetJob(metadata.ResourceID{Name: tsName, Variant: "", Type: metadata.TRAINING_SET_VARIANT}, ""); err == nil {
 		t.Fatalf("did not trigger error trying to run job with nonexistent provider")
 	}
 	providerName = createSafeUUID()
@@ -580,7 +597,7 @@ func TestTrainingSetJobError(t *testing.T) {
 			Variant:     "",
 			Description: "",
 			Type:        string(provider.Int),
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Entity:      entityName,
 			Owner:       userName,
 			Provider:    providerName,
@@ -593,7 +610,7 @@ func TestTrainingSetJobError(t *testing.T) {
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -612,15 +629,15 @@ func TestTrainingSetJobError(t *testing.T) {
 			Description: "",
 			Owner:       userName,
 			Provider:    providerName,
-			Label:       metadata.NameVariant{labelName, ""},
-			Features:    []metadata.NameVariant{{featureName, ""}},
+			Label:       metadata.NameVariant{Name: labelName, Variant: ""},
+			Features:    []metadata.NameVariant{{Name: featureName, Variant: ""}},
 			Schedule:    "",
 		},
 	}
 	if err := coord.Metadata.CreateAll(context.Background(), defs); err != nil {
 		t.Fatalf("could not create metadata entries: %v", err)
 	}
-	if err := coord.runTrainingSetJob(metadata.ResourceID{tsName, "", metadata.TRAINING_SET_VARIANT}, ""); err == nil {
+	if err := coord.runTrainingSetJob(metadata.ResourceID{Name: tsName, Variant: "", Type: metadata.TRAINING_SET_VARIANT}, ""); err == nil {
 		t.Fatalf("did not trigger error trying to convert online provider to offline")
 	}
 }
@@ -668,7 +685,7 @@ func TestRunPrimaryTableJobError(t *testing.T) {
 	if err := coord.Metadata.CreateAll(context.Background(), defs); err != nil {
 		t.Fatalf("could not create test metadata entries")
 	}
-	transformSource, err := coord.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{sourceNoPrimaryNameSet, ""})
+	transformSource, err := coord.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{Name: sourceNoPrimaryNameSet, Variant: ""})
 	if err != nil {
 		t.Fatalf("could not fetch created source variant: %v", err)
 	}
@@ -680,7 +697,7 @@ func TestRunPrimaryTableJobError(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not get provider as offline store: %v", err)
 	}
-	sourceResourceID := metadata.ResourceID{sourceNoPrimaryNameSet, "", metadata.SOURCE_VARIANT}
+	sourceResourceID := metadata.ResourceID{Name: sourceNoPrimaryNameSet, Variant: "", Type: metadata.SOURCE_VARIANT}
 	if err := coord.runPrimaryTableJob(transformSource, sourceResourceID, offlineProvider, ""); err == nil {
 		t.Fatalf("did not catch error trying to run primary table job with no source table set")
 	}
@@ -716,11 +733,11 @@ func TestRunPrimaryTableJobError(t *testing.T) {
 	if err := coord.Metadata.CreateAll(context.Background(), newDefs); err != nil {
 		t.Fatalf("could not create test metadata entries: %v", err)
 	}
-	newTransformSource, err := coord.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{sourceNoActualPrimaryTable, ""})
+	newTransformSource, err := coord.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{Name: sourceNoActualPrimaryTable, Variant: ""})
 	if err != nil {
 		t.Fatalf("could not fetch created source variant: %v", err)
 	}
-	newSourceResourceID := metadata.ResourceID{sourceNoActualPrimaryTable, "", metadata.SOURCE_VARIANT}
+	newSourceResourceID := metadata.ResourceID{Name: sourceNoActualPrimaryTable, Variant: "", Type: metadata.SOURCE_VARIANT}
 	if err := coord.runPrimaryTableJob(newTransformSource, newSourceResourceID, offlineProvider, ""); err == nil {
 		t.Fatalf("did not catch error trying to create primary table when no source table exists in database")
 	}
@@ -738,7 +755,7 @@ func TestMapNameVariantsToTablesError(t *testing.T) {
 	}
 	defer coord.Metadata.Close()
 	ghostResourceName := createSafeUUID()
-	ghostNameVariants := []metadata.NameVariant{{ghostResourceName, ""}}
+	ghostNameVariants := []metadata.NameVariant{{Name: ghostResourceName, Variant: ""}}
 	if _, err := coord.mapNameVariantsToTables(ghostNameVariants); err == nil {
 		t.Fatalf("did not catch error creating map from nonexistent resource")
 	}
@@ -775,7 +792,7 @@ func TestMapNameVariantsToTablesError(t *testing.T) {
 	if err := coord.Metadata.CreateAll(context.Background(), defs); err != nil {
 		t.Fatalf("could not create test metadata entries")
 	}
-	notReadyNameVariants := []metadata.NameVariant{{sourceNotReady, ""}}
+	notReadyNameVariants := []metadata.NameVariant{{Name: sourceNotReady, Variant: ""}}
 	if _, err := coord.mapNameVariantsToTables(notReadyNameVariants); err == nil {
 		t.Fatalf("did not catch error creating map from not ready resource")
 	}
@@ -793,7 +810,7 @@ func TestRegisterSourceJobErrors(t *testing.T) {
 	}
 	defer coord.Metadata.Close()
 	ghostResourceName := createSafeUUID()
-	ghostResourceID := metadata.ResourceID{ghostResourceName, "", metadata.SOURCE_VARIANT}
+	ghostResourceID := metadata.ResourceID{Name: ghostResourceName, Variant: "", Type: metadata.SOURCE_VARIANT}
 	if err := coord.runRegisterSourceJob(ghostResourceID, ""); err == nil {
 		t.Fatalf("did not catch error registering nonexistent resource")
 	}
@@ -830,7 +847,7 @@ func TestRegisterSourceJobErrors(t *testing.T) {
 	if err := coord.Metadata.CreateAll(context.Background(), providerErrorDefs); err != nil {
 		t.Fatalf("could not create test metadata entries")
 	}
-	sourceWithoutProviderResourceID := metadata.ResourceID{sourceWithoutProvider, "", metadata.SOURCE_VARIANT}
+	sourceWithoutProviderResourceID := metadata.ResourceID{Name: sourceWithoutProvider, Variant: "", Type: metadata.SOURCE_VARIANT}
 	if err := coord.runRegisterSourceJob(sourceWithoutProviderResourceID, ""); err == nil {
 		t.Fatalf("did not catch error registering registering resource without provider in offline store")
 	}
@@ -872,7 +889,7 @@ func TestRegisterSourceJobErrors(t *testing.T) {
 	if err := coord.Metadata.CreateAll(context.Background(), onlineErrorDefs); err != nil {
 		t.Fatalf("could not create test metadata entries")
 	}
-	sourceWithOnlineProvider := metadata.ResourceID{sourceWithoutOfflineProvider, "", metadata.SOURCE_VARIANT}
+	sourceWithOnlineProvider := metadata.ResourceID{Name: sourceWithoutOfflineProvider, Variant: "", Type: metadata.SOURCE_VARIANT}
 	if err := coord.runRegisterSourceJob(sourceWithOnlineProvider, ""); err == nil {
 		t.Fatalf("did not catch error registering resource with online provider")
 	}
@@ -1069,7 +1086,7 @@ func materializeFeatureWithProvider(client *metadata.Client, offlineConfig pc.Se
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -1196,7 +1213,7 @@ func createTrainingSetWithProvider(client *metadata.Client, config pc.Serialized
 			Variant:     "",
 			Description: "",
 			Type:        string(provider.Int),
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Entity:      entityName,
 			Owner:       userName,
 			Provider:    providerName,
@@ -1209,7 +1226,7 @@ func createTrainingSetWithProvider(client *metadata.Client, config pc.Serialized
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -1227,8 +1244,8 @@ func createTrainingSetWithProvider(client *metadata.Client, config pc.Serialized
 			Description: "",
 			Owner:       userName,
 			Provider:    providerName,
-			Label:       metadata.NameVariant{labelName, ""},
-			Features:    []metadata.NameVariant{{featureName, ""}},
+			Label:       metadata.NameVariant{Name: labelName, Variant: ""},
+			Features:    []metadata.NameVariant{{Name: featureName, Variant: ""}},
 			Schedule:    schedule,
 		},
 	}
@@ -28,3 +28,18 @@ type ResourceAlreadyFailedError struct {
 func (m ResourceAlreadyFailedError) Error() string {
 	return fmt.Sprintf("resource failed in a previous run: %s %s %s", m.resourceID.Type, m.resourceID.Name, m.resourceID.Variant)
 }
+
+// RunnerFailedError is a job failing in its runner. It keeps the runner so
+// the failure can be recorded with it.
+type RunnerFailedError struct {
+	runner string
+	err    error
+}
+
+func (m RunnerFailedError) Error() string {
+	return m.err.Error()
+}
+
+func (m RunnerFailedError) Unwrap() error {
+	return m.err
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/featureform/logging"
 	"github.com/featureform/metadata"
 	"github.com/featureform/runner"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -20,7 +22,6 @@ func main() {
 	metadataPort := help.GetEnv("METADATA_PORT", "8080")
 	metadataUrl := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
 	useK8sRunner := help.GetEnv("K8S_RUNNER_ENABLE", "false")
-	fmt.Printf("connecting to etcd: %s\n", etcdUrl)
 	fmt.Printf("connecting to metadata: %s\n", metadataUrl)
 	etcdConfig := clientv3.Config{
 		Endpoints:   []string{etcdUrl},
@@ -28,17 +29,6 @@ func main() {
 		Password:    help.GetEnv("ETCD_PASSWORD", "secretpassword"),
 		DialTimeout: time.Second * 1,
 	}
-	cli, err := clientv3.New(etcdConfig)
-	if err != nil {
-		panic(err)
-	}
-	fmt.Println("connected to etcd")
-	defer func(cli *clientv3.Client) {
-		err := cli.Close()
-		if err != nil {
-			panic(fmt.Errorf("failed to close etcd client: %w", err))
-		}
-	}(cli)
 	if err := runner.RegisterFactory(string(runner.COPY_TO_ONLINE), runner.MaterializedChunkRunnerFactory); err != nil {
 		panic(fmt.Errorf("failed to register 'Copy to Online' runner factory: %w", err))
 	}
@@ -53,7 +43,6 @@ func main() {
 	}
 	logger := logging.NewLogger("coordinator")
 	defer logger.Sync()
-	logger.Debug("Connected to ETCD")
 	client, err := metadata.NewClient(metadataUrl, logger)
 	if err != nil {
 		logger.Errorw("Failed to connect: %v", err)
@@ -66,7 +55,37 @@ func main() {
 	} else {
 		spawner = &coordinator.KubernetesJobSpawner{EtcdConfig: etcdConfig}
 	}
-	coord, err := coordinator.NewCoordinator(client, logger, cli, spawner)
+	var coord *coordinator.Coordinator
+	// Jobs are read from wherever the metadata server stores them.
+	if help.GetEnv("METADATA_STORAGE", "etcd") == "sql" {
+		driver := help.GetEnv("METADATA_SQL_DRIVER", string(metadata.PostgresDriver))
+		fmt.Printf("reading jobs from %s\n", driver)
+		var storage metadata.SQLStorage
+		storage, err = metadata.SQLConfig{
+			Driver:     metadata.SQLDriver(driver),
+			DataSource: help.GetEnv("METADATA_SQL_DSN", ""),
+		}.Open()
+		if err != nil {
+			panic(fmt.Errorf("failed to open metadata database: %w", err))
+		}
+		defer storage.DB.Close()
+		coord, err = coordinator.NewSQLCoordinator(client, logger, storage, spawner)
+	} else {
+		fmt.Printf("connecting to etcd: %s\n", etcdUrl)
+		var cli *clientv3.Client
+		cli, err = clientv3.New(etcdConfig)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("connected to etcd")
+		defer func(cli *clientv3.Client) {
+			err := cli.Close()
+			if err != nil {
+				panic(fmt.Errorf("failed to close etcd client: %w", err))
+			}
+		}(cli)
+		coord, err = coordinator.NewCoordinator(client, logger, cli, spawner)
+	}
 	if err != nil {
 		logger.Errorw("Failed to set up coordinator: %v", err)
 		panic(err)
@@ -626,7 +626,7 @@ func createTrainingSetWithProvider(sourceName string, featureName string, labelN
 			Variant:     "",
 			Description: "",
 			Type:        string(provider.Int),
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Entity:      entityName,
 			Owner:       userName,
 			Provider:    providerName,
@@ -639,7 +639,7 @@ func createTrainingSetWithProvider(sourceName string, featureName string, labelN
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -657,8 +657,8 @@ func createTrainingSetWithProvider(sourceName string, featureName string, labelN
 			Description: "",
 			Owner:       userName,
 			Provider:    providerName,
-			Label:       metadata.NameVariant{labelName, ""},
-			Features:    []metadata.NameVariant{{featureName, ""}},
+			Label:       metadata.NameVariant{Name: labelName, Variant: ""},
+			Features:    []metadata.NameVariant{{Name: featureName, Variant: ""}},
 			Schedule:    schedule,
 		},
 	}
@@ -713,7 +713,7 @@ func materializeFeatureWithProvider(featureName string, sourceName string, origi
 		metadata.FeatureDef{
 			Name:        featureName,
 			Variant:     "",
-			Source:      metadata.NameVariant{sourceName, ""},
+			Source:      metadata.NameVariant{Name: sourceName, Variant: ""},
 			Type:        string(provider.Int),
 			Entity:      entityName,
 			Owner:       userName,
@@ -277,6 +277,16 @@ func getPodLogs(namespace string, name string) string {
 	return str
 }
 
+// JobFailedError is a job that failed while running, with its pod's logs.
+type JobFailedError struct {
+	Job  string
+	Logs string
+}
+
+func (e JobFailedError) Error() string {
+	return fmt.Sprintf("job failed while running: container: %s", e.Job)
+}
+
 func (k KubernetesCompletionWatcher) Wait() error {
 	watcher, err := k.jobClient.Watch()
 	if err != nil {
@@ -292,8 +302,7 @@ func (k KubernetesCompletionWatcher) Wait() error {
 				return nil
 			}
 			if failed := job.Status.Failed; failed > 0 {
-				return fmt.Errorf("job failed while running: container: %s: error: %s",
-					job.Name, getPodLogs(job.Namespace, job.GetName()))
+				return JobFailedError{Job: job.Name, Logs: getPodLogs(job.Namespace, job.GetName())}
 			}
 		}
 
@@ -0,0 +1,355 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// UserMetadataKey is the gRPC metadata key that carries the user making a
+// request. Requests without it are recorded with an empty user.
+const UserMetadataKey = "featureform-user"
+
+// ContextWithUser returns a context that sends user as the acting user of
+// outgoing metadata requests.
+func ContextWithUser(ctx context.Context, user string) context.Context {
+	return grpcmd.AppendToOutgoingContext(ctx, UserMetadataKey, user)
+}
+
+func userFromContext(ctx context.Context) string {
+	md, ok := grpcmd.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	users := md.Get(UserMetadataKey)
+	if len(users) == 0 {
+		return ""
+	}
+	return users[0]
+}
+
+// AuditLog stores a record of every change made to resources.
+type AuditLog interface {
+	Append(event *pb.AuditEvent) error
+	// History returns the events of a resource, oldest first.
+	History(id ResourceID) ([]*pb.AuditEvent, error)
+	// Range returns the events of all resources between start and end
+	// inclusive, oldest first.
+	Range(start, end time.Time) ([]*pb.AuditEvent, error)
+	// Watch calls fn with each event appended from startRevision on, or
+	// from now if startRevision is 0, until ctx is done or fn fails.
+	Watch(ctx context.Context, startRevision int64, fn func(*pb.AuditEvent) error) error
+}
+
+// ErrWatchBehind is returned by watches that fell too far behind the events
+// being appended. They can be resumed from the last revision they saw.
+var ErrWatchBehind = status.Error(codes.ResourceExhausted, "watch fell behind")
+
+// Events are buffered for each watch up to this many before it's dropped.
+const watchBufferSize = 256
+
+type LocalAuditLog struct {
+	mtx      sync.RWMutex
+	events   []*pb.AuditEvent
+	revision int64
+	watches  map[chan *pb.AuditEvent]bool
+}
+
+func NewLocalAuditLog() *LocalAuditLog {
+	return &LocalAuditLog{
+		events:  make([]*pb.AuditEvent, 0),
+		watches: make(map[chan *pb.AuditEvent]bool),
+	}
+}
+
+// Append gives the event the next revision if it doesn't have one.
+func (auditLog *LocalAuditLog) Append(event *pb.AuditEvent) error {
+	auditLog.mtx.Lock()
+	defer auditLog.mtx.Unlock()
+	if event.Revision == 0 {
+		event.Revision = auditLog.revision + 1
+	}
+	auditLog.revision = event.Revision
+	auditLog.events = append(auditLog.events, event)
+	for watch := range auditLog.watches {
+		select {
+		case watch <- event:
+		default:
+			delete(auditLog.watches, watch)
+			close(watch)
+		}
+	}
+	return nil
+}
+
+func (auditLog *LocalAuditLog) Watch(ctx context.Context, startRevision int64, fn func(*pb.AuditEvent) error) error {
+	auditLog.mtx.Lock()
+	backlog := make([]*pb.AuditEvent, 0)
+	if startRevision > 0 {
+		for _, event := range auditLog.events {
+			if event.Revision >= startRevision {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+	watch := make(chan *pb.AuditEvent, watchBufferSize)
+	auditLog.watches[watch] = true
+	auditLog.mtx.Unlock()
+	defer func() {
+		auditLog.mtx.Lock()
+		delete(auditLog.watches, watch)
+		auditLog.mtx.Unlock()
+	}()
+	for _, event := range backlog {
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watch:
+			if !ok {
+				return ErrWatchBehind
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (auditLog *LocalAuditLog) History(id ResourceID) ([]*pb.AuditEvent, error) {
+	auditLog.mtx.RLock()
+	defer auditLog.mtx.RUnlock()
+	events := make([]*pb.AuditEvent, 0)
+	for _, event := range auditLog.events {
+		if resourceIDFromProto(event.ResourceId) == id {
+			events = append(events, event)
+		}
+	}
+	sortAuditEvents(events)
+	return events, nil
+}
+
+func (auditLog *LocalAuditLog) Range(start, end time.Time) ([]*pb.AuditEvent, error) {
+	auditLog.mtx.RLock()
+	defer auditLog.mtx.RUnlock()
+	events := make([]*pb.AuditEvent, 0)
+	for _, event := range auditLog.events {
+		if eventInRange(event, start, end) {
+			events = append(events, event)
+		}
+	}
+	sortAuditEvents(events)
+	return events, nil
+}
+
+func eventInRange(event *pb.AuditEvent, start, end time.Time) bool {
+	ts := event.Timestamp.AsTime()
+	return !ts.Before(start) && !ts.After(end)
+}
+
+func sortAuditEvents(events []*pb.AuditEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.AsTime().Before(events[j].Timestamp.AsTime())
+	})
+}
+
+// Fields whose values are never written to the audit log. Changes to them
+// are still recorded.
+var redactedFields = map[string]bool{
+	"serializedConfig": true,
+}
+
+const redactedValue = `"<redacted>"`
+
+// Fields that change with every write, so aren't part of diffs.
+var unauditedFields = map[string]bool{
+	"revision": true,
+}
+
+// diffResources compares the top level fields of two resources. Either may
+// be nil, in which case every field of the other is part of the diff.
+func diffResources(before, after proto.Message) ([]*pb.FieldChange, error) {
+	oldFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(oldFields)+len(newFields))
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, has := oldFields[name]; !has {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	changes := make([]*pb.FieldChange, 0)
+	for _, name := range names {
+		oldValue, newValue := oldFields[name], newFields[name]
+		if oldValue == newValue || unauditedFields[name] {
+			continue
+		}
+		if redactedFields[name] {
+			if oldValue != "" {
+				oldValue = redactedValue
+			}
+			if newValue != "" {
+				newValue = redactedValue
+			}
+		}
+		changes = append(changes, &pb.FieldChange{Field: name, OldValue: oldValue, NewValue: newValue})
+	}
+	return changes, nil
+}
+
+func jsonFields(msg proto.Message) (map[string]string, error) {
+	fields := make(map[string]string)
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return fields, nil
+	}
+	serialized, err := protojson.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(serialized, &raw); err != nil {
+		return nil, err
+	}
+	for name, value := range raw {
+		// protojson doesn't promise stable whitespace.
+		var compacted bytes.Buffer
+		if err := json.Compact(&compacted, value); err != nil {
+			return nil, err
+		}
+		fields[name] = compacted.String()
+	}
+	return fields, nil
+}
+
+// audit records the change from before to after in the audit log. Updates
+// that don't change anything aren't recorded.
+func (serv *MetadataServer) audit(ctx context.Context, action pb.AuditEvent_Action, id ResourceID, before, after proto.Message) {
+	changes, err := diffResources(before, after)
+	if err != nil {
+		serv.Logger.Errorw("Could not diff resource for audit log", "id", id.String(), "error", err)
+		return
+	}
+	if len(changes) == 0 && (action == pb.AuditEvent_UPDATE || action == pb.AuditEvent_STATUS_CHANGE) {
+		return
+	}
+	serv.recordAuditEvent(ctx, action, id, changes)
+}
+
+// recordAuditEvent appends an event to the audit log. The change it records
+// has already been made, so failures are logged rather than returned.
+func (serv *MetadataServer) recordAuditEvent(ctx context.Context, action pb.AuditEvent_Action, id ResourceID, changes []*pb.FieldChange) {
+	event := &pb.AuditEvent{
+		ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+		Action:     action,
+		User:       userFromContext(ctx),
+		Timestamp:  tspb.Now(),
+		Changes:    changes,
+	}
+	if err := serv.auditLog.Append(event); err != nil {
+		serv.Logger.Errorw("Could not append to audit log", "id", id.String(), "action", action.String(), "error", err)
+	}
+}
+
+func (serv *MetadataServer) GetResourceHistory(req *pb.ResourceID, stream pb.Metadata_GetResourceHistoryServer) error {
+	id := resourceIDFromProto(req)
+	events, err := serv.auditLog.History(id)
+	if err != nil {
+		serv.Logger.Errorw("Could not get resource history", "id", id.String(), "error", err)
+		return err
+	}
+	for _, event := range events {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (serv *MetadataServer) ListAuditEvents(req *pb.AuditEventsRequest, stream pb.Metadata_ListAuditEventsServer) error {
+	end := time.Now()
+	if req.End != nil {
+		end = req.End.AsTime()
+	}
+	events, err := serv.auditLog.Range(req.GetStart().AsTime(), end)
+	if err != nil {
+		serv.Logger.Errorw("Could not list audit events", "error", err)
+		return err
+	}
+	for _, event := range events {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func watchMatches(req *pb.WatchRequest, event *pb.AuditEvent) bool {
+	if len(req.GetResourceTypes()) > 0 {
+		found := false
+		for _, t := range req.ResourceTypes {
+			if t == event.GetResourceId().GetResourceType() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(req.GetNames()) > 0 {
+		found := false
+		for _, name := range req.Names {
+			if name == event.GetResourceId().GetResource().GetName() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (serv *MetadataServer) Watch(req *pb.WatchRequest, stream pb.Metadata_WatchServer) error {
+	ctx := stream.Context()
+	err := serv.auditLog.Watch(ctx, req.GetStartRevision(), func(event *pb.AuditEvent) error {
+		if !watchMatches(req, event) {
+			return nil
+		}
+		return stream.Send(event)
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	serv.Logger.Errorw("Watch failed", "start_revision", req.GetStartRevision(), "error", err)
+	return err
+}
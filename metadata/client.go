@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/featureform/metadata/notify"
 	pb "github.com/featureform/metadata/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -23,12 +24,15 @@ import (
 type NameVariant struct {
 	Name    string
 	Variant string
+	// Namespace is empty for the default namespace.
+	Namespace string
 }
 
 func (variant NameVariant) Serialize() *pb.NameVariant {
 	return &pb.NameVariant{
-		Name:    variant.Name,
-		Variant: variant.Variant,
+		Name:      variant.Name,
+		Variant:   variant.Variant,
+		Namespace: variant.Namespace,
 	}
 }
 
@@ -38,8 +42,9 @@ func (variant NameVariant) ClientString() string {
 
 func parseNameVariant(serialized *pb.NameVariant) NameVariant {
 	return NameVariant{
-		Name:    serialized.Name,
-		Variant: serialized.Variant,
+		Name:      serialized.Name,
+		Variant:   serialized.Variant,
+		Namespace: serialized.Namespace,
 	}
 }
 
@@ -89,6 +94,17 @@ type Client struct {
 	Logger   *zap.SugaredLogger
 	conn     *grpc.ClientConn
 	GrpcConn pb.MetadataClient
+	// namespace is where resources given by name alone are looked up.
+	namespace string
+}
+
+// InNamespace returns a client that gets and lists resources given by name
+// alone in namespace. Calls that take NameVariants or definitions use the
+// namespaces in them.
+func (client *Client) InNamespace(namespace string) *Client {
+	namespaced := *client
+	namespaced.namespace = namespace
+	return &namespaced
 }
 
 type ResourceDef interface {
@@ -97,22 +113,156 @@ type ResourceDef interface {
 
 // accessible to the frontend as it does not directly change status in metadata
 func (client *Client) RequestScheduleChange(ctx context.Context, resID ResourceID, schedule string) error {
-	nameVariant := pb.NameVariant{Name: resID.Name, Variant: resID.Variant}
-	resourceID := pb.ResourceID{Resource: &nameVariant, ResourceType: resID.Type.Serialized()}
+	resourceID := pb.ResourceID{Resource: resID.Proto(), ResourceType: resID.Type.Serialized()}
 	scheduleChangeRequest := pb.ScheduleChangeRequest{ResourceId: &resourceID, Schedule: schedule}
 	_, err := client.GrpcConn.RequestScheduleChange(ctx, &scheduleChangeRequest)
 	return err
 }
 
 func (client *Client) SetStatus(ctx context.Context, resID ResourceID, status ResourceStatus, errorMessage string) error {
-	nameVariant := pb.NameVariant{Name: resID.Name, Variant: resID.Variant}
-	resourceID := pb.ResourceID{Resource: &nameVariant, ResourceType: resID.Type.Serialized()}
+	return client.SetStatusIfRevision(ctx, resID, status, errorMessage, 0)
+}
+
+// SetStatusIfRevision fails with an Aborted status if the resource isn't at
+// revision. A revision of 0 sets the status whatever it's at.
+func (client *Client) SetStatusIfRevision(ctx context.Context, resID ResourceID, status ResourceStatus, errorMessage string, revision int64) error {
+	resourceID := pb.ResourceID{Resource: resID.Proto(), ResourceType: resID.Type.Serialized()}
 	resourceStatus := pb.ResourceStatus{Status: pb.ResourceStatus_Status(status), ErrorMessage: errorMessage}
-	statusRequest := pb.SetStatusRequest{ResourceId: &resourceID, Status: &resourceStatus}
+	statusRequest := pb.SetStatusRequest{ResourceId: &resourceID, Status: &resourceStatus, ExpectedRevision: revision}
 	_, err := client.GrpcConn.SetResourceStatus(ctx, &statusRequest)
 	return err
 }
 
+// SetFailed sets a resource's status to FAILED with why it failed. Failures
+// without a time are given the time they're recorded.
+func (client *Client) SetFailed(ctx context.Context, resID ResourceID, failure ResourceError) error {
+	resourceID := pb.ResourceID{Resource: resID.Proto(), ResourceType: resID.Type.Serialized()}
+	resourceStatus := pb.ResourceStatus{Status: pb.ResourceStatus_FAILED, ErrorMessage: failure.Message, Error: failure.Serialize()}
+	_, err := client.GrpcConn.SetResourceStatus(ctx, &pb.SetStatusRequest{ResourceId: &resourceID, Status: &resourceStatus})
+	return err
+}
+
+// DeleteResource deletes a resource, and with force everything that depends on
+// it. It returns the resources that were deleted.
+func (client *Client) DeleteResource(ctx context.Context, resID ResourceID, force, cleanup bool) ([]ResourceID, error) {
+	resourceID := pb.ResourceID{Resource: resID.Proto(), ResourceType: resID.Type.Serialized()}
+	resp, err := client.GrpcConn.DeleteResource(ctx, &pb.DeleteResourceRequest{ResourceId: &resourceID, Force: force, Cleanup: cleanup})
+	if err != nil {
+		return nil, err
+	}
+	return resourceIDsFromProto(resp.Deleted), nil
+}
+
+// ArchiveResource archives a resource, and with force everything that depends
+// on it. It returns the resources that were archived.
+func (client *Client) ArchiveResource(ctx context.Context, resID ResourceID, force bool) ([]ResourceID, error) {
+	resourceID := pb.ResourceID{Resource: resID.Proto(), ResourceType: resID.Type.Serialized()}
+	resp, err := client.GrpcConn.ArchiveResource(ctx, &pb.ArchiveResourceRequest{ResourceId: &resourceID, Force: force})
+	if err != nil {
+		return nil, err
+	}
+	return resourceIDsFromProto(resp.Archived), nil
+}
+
+// GetResourceHistory returns every recorded change to a resource, oldest
+// first.
+func (client *Client) GetResourceHistory(ctx context.Context, resID ResourceID) ([]*pb.AuditEvent, error) {
+	stream, err := client.GrpcConn.GetResourceHistory(ctx, &pb.ResourceID{Resource: resID.Proto(), ResourceType: resID.Type.Serialized()})
+	if err != nil {
+		return nil, err
+	}
+	return parseAuditEventStream(stream)
+}
+
+// ListAuditEvents returns the changes made to any resource between start and
+// end, oldest first.
+func (client *Client) ListAuditEvents(ctx context.Context, start, end time.Time) ([]*pb.AuditEvent, error) {
+	stream, err := client.GrpcConn.ListAuditEvents(ctx, &pb.AuditEventsRequest{Start: tspb.New(start), End: tspb.New(end)})
+	if err != nil {
+		return nil, err
+	}
+	return parseAuditEventStream(stream)
+}
+
+// WatchOptions selects the changes a watch is sent. Empty selections match
+// every resource.
+type WatchOptions struct {
+	Types []ResourceType
+	Names []string
+	// StartRevision replays changes from a revision on. Watches resume from
+	// one past the last revision received. Zero only sends new changes.
+	StartRevision int64
+}
+
+func (opts WatchOptions) Serialize() *pb.WatchRequest {
+	types := make([]pb.ResourceType, len(opts.Types))
+	for i, t := range opts.Types {
+		types[i] = t.Serialized()
+	}
+	return &pb.WatchRequest{
+		ResourceTypes: types,
+		Names:         opts.Names,
+		StartRevision: opts.StartRevision,
+	}
+}
+
+// Watch calls fn with each change to a resource as it's made, until ctx is
+// done, fn returns an error or the watch fails.
+func (client *Client) Watch(ctx context.Context, opts WatchOptions, fn func(*pb.AuditEvent) error) error {
+	stream, err := client.GrpcConn.Watch(ctx, opts.Serialize())
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+}
+
+type auditEventStream interface {
+	Recv() (*pb.AuditEvent, error)
+}
+
+func parseAuditEventStream(stream auditEventStream) ([]*pb.AuditEvent, error) {
+	events := make([]*pb.AuditEvent, 0)
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+}
+
+// GetLineage returns the resources upstream and downstream of a resource, up
+// to depth edges away. A depth of 0 returns everything.
+func (client *Client) GetLineage(ctx context.Context, resID ResourceID, direction pb.LineageRequest_Direction, depth int) (*pb.Lineage, error) {
+	return client.GrpcConn.GetLineage(ctx, &pb.LineageRequest{
+		ResourceId: &pb.ResourceID{Resource: resID.Proto(), ResourceType: resID.Type.Serialized()},
+		Direction:  direction,
+		Depth:      int32(depth),
+	})
+}
+
+func resourceIDsFromProto(serialized []*pb.ResourceID) []ResourceID {
+	ids := make([]ResourceID, len(serialized))
+	for i, id := range serialized {
+		ids[i] = resourceIDFromProto(id)
+	}
+	return ids
+}
+
 func (client *Client) CreateAll(ctx context.Context, defs []ResourceDef) error {
 	for _, def := range defs {
 		if err := client.Create(ctx, def); err != nil {
@@ -140,17 +290,222 @@ func (client *Client) Create(ctx context.Context, def ResourceDef) error {
 		return client.CreateEntity(ctx, casted)
 	case ModelDef:
 		return client.CreateModel(ctx, casted)
+	case FeatureGroupDef:
+		return client.CreateFeatureGroupVariant(ctx, casted)
+	case ModelVariantDef:
+		return client.CreateModelVariant(ctx, casted)
+	case DefinitionDef:
+		return client.createDefinition(ctx, casted.Definition)
 	default:
 		return fmt.Errorf("%T not implemented in Create", casted)
 	}
 }
 
-func (client *Client) ListFeatures(ctx context.Context) ([]*Feature, error) {
-	stream, err := client.GrpcConn.ListFeatures(ctx, &pb.Empty{})
+// Plan reports what CreateAll would do with defs without changing anything.
+// There's a plan for every def, in the same order.
+func (client *Client) Plan(ctx context.Context, defs []ResourceDef) ([]*pb.ResourcePlan, error) {
+	definitions := make([]*pb.ResourceDefinition, len(defs))
+	for i, def := range defs {
+		definition, err := serializeDefinition(def)
+		if err != nil {
+			return nil, err
+		}
+		definitions[i] = definition
+	}
+	resp, err := client.GrpcConn.Plan(ctx, &pb.PlanRequest{Definitions: definitions})
 	if err != nil {
 		return nil, err
 	}
-	return client.parseFeatureStream(stream)
+	return resp.Plans, nil
+}
+
+func serializeDefinition(def ResourceDef) (*pb.ResourceDefinition, error) {
+	var err error
+	definition := &pb.ResourceDefinition{}
+	switch casted := def.(type) {
+	case FeatureDef:
+		var serialized *pb.FeatureVariant
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_FeatureVariant{FeatureVariant: serialized}
+	case LabelDef:
+		var serialized *pb.LabelVariant
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_LabelVariant{LabelVariant: serialized}
+	case TrainingSetDef:
+		var serialized *pb.TrainingSetVariant
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_TrainingSetVariant{TrainingSetVariant: serialized}
+	case SourceDef:
+		var serialized *pb.SourceVariant
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_SourceVariant{SourceVariant: serialized}
+	case UserDef:
+		var serialized *pb.User
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_User{User: serialized}
+	case ProviderDef:
+		var serialized *pb.Provider
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_Provider{Provider: serialized}
+	case EntityDef:
+		var serialized *pb.Entity
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_Entity{Entity: serialized}
+	case ModelDef:
+		var serialized *pb.Model
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_Model{Model: serialized}
+	case FeatureGroupDef:
+		var serialized *pb.FeatureGroupVariant
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_FeatureGroupVariant{FeatureGroupVariant: serialized}
+	case ModelVariantDef:
+		var serialized *pb.ModelVariant
+		serialized, err = casted.Serialize()
+		definition.Resource = &pb.ResourceDefinition_ModelVariant{ModelVariant: serialized}
+	case DefinitionDef:
+		return casted.Definition, nil
+	default:
+		return nil, fmt.Errorf("%T not implemented in Plan", casted)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+// DefinitionDef is a ResourceDef that's already serialized, like the
+// resources of a Manifest.
+type DefinitionDef struct {
+	Definition *pb.ResourceDefinition
+}
+
+func (def DefinitionDef) ResourceType() ResourceType {
+	switch def.Definition.Resource.(type) {
+	case *pb.ResourceDefinition_User:
+		return USER
+	case *pb.ResourceDefinition_Provider:
+		return PROVIDER
+	case *pb.ResourceDefinition_Entity:
+		return ENTITY
+	case *pb.ResourceDefinition_SourceVariant:
+		return SOURCE_VARIANT
+	case *pb.ResourceDefinition_FeatureVariant:
+		return FEATURE_VARIANT
+	case *pb.ResourceDefinition_LabelVariant:
+		return LABEL_VARIANT
+	case *pb.ResourceDefinition_TrainingSetVariant:
+		return TRAINING_SET_VARIANT
+	case *pb.ResourceDefinition_FeatureGroupVariant:
+		return FEATURE_GROUP_VARIANT
+	case *pb.ResourceDefinition_ModelVariant:
+		return MODEL_VARIANT
+	default:
+		return MODEL
+	}
+}
+
+func (client *Client) createDefinition(ctx context.Context, definition *pb.ResourceDefinition) error {
+	var err error
+	switch casted := definition.Resource.(type) {
+	case *pb.ResourceDefinition_User:
+		_, err = client.GrpcConn.CreateUser(ctx, casted.User)
+	case *pb.ResourceDefinition_Provider:
+		_, err = client.GrpcConn.CreateProvider(ctx, casted.Provider)
+	case *pb.ResourceDefinition_Entity:
+		_, err = client.GrpcConn.CreateEntity(ctx, casted.Entity)
+	case *pb.ResourceDefinition_SourceVariant:
+		_, err = client.GrpcConn.CreateSourceVariant(ctx, casted.SourceVariant)
+	case *pb.ResourceDefinition_FeatureVariant:
+		_, err = client.GrpcConn.CreateFeatureVariant(ctx, casted.FeatureVariant)
+	case *pb.ResourceDefinition_LabelVariant:
+		_, err = client.GrpcConn.CreateLabelVariant(ctx, casted.LabelVariant)
+	case *pb.ResourceDefinition_TrainingSetVariant:
+		_, err = client.GrpcConn.CreateTrainingSetVariant(ctx, casted.TrainingSetVariant)
+	case *pb.ResourceDefinition_Model:
+		_, err = client.GrpcConn.CreateModel(ctx, casted.Model)
+	case *pb.ResourceDefinition_FeatureGroupVariant:
+		_, err = client.GrpcConn.CreateFeatureGroupVariant(ctx, casted.FeatureGroupVariant)
+	case *pb.ResourceDefinition_ModelVariant:
+		_, err = client.GrpcConn.CreateModelVariant(ctx, casted.ModelVariant)
+	default:
+		return fmt.Errorf("%T not implemented in Create", casted)
+	}
+	return err
+}
+
+type ListSort int32
+
+const (
+	SortByName    ListSort = ListSort(pb.ListRequest_NAME)
+	SortByCreated          = ListSort(pb.ListRequest_CREATED)
+)
+
+// ListOptions page, filter and sort lists. Resources with variants match a
+// filter if any of their variants do. The zero value lists everything by name.
+type ListOptions struct {
+	// Everything is listed if PageSize isn't set.
+	PageSize  int
+	PageToken string
+	Owner     string
+	Statuses  []ResourceStatus
+	Provider  string
+	Tag       string
+	// Resources created at or before CreatedAfter are left out if it's set.
+	CreatedAfter time.Time
+	SortBy       ListSort
+	Descending   bool
+	// Namespace is the namespace to list, the client's if unset.
+	Namespace string
+}
+
+func (opts ListOptions) Serialize() *pb.ListRequest {
+	filter := &pb.ListFilter{
+		Owner:     opts.Owner,
+		Provider:  opts.Provider,
+		Tag:       opts.Tag,
+		Namespace: opts.Namespace,
+	}
+	for _, status := range opts.Statuses {
+		filter.Statuses = append(filter.Statuses, pb.ResourceStatus_Status(status))
+	}
+	if !opts.CreatedAfter.IsZero() {
+		filter.CreatedAfter = tspb.New(opts.CreatedAfter)
+	}
+	return &pb.ListRequest{
+		PageSize:   int32(opts.PageSize),
+		PageToken:  opts.PageToken,
+		Filter:     filter,
+		SortBy:     pb.ListRequest_SortBy(opts.SortBy),
+		Descending: opts.Descending,
+	}
+}
+
+// listRequest lists the client's namespace unless opts gives another.
+func (client *Client) listRequest(opts ListOptions) *pb.ListRequest {
+	if opts.Namespace == "" {
+		opts.Namespace = client.namespace
+	}
+	return opts.Serialize()
+}
+
+func (client *Client) ListFeatures(ctx context.Context) ([]*Feature, error) {
+	features, _, err := client.ListFeaturesPage(ctx, ListOptions{})
+	return features, err
+}
+
+// ListFeaturesPage returns a page of features and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListFeaturesPage(ctx context.Context, opts ListOptions) ([]*Feature, string, error) {
+	stream, err := client.GrpcConn.ListFeatures(ctx, client.listRequest(opts))
+	if err != nil {
+		return nil, "", err
+	}
+	features, err := client.parseFeatureStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return features, nextPageToken(stream), nil
 }
 
 func (client *Client) GetFeature(ctx context.Context, feature string) (*Feature, error) {
@@ -168,7 +523,7 @@ func (client *Client) GetFeatures(ctx context.Context, features []string) ([]*Fe
 	}
 	go func() {
 		for _, feature := range features {
-			stream.Send(&pb.Name{Name: feature})
+			stream.Send(&pb.Name{Name: feature, Namespace: client.namespace})
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -185,7 +540,7 @@ func (client *Client) GetFeatureVariants(ctx context.Context, ids []NameVariant)
 	}
 	go func() {
 		for _, id := range ids {
-			stream.Send(&pb.NameVariant{Name: id.Name, Variant: id.Variant})
+			stream.Send(id.Serialize())
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -223,6 +578,7 @@ type FeatureDef struct {
 	Mode        ComputationMode
 	IsOnDemand  bool
 	IsEmbedding bool
+	Namespace   string
 }
 
 type ResourceVariantColumns struct {
@@ -268,7 +624,7 @@ func (def FeatureDef) ResourceType() ResourceType {
 	return FEATURE_VARIANT
 }
 
-func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef) error {
+func (def FeatureDef) Serialize() (*pb.FeatureVariant, error) {
 	serialized := &pb.FeatureVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -284,6 +640,7 @@ func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef)
 		Properties:  def.Properties.Serialize(),
 		Mode:        pb.ComputationMode(def.Mode),
 		IsEmbedding: def.IsEmbedding,
+		Namespace:   def.Namespace,
 	}
 	switch x := def.Location.(type) {
 	case ResourceVariantColumns:
@@ -291,11 +648,19 @@ func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef)
 	case PythonFunction:
 		serialized.Location = def.Location.(PythonFunction).SerializePythonFunction()
 	case nil:
-		return fmt.Errorf("FeatureDef Columns not set")
+		return nil, fmt.Errorf("FeatureDef Columns not set")
 	default:
-		return fmt.Errorf("FeatureDef Columns has unexpected type %T", x)
+		return nil, fmt.Errorf("FeatureDef Columns has unexpected type %T", x)
+	}
+	return serialized, nil
+}
+
+func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
 	}
-	_, err := client.GrpcConn.CreateFeatureVariant(ctx, serialized)
+	_, err = client.GrpcConn.CreateFeatureVariant(ctx, serialized)
 	return err
 }
 
@@ -336,11 +701,22 @@ func (client *Client) parseFeatureVariantStream(stream featureVariantStream) ([]
 }
 
 func (client *Client) ListLabels(ctx context.Context) ([]*Label, error) {
-	stream, err := client.GrpcConn.ListLabels(ctx, &pb.Empty{})
+	labels, _, err := client.ListLabelsPage(ctx, ListOptions{})
+	return labels, err
+}
+
+// ListLabelsPage returns a page of labels and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListLabelsPage(ctx context.Context, opts ListOptions) ([]*Label, string, error) {
+	stream, err := client.GrpcConn.ListLabels(ctx, client.listRequest(opts))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return client.parseLabelStream(stream)
+	labels, err := client.parseLabelStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return labels, nextPageToken(stream), nil
 }
 
 func (client *Client) GetLabel(ctx context.Context, label string) (*Label, error) {
@@ -358,7 +734,7 @@ func (client *Client) GetLabels(ctx context.Context, labels []string) ([]*Label,
 	}
 	go func() {
 		for _, label := range labels {
-			stream.Send(&pb.Name{Name: label})
+			stream.Send(&pb.Name{Name: label, Namespace: client.namespace})
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -380,13 +756,14 @@ type LabelDef struct {
 	Location    interface{}
 	Tags        Tags
 	Properties  Properties
+	Namespace   string
 }
 
 func (def LabelDef) ResourceType() ResourceType {
 	return LABEL_VARIANT
 }
 
-func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) error {
+func (def LabelDef) Serialize() (*pb.LabelVariant, error) {
 	serialized := &pb.LabelVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -399,16 +776,25 @@ func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) erro
 		Provider:    def.Provider,
 		Tags:        &pb.Tags{Tag: def.Tags},
 		Properties:  def.Properties.Serialize(),
+		Namespace:   def.Namespace,
 	}
 	switch x := def.Location.(type) {
 	case ResourceVariantColumns:
 		serialized.Location = def.Location.(ResourceVariantColumns).SerializeLabelColumns()
 	case nil:
-		return fmt.Errorf("LabelDef Primary not set")
+		return nil, fmt.Errorf("LabelDef Primary not set")
 	default:
-		return fmt.Errorf("LabelDef Primary has unexpected type %T", x)
+		return nil, fmt.Errorf("LabelDef Primary has unexpected type %T", x)
+	}
+	return serialized, nil
+}
+
+func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
 	}
-	_, err := client.GrpcConn.CreateLabelVariant(ctx, serialized)
+	_, err = client.GrpcConn.CreateLabelVariant(ctx, serialized)
 	return err
 }
 
@@ -419,7 +805,7 @@ func (client *Client) GetLabelVariants(ctx context.Context, ids []NameVariant) (
 	}
 	go func() {
 		for _, id := range ids {
-			stream.Send(&pb.NameVariant{Name: id.Name, Variant: id.Variant})
+			stream.Send(id.Serialize())
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -474,11 +860,22 @@ func (client *Client) parseLabelVariantStream(stream labelVariantStream) ([]*Lab
 }
 
 func (client *Client) ListTrainingSets(ctx context.Context) ([]*TrainingSet, error) {
-	stream, err := client.GrpcConn.ListTrainingSets(ctx, &pb.Empty{})
+	trainingSets, _, err := client.ListTrainingSetsPage(ctx, ListOptions{})
+	return trainingSets, err
+}
+
+// ListTrainingSetsPage returns a page of training sets and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListTrainingSetsPage(ctx context.Context, opts ListOptions) ([]*TrainingSet, string, error) {
+	stream, err := client.GrpcConn.ListTrainingSets(ctx, client.listRequest(opts))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return client.parseTrainingSetStream(stream)
+	trainingSets, err := client.parseTrainingSetStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return trainingSets, nextPageToken(stream), nil
 }
 
 func (client *Client) GetTrainingSet(ctx context.Context, trainingSet string) (*TrainingSet, error) {
@@ -496,7 +893,7 @@ func (client *Client) GetTrainingSets(ctx context.Context, trainingSets []string
 	}
 	go func() {
 		for _, trainingSet := range trainingSets {
-			stream.Send(&pb.Name{Name: trainingSet})
+			stream.Send(&pb.Name{Name: trainingSet, Namespace: client.namespace})
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -515,15 +912,22 @@ type TrainingSetDef struct {
 	Schedule    string
 	Label       NameVariant
 	Features    NameVariants
-	Tags        Tags
-	Properties  Properties
+	// The features of FeatureGroups are added to Features when the training
+	// set is created.
+	FeatureGroups NameVariants
+	Tags          Tags
+	Properties    Properties
+	Namespace     string
+	// AllowDeprecated creates the training set even if some of its features
+	// are deprecated.
+	AllowDeprecated bool
 }
 
 func (def TrainingSetDef) ResourceType() ResourceType {
 	return TRAINING_SET_VARIANT
 }
 
-func (client *Client) CreateTrainingSetVariant(ctx context.Context, def TrainingSetDef) error {
+func (def TrainingSetDef) Serialize() (*pb.TrainingSetVariant, error) {
 	serialized := &pb.TrainingSetVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -536,8 +940,20 @@ func (client *Client) CreateTrainingSetVariant(ctx context.Context, def Training
 		Schedule:    def.Schedule,
 		Tags:        &pb.Tags{Tag: def.Tags},
 		Properties:  def.Properties.Serialize(),
+		Namespace:   def.Namespace,
+
+		AllowDeprecated: def.AllowDeprecated,
+		FeatureGroups:   def.FeatureGroups.Serialize(),
+	}
+	return serialized, nil
+}
+
+func (client *Client) CreateTrainingSetVariant(ctx context.Context, def TrainingSetDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
 	}
-	_, err := client.GrpcConn.CreateTrainingSetVariant(ctx, serialized)
+	_, err = client.GrpcConn.CreateTrainingSetVariant(ctx, serialized)
 	return err
 }
 
@@ -556,7 +972,7 @@ func (client *Client) GetTrainingSetVariants(ctx context.Context, ids []NameVari
 	}
 	go func() {
 		for _, id := range ids {
-			stream.Send(&pb.NameVariant{Name: id.Name, Variant: id.Variant})
+			stream.Send(id.Serialize())
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -603,11 +1019,22 @@ func (client *Client) parseTrainingSetVariantStream(stream trainingSetVariantStr
 }
 
 func (client *Client) ListSources(ctx context.Context) ([]*Source, error) {
-	stream, err := client.GrpcConn.ListSources(ctx, &pb.Empty{})
+	sources, _, err := client.ListSourcesPage(ctx, ListOptions{})
+	return sources, err
+}
+
+// ListSourcesPage returns a page of sources and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListSourcesPage(ctx context.Context, opts ListOptions) ([]*Source, string, error) {
+	stream, err := client.GrpcConn.ListSources(ctx, client.listRequest(opts))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return client.parseSourceStream(stream)
+	sources, err := client.parseSourceStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return sources, nextPageToken(stream), nil
 }
 
 func (client *Client) GetSource(ctx context.Context, source string) (*Source, error) {
@@ -625,7 +1052,7 @@ func (client *Client) GetSources(ctx context.Context, sources []string) ([]*Sour
 	}
 	go func() {
 		for _, source := range sources {
-			stream.Send(&pb.Name{Name: source})
+			stream.Send(&pb.Name{Name: source, Namespace: client.namespace})
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -645,6 +1072,7 @@ type SourceDef struct {
 	Definition  SourceType
 	Tags        Tags
 	Properties  Properties
+	Namespace   string
 }
 
 type SourceType interface {
@@ -741,7 +1169,7 @@ func (def SourceDef) ResourceType() ResourceType {
 	return SOURCE_VARIANT
 }
 
-func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) error {
+func (def SourceDef) Serialize() (*pb.SourceVariant, error) {
 	serialized := &pb.SourceVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -752,6 +1180,7 @@ func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) er
 		Schedule:    def.Schedule,
 		Tags:        &pb.Tags{Tag: def.Tags},
 		Properties:  def.Properties.Serialize(),
+		Namespace:   def.Namespace,
 	}
 	var err error
 	switch x := def.Definition.(type) {
@@ -760,10 +1189,18 @@ func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) er
 	case PrimaryDataSource:
 		serialized.Definition, err = def.Definition.(PrimaryDataSource).Serialize()
 	case nil:
-		return fmt.Errorf("SourceDef Definition not set")
+		return nil, fmt.Errorf("SourceDef Definition not set")
 	default:
-		return fmt.Errorf("SourceDef Definition has unexpected type %T", x)
+		return nil, fmt.Errorf("SourceDef Definition has unexpected type %T", x)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return serialized, nil
+}
+
+func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) error {
+	serialized, err := def.Serialize()
 	if err != nil {
 		return err
 	}
@@ -778,7 +1215,7 @@ func (client *Client) GetSourceVariants(ctx context.Context, ids []NameVariant)
 	}
 	go func() {
 		for _, id := range ids {
-			err := stream.Send(&pb.NameVariant{Name: id.Name, Variant: id.Variant})
+			err := stream.Send(id.Serialize())
 			if err != nil {
 				client.Logger.Errorw("Failed to send source variant", "name", id.Name, "variant", id.Variant, "error", err)
 			}
@@ -841,11 +1278,22 @@ func (client *Client) parseSourceVariantStream(stream sourceVariantStream) ([]*S
 }
 
 func (client *Client) ListUsers(ctx context.Context) ([]*User, error) {
-	stream, err := client.GrpcConn.ListUsers(ctx, &pb.Empty{})
+	users, _, err := client.ListUsersPage(ctx, ListOptions{})
+	return users, err
+}
+
+// ListUsersPage returns a page of users and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListUsersPage(ctx context.Context, opts ListOptions) ([]*User, string, error) {
+	stream, err := client.GrpcConn.ListUsers(ctx, client.listRequest(opts))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return client.parseUserStream(stream)
+	users, err := client.parseUserStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return users, nextPageToken(stream), nil
 }
 
 func (client *Client) GetUser(ctx context.Context, user string) (*User, error) {
@@ -877,19 +1325,30 @@ type UserDef struct {
 	Name       string
 	Tags       Tags
 	Properties Properties
+	// Notifications replace the user's subscriptions, if there are any.
+	Notifications []notify.Subscription
 }
 
 func (def UserDef) ResourceType() ResourceType {
 	return USER
 }
 
-func (client *Client) CreateUser(ctx context.Context, def UserDef) error {
+func (def UserDef) Serialize() (*pb.User, error) {
 	serialized := &pb.User{
-		Name:       def.Name,
-		Tags:       &pb.Tags{Tag: def.Tags},
-		Properties: def.Properties.Serialize(),
+		Name:          def.Name,
+		Tags:          &pb.Tags{Tag: def.Tags},
+		Properties:    def.Properties.Serialize(),
+		Notifications: serializeSubscriptions(def.Notifications),
+	}
+	return serialized, nil
+}
+
+func (client *Client) CreateUser(ctx context.Context, def UserDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
 	}
-	_, err := client.GrpcConn.CreateUser(ctx, serialized)
+	_, err = client.GrpcConn.CreateUser(ctx, serialized)
 	return err
 }
 
@@ -912,11 +1371,22 @@ func (client *Client) parseUserStream(stream userStream) ([]*User, error) {
 }
 
 func (client *Client) ListProviders(ctx context.Context) ([]*Provider, error) {
-	stream, err := client.GrpcConn.ListProviders(ctx, &pb.Empty{})
+	providers, _, err := client.ListProvidersPage(ctx, ListOptions{})
+	return providers, err
+}
+
+// ListProvidersPage returns a page of providers and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListProvidersPage(ctx context.Context, opts ListOptions) ([]*Provider, string, error) {
+	stream, err := client.GrpcConn.ListProviders(ctx, client.listRequest(opts))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return client.parseProviderStream(stream)
+	providers, err := client.parseProviderStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return providers, nextPageToken(stream), nil
 }
 
 func (client *Client) GetProvider(ctx context.Context, provider string) (*Provider, error) {
@@ -953,13 +1423,15 @@ type ProviderDef struct {
 	SerializedConfig []byte
 	Tags             Tags
 	Properties       Properties
+	// Namespaces the provider is visible to, or every one if empty.
+	Namespaces []string
 }
 
 func (def ProviderDef) ResourceType() ResourceType {
 	return PROVIDER
 }
 
-func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error {
+func (def ProviderDef) Serialize() (*pb.Provider, error) {
 	serialized := &pb.Provider{
 		Name:             def.Name,
 		Description:      def.Description,
@@ -970,8 +1442,17 @@ func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error
 		SerializedConfig: def.SerializedConfig,
 		Tags:             &pb.Tags{Tag: def.Tags},
 		Properties:       def.Properties.Serialize(),
+		Namespaces:       def.Namespaces,
+	}
+	return serialized, nil
+}
+
+func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
 	}
-	_, err := client.GrpcConn.CreateProvider(ctx, serialized)
+	_, err = client.GrpcConn.CreateProvider(ctx, serialized)
 	return err
 }
 
@@ -994,11 +1475,22 @@ func (client *Client) parseProviderStream(stream providerStream) ([]*Provider, e
 }
 
 func (client *Client) ListEntities(ctx context.Context) ([]*Entity, error) {
-	stream, err := client.GrpcConn.ListEntities(ctx, &pb.Empty{})
+	entities, _, err := client.ListEntitiesPage(ctx, ListOptions{})
+	return entities, err
+}
+
+// ListEntitiesPage returns a page of entities and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListEntitiesPage(ctx context.Context, opts ListOptions) ([]*Entity, string, error) {
+	stream, err := client.GrpcConn.ListEntities(ctx, client.listRequest(opts))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return client.parseEntityStream(stream)
+	entities, err := client.parseEntityStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return entities, nextPageToken(stream), nil
 }
 
 func (client *Client) GetEntity(ctx context.Context, entity string) (*Entity, error) {
@@ -1016,7 +1508,7 @@ func (client *Client) GetEntities(ctx context.Context, entities []string) ([]*En
 	}
 	go func() {
 		for _, entity := range entities {
-			stream.Send(&pb.Name{Name: entity})
+			stream.Send(&pb.Name{Name: entity, Namespace: client.namespace})
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -1031,21 +1523,31 @@ type EntityDef struct {
 	Description string
 	Tags        Tags
 	Properties  Properties
+	Namespace   string
 }
 
 func (def EntityDef) ResourceType() ResourceType {
 	return ENTITY
 }
 
-func (client *Client) CreateEntity(ctx context.Context, def EntityDef) error {
+func (def EntityDef) Serialize() (*pb.Entity, error) {
 	serialized := &pb.Entity{
 		Name:        def.Name,
 		Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
 		Description: def.Description,
 		Tags:        &pb.Tags{Tag: def.Tags},
 		Properties:  def.Properties.Serialize(),
+		Namespace:   def.Namespace,
 	}
-	_, err := client.GrpcConn.CreateEntity(ctx, serialized)
+	return serialized, nil
+}
+
+func (client *Client) CreateEntity(ctx context.Context, def EntityDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.GrpcConn.CreateEntity(ctx, serialized)
 	return err
 }
 
@@ -1068,11 +1570,22 @@ func (client *Client) parseEntityStream(stream entityStream) ([]*Entity, error)
 }
 
 func (client *Client) ListModels(ctx context.Context) ([]*Model, error) {
-	stream, err := client.GrpcConn.ListModels(ctx, &pb.Empty{})
+	models, _, err := client.ListModelsPage(ctx, ListOptions{})
+	return models, err
+}
+
+// ListModelsPage returns a page of models and the token of the next page, which is
+// empty if there are no more.
+func (client *Client) ListModelsPage(ctx context.Context, opts ListOptions) ([]*Model, string, error) {
+	stream, err := client.GrpcConn.ListModels(ctx, client.listRequest(opts))
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
-	return client.parseModelStream(stream)
+	models, err := client.parseModelStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return models, nextPageToken(stream), nil
 }
 
 func (client *Client) GetModel(ctx context.Context, model string) (*Model, error) {
@@ -1090,7 +1603,7 @@ func (client *Client) GetModels(ctx context.Context, models []string) ([]*Model,
 	}
 	go func() {
 		for _, model := range models {
-			stream.Send(&pb.Name{Name: model})
+			stream.Send(&pb.Name{Name: model, Namespace: client.namespace})
 		}
 		err := stream.CloseSend()
 		if err != nil {
@@ -1101,62 +1614,318 @@ func (client *Client) GetModels(ctx context.Context, models []string) ([]*Model,
 }
 
 type ModelDef struct {
-	Name         string
-	Description  string
-	Features     NameVariants
-	Trainingsets NameVariants
-	Tags         Tags
-	Properties   Properties
+	Name          string
+	Description   string
+	Features      NameVariants
+	FeatureGroups NameVariants
+	Trainingsets  NameVariants
+	Tags          Tags
+	Properties    Properties
+	Namespace     string
 }
 
 func (def ModelDef) ResourceType() ResourceType {
 	return MODEL
 }
 
-func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
+func (def ModelDef) Serialize() (*pb.Model, error) {
 	serialized := &pb.Model{
-		Name:         def.Name,
-		Description:  def.Description,
-		Features:     def.Features.Serialize(),
-		Trainingsets: def.Trainingsets.Serialize(),
-		Tags:         &pb.Tags{Tag: def.Tags},
-		Properties:   def.Properties.Serialize(),
-	}
-	_, err := client.GrpcConn.CreateModel(ctx, serialized)
+		Name:          def.Name,
+		Description:   def.Description,
+		Features:      def.Features.Serialize(),
+		FeatureGroups: def.FeatureGroups.Serialize(),
+		Trainingsets:  def.Trainingsets.Serialize(),
+		Tags:          &pb.Tags{Tag: def.Tags},
+		Properties:    def.Properties.Serialize(),
+		Namespace:     def.Namespace,
+	}
+	return serialized, nil
+}
+
+func (client *Client) CreateModel(ctx context.Context, def ModelDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.GrpcConn.CreateModel(ctx, serialized)
 	return err
 }
 
-type modelStream interface {
-	Recv() (*pb.Model, error)
+// ModelVariantDef is a model trained on a training set variant. Its
+// features and label are the training set's.
+type ModelVariantDef struct {
+	Name        string
+	Variant     string
+	Description string
+	Owner       string
+	TrainingSet NameVariant
+	Tags        Tags
+	Properties  Properties
+	Namespace   string
 }
 
-func (client *Client) parseModelStream(stream modelStream) ([]*Model, error) {
-	models := make([]*Model, 0)
-	for {
-		serial, err := stream.Recv()
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return nil, err
-		}
-		models = append(models, wrapProtoModel(serial))
-	}
-	return models, nil
+func (def ModelVariantDef) ResourceType() ResourceType {
+	return MODEL_VARIANT
 }
 
-type protoStringer struct {
-	msg proto.Message
+func (def ModelVariantDef) Serialize() (*pb.ModelVariant, error) {
+	serialized := &pb.ModelVariant{
+		Name:        def.Name,
+		Variant:     def.Variant,
+		Description: def.Description,
+		Owner:       def.Owner,
+		TrainingSet: def.TrainingSet.Serialize(),
+		Tags:        &pb.Tags{Tag: def.Tags},
+		Properties:  def.Properties.Serialize(),
+		Namespace:   def.Namespace,
+	}
+	return serialized, nil
 }
 
-func (stringer protoStringer) String() string {
-	bytes, err := protojson.Marshal(stringer.msg)
+func (client *Client) CreateModelVariant(ctx context.Context, def ModelVariantDef) error {
+	serialized, err := def.Serialize()
 	if err != nil {
-		return err.Error()
+		return err
 	}
-	return string(bytes)
+	_, err = client.GrpcConn.CreateModelVariant(ctx, serialized)
+	return err
 }
 
-type createdGetter interface {
+func (client *Client) GetModelVariant(ctx context.Context, id NameVariant) (*ModelVariant, error) {
+	variants, err := client.GetModelVariants(ctx, []NameVariant{id})
+	if err != nil {
+		return nil, err
+	}
+	return variants[0], nil
+}
+
+func (client *Client) GetModelVariants(ctx context.Context, ids []NameVariant) ([]*ModelVariant, error) {
+	stream, err := client.GrpcConn.GetModelVariants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for _, id := range ids {
+			stream.Send(id.Serialize())
+		}
+		err := stream.CloseSend()
+		if err != nil {
+			client.Logger.Errorw("Failed to close send", "Err", err)
+		}
+	}()
+	return client.parseModelVariantStream(stream)
+}
+
+type modelStream interface {
+	Recv() (*pb.Model, error)
+}
+
+func (client *Client) parseModelStream(stream modelStream) ([]*Model, error) {
+	models := make([]*Model, 0)
+	for {
+		serial, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		models = append(models, wrapProtoModel(serial))
+	}
+	return models, nil
+}
+
+type modelVariantStream interface {
+	Recv() (*pb.ModelVariant, error)
+}
+
+func (client *Client) parseModelVariantStream(stream modelVariantStream) ([]*ModelVariant, error) {
+	variants := make([]*ModelVariant, 0)
+	for {
+		serial, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		variants = append(variants, wrapProtoModelVariant(serial))
+	}
+	return variants, nil
+}
+
+func (client *Client) ListFeatureGroups(ctx context.Context) ([]*FeatureGroup, error) {
+	groups, _, err := client.ListFeatureGroupsPage(ctx, ListOptions{})
+	return groups, err
+}
+
+// ListFeatureGroupsPage returns a page of feature groups and the token of the
+// next page, which is empty if there are no more.
+func (client *Client) ListFeatureGroupsPage(ctx context.Context, opts ListOptions) ([]*FeatureGroup, string, error) {
+	stream, err := client.GrpcConn.ListFeatureGroups(ctx, client.listRequest(opts))
+	if err != nil {
+		return nil, "", err
+	}
+	groups, err := client.parseFeatureGroupStream(stream)
+	if err != nil {
+		return nil, "", err
+	}
+	return groups, nextPageToken(stream), nil
+}
+
+func (client *Client) GetFeatureGroup(ctx context.Context, group string) (*FeatureGroup, error) {
+	groups, err := client.GetFeatureGroups(ctx, []string{group})
+	if err != nil {
+		return nil, err
+	}
+	return groups[0], nil
+}
+
+func (client *Client) GetFeatureGroups(ctx context.Context, groups []string) ([]*FeatureGroup, error) {
+	stream, err := client.GrpcConn.GetFeatureGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for _, group := range groups {
+			stream.Send(&pb.Name{Name: group, Namespace: client.namespace})
+		}
+		err := stream.CloseSend()
+		if err != nil {
+			client.Logger.Errorw("Failed to close send", "Err", err)
+		}
+	}()
+	return client.parseFeatureGroupStream(stream)
+}
+
+type FeatureGroupDef struct {
+	Name        string
+	Variant     string
+	Description string
+	Owner       string
+	Entity      string
+	Features    NameVariants
+	Tags        Tags
+	Properties  Properties
+	Namespace   string
+}
+
+func (def FeatureGroupDef) ResourceType() ResourceType {
+	return FEATURE_GROUP_VARIANT
+}
+
+func (def FeatureGroupDef) Serialize() (*pb.FeatureGroupVariant, error) {
+	serialized := &pb.FeatureGroupVariant{
+		Name:        def.Name,
+		Variant:     def.Variant,
+		Description: def.Description,
+		Owner:       def.Owner,
+		Entity:      def.Entity,
+		Features:    def.Features.Serialize(),
+		Tags:        &pb.Tags{Tag: def.Tags},
+		Properties:  def.Properties.Serialize(),
+		Namespace:   def.Namespace,
+	}
+	return serialized, nil
+}
+
+func (client *Client) CreateFeatureGroupVariant(ctx context.Context, def FeatureGroupDef) error {
+	serialized, err := def.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.GrpcConn.CreateFeatureGroupVariant(ctx, serialized)
+	return err
+}
+
+func (client *Client) GetFeatureGroupVariant(ctx context.Context, id NameVariant) (*FeatureGroupVariant, error) {
+	variants, err := client.GetFeatureGroupVariants(ctx, []NameVariant{id})
+	if err != nil {
+		return nil, err
+	}
+	return variants[0], nil
+}
+
+func (client *Client) GetFeatureGroupVariants(ctx context.Context, ids []NameVariant) ([]*FeatureGroupVariant, error) {
+	stream, err := client.GrpcConn.GetFeatureGroupVariants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		for _, id := range ids {
+			stream.Send(id.Serialize())
+		}
+		err := stream.CloseSend()
+		if err != nil {
+			client.Logger.Errorw("Failed to close send", "Err", err)
+		}
+	}()
+	return client.parseFeatureGroupVariantStream(stream)
+}
+
+type featureGroupStream interface {
+	Recv() (*pb.FeatureGroup, error)
+}
+
+func (client *Client) parseFeatureGroupStream(stream featureGroupStream) ([]*FeatureGroup, error) {
+	groups := make([]*FeatureGroup, 0)
+	for {
+		serial, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		groups = append(groups, wrapProtoFeatureGroup(serial))
+	}
+	return groups, nil
+}
+
+type featureGroupVariantStream interface {
+	Recv() (*pb.FeatureGroupVariant, error)
+}
+
+func (client *Client) parseFeatureGroupVariantStream(stream featureGroupVariantStream) ([]*FeatureGroupVariant, error) {
+	variants := make([]*FeatureGroupVariant, 0)
+	for {
+		serial, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		variants = append(variants, wrapProtoFeatureGroupVariant(serial))
+	}
+	return variants, nil
+}
+
+type featureGroupsGetter interface {
+	GetFeatureGroups() []*pb.NameVariant
+}
+
+type fetchFeatureGroupsFns struct {
+	getter featureGroupsGetter
+}
+
+func (fn fetchFeatureGroupsFns) FeatureGroups() NameVariants {
+	return parseNameVariants(fn.getter.GetFeatureGroups())
+}
+
+func (fn fetchFeatureGroupsFns) FetchFeatureGroups(client *Client, ctx context.Context) ([]*FeatureGroupVariant, error) {
+	return client.GetFeatureGroupVariants(ctx, fn.FeatureGroups())
+}
+
+type protoStringer struct {
+	msg proto.Message
+}
+
+func (stringer protoStringer) String() string {
+	bytes, err := protojson.Marshal(stringer.msg)
+	if err != nil {
+		return err.Error()
+	}
+	return string(bytes)
+}
+
+type createdGetter interface {
 	GetCreated() *tspb.Timestamp
 }
 
@@ -1184,6 +1953,7 @@ func (fn lastUpdatedFn) LastUpdated() time.Time {
 
 type variantsDescriber interface {
 	GetName() string
+	GetNamespace() string
 	GetDefaultVariant() string
 	GetVariants() []string
 }
@@ -1196,6 +1966,10 @@ func (fns variantsFns) Name() string {
 	return fns.getter.GetName()
 }
 
+func (fns variantsFns) Namespace() string {
+	return fns.getter.GetNamespace()
+}
+
 func (fns variantsFns) DefaultVariant() string {
 	return fns.getter.GetDefaultVariant()
 }
@@ -1210,8 +1984,9 @@ func (fns variantsFns) NameVariants() NameVariants {
 	nameVariants := make([]NameVariant, len(variants))
 	for i, variant := range variants {
 		nameVariants[i] = NameVariant{
-			Name:    name,
-			Variant: variant,
+			Name:      name,
+			Variant:   variant,
+			Namespace: fns.getter.GetNamespace(),
 		}
 	}
 	return nameVariants
@@ -1313,6 +2088,18 @@ func (fn fetchSourceFns) FetchSource(client *Client, ctx context.Context) (*Sour
 	return client.GetSourceVariant(ctx, fn.Source())
 }
 
+type namespaceGetter interface {
+	GetNamespace() string
+}
+
+type fetchNamespaceFn struct {
+	getter namespaceGetter
+}
+
+func (fn fetchNamespaceFn) Namespace() string {
+	return fn.getter.GetNamespace()
+}
+
 type tagsGetter interface {
 	GetTags() *pb.Tags
 }
@@ -1375,17 +2162,41 @@ func (fn fetchDimensionFn) Dimension() int32 {
 	return fn.getter.GetDimension()
 }
 
+type lifecycleGetter interface {
+	GetLifecycle() *pb.Lifecycle
+}
+
+type fetchLifecycleFn struct {
+	getter lifecycleGetter
+}
+
+func (fn fetchLifecycleFn) Lifecycle() Lifecycle {
+	return parseLifecycle(fn.getter.GetLifecycle())
+}
+
+// Revision is what the resource was at when it was read. Writes given it
+// fail if the resource has changed since.
+type fetchRevisionFn struct {
+	getter revisionGetter
+}
+
+func (fn fetchRevisionFn) Revision() int64 {
+	return fn.getter.GetRevision()
+}
+
 type Feature struct {
 	serialized *pb.Feature
 	variantsFns
 	protoStringer
+	fetchRevisionFn
 }
 
 func wrapProtoFeature(serialized *pb.Feature) *Feature {
 	return &Feature{
-		serialized:    serialized,
-		variantsFns:   variantsFns{serialized},
-		protoStringer: protoStringer{serialized},
+		serialized:      serialized,
+		variantsFns:     variantsFns{serialized},
+		protoStringer:   protoStringer{serialized},
+		fetchRevisionFn: fetchRevisionFn{serialized},
 	}
 }
 
@@ -1395,31 +2206,41 @@ func (feature Feature) FetchVariants(client *Client, ctx context.Context) ([]*Fe
 
 type FeatureVariant struct {
 	serialized *pb.FeatureVariant
+	fetchNamespaceFn
 	fetchTrainingSetsFns
+	fetchFeatureGroupsFns
 	fetchProviderFns
 	fetchSourceFns
 	createdFn
 	lastUpdatedFn
 	protoStringer
+	fetchRevisionFn
+	fetchStatusErrorFn
 	fetchTagsFn
 	fetchPropertiesFn
 	fetchIsEmbeddingFn
 	fetchDimensionFn
+	fetchLifecycleFn
 }
 
 func wrapProtoFeatureVariant(serialized *pb.FeatureVariant) *FeatureVariant {
 	return &FeatureVariant{
-		serialized:           serialized,
-		fetchTrainingSetsFns: fetchTrainingSetsFns{serialized},
-		fetchProviderFns:     fetchProviderFns{serialized},
-		fetchSourceFns:       fetchSourceFns{serialized},
-		createdFn:            createdFn{serialized},
-		lastUpdatedFn:        lastUpdatedFn{serialized},
-		protoStringer:        protoStringer{serialized},
-		fetchTagsFn:          fetchTagsFn{serialized},
-		fetchPropertiesFn:    fetchPropertiesFn{serialized},
-		fetchIsEmbeddingFn:   fetchIsEmbeddingFn{serialized},
-		fetchDimensionFn:     fetchDimensionFn{serialized},
+		serialized:            serialized,
+		fetchNamespaceFn:      fetchNamespaceFn{serialized},
+		fetchTrainingSetsFns:  fetchTrainingSetsFns{serialized},
+		fetchFeatureGroupsFns: fetchFeatureGroupsFns{serialized},
+		fetchProviderFns:      fetchProviderFns{serialized},
+		fetchSourceFns:        fetchSourceFns{serialized},
+		createdFn:             createdFn{serialized},
+		lastUpdatedFn:         lastUpdatedFn{serialized},
+		protoStringer:         protoStringer{serialized},
+		fetchRevisionFn:       fetchRevisionFn{serialized},
+		fetchStatusErrorFn:    fetchStatusErrorFn{serialized},
+		fetchTagsFn:           fetchTagsFn{serialized},
+		fetchPropertiesFn:     fetchPropertiesFn{serialized},
+		fetchIsEmbeddingFn:    fetchIsEmbeddingFn{serialized},
+		fetchDimensionFn:      fetchDimensionFn{serialized},
+		fetchLifecycleFn:      fetchLifecycleFn{serialized},
 	}
 }
 
@@ -1532,6 +2353,7 @@ type User struct {
 	fetchLabelsFns
 	fetchSourcesFns
 	protoStringer
+	fetchRevisionFn
 	fetchTagsFn
 	fetchPropertiesFn
 }
@@ -1548,6 +2370,7 @@ func wrapProtoUser(serialized *pb.User) *User {
 		fetchLabelsFns:       fetchLabelsFns{serialized},
 		fetchSourcesFns:      fetchSourcesFns{serialized},
 		protoStringer:        protoStringer{serialized},
+		fetchRevisionFn:      fetchRevisionFn{serialized},
 		fetchTagsFn:          fetchTagsFn{serialized},
 		fetchPropertiesFn:    fetchPropertiesFn{serialized},
 	}
@@ -1586,6 +2409,7 @@ type Provider struct {
 	fetchLabelsFns
 	fetchSourcesFns
 	protoStringer
+	fetchRevisionFn
 	fetchTagsFn
 	fetchPropertiesFn
 }
@@ -1602,6 +2426,7 @@ func wrapProtoProvider(serialized *pb.Provider) *Provider {
 		fetchLabelsFns:       fetchLabelsFns{serialized},
 		fetchSourcesFns:      fetchSourcesFns{serialized},
 		protoStringer:        protoStringer{serialized},
+		fetchRevisionFn:      fetchRevisionFn{serialized},
 		fetchTagsFn:          fetchTagsFn{serialized},
 		fetchPropertiesFn:    fetchPropertiesFn{serialized},
 	}
@@ -1655,10 +2480,13 @@ func (provider *Provider) Properties() Properties {
 
 type Model struct {
 	serialized *pb.Model
+	fetchNamespaceFn
 	fetchTrainingSetsFns
 	fetchFeaturesFns
+	fetchFeatureGroupsFns
 	fetchLabelsFns
 	protoStringer
+	fetchRevisionFn
 	fetchTagsFn
 	fetchPropertiesFn
 }
@@ -1669,13 +2497,16 @@ func (m Model) Variant() string {
 
 func wrapProtoModel(serialized *pb.Model) *Model {
 	return &Model{
-		serialized:           serialized,
-		fetchTrainingSetsFns: fetchTrainingSetsFns{serialized},
-		fetchFeaturesFns:     fetchFeaturesFns{serialized},
-		fetchLabelsFns:       fetchLabelsFns{serialized},
-		protoStringer:        protoStringer{serialized},
-		fetchTagsFn:          fetchTagsFn{serialized},
-		fetchPropertiesFn:    fetchPropertiesFn{serialized},
+		serialized:            serialized,
+		fetchNamespaceFn:      fetchNamespaceFn{serialized},
+		fetchTrainingSetsFns:  fetchTrainingSetsFns{serialized},
+		fetchFeaturesFns:      fetchFeaturesFns{serialized},
+		fetchFeatureGroupsFns: fetchFeatureGroupsFns{serialized},
+		fetchLabelsFns:        fetchLabelsFns{serialized},
+		protoStringer:         protoStringer{serialized},
+		fetchRevisionFn:       fetchRevisionFn{serialized},
+		fetchTagsFn:           fetchTagsFn{serialized},
+		fetchPropertiesFn:     fetchPropertiesFn{serialized},
 	}
 }
 
@@ -1703,17 +2534,185 @@ func (model *Model) Properties() Properties {
 	return model.fetchPropertiesFn.Properties()
 }
 
+func (model *Model) DefaultVariant() string {
+	return model.serialized.GetDefaultVariant()
+}
+
+func (model *Model) Variants() []string {
+	return model.serialized.GetVariants()
+}
+
+func (model *Model) FetchVariants(client *Client, ctx context.Context) ([]*ModelVariant, error) {
+	ids := make(NameVariants, len(model.Variants()))
+	for i, variant := range model.Variants() {
+		ids[i] = NameVariant{Name: model.Name(), Variant: variant, Namespace: model.Namespace()}
+	}
+	return client.GetModelVariants(ctx, ids)
+}
+
+type ModelVariant struct {
+	serialized *pb.ModelVariant
+	fetchNamespaceFn
+	fetchFeaturesFns
+	fetchFeatureGroupsFns
+	createdFn
+	protoStringer
+	fetchRevisionFn
+	fetchTagsFn
+	fetchPropertiesFn
+}
+
+func wrapProtoModelVariant(serialized *pb.ModelVariant) *ModelVariant {
+	return &ModelVariant{
+		serialized:            serialized,
+		fetchNamespaceFn:      fetchNamespaceFn{serialized},
+		fetchFeaturesFns:      fetchFeaturesFns{serialized},
+		fetchFeatureGroupsFns: fetchFeatureGroupsFns{serialized},
+		createdFn:             createdFn{serialized},
+		protoStringer:         protoStringer{serialized},
+		fetchRevisionFn:       fetchRevisionFn{serialized},
+		fetchTagsFn:           fetchTagsFn{serialized},
+		fetchPropertiesFn:     fetchPropertiesFn{serialized},
+	}
+}
+
+func (variant *ModelVariant) Name() string {
+	return variant.serialized.GetName()
+}
+
+func (variant *ModelVariant) Variant() string {
+	return variant.serialized.GetVariant()
+}
+
+func (variant *ModelVariant) Description() string {
+	return variant.serialized.GetDescription()
+}
+
+func (variant *ModelVariant) Owner() string {
+	return variant.serialized.GetOwner()
+}
+
+func (variant *ModelVariant) Status() ResourceStatus {
+	if variant.serialized.GetStatus() != nil {
+		return ResourceStatus(variant.serialized.GetStatus().Status)
+	}
+	return ResourceStatus(0)
+}
+
+func (variant *ModelVariant) TrainingSet() NameVariant {
+	return parseNameVariant(variant.serialized.GetTrainingSet())
+}
+
+func (variant *ModelVariant) Label() NameVariant {
+	return parseNameVariant(variant.serialized.GetLabel())
+}
+
+func (variant *ModelVariant) FetchTrainingSet(client *Client, ctx context.Context) (*TrainingSetVariant, error) {
+	return client.GetTrainingSetVariant(ctx, variant.TrainingSet())
+}
+
+func (variant *ModelVariant) Tags() Tags {
+	return variant.fetchTagsFn.Tags()
+}
+
+func (variant *ModelVariant) Properties() Properties {
+	return variant.fetchPropertiesFn.Properties()
+}
+
+type FeatureGroup struct {
+	serialized *pb.FeatureGroup
+	variantsFns
+	protoStringer
+	fetchRevisionFn
+}
+
+func wrapProtoFeatureGroup(serialized *pb.FeatureGroup) *FeatureGroup {
+	return &FeatureGroup{
+		serialized:      serialized,
+		variantsFns:     variantsFns{serialized},
+		protoStringer:   protoStringer{serialized},
+		fetchRevisionFn: fetchRevisionFn{serialized},
+	}
+}
+
+func (group FeatureGroup) FetchVariants(client *Client, ctx context.Context) ([]*FeatureGroupVariant, error) {
+	return client.GetFeatureGroupVariants(ctx, group.NameVariants())
+}
+
+type FeatureGroupVariant struct {
+	serialized *pb.FeatureGroupVariant
+	fetchNamespaceFn
+	fetchFeaturesFns
+	fetchTrainingSetsFns
+	createdFn
+	protoStringer
+	fetchRevisionFn
+	fetchTagsFn
+	fetchPropertiesFn
+}
+
+func wrapProtoFeatureGroupVariant(serialized *pb.FeatureGroupVariant) *FeatureGroupVariant {
+	return &FeatureGroupVariant{
+		serialized:           serialized,
+		fetchNamespaceFn:     fetchNamespaceFn{serialized},
+		fetchFeaturesFns:     fetchFeaturesFns{serialized},
+		fetchTrainingSetsFns: fetchTrainingSetsFns{serialized},
+		createdFn:            createdFn{serialized},
+		protoStringer:        protoStringer{serialized},
+		fetchRevisionFn:      fetchRevisionFn{serialized},
+		fetchTagsFn:          fetchTagsFn{serialized},
+		fetchPropertiesFn:    fetchPropertiesFn{serialized},
+	}
+}
+
+func (variant *FeatureGroupVariant) Name() string {
+	return variant.serialized.GetName()
+}
+
+func (variant *FeatureGroupVariant) Variant() string {
+	return variant.serialized.GetVariant()
+}
+
+func (variant *FeatureGroupVariant) Description() string {
+	return variant.serialized.GetDescription()
+}
+
+func (variant *FeatureGroupVariant) Owner() string {
+	return variant.serialized.GetOwner()
+}
+
+func (variant *FeatureGroupVariant) Entity() string {
+	return variant.serialized.GetEntity()
+}
+
+func (variant *FeatureGroupVariant) Status() ResourceStatus {
+	if variant.serialized.GetStatus() != nil {
+		return ResourceStatus(variant.serialized.GetStatus().Status)
+	}
+	return ResourceStatus(0)
+}
+
+func (variant *FeatureGroupVariant) Tags() Tags {
+	return variant.fetchTagsFn.Tags()
+}
+
+func (variant *FeatureGroupVariant) Properties() Properties {
+	return variant.fetchPropertiesFn.Properties()
+}
+
 type Label struct {
 	serialized *pb.Label
 	variantsFns
 	protoStringer
+	fetchRevisionFn
 }
 
 func wrapProtoLabel(serialized *pb.Label) *Label {
 	return &Label{
-		serialized:    serialized,
-		variantsFns:   variantsFns{serialized},
-		protoStringer: protoStringer{serialized},
+		serialized:      serialized,
+		variantsFns:     variantsFns{serialized},
+		protoStringer:   protoStringer{serialized},
+		fetchRevisionFn: fetchRevisionFn{serialized},
 	}
 }
 
@@ -1723,11 +2722,14 @@ func (label Label) FetchVariants(client *Client, ctx context.Context) ([]*LabelV
 
 type LabelVariant struct {
 	serialized *pb.LabelVariant
+	fetchNamespaceFn
 	fetchTrainingSetsFns
 	fetchProviderFns
 	fetchSourceFns
 	createdFn
 	protoStringer
+	fetchRevisionFn
+	fetchStatusErrorFn
 	fetchTagsFn
 	fetchPropertiesFn
 }
@@ -1735,11 +2737,14 @@ type LabelVariant struct {
 func wrapProtoLabelVariant(serialized *pb.LabelVariant) *LabelVariant {
 	return &LabelVariant{
 		serialized:           serialized,
+		fetchNamespaceFn:     fetchNamespaceFn{serialized},
 		fetchTrainingSetsFns: fetchTrainingSetsFns{serialized},
 		fetchProviderFns:     fetchProviderFns{serialized},
 		fetchSourceFns:       fetchSourceFns{serialized},
 		createdFn:            createdFn{serialized},
 		protoStringer:        protoStringer{serialized},
+		fetchRevisionFn:      fetchRevisionFn{serialized},
+		fetchStatusErrorFn:   fetchStatusErrorFn{serialized},
 		fetchTagsFn:          fetchTagsFn{serialized},
 		fetchPropertiesFn:    fetchPropertiesFn{serialized},
 	}
@@ -1813,13 +2818,15 @@ type TrainingSet struct {
 	serialized *pb.TrainingSet
 	variantsFns
 	protoStringer
+	fetchRevisionFn
 }
 
 func wrapProtoTrainingSet(serialized *pb.TrainingSet) *TrainingSet {
 	return &TrainingSet{
-		serialized:    serialized,
-		variantsFns:   variantsFns{serialized},
-		protoStringer: protoStringer{serialized},
+		serialized:      serialized,
+		variantsFns:     variantsFns{serialized},
+		protoStringer:   protoStringer{serialized},
+		fetchRevisionFn: fetchRevisionFn{serialized},
 	}
 }
 
@@ -1829,25 +2836,35 @@ func (trainingSet TrainingSet) FetchVariants(client *Client, ctx context.Context
 
 type TrainingSetVariant struct {
 	serialized *pb.TrainingSetVariant
+	fetchNamespaceFn
 	fetchFeaturesFns
+	fetchFeatureGroupsFns
 	fetchProviderFns
 	createdFn
 	lastUpdatedFn
 	protoStringer
+	fetchRevisionFn
+	fetchStatusErrorFn
 	fetchTagsFn
 	fetchPropertiesFn
+	fetchLifecycleFn
 }
 
 func wrapProtoTrainingSetVariant(serialized *pb.TrainingSetVariant) *TrainingSetVariant {
 	return &TrainingSetVariant{
-		serialized:        serialized,
-		fetchFeaturesFns:  fetchFeaturesFns{serialized},
-		fetchProviderFns:  fetchProviderFns{serialized},
-		createdFn:         createdFn{serialized},
-		lastUpdatedFn:     lastUpdatedFn{serialized},
-		protoStringer:     protoStringer{serialized},
-		fetchTagsFn:       fetchTagsFn{serialized},
-		fetchPropertiesFn: fetchPropertiesFn{serialized},
+		serialized:            serialized,
+		fetchNamespaceFn:      fetchNamespaceFn{serialized},
+		fetchFeaturesFns:      fetchFeaturesFns{serialized},
+		fetchFeatureGroupsFns: fetchFeatureGroupsFns{serialized},
+		fetchProviderFns:      fetchProviderFns{serialized},
+		createdFn:             createdFn{serialized},
+		lastUpdatedFn:         lastUpdatedFn{serialized},
+		protoStringer:         protoStringer{serialized},
+		fetchRevisionFn:       fetchRevisionFn{serialized},
+		fetchStatusErrorFn:    fetchStatusErrorFn{serialized},
+		fetchTagsFn:           fetchTagsFn{serialized},
+		fetchPropertiesFn:     fetchPropertiesFn{serialized},
+		fetchLifecycleFn:      fetchLifecycleFn{serialized},
 	}
 }
 
@@ -1889,6 +2906,11 @@ func (variant *TrainingSetVariant) LagFeatures() []*pb.FeatureLag {
 	return variant.serialized.GetFeatureLags()
 }
 
+// Models are the model variants trained on the training set.
+func (variant *TrainingSetVariant) Models() NameVariants {
+	return parseNameVariants(variant.serialized.GetModels())
+}
+
 func (variant *TrainingSetVariant) FetchLabel(client *Client, ctx context.Context) (*LabelVariant, error) {
 	labelList, err := client.GetLabelVariants(ctx, []NameVariant{variant.Label()})
 	if err != nil {
@@ -1909,13 +2931,15 @@ type Source struct {
 	serialized *pb.Source
 	variantsFns
 	protoStringer
+	fetchRevisionFn
 }
 
 func wrapProtoSource(serialized *pb.Source) *Source {
 	return &Source{
-		serialized:    serialized,
-		variantsFns:   variantsFns{serialized},
-		protoStringer: protoStringer{serialized},
+		serialized:      serialized,
+		variantsFns:     variantsFns{serialized},
+		protoStringer:   protoStringer{serialized},
+		fetchRevisionFn: fetchRevisionFn{serialized},
 	}
 }
 
@@ -1925,6 +2949,7 @@ func (source Source) FetchVariants(client *Client, ctx context.Context) ([]*Sour
 
 type SourceVariant struct {
 	serialized *pb.SourceVariant
+	fetchNamespaceFn
 	fetchTrainingSetsFns
 	fetchFeaturesFns
 	fetchLabelsFns
@@ -1932,6 +2957,8 @@ type SourceVariant struct {
 	createdFn
 	lastUpdatedFn
 	protoStringer
+	fetchRevisionFn
+	fetchStatusErrorFn
 	fetchTagsFn
 	fetchPropertiesFn
 }
@@ -1998,6 +3025,7 @@ func (variant *SourceVariant) DFTransformationQuerySource() string {
 func wrapProtoSourceVariant(serialized *pb.SourceVariant) *SourceVariant {
 	return &SourceVariant{
 		serialized:           serialized,
+		fetchNamespaceFn:     fetchNamespaceFn{serialized},
 		fetchTrainingSetsFns: fetchTrainingSetsFns{serialized},
 		fetchFeaturesFns:     fetchFeaturesFns{serialized},
 		fetchLabelsFns:       fetchLabelsFns{serialized},
@@ -2005,6 +3033,8 @@ func wrapProtoSourceVariant(serialized *pb.SourceVariant) *SourceVariant {
 		createdFn:            createdFn{serialized},
 		lastUpdatedFn:        lastUpdatedFn{serialized},
 		protoStringer:        protoStringer{serialized},
+		fetchRevisionFn:      fetchRevisionFn{serialized},
+		fetchStatusErrorFn:   fetchStatusErrorFn{serialized},
 		fetchTagsFn:          fetchTagsFn{serialized},
 		fetchPropertiesFn:    fetchPropertiesFn{serialized},
 	}
@@ -2148,10 +3178,12 @@ func (variant *SourceVariant) Properties() Properties {
 
 type Entity struct {
 	serialized *pb.Entity
+	fetchNamespaceFn
 	fetchTrainingSetsFns
 	fetchFeaturesFns
 	fetchLabelsFns
 	protoStringer
+	fetchRevisionFn
 	fetchTagsFn
 	fetchPropertiesFn
 }
@@ -2163,10 +3195,12 @@ func (e Entity) Variant() string {
 func wrapProtoEntity(serialized *pb.Entity) *Entity {
 	return &Entity{
 		serialized:           serialized,
+		fetchNamespaceFn:     fetchNamespaceFn{serialized},
 		fetchTrainingSetsFns: fetchTrainingSetsFns{serialized},
 		fetchFeaturesFns:     fetchFeaturesFns{serialized},
 		fetchLabelsFns:       fetchLabelsFns{serialized},
 		protoStringer:        protoStringer{serialized},
+		fetchRevisionFn:      fetchRevisionFn{serialized},
 		fetchTagsFn:          fetchTagsFn{serialized},
 		fetchPropertiesFn:    fetchPropertiesFn{serialized},
 	}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strconv"
 	"strings"
+	"time"
 
 	help "github.com/featureform/helpers"
 	"github.com/featureform/metadata"
@@ -24,6 +26,8 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var searchClient search.Searcher
@@ -187,22 +191,25 @@ func featureShallowMap(variant *metadata.FeatureVariant) metadata.FeatureVariant
 	switch variant.Mode() {
 	case metadata.PRECOMPUTED:
 		fv = metadata.FeatureVariantResource{
-			Created:     variant.Created(),
-			Description: variant.Description(),
-			Entity:      variant.Entity(),
-			Name:        variant.Name(),
-			DataType:    variant.Type(),
-			Variant:     variant.Variant(),
-			Owner:       variant.Owner(),
-			Provider:    variant.Provider(),
-			Source:      variant.Source(),
-			Location:    columnsToMap(variant.LocationColumns().(metadata.ResourceVariantColumns)),
-			Status:      variant.Status().String(),
-			Error:       variant.Error(),
-			Tags:        variant.Tags(),
-			Properties:  variant.Properties(),
-			Mode:        variant.Mode().String(),
-			IsOnDemand:  variant.IsOnDemand(),
+			Created:      variant.Created(),
+			Description:  variant.Description(),
+			Entity:       variant.Entity(),
+			Name:         variant.Name(),
+			DataType:     variant.Type(),
+			Variant:      variant.Variant(),
+			Owner:        variant.Owner(),
+			Provider:     variant.Provider(),
+			Source:       variant.Source(),
+			Location:     columnsToMap(variant.LocationColumns().(metadata.ResourceVariantColumns)),
+			Status:       variant.Status().String(),
+			Error:        variant.Error(),
+			Failure:      variant.Failure().Resource(),
+			ErrorHistory: variant.ErrorHistory().Resource(),
+			Tags:         variant.Tags(),
+			Properties:   variant.Properties(),
+			Mode:         variant.Mode().String(),
+			IsOnDemand:   variant.IsOnDemand(),
+			Lifecycle:    variant.Lifecycle().Resource(),
 		}
 	case metadata.CLIENT_COMPUTED:
 		location := make(map[string]string)
@@ -210,18 +217,21 @@ func featureShallowMap(variant *metadata.FeatureVariant) metadata.FeatureVariant
 			location["query"] = string(pyFunc.Query)
 		}
 		fv = metadata.FeatureVariantResource{
-			Created:     variant.Created(),
-			Description: variant.Description(),
-			Name:        variant.Name(),
-			Variant:     variant.Variant(),
-			Owner:       variant.Owner(),
-			Location:    location,
-			Status:      variant.Status().String(),
-			Error:       variant.Error(),
-			Tags:        variant.Tags(),
-			Properties:  variant.Properties(),
-			Mode:        variant.Mode().String(),
-			IsOnDemand:  variant.IsOnDemand(),
+			Created:      variant.Created(),
+			Description:  variant.Description(),
+			Name:         variant.Name(),
+			Variant:      variant.Variant(),
+			Owner:        variant.Owner(),
+			Location:     location,
+			Status:       variant.Status().String(),
+			Error:        variant.Error(),
+			Failure:      variant.Failure().Resource(),
+			ErrorHistory: variant.ErrorHistory().Resource(),
+			Tags:         variant.Tags(),
+			Properties:   variant.Properties(),
+			Mode:         variant.Mode().String(),
+			IsOnDemand:   variant.IsOnDemand(),
+			Lifecycle:    variant.Lifecycle().Resource(),
 		}
 	default:
 		fmt.Printf("Unknown computation mode %v\n", variant.Mode())
@@ -231,36 +241,41 @@ func featureShallowMap(variant *metadata.FeatureVariant) metadata.FeatureVariant
 
 func labelShallowMap(variant *metadata.LabelVariant) metadata.LabelVariantResource {
 	return metadata.LabelVariantResource{
-		Created:     variant.Created(),
-		Description: variant.Description(),
-		Entity:      variant.Entity(),
-		Name:        variant.Name(),
-		DataType:    variant.Type(),
-		Variant:     variant.Variant(),
-		Owner:       variant.Owner(),
-		Provider:    variant.Provider(),
-		Source:      variant.Source(),
-		Location:    columnsToMap(variant.LocationColumns().(metadata.ResourceVariantColumns)),
-		Status:      variant.Status().String(),
-		Error:       variant.Error(),
-		Tags:        variant.Tags(),
-		Properties:  variant.Properties(),
+		Created:      variant.Created(),
+		Description:  variant.Description(),
+		Entity:       variant.Entity(),
+		Name:         variant.Name(),
+		DataType:     variant.Type(),
+		Variant:      variant.Variant(),
+		Owner:        variant.Owner(),
+		Provider:     variant.Provider(),
+		Source:       variant.Source(),
+		Location:     columnsToMap(variant.LocationColumns().(metadata.ResourceVariantColumns)),
+		Status:       variant.Status().String(),
+		Error:        variant.Error(),
+		Failure:      variant.Failure().Resource(),
+		ErrorHistory: variant.ErrorHistory().Resource(),
+		Tags:         variant.Tags(),
+		Properties:   variant.Properties(),
 	}
 }
 
 func trainingSetShallowMap(variant *metadata.TrainingSetVariant) metadata.TrainingSetVariantResource {
 	return metadata.TrainingSetVariantResource{
-		Created:     variant.Created(),
-		Description: variant.Description(),
-		Name:        variant.Name(),
-		Variant:     variant.Variant(),
-		Owner:       variant.Owner(),
-		Provider:    variant.Provider(),
-		Label:       variant.Label(),
-		Status:      variant.Status().String(),
-		Error:       variant.Error(),
-		Tags:        variant.Tags(),
-		Properties:  variant.Properties(),
+		Created:      variant.Created(),
+		Description:  variant.Description(),
+		Name:         variant.Name(),
+		Variant:      variant.Variant(),
+		Owner:        variant.Owner(),
+		Provider:     variant.Provider(),
+		Label:        variant.Label(),
+		Status:       variant.Status().String(),
+		Error:        variant.Error(),
+		Failure:      variant.Failure().Resource(),
+		ErrorHistory: variant.ErrorHistory().Resource(),
+		Tags:         variant.Tags(),
+		Properties:   variant.Properties(),
+		Lifecycle:    variant.Lifecycle().Resource(),
 	}
 }
 
@@ -280,6 +295,8 @@ func sourceShallowMap(variant *metadata.SourceVariant) metadata.SourceVariantRes
 		SourceType:     getSourceType(variant),
 		Properties:     variant.Properties(),
 		Error:          variant.Error(),
+		Failure:        variant.Failure().Resource(),
+		ErrorHistory:   variant.ErrorHistory().Resource(),
 		Specifications: getSourceArgs(variant),
 		Inputs:         getInputs(variant),
 	}
@@ -506,9 +523,10 @@ func (m *MetadataServer) readFromLabel(label *metadata.Label, deepCopy bool) (ma
 }
 
 func (m *MetadataServer) GetMetadata(c *gin.Context) {
+	client := m.client.InNamespace(c.Query("namespace"))
 	switch c.Param("type") {
 	case "features":
-		feature, err := m.client.GetFeature(context.Background(), c.Param("resource"))
+		feature, err := client.GetFeature(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "feature"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -528,7 +546,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 			Variants:       variantList,
 		})
 	case "labels":
-		label, err := m.client.GetLabel(context.Background(), c.Param("resource"))
+		label, err := client.GetLabel(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "label"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -548,7 +566,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 			Variants:       variantList,
 		})
 	case "training-sets":
-		trainingSet, err := m.client.GetTrainingSet(context.Background(), c.Param("resource"))
+		trainingSet, err := client.GetTrainingSet(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "training set"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -568,7 +586,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 			Variants:       variantList,
 		})
 	case "sources":
-		source, err := m.client.GetSource(context.Background(), c.Param("resource"))
+		source, err := client.GetSource(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "source"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -588,7 +606,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 			Variants:       variantList,
 		})
 	case "entities":
-		entity, err := m.client.GetEntity(context.Background(), c.Param("resource"))
+		entity, err := client.GetEntity(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "entity"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -636,7 +654,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 		}
 		c.JSON(http.StatusOK, entityResource)
 	case "users":
-		user, err := m.client.GetUser(context.Background(), c.Param("resource"))
+		user, err := client.GetUser(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "user"}
 			m.logger.Errorw(err.Error(), "Metadata error", err)
@@ -692,7 +710,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 		}
 		c.JSON(http.StatusOK, userResource)
 	case "models":
-		model, err := m.client.GetModel(context.Background(), c.Param("resource"))
+		model, err := client.GetModel(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "model"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -740,7 +758,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 		}
 		c.JSON(http.StatusOK, modelResource)
 	case "providers":
-		provider, err := m.client.GetProvider(context.Background(), c.Param("resource"))
+		provider, err := client.GetProvider(context.Background(), c.Param("resource"))
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "provider"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -803,11 +821,72 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 	}
 }
 
-func (m *MetadataServer) GetMetadataList(c *gin.Context) {
+// nextPageTokenHeader is set on list responses when there are more pages.
+const nextPageTokenHeader = "X-Next-Page-Token"
+
+var listSorts = map[string]metadata.ListSort{
+	"":        metadata.SortByName,
+	"name":    metadata.SortByName,
+	"created": metadata.SortByCreated,
+}
 
+// listOptions reads the page, filter and sort query parameters of a list.
+func listOptions(c *gin.Context) (metadata.ListOptions, error) {
+	opts := metadata.ListOptions{
+		PageToken: c.Query("page_token"),
+		Owner:     c.Query("owner"),
+		Provider:  c.Query("provider"),
+		Tag:       c.Query("tag"),
+		Namespace: c.Query("namespace"),
+	}
+	if param := c.Query("page_size"); param != "" {
+		pageSize, err := strconv.Atoi(param)
+		if err != nil || pageSize < 0 {
+			return opts, fmt.Errorf("invalid page size: %s", param)
+		}
+		opts.PageSize = pageSize
+	}
+	if param := c.Query("status"); param != "" {
+		for _, name := range strings.Split(param, ",") {
+			status, has := pb.ResourceStatus_Status_value[strings.ToUpper(name)]
+			if !has {
+				return opts, fmt.Errorf("unknown status: %s", name)
+			}
+			opts.Statuses = append(opts.Statuses, metadata.ResourceStatus(status))
+		}
+	}
+	if param := c.Query("created_after"); param != "" {
+		createdAfter, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return opts, fmt.Errorf("invalid created_after: %s", param)
+		}
+		opts.CreatedAfter = createdAfter
+	}
+	sortBy, has := listSorts[c.Query("sort")]
+	if !has {
+		return opts, fmt.Errorf("unknown sort: %s", c.Query("sort"))
+	}
+	opts.SortBy = sortBy
+	switch c.Query("order") {
+	case "", "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		return opts, fmt.Errorf("unknown order: %s", c.Query("order"))
+	}
+	return opts, nil
+}
+
+func (m *MetadataServer) GetMetadataList(c *gin.Context) {
+	opts, err := listOptions(c)
+	if err != nil {
+		m.logger.Errorw("Invalid list options", "Error", err)
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
 	switch c.Param("type") {
 	case "features":
-		features, err := m.client.ListFeatures(context.Background())
+		features, nextPageToken, err := m.client.ListFeaturesPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "features"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -830,9 +909,10 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Variants:       variantList,
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, featureList)
 	case "training-sets":
-		trainingSets, err := m.client.ListTrainingSets(context.Background())
+		trainingSets, nextPageToken, err := m.client.ListTrainingSetsPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "training sets"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -855,9 +935,10 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Variants:       variantList,
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, trainingSetList)
 	case "sources":
-		sources, err := m.client.ListSources(context.Background())
+		sources, nextPageToken, err := m.client.ListSourcesPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "sources"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -880,9 +961,10 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Variants:       variantList,
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, sourceList)
 	case "labels":
-		labels, err := m.client.ListLabels(context.Background())
+		labels, nextPageToken, err := m.client.ListLabelsPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "labels"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -906,9 +988,10 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Variants:       variantList,
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, labelList)
 	case "entities":
-		entities, err := m.client.ListEntities(context.Background())
+		entities, nextPageToken, err := m.client.ListEntitiesPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "entities"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -926,10 +1009,11 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Properties:  entity.Properties(),
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, entityList)
 
 	case "models":
-		models, err := m.client.ListModels(context.Background())
+		models, nextPageToken, err := m.client.ListModelsPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "models"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -947,10 +1031,11 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Properties:  model.Properties(),
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, modelList)
 
 	case "users":
-		users, err := m.client.ListUsers(context.Background())
+		users, nextPageToken, err := m.client.ListUsersPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "users"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -967,10 +1052,11 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Properties: user.Properties(),
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, userList)
 
 	case "providers":
-		providers, err := m.client.ListProviders(context.Background())
+		providers, nextPageToken, err := m.client.ListProvidersPage(context.Background(), opts)
 		if err != nil {
 			fetchError := &FetchError{StatusCode: 500, Type: "providers"}
 			m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -991,6 +1077,7 @@ func (m *MetadataServer) GetMetadataList(c *gin.Context) {
 				Properties:   provider.Properties(),
 			}
 		}
+		c.Header(nextPageTokenHeader, nextPageToken)
 		c.JSON(http.StatusOK, providerList)
 
 	default:
@@ -1017,6 +1104,27 @@ func (m *MetadataServer) GetSearch(c *gin.Context) {
 	c.JSON(200, result)
 }
 
+// followSearchIndex keeps the embedded search index up to date with changes
+// made after it was filled.
+func (m *MetadataServer) followSearchIndex(ctx context.Context) {
+	var lastRevision int64
+	for ctx.Err() == nil {
+		opts := metadata.WatchOptions{}
+		if lastRevision > 0 {
+			opts.StartRevision = lastRevision + 1
+		}
+		err := m.client.Watch(ctx, opts, func(event *pb.AuditEvent) error {
+			lastRevision = event.Revision
+			if err := metadata.IndexChange(m.lookup, searchClient, event); err != nil {
+				m.logger.Errorw("Failed to index change", "event", event.String(), "error", err)
+			}
+			return nil
+		})
+		m.logger.Errorw("Search index watch stopped; restarting", "error", err)
+		time.Sleep(time.Second)
+	}
+}
+
 func (m *MetadataServer) GetVersionMap(c *gin.Context) {
 	versionMap := map[string]string{
 		"version": help.GetEnv("FEATUREFORM_VERSION", ""),
@@ -1032,6 +1140,7 @@ type SourceDataResponse struct {
 func (m *MetadataServer) GetSourceData(c *gin.Context) {
 	name := c.Query("name")
 	variant := c.Query("variant")
+	namespace := c.Query("namespace")
 	var limit int64 = 150
 	response := SourceDataResponse{}
 	if name == "" || variant == "" {
@@ -1040,7 +1149,7 @@ func (m *MetadataServer) GetSourceData(c *gin.Context) {
 		c.JSON(fetchError.StatusCode, fetchError.Error())
 		return
 	}
-	iter, err := m.getSourceDataIterator(name, variant, limit)
+	iter, err := m.getSourceDataIterator(namespace, name, variant, limit)
 	if err != nil {
 		fetchError := &FetchError{StatusCode: 500, Type: "GetSourceData - getSourceDataIterator() threw an exception"}
 		m.logger.Errorw(fetchError.Error(), "Metadata error", err)
@@ -1086,10 +1195,10 @@ func extractRowValue(rowString *proto.Value) string {
 	return result
 }
 
-func (m *MetadataServer) getSourceDataIterator(name, variant string, limit int64) (provider.GenericTableIterator, error) {
+func (m *MetadataServer) getSourceDataIterator(namespace, name, variant string, limit int64) (provider.GenericTableIterator, error) {
 	ctx := context.TODO()
 	m.logger.Infow("Getting Source Variant Iterator", "name", name, "variant", variant)
-	sv, err := m.client.GetSourceVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	sv, err := m.client.GetSourceVariant(ctx, metadata.NameVariant{Name: name, Variant: variant, Namespace: namespace})
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get source variant")
 	}
@@ -1109,7 +1218,7 @@ func (m *MetadataServer) getSourceDataIterator(name, variant string, limit int64
 	var primary provider.PrimaryTable
 	var providerErr error
 	if sv.IsTransformation() {
-		t, err := store.GetTransformationTable(provider.ResourceID{Name: name, Variant: variant, Type: provider.Transformation})
+		t, err := store.GetTransformationTable(provider.ResourceID{Name: metadata.QualifiedName(namespace, name), Variant: variant, Type: provider.Transformation})
 		if err != nil {
 			providerErr = err
 		} else {
@@ -1117,7 +1226,7 @@ func (m *MetadataServer) getSourceDataIterator(name, variant string, limit int64
 			primary = t.(provider.PrimaryTable)
 		}
 	} else {
-		primary, providerErr = store.GetPrimaryTable(provider.ResourceID{Name: name, Variant: variant, Type: provider.Primary})
+		primary, providerErr = store.GetPrimaryTable(provider.ResourceID{Name: metadata.QualifiedName(namespace, name), Variant: variant, Type: provider.Primary})
 	}
 	if providerErr != nil {
 		return nil, errors.Wrap(err, "could not get primary table")
@@ -1246,18 +1355,19 @@ func (m *MetadataServer) PostTags(c *gin.Context) {
 		Variant: variant,
 		Type:    resourceType,
 	}
-	foundResource, err := m.lookup.Lookup(objID)
-
-	if err != nil {
-		fetchError := m.GetTagError(400, err, c, "PostTags - Error finding the resource with resourceID")
+	if err := m.client.SetTags(c.Request.Context(), objID, requestBody.Tags); err != nil {
+		code := http.StatusInternalServerError
+		switch status.Code(err) {
+		case codes.NotFound, codes.InvalidArgument:
+			code = http.StatusBadRequest
+		case codes.Aborted:
+			code = http.StatusConflict
+		}
+		fetchError := m.GetTagError(code, err, c, "PostTags - Error setting the tags of the resource")
 		c.JSON(fetchError.StatusCode, fetchError.Error())
 		return
 	}
 
-	replaceTags(resourceTypeParam, foundResource, &pb.Tags{Tag: requestBody.Tags})
-
-	m.lookup.Set(objID, foundResource)
-
 	c.JSON(http.StatusOK, TagResult{
 		Name:    name,
 		Variant: variant,
@@ -1265,74 +1375,110 @@ func (m *MetadataServer) PostTags(c *gin.Context) {
 	})
 }
 
-func replaceTags(resourceTypeParam string, currentResource metadata.Resource, newTagList *pb.Tags) error {
-	deserialized := currentResource.Proto()
-	switch resourceTypeParam {
-	case "features":
-		variantUpdate, ok := deserialized.(*pb.FeatureVariant)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
-		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
-	case "labels":
-		variantUpdate, ok := deserialized.(*pb.LabelVariant)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
-		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
-	case "training-sets":
-		variantUpdate, ok := deserialized.(*pb.TrainingSetVariant)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
-		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
-	case "sources":
-		variantUpdate, ok := deserialized.(*pb.SourceVariant)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
-		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
-	case "entities":
-		variantUpdate, ok := deserialized.(*pb.Entity)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
-		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
-	case "users":
-		variantUpdate, ok := deserialized.(*pb.User)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
-		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
-	case "models":
-		variantUpdate, ok := deserialized.(*pb.Model)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
-		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
-	case "providers":
-		variantUpdate, ok := deserialized.(*pb.Provider)
-		if !ok {
-			return errors.New("replaceTags - Failed to deserialize variant")
+type LineageNode struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Variant string `json:"variant"`
+}
+
+type LineageEdge struct {
+	Upstream   LineageNode `json:"upstream"`
+	Downstream LineageNode `json:"downstream"`
+}
+
+type LineageResponse struct {
+	Nodes []LineageNode `json:"nodes"`
+	Edges []LineageEdge `json:"edges"`
+}
+
+var lineageTypes = map[string][2]metadata.ResourceType{
+	"features":      {metadata.FEATURE, metadata.FEATURE_VARIANT},
+	"labels":        {metadata.LABEL, metadata.LABEL_VARIANT},
+	"sources":       {metadata.SOURCE, metadata.SOURCE_VARIANT},
+	"training-sets": {metadata.TRAINING_SET, metadata.TRAINING_SET_VARIANT},
+	"entities":      {metadata.ENTITY, metadata.ENTITY},
+	"providers":     {metadata.PROVIDER, metadata.PROVIDER},
+	"models":        {metadata.MODEL, metadata.MODEL},
+}
+
+var lineageDirections = map[string]pb.LineageRequest_Direction{
+	"":           pb.LineageRequest_BOTH,
+	"both":       pb.LineageRequest_BOTH,
+	"upstream":   pb.LineageRequest_UPSTREAM,
+	"downstream": pb.LineageRequest_DOWNSTREAM,
+}
+
+func lineageNode(id *pb.ResourceID) LineageNode {
+	return LineageNode{
+		Type:    id.GetResourceType().String(),
+		Name:    id.GetResource().GetName(),
+		Variant: id.GetResource().GetVariant(),
+	}
+}
+
+// GetLineage returns the lineage graph of a resource, or of one of its
+// variants if the variant query parameter is set. The direction and depth
+// parameters limit the graph, and format=dot returns it as Graphviz DOT.
+func (m *MetadataServer) GetLineage(c *gin.Context) {
+	types, has := lineageTypes[c.Param("type")]
+	if !has {
+		fetchError := &FetchError{StatusCode: 400, Type: "GetLineage - Unknown resource type"}
+		c.JSON(fetchError.StatusCode, fetchError.Error())
+		return
+	}
+	id := metadata.ResourceID{Name: c.Param("resource"), Type: types[0]}
+	if variant := c.Query("variant"); variant != "" {
+		id.Variant = variant
+		id.Type = types[1]
+	}
+	direction, has := lineageDirections[c.Query("direction")]
+	if !has {
+		fetchError := &FetchError{StatusCode: 400, Type: "GetLineage - Unknown direction"}
+		c.JSON(fetchError.StatusCode, fetchError.Error())
+		return
+	}
+	depth := 0
+	if param := c.Query("depth"); param != "" {
+		var err error
+		if depth, err = strconv.Atoi(param); err != nil {
+			fetchError := &FetchError{StatusCode: 400, Type: "GetLineage - Invalid depth"}
+			c.JSON(fetchError.StatusCode, fetchError.Error())
+			return
 		}
-		variantUpdate.Tags.Reset()
-		variantUpdate.Tags = newTagList
 	}
-	return nil
+	lineage, err := m.client.GetLineage(context.Background(), id, direction, depth)
+	if err != nil {
+		fetchError := &FetchError{StatusCode: 500, Type: "lineage"}
+		m.logger.Errorw(fetchError.Error(), "Metadata error", err)
+		c.JSON(fetchError.StatusCode, fetchError.Error())
+		return
+	}
+	if c.Query("format") == "dot" {
+		c.Data(http.StatusOK, "text/vnd.graphviz", []byte(lineage.Dot))
+		return
+	}
+	response := LineageResponse{
+		Nodes: make([]LineageNode, len(lineage.Nodes)),
+		Edges: make([]LineageEdge, len(lineage.Edges)),
+	}
+	for i, node := range lineage.Nodes {
+		response.Nodes[i] = lineageNode(node)
+	}
+	for i, edge := range lineage.Edges {
+		response.Edges[i] = LineageEdge{Upstream: lineageNode(edge.Upstream), Downstream: lineageNode(edge.Downstream)}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 func (m *MetadataServer) Start(port string) {
 	router := gin.Default()
-	router.Use(cors.Default())
+	corsConfig := cors.DefaultConfig()
+	corsConfig.AllowAllOrigins = true
+	corsConfig.ExposeHeaders = []string{nextPageTokenHeader}
+	router.Use(cors.New(corsConfig))
 	router.GET("/data/:type", m.GetMetadataList)
 	router.GET("/data/:type/:resource", m.GetMetadata)
+	router.GET("/data/:type/:resource/lineage", m.GetLineage)
 	router.GET("/data/search", m.GetSearch)
 	router.GET("/data/version", m.GetVersionMap)
 	router.GET("/data/sourcedata", m.GetSourceData)
@@ -1345,21 +1491,26 @@ func main() {
 	logger := zap.NewExample().Sugar()
 	metadataHost := help.GetEnv("METADATA_HOST", "localhost")
 	metadataPort := help.GetEnv("METADATA_PORT", "8080")
-	searchHost := help.GetEnv("MEILISEARCH_HOST", "localhost")
-	searchPort := help.GetEnv("MEILISEARCH_PORT", "7700")
-	searchEndpoint := fmt.Sprintf("http://%s:%s", searchHost, searchPort)
-	searchApiKey := help.GetEnv("MEILISEARCH_APIKEY", "xyz")
-	logger.Infof("Connecting to typesense at: %s\n", searchEndpoint)
-	sc, err := search.NewMeilisearch(&search.MeilisearchParams{
-		Host:   searchHost,
-		Port:   searchPort,
-		ApiKey: searchApiKey,
-	})
-	if err != nil {
-		logger.Panicw("Failed to create new meil search", err)
+	embeddedSearch := help.GetEnv("SEARCH_BACKEND", "meilisearch") == "embedded"
+	if embeddedSearch {
+		logger.Info("Using embedded search")
+		searchClient = search.NewEmbeddedSearch()
+	} else {
+		searchHost := help.GetEnv("MEILISEARCH_HOST", "localhost")
+		searchPort := help.GetEnv("MEILISEARCH_PORT", "7700")
+		searchEndpoint := fmt.Sprintf("http://%s:%s", searchHost, searchPort)
+		searchApiKey := help.GetEnv("MEILISEARCH_APIKEY", "xyz")
+		logger.Infof("Connecting to typesense at: %s\n", searchEndpoint)
+		sc, err := search.NewMeilisearch(&search.MeilisearchParams{
+			Host:   searchHost,
+			Port:   searchPort,
+			ApiKey: searchApiKey,
+		})
+		if err != nil {
+			logger.Panicw("Failed to create new meil search", err)
+		}
+		searchClient = sc
 	}
-
-	searchClient = sc
 	metadataAddress := fmt.Sprintf("%s:%s", metadataHost, metadataPort)
 	logger.Infof("Looking for metadata at: %s\n", metadataAddress)
 	client, err := metadata.NewClient(metadataAddress, logger)
@@ -1381,6 +1532,12 @@ func main() {
 	if err != nil {
 		logger.Panicw("Failed to create server", "error", err)
 	}
+	if embeddedSearch {
+		if err := metadata.IndexResources(metadataServer.lookup, searchClient); err != nil {
+			logger.Panicw("Failed to index resources", "error", err)
+		}
+		go metadataServer.followSearchIndex(context.Background())
+	}
 	metadataHTTPPort := help.GetEnv("METADATA_HTTP_PORT", "3001")
 	metadataServingPort := fmt.Sprintf(":%s", metadataHTTPPort)
 	logger.Infof("Serving HTTP Metadata on port: %s\n", metadataServingPort)
@@ -2,8 +2,10 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -11,9 +13,11 @@ import (
 
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
+	pt "github.com/featureform/provider/provider_type"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
 )
 
 func GetTestGinContext(mockRecorder *httptest.ResponseRecorder) *gin.Context {
@@ -87,26 +91,43 @@ func TestPostTags(t *testing.T) {
 
 	MockJsonPost(ctx, params, tagList)
 
-	res := metadata.ResourceID{
-		Name:    name,
-		Variant: variant,
-		Type:    metadata.SOURCE_VARIANT,
+	logger := zaptest.NewLogger(t).Sugar()
+	meta, err := metadata.NewMetadataServer(&metadata.Config{
+		Logger:          logger,
+		StorageProvider: metadata.LocalStorageProvider{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go meta.ServeOnListener(lis)
+	defer meta.Stop()
+	client, err := metadata.NewClient(lis.Addr().String(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create client: %s", err)
+	}
+	defer client.Close()
+	defs := []metadata.ResourceDef{
+		metadata.UserDef{Name: "Featureform"},
+		metadata.ProviderDef{Name: "mockOffline", Type: string(pt.MemoryOffline)},
+		metadata.SourceDef{
+			Name:       name,
+			Variant:    variant,
+			Owner:      "Featureform",
+			Provider:   "mockOffline",
+			Definition: metadata.PrimaryDataSource{Location: metadata.SQLTable{Name: "transactions"}},
+		},
+	}
+	if err := client.CreateAll(context.Background(), defs); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
 	}
 
-	resource :=
-		&metadata.SourceResource{}
-
-	localStorageProvider := LocalStorageProvider{}
-	lookup, _ := localStorageProvider.GetResourceLookup()
-	lookup.Set(res, resource)
-
-	logger := zap.NewExample().Sugar()
-	client := &metadata.Client{}
 	serv := MetadataServer{
-		lookup:          lookup,
-		client:          client,
-		logger:          logger,
-		StorageProvider: localStorageProvider,
+		client: client,
+		logger: logger,
 	}
 	serv.PostTags(ctx)
 
@@ -117,6 +138,17 @@ func TestPostTags(t *testing.T) {
 	assert.Equal(t, name, data.Name)
 	assert.Equal(t, variant, data.Variant)
 	assert.Equal(t, tagList, data.Tags)
+	source, err := client.GetSourceVariant(context.Background(), metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		t.Fatalf("Failed to get source: %s", err)
+	}
+	assert.Equal(t, metadata.Tags(tagList), source.Tags())
+
+	missingRecorder := httptest.NewRecorder()
+	missing := GetTestGinContext(missingRecorder)
+	MockJsonPost(missing, []gin.Param{{Key: "resource", Value: "missing"}, {Key: "type", Value: resourceType}}, tagList)
+	serv.PostTags(missing)
+	assert.Equal(t, http.StatusBadRequest, missingRecorder.Code)
 }
 
 func MockGetSourceGet(c *gin.Context, params gin.Params, u url.Values) {
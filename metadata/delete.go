@@ -0,0 +1,222 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/featureform/metadata/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// ResourceCleaner removes the tables that providers hold for a resource that
+// is being deleted. It's called before the resource is removed, so it and its
+// dependencies can still be looked up.
+type ResourceCleaner interface {
+	Cleanup(res Resource, lookup ResourceLookup) error
+}
+
+func resourceIDFromProto(id *pb.ResourceID) ResourceID {
+	return ResourceID{
+		Name:    id.GetResource().GetName(),
+		Variant: id.GetResource().GetVariant(),
+		Type:    ResourceType(id.GetResourceType()),
+		// Users and providers aren't namespaced.
+		Namespace: namespaceOf(ResourceType(id.GetResourceType()), id.GetResource().GetNamespace()),
+	}
+}
+
+func resourceIDsToProto(ids []ResourceID) []*pb.ResourceID {
+	serialized := make([]*pb.ResourceID, len(ids))
+	for i, id := range ids {
+		serialized[i] = &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()}
+	}
+	return serialized
+}
+
+func isArchived(res Resource) bool {
+	return resourceStatus(res).GetStatus() == pb.ResourceStatus_ARCHIVED
+}
+
+// dependants maps every resource to the resources that directly depend on it.
+func (serv *MetadataServer) dependants() (map[ResourceID][]ResourceID, error) {
+	resources, err := serv.lookup.List()
+	if err != nil {
+		return nil, err
+	}
+	dependants := make(map[ResourceID][]ResourceID)
+	for _, res := range resources {
+		deps, err := res.Dependencies(serv.lookup)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("could not get dependencies for: %s", res.ID()))
+		}
+		depList, err := deps.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range depList {
+			dependants[dep.ID()] = append(dependants[dep.ID()], res.ID())
+		}
+	}
+	return dependants, nil
+}
+
+// affectedResources returns the resource and, if force is set, every resource
+// that depends on it, ordered so that dependants come before the resources
+// they depend on. Only dependants that include returns true for are
+// considered. Without force, any such dependant is an error.
+func (serv *MetadataServer) affectedResources(id ResourceID, force bool, include func(Resource) bool) ([]ResourceID, error) {
+	if _, err := serv.lookup.Lookup(id); err != nil {
+		return nil, err
+	}
+	dependants, err := serv.dependants()
+	if err != nil {
+		return nil, err
+	}
+	liveDependants := func(id ResourceID) ([]ResourceID, error) {
+		live := make([]ResourceID, 0)
+		for _, depID := range dependants[id] {
+			dep, err := serv.lookup.Lookup(depID)
+			if err != nil {
+				return nil, err
+			}
+			if include(dep) {
+				live = append(live, depID)
+			}
+		}
+		return live, nil
+	}
+	if !force {
+		live, err := liveDependants(id)
+		if err != nil {
+			return nil, err
+		}
+		if len(live) > 0 {
+			return nil, &ResourceHasDependants{ID: id, Dependants: live}
+		}
+		return []ResourceID{id}, nil
+	}
+	ordered := make([]ResourceID, 0)
+	visited := make(map[ResourceID]struct{})
+	var visit func(id ResourceID) error
+	visit = func(id ResourceID) error {
+		if _, has := visited[id]; has {
+			return nil
+		}
+		visited[id] = struct{}{}
+		live, err := liveDependants(id)
+		if err != nil {
+			return err
+		}
+		for _, depID := range live {
+			if err := visit(depID); err != nil {
+				return err
+			}
+		}
+		ordered = append(ordered, id)
+		return nil
+	}
+	if err := visit(id); err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+func (serv *MetadataServer) DeleteResource(ctx context.Context, req *pb.DeleteResourceRequest) (*pb.DeleteResourceResponse, error) {
+	id := resourceIDFromProto(req.GetResourceId())
+	serv.Logger.Infow("Deleting resource", "id", id.String(), "force", req.GetForce(), "cleanup", req.GetCleanup())
+	affected, err := serv.affectedResources(id, req.GetForce(), func(Resource) bool { return true })
+	if err != nil {
+		serv.Logger.Errorw("Could not delete resource", "id", id.String(), "error", err)
+		return nil, err
+	}
+	deleted := make([]ResourceID, 0, len(affected))
+	for _, id := range affected {
+		ids, err := serv.deleteResource(ctx, id, req.GetCleanup())
+		if err != nil {
+			serv.Logger.Errorw("Could not delete resource", "id", id.String(), "error", err)
+			return nil, err
+		}
+		deleted = append(deleted, ids...)
+	}
+	return &pb.DeleteResourceResponse{Deleted: resourceIDsToProto(deleted)}, nil
+}
+
+// deleteResource removes a resource and its job, and removes it from the
+// resources it depends on. A parent left without variants is deleted with
+// it. It returns everything that was deleted.
+func (serv *MetadataServer) deleteResource(ctx context.Context, id ResourceID, cleanup bool) ([]ResourceID, error) {
+	res, err := serv.lookup.Lookup(id)
+	if _, isNotFound := err.(*ResourceNotFound); isNotFound {
+		// Its last variant was deleted before it.
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if cleanup && serv.cleaner != nil {
+		if err := serv.cleaner.Cleanup(res, serv.lookup); err != nil {
+			return nil, fmt.Errorf("clean up %s: %w", id, err)
+		}
+	}
+	if err := serv.propagateChange(ctx, res, delete_op); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("failed to update dependencies of: %s", id))
+	}
+	if err := serv.lookup.DeleteJob(id); err != nil {
+		return nil, fmt.Errorf("delete job: %w", err)
+	}
+	if err := serv.lookup.Delete(id); err != nil {
+		return nil, err
+	}
+	serv.audit(ctx, pb.AuditEvent_DELETE, id, res.Proto(), nil)
+	deleted := []ResourceID{id}
+	parentID, hasParent := id.Parent()
+	if !hasParent {
+		return deleted, nil
+	}
+	parent, err := serv.lookup.Lookup(parentID)
+	if _, isNotFound := err.(*ResourceNotFound); isNotFound {
+		return deleted, nil
+	} else if err != nil {
+		return nil, err
+	}
+	variants, ok := parent.Proto().(interface{ GetVariants() []string })
+	if ok && len(variants.GetVariants()) == 0 {
+		if err := serv.lookup.Delete(parentID); err != nil {
+			return nil, err
+		}
+		serv.audit(ctx, pb.AuditEvent_DELETE, parentID, parent.Proto(), nil)
+		deleted = append(deleted, parentID)
+	}
+	return deleted, nil
+}
+
+func (serv *MetadataServer) ArchiveResource(ctx context.Context, req *pb.ArchiveResourceRequest) (*pb.ArchiveResourceResponse, error) {
+	id := resourceIDFromProto(req.GetResourceId())
+	serv.Logger.Infow("Archiving resource", "id", id.String(), "force", req.GetForce())
+	affected, err := serv.affectedResources(id, req.GetForce(), func(res Resource) bool { return !isArchived(res) })
+	if err != nil {
+		serv.Logger.Errorw("Could not archive resource", "id", id.String(), "error", err)
+		return nil, err
+	}
+	for _, id := range affected {
+		res, err := serv.lookup.Lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		before := proto.Clone(res.Proto())
+		if err := serv.lookup.DeleteJob(id); err != nil {
+			return nil, fmt.Errorf("delete job: %w", err)
+		}
+		if err := serv.lookup.SetStatus(id, &pb.ResourceStatus{Status: pb.ResourceStatus_ARCHIVED}); err != nil {
+			return nil, err
+		}
+		if after, err := serv.lookup.Lookup(id); err == nil {
+			serv.audit(ctx, pb.AuditEvent_ARCHIVE, id, before, after.Proto())
+		}
+	}
+	return &pb.ArchiveResourceResponse{Archived: resourceIDsToProto(affected)}, nil
+}
@@ -12,8 +12,11 @@ import (
 
 	help "github.com/featureform/helpers"
 	pb "github.com/featureform/metadata/proto"
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -22,6 +25,7 @@ type StorageType string
 const (
 	RESOURCE StorageType = "Resource"
 	JOB                  = "Job"
+	AUDIT                = "Audit"
 )
 
 type EtcdNode struct {
@@ -150,8 +154,17 @@ func (config EtcdConfig) MakeAddresses() []string {
 }
 
 // Uses Storage Type as prefix so Resources and Jobs can be queried more easily
+// keyName is the name of a resource in its keys. Names can't start with an
+// underscore, so names in other namespaces can't clash with default ones.
+func keyName(id ResourceID) string {
+	if id.Namespace == DefaultNamespace {
+		return id.Name
+	}
+	return fmt.Sprintf("_%s__%s", id.Namespace, id.Name)
+}
+
 func createKey(id ResourceID) string {
-	return fmt.Sprintf("%s__%s__%s", id.Type, id.Name, id.Variant)
+	return fmt.Sprintf("%s__%s__%s", id.Type, keyName(id), id.Variant)
 }
 
 // Puts K/V into ETCD
@@ -165,6 +178,17 @@ func (s EtcdStorage) Put(key string, value string) error {
 	return nil
 }
 
+// Deletes a key from ETCD, returning the number of keys deleted
+func (s EtcdStorage) Delete(key string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	resp, err := s.Client.Delete(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Deleted, nil
+}
+
 func (s EtcdStorage) genericGet(key string, withPrefix bool) (*clientv3.GetResponse, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	defer cancel()
@@ -183,14 +207,106 @@ func (s EtcdStorage) genericGet(key string, withPrefix bool) (*clientv3.GetRespo
 
 // Gets value from ETCD using a key, error if it doesn't exist
 func (s EtcdStorage) Get(key string) ([]byte, error) {
-	resp, err := s.genericGet(key, false)
+	value, _, err := s.GetWithRevision(key)
+	return value, err
+}
+
+// GetSortedRange gets up to limit key values from start up to, but not
+// including, end, in key order or reverse key order. A limit of 0 gets all
+// of them.
+func (s EtcdStorage) GetSortedRange(start, end string, descending bool, limit int) ([]*mvccpb.KeyValue, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	order := clientv3.SortAscend
+	if descending {
+		order = clientv3.SortDescend
+	}
+	resp, err := s.Client.Get(ctx, start, clientv3.WithRange(end), clientv3.WithSort(clientv3.SortByKey, order), clientv3.WithLimit(int64(limit)))
 	if err != nil {
 		return nil, err
 	}
+	return resp.Kvs, nil
+}
+
+// PutAll puts every key in a single transaction.
+func (s EtcdStorage) PutAll(values map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	ops := make([]clientv3.Op, 0, len(values))
+	for key, value := range values {
+		ops = append(ops, clientv3.OpPut(key, value))
+	}
+	_, err := s.Client.Txn(ctx).Then(ops...).Commit()
+	return err
+}
+
+// maxTxnOps is etcd's default limit on the operations in a transaction.
+const maxTxnOps = 128
+
+// GetAll gets every key with as few transactions as etcd allows, returning
+// them in the same order. Keys that don't exist are nil.
+func (s EtcdStorage) GetAll(keys []string) ([]*mvccpb.KeyValue, error) {
+	kvs := make([]*mvccpb.KeyValue, 0, len(keys))
+	for start := 0; start < len(keys); start += maxTxnOps {
+		end := start + maxTxnOps
+		if end > len(keys) {
+			end = len(keys)
+		}
+		ops := make([]clientv3.Op, 0, end-start)
+		for _, key := range keys[start:end] {
+			ops = append(ops, clientv3.OpGet(key))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+		resp, err := s.Client.Txn(ctx).Then(ops...).Commit()
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range resp.Responses {
+			var kv *mvccpb.KeyValue
+			if found := op.GetResponseRange().Kvs; len(found) > 0 {
+				kv = found[0]
+			}
+			kvs = append(kvs, kv)
+		}
+	}
+	return kvs, nil
+}
+
+// GetWithRevision also returns the revision the key was last modified at.
+func (s EtcdStorage) GetWithRevision(key string) ([]byte, int64, error) {
+	resp, err := s.genericGet(key, false)
+	if err != nil {
+		return nil, 0, err
+	}
 	if len(resp.Kvs) == 0 {
-		return nil, KeyNotFoundError{key}
+		return nil, 0, KeyNotFoundError{key}
+	}
+	return resp.Kvs[0].Value, resp.Kvs[0].ModRevision, nil
+}
+
+// PutIfRevision puts a value in a transaction that checks the key was last
+// modified at revision, which is 0 for keys that don't exist. It returns
+// whether the put happened, and the key's revision if it didn't.
+func (s EtcdStorage) PutIfRevision(key string, value string, revision int64) (bool, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	resp, err := s.Client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", revision)).
+		Then(clientv3.OpPut(key, value)).
+		Else(clientv3.OpGet(key)).
+		Commit()
+	if err != nil {
+		return false, 0, err
+	}
+	if resp.Succeeded {
+		return true, 0, nil
+	}
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) == 0 {
+		return false, 0, nil
 	}
-	return resp.Kvs[0].Value, nil
+	return false, kvs[0].ModRevision, nil
 }
 
 // Gets values from ETCD using a prefix key.
@@ -245,6 +361,12 @@ func (s EtcdStorage) ParseResource(res EtcdRow, resType Resource) (Resource, err
 
 // Returns an empty Resource Object of the given type to unmarshal etcd value into
 func (lookup EtcdResourceLookup) createEmptyResource(t ResourceType) (Resource, error) {
+	return emptyResource(t)
+}
+
+// emptyResource returns a resource of type t with an empty proto to
+// deserialize into.
+func emptyResource(t ResourceType) (Resource, error) {
 	var resource Resource
 	switch t {
 	case FEATURE:
@@ -283,12 +405,33 @@ func (lookup EtcdResourceLookup) createEmptyResource(t ResourceType) (Resource,
 	case MODEL:
 		resource = &modelResource{&pb.Model{}}
 		break
+	case FEATURE_GROUP:
+		resource = &featureGroupResource{&pb.FeatureGroup{}}
+		break
+	case FEATURE_GROUP_VARIANT:
+		resource = &featureGroupVariantResource{&pb.FeatureGroupVariant{}}
+		break
+	case MODEL_VARIANT:
+		resource = &modelVariantResource{&pb.ModelVariant{}}
+		break
 	default:
 		return nil, fmt.Errorf("Invalid Type\n")
 	}
 	return resource, nil
 }
 
+// unmarshalResource deserializes a resource of type t.
+func unmarshalResource(t ResourceType, value []byte) (Resource, error) {
+	res, err := emptyResource(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := proto.Unmarshal(value, res.Proto()); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
 // Serializes the entire ETCD Storage Object to be put into ETCD
 func (lookup EtcdResourceLookup) serializeResource(res Resource) ([]byte, error) {
 	p, err := proto.Marshal(res.Proto())
@@ -321,9 +464,10 @@ func (lookup EtcdResourceLookup) deserialize(value []byte) (EtcdRow, error) {
 	return msg, nil
 }
 
+// Resources are at the etcd revision their key was last modified at.
 func (lookup EtcdResourceLookup) Lookup(id ResourceID) (Resource, error) {
 	key := createKey(id)
-	resp, err := lookup.Connection.Get(key)
+	resp, revision, err := lookup.Connection.GetWithRevision(key)
 	if err != nil || len(resp) == 0 {
 		return nil, &ResourceNotFound{id, err}
 	}
@@ -339,6 +483,7 @@ func (lookup EtcdResourceLookup) Lookup(id ResourceID) (Resource, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("failed to parse resource: %s", id))
 	}
+	setRevision(resource, revision)
 	return resource, nil
 }
 
@@ -355,11 +500,117 @@ func (lookup EtcdResourceLookup) Has(id ResourceID) (bool, error) {
 }
 
 func GetJobKey(id ResourceID) string {
-	return fmt.Sprintf("JOB__%s__%s__%s", id.Type, id.Name, id.Variant)
+	return fmt.Sprintf("JOB__%s__%s__%s", id.Type, keyName(id), id.Variant)
 }
 
 func GetScheduleJobKey(id ResourceID) string {
-	return fmt.Sprintf("SCHEDULEJOB__%s__%s__%s", id.Type, id.Name, id.Variant)
+	return fmt.Sprintf("SCHEDULEJOB__%s__%s__%s", id.Type, keyName(id), id.Variant)
+}
+
+// GetLockKey is the key of the lock on the job at jobKey.
+func GetLockKey(jobKey string) string {
+	return fmt.Sprintf("LOCK_%s", jobKey)
+}
+
+// EtcdJobStore keeps jobs in etcd, claiming them with etcd mutexes that are
+// released if their coordinator's session ends.
+type EtcdJobStore struct {
+	Client *clientv3.Client
+}
+
+func (store EtcdJobStore) Watch(ctx context.Context, prefix string, fn func(key, value string)) error {
+	resp, err := store.Client.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not get jobs: %s", prefix))
+	}
+	for _, kv := range resp.Kvs {
+		fn(string(kv.Key), string(kv.Value))
+	}
+	watch := store.Client.Watch(ctx, prefix, clientv3.WithPrefix(), clientv3.WithRev(resp.Header.Revision+1))
+	for wresp := range watch {
+		if err := wresp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range wresp.Events {
+			if ev.Type == mvccpb.PUT {
+				fn(string(ev.Kv.Key), string(ev.Kv.Value))
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+func (store EtcdJobStore) Has(prefix string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	resp, err := store.Client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("could not get jobs: %s", prefix))
+	}
+	return resp.Count > 0, nil
+}
+
+func (store EtcdJobStore) Lock(key string) (JobLock, error) {
+	session, err := concurrency.NewSession(store.Client, concurrency.WithTTL(1))
+	if err != nil {
+		return nil, fmt.Errorf("new session: %v", err)
+	}
+	mtx := concurrency.NewMutex(session, GetLockKey(key))
+	if err := mtx.Lock(context.Background()); err != nil {
+		session.Close()
+		return nil, err
+	}
+	return &etcdJobLock{client: store.Client, session: session, mtx: mtx, key: key}, nil
+}
+
+// etcdJobLock checks that it still owns the mutex in the same transaction
+// as each read and write.
+type etcdJobLock struct {
+	client  *clientv3.Client
+	session *concurrency.Session
+	mtx     *concurrency.Mutex
+	key     string
+}
+
+func (lock *etcdJobLock) txn(op clientv3.Op) (*clientv3.TxnResponse, error) {
+	resp, err := lock.client.Txn(context.Background()).If(lock.mtx.IsOwner()).Then(op).Commit()
+	if err != nil {
+		return nil, fmt.Errorf("transaction did not succeed: %v", err)
+	}
+	if !resp.Succeeded {
+		return nil, fmt.Errorf("was not owner of lock")
+	}
+	return resp, nil
+}
+
+func (lock *etcdJobLock) Get() (string, bool, error) {
+	resp, err := lock.txn(clientv3.OpGet(lock.key))
+	if err != nil {
+		return "", false, err
+	}
+	kvs := resp.Responses[0].GetResponseRange().GetKvs()
+	if len(kvs) == 0 {
+		return "", false, nil
+	}
+	return string(kvs[0].Value), true, nil
+}
+
+func (lock *etcdJobLock) Put(value string) error {
+	_, err := lock.txn(clientv3.OpPut(lock.key, value))
+	return err
+}
+
+func (lock *etcdJobLock) Delete() (bool, error) {
+	resp, err := lock.txn(clientv3.OpDelete(lock.key))
+	if err != nil {
+		return false, err
+	}
+	return resp.Responses[0].GetResponseDeleteRange().Deleted == 1, nil
+}
+
+func (lock *etcdJobLock) Unlock() error {
+	defer lock.session.Close()
+	return lock.mtx.Unlock(context.Background())
 }
 
 func (lookup EtcdResourceLookup) HasJob(id ResourceID) (bool, error) {
@@ -394,6 +645,16 @@ func (lookup EtcdResourceLookup) SetJob(id ResourceID, schedule string) error {
 	return nil
 }
 
+// Removes the resource's coordinator job and schedule, if it has them
+func (lookup EtcdResourceLookup) DeleteJob(id ResourceID) error {
+	for _, key := range []string{GetJobKey(id), GetScheduleJobKey(id)} {
+		if _, err := lookup.Connection.Delete(key); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not delete job: %s", key))
+		}
+	}
+	return nil
+}
+
 func (lookup EtcdResourceLookup) SetSchedule(id ResourceID, schedule string) error {
 	coordinatorScheduleJob := CoordinatorScheduleJob{
 		Attempts: 0,
@@ -425,16 +686,47 @@ func (lookup EtcdResourceLookup) Set(id ResourceID, res Resource) error {
 	return nil
 }
 
+func (lookup EtcdResourceLookup) SetIfRevision(id ResourceID, res Resource, revision int64) error {
+	serRes, err := lookup.serializeResource(res)
+	if err != nil {
+		return err
+	}
+	put, actual, err := lookup.Connection.PutIfRevision(createKey(id), string(serRes), revision)
+	if err != nil {
+		return err
+	}
+	if !put {
+		return &RevisionConflict{ID: id, Expected: revision, Actual: actual}
+	}
+	return nil
+}
+
+func (lookup EtcdResourceLookup) Delete(id ResourceID) error {
+	deleted, err := lookup.Connection.Delete(createKey(id))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not delete: %s", id))
+	}
+	if deleted == 0 {
+		return &ResourceNotFound{id, nil}
+	}
+	return nil
+}
+
 func (lookup EtcdResourceLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
 	resources := make(LocalResourceLookup, len(ids))
-
-	for _, id := range ids {
-		key := createKey(id)
-		resp, err := lookup.Connection.Get(key)
-		if err != nil {
-			return nil, &ResourceNotFound{id, err}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = createKey(id)
+	}
+	kvs, err := lookup.Connection.GetAll(keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "submap get")
+	}
+	for i, id := range ids {
+		if kvs[i] == nil {
+			return nil, &ResourceNotFound{id, KeyNotFoundError{keys[i]}}
 		}
-		etcdStore, err := lookup.deserialize(resp)
+		etcdStore, err := lookup.deserialize(kvs[i].Value)
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("submap deserialize: %s", id))
 		}
@@ -448,18 +740,26 @@ func (lookup EtcdResourceLookup) Submap(ids []ResourceID) (ResourceLookup, error
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("submap parse resource: %s", id))
 		}
+		setRevision(res, kvs[i].ModRevision)
 		resources[id] = res
 	}
 	return resources, nil
 }
 
+// typeKeyPrefix is the prefix of the keys of resources of type t. The
+// separator stops FEATURE from matching FEATURE_VARIANT keys.
+func typeKeyPrefix(t ResourceType) string {
+	return fmt.Sprintf("%s__", t)
+}
+
 func (lookup EtcdResourceLookup) ListForType(t ResourceType) ([]Resource, error) {
 	resources := make([]Resource, 0)
-	resp, err := lookup.Connection.GetWithPrefix(t.String())
+	resp, err := lookup.Connection.genericGet(typeKeyPrefix(t), true)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("could not get prefix: %s", t))
 	}
-	for _, res := range resp {
+	for _, kv := range resp.Kvs {
+		res := kv.Value
 		etcdStore, err := lookup.deserialize(res)
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("could not deserialize: %s", res))
@@ -469,6 +769,7 @@ func (lookup EtcdResourceLookup) ListForType(t ResourceType) ([]Resource, error)
 			return nil, errors.Wrap(err, fmt.Sprintf("could not create empty resource: %s", res))
 		}
 		resource, err = lookup.Connection.ParseResource(etcdStore, resource)
+		setRevision(resource, kv.ModRevision)
 		if resource.ID().Type == t {
 			resources = append(resources, resource)
 		}
@@ -476,37 +777,215 @@ func (lookup EtcdResourceLookup) ListForType(t ResourceType) ([]Resource, error)
 	return resources, nil
 }
 
+func (lookup EtcdResourceLookup) ListRange(t ResourceType, start, end string, descending bool, limit int) ([]Resource, error) {
+	kvs, err := lookup.Connection.GetSortedRange(start, end, descending, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not get range: %s", t))
+	}
+	resources := make([]Resource, 0, len(kvs))
+	for _, kv := range kvs {
+		etcdStore, err := lookup.deserialize(kv.Value)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("could not deserialize: %s", kv.Key))
+		}
+		resource, err := lookup.createEmptyResource(etcdStore.ResourceType)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("could not create empty resource: %s", kv.Key))
+		}
+		resource, err = lookup.Connection.ParseResource(etcdStore, resource)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("could not parse resource: %s", kv.Key))
+		}
+		setRevision(resource, kv.ModRevision)
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
 func (lookup EtcdResourceLookup) List() ([]Resource, error) {
 	resources := make([]Resource, 0)
-	resp, err := lookup.Connection.GetWithPrefix("")
+	resp, err := lookup.Connection.genericGet("", true)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("could not get prefix: %v", resources))
 	}
-	for _, res := range resp {
+	for _, kv := range resp.Kvs {
+		res := kv.Value
 		etcdStore, err := lookup.deserialize(res)
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("list deserialize: %s", res))
 		}
+		// Jobs and audit events share the keyspace.
+		if etcdStore.StorageType != RESOURCE {
+			continue
+		}
 		resource, err := lookup.createEmptyResource(etcdStore.ResourceType)
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("list create empty resource: %s", res))
 		}
 		resource, err = lookup.Connection.ParseResource(etcdStore, resource)
+		setRevision(resource, kv.ModRevision)
 		resources = append(resources, resource)
 	}
 	return resources, nil
 }
 
-func (lookup EtcdResourceLookup) SetStatus(id ResourceID, status pb.ResourceStatus) error {
-	res, err := lookup.Lookup(id)
+// SetStatus retries if the resource is written while its status is set, so
+// that neither write is lost.
+func (lookup EtcdResourceLookup) SetStatus(id ResourceID, status *pb.ResourceStatus) error {
+	_, _, err := updateResource(lookup, id, 0, func(res Resource) error {
+		return res.UpdateStatus(status)
+	})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not set status of ID: %v", id))
+	}
+	return nil
+}
+
+// EtcdAuditLog keeps audit events in etcd, keyed by resource and time so a
+// resource's history is a single prefix scan. Each event is also kept under
+// a key ordered by time alone, so a time range is a single range read.
+// Events appended before the time keys existed are only in histories.
+type EtcdAuditLog struct {
+	Connection EtcdStorage
+}
+
+const (
+	auditKeyPrefix     = "AUDIT__"
+	auditTimeKeyPrefix = "AUDITTIME__"
+)
+
+func auditResourcePrefix(id ResourceID) string {
+	return fmt.Sprintf("%s%s__", auditKeyPrefix, createKey(id))
+}
+
+// auditEventKey is the key of an event in a resource's history. Events can
+// share a timestamp, like a resource and the parent created with it, so the
+// key ends in a unique suffix.
+func auditEventKey(id ResourceID, nanos int64, suffix string) string {
+	return fmt.Sprintf("%s%020d__%s", auditResourcePrefix(id), nanos, suffix)
+}
+
+// auditTimeKey is the key of an event ordered by time across resources.
+// Times before the epoch are kept at it so that keys sort.
+func auditTimeKey(nanos int64, suffix string) string {
+	if nanos < 0 {
+		nanos = 0
+	}
+	return fmt.Sprintf("%s%020d__%s", auditTimeKeyPrefix, nanos, suffix)
+}
+
+func (auditLog EtcdAuditLog) Append(event *pb.AuditEvent) error {
+	id := resourceIDFromProto(event.ResourceId)
+	p, err := proto.Marshal(event)
 	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not lookup ID: %v", id))
+		return err
 	}
-	if err := res.UpdateStatus(status); err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not update ID: %v", id))
+	row, err := json.Marshal(EtcdRowTemp{
+		ResourceType: id.Type,
+		StorageType:  AUDIT,
+		Message:      p,
+	})
+	if err != nil {
+		return err
 	}
-	if err := lookup.Set(id, res); err != nil {
-		return errors.Wrap(err, fmt.Sprintf("could not set ID: %v", id))
+	nanos := event.Timestamp.AsTime().UnixNano()
+	suffix := uuid.NewString()
+	return auditLog.Connection.PutAll(map[string]string{
+		auditEventKey(id, nanos, suffix): string(row),
+		auditTimeKey(nanos, suffix):      string(row),
+	})
+}
+
+func (auditLog EtcdAuditLog) eventsWithPrefix(prefix string) ([]*pb.AuditEvent, error) {
+	resp, err := auditLog.Connection.genericGet(prefix, true)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return parseAuditRows(resp.Kvs)
+}
+
+// parseAuditRows parses the events in kvs. Their revisions are the etcd
+// revisions they were written at, as they are when watched.
+func parseAuditRows(kvs []*mvccpb.KeyValue) ([]*pb.AuditEvent, error) {
+	events := make([]*pb.AuditEvent, 0, len(kvs))
+	for _, kv := range kvs {
+		var row EtcdRowTemp
+		if err := json.Unmarshal(kv.Value, &row); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("failed to parse audit event: %s", kv.Key))
+		}
+		event := &pb.AuditEvent{}
+		if err := proto.Unmarshal(row.Message, event); err != nil {
+			return nil, err
+		}
+		event.Revision = kv.ModRevision
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (auditLog EtcdAuditLog) History(id ResourceID) ([]*pb.AuditEvent, error) {
+	events, err := auditLog.eventsWithPrefix(auditResourcePrefix(id))
+	if err != nil {
+		return nil, err
+	}
+	sortAuditEvents(events)
+	return events, nil
+}
+
+// Watch uses etcd's own watch, so events appended by every metadata server
+// are seen. Revisions are the etcd revisions the events were written at.
+func (auditLog EtcdAuditLog) Watch(ctx context.Context, startRevision int64, fn func(*pb.AuditEvent) error) error {
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if startRevision > 0 {
+		opts = append(opts, clientv3.WithRev(startRevision))
+	}
+	ctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	for resp := range auditLog.Connection.Client.Watch(ctx, auditKeyPrefix, opts...) {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range resp.Events {
+			if ev.Type != clientv3.EventTypePut {
+				continue
+			}
+			var row EtcdRowTemp
+			if err := json.Unmarshal(ev.Kv.Value, &row); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to parse audit event: %s", ev.Kv.Key))
+			}
+			event := &pb.AuditEvent{}
+			if err := proto.Unmarshal(row.Message, event); err != nil {
+				return err
+			}
+			event.Revision = ev.Kv.ModRevision
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// Range only reads the time keys between start and end.
+func (auditLog EtcdAuditLog) Range(start, end time.Time) ([]*pb.AuditEvent, error) {
+	if end.Before(start) {
+		return []*pb.AuditEvent{}, nil
+	}
+	// Suffixes come after the separator, so these bound every key in range.
+	resp, err := auditLog.Connection.GetSortedRange(auditTimeKey(start.UnixNano(), ""), auditTimeKey(end.UnixNano()+1, ""), false, 0)
+	if err != nil {
+		return nil, err
+	}
+	events, err := parseAuditRows(resp)
+	if err != nil {
+		return nil, err
+	}
+	inRange := make([]*pb.AuditEvent, 0, len(events))
+	for _, event := range events {
+		if eventInRange(event, start, end) {
+			inRange = append(inRange, event)
+		}
+	}
+	sortAuditEvents(inRange)
+	return inRange, nil
 }
@@ -912,3 +912,64 @@ func TestGetJobKeys(t *testing.T) {
 		t.Fatalf("Could not generate correct schedule job key")
 	}
 }
+
+func TestAuditKeys(t *testing.T) {
+	id := ResourceID{Name: "user", Type: USER}
+	if auditEventKey(id, 1, "a") == auditEventKey(id, 1, "b") {
+		t.Fatalf("Expected events at the same time to have different keys")
+	}
+	// Range reads from the start key up to the end key, so it includes every
+	// suffix at its bounds and nothing outside them.
+	start, end := auditTimeKey(10, ""), auditTimeKey(21, "")
+	inRange := []string{auditTimeKey(10, "a"), auditTimeKey(15, "b"), auditTimeKey(20, "c")}
+	for _, key := range inRange {
+		if key < start || key >= end {
+			t.Fatalf("Expected %s between %s and %s", key, start, end)
+		}
+	}
+	for _, key := range []string{auditTimeKey(9, "a"), auditTimeKey(21, "a"), auditTimeKey(100, "a")} {
+		if key >= start && key < end {
+			t.Fatalf("Expected %s outside %s and %s", key, start, end)
+		}
+	}
+	if auditTimeKey(-5, "a") != auditTimeKey(0, "a") {
+		t.Fatalf("Expected times before the epoch to be kept at it")
+	}
+}
+
+func TestEtcdAuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	etcd := Etcd{}
+	etcd.init()
+	t.Cleanup(etcd.clearDatabase)
+	auditLog := EtcdAuditLog{Connection: EtcdStorage{Client: etcd.client}}
+	id := ResourceID{Name: "user", Type: USER}
+	start := time.Now()
+	// Two events at the same time are both kept.
+	for i, action := range []pb.AuditEvent_Action{pb.AuditEvent_CREATE, pb.AuditEvent_UPDATE, pb.AuditEvent_UPDATE} {
+		event := &pb.AuditEvent{
+			ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+			Action:     action,
+			Timestamp:  tspb.New(start.Add(time.Duration(i/2) * time.Second)),
+		}
+		if err := auditLog.Append(event); err != nil {
+			t.Fatalf("Failed to append: %s", err)
+		}
+	}
+	history, err := auditLog.History(id)
+	if err != nil {
+		t.Fatalf("Failed to get history: %s", err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("Wrong history: %v", history)
+	}
+	events, err := auditLog.Range(start.Add(time.Millisecond), start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to get range: %s", err)
+	}
+	if len(events) != 1 || events[0].Action != pb.AuditEvent_UPDATE {
+		t.Fatalf("Wrong events in range: %v", events)
+	}
+}
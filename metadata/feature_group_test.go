@@ -0,0 +1,174 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestFeatureGroup(t *testing.T) {
+	group := NameVariant{Name: "user-features", Variant: "v1"}
+	defs := append(filledResourceDefs(),
+		FeatureGroupDef{
+			Name:    group.Name,
+			Variant: group.Variant,
+			Owner:   "Featureform",
+			Entity:  "user",
+			Features: NameVariants{
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
+			},
+		},
+		TrainingSetDef{
+			Name:          "grouped",
+			Variant:       "variant",
+			Provider:      "mockOffline",
+			Owner:         "Featureform",
+			Label:         NameVariant{Name: "label", Variant: "variant"},
+			Features:      NameVariants{{Name: "feature2", Variant: "variant"}},
+			FeatureGroups: NameVariants{group},
+		},
+		ModelDef{
+			Name:          "grouped-model",
+			FeatureGroups: NameVariants{group},
+		},
+	)
+	ctx := testContext{
+		Defs: defs,
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	parent, err := client.GetFeatureGroup(bg, group.Name)
+	if err != nil {
+		t.Fatalf("Failed to get feature group: %s", err)
+	}
+	if parent.DefaultVariant() != group.Variant || !reflect.DeepEqual(parent.Variants(), []string{group.Variant}) {
+		t.Fatalf("Wrong feature group variants: %s", parent)
+	}
+	variant, err := client.GetFeatureGroupVariant(bg, group)
+	if err != nil {
+		t.Fatalf("Failed to get feature group variant: %s", err)
+	}
+	if variant.Status() != READY {
+		t.Fatalf("Expected feature group to be ready: %s", variant.Status())
+	}
+	trainingSet := NameVariant{Name: "grouped", Variant: "variant"}
+	if !reflect.DeepEqual(variant.TrainingSets(), NameVariants{trainingSet}) {
+		t.Fatalf("Wrong feature group training sets: %v", variant.TrainingSets())
+	}
+
+	// The group's features are added to the training set's after its own.
+	ts, err := client.GetTrainingSetVariant(bg, trainingSet)
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	expected := NameVariants{
+		{Name: "feature2", Variant: "variant"},
+		{Name: "feature", Variant: "variant"},
+	}
+	if !reflect.DeepEqual(ts.Features(), expected) {
+		t.Fatalf("Expected training set features %v, found %v", expected, ts.Features())
+	}
+	if !reflect.DeepEqual(ts.FeatureGroups(), NameVariants{group}) {
+		t.Fatalf("Wrong training set feature groups: %v", ts.FeatureGroups())
+	}
+	feature, err := client.GetFeatureVariant(bg, NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if !reflect.DeepEqual(feature.FeatureGroups(), NameVariants{group}) {
+		t.Fatalf("Wrong feature groups of feature: %v", feature.FeatureGroups())
+	}
+
+	groupID := ResourceID{Name: group.Name, Variant: group.Variant, Type: FEATURE_GROUP_VARIANT}
+	lineage, err := client.GetLineage(bg, groupID, pb.LineageRequest_BOTH, 1)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	nodes := make(map[ResourceID]bool)
+	for _, node := range lineage.Nodes {
+		nodes[resourceIDFromProto(node)] = true
+	}
+	expectedNodes := map[ResourceID]bool{
+		groupID:                      true,
+		{Name: "user", Type: ENTITY}: true,
+		{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}:      true,
+		{Name: "feature2", Variant: "variant", Type: FEATURE_VARIANT}:     true,
+		{Name: "grouped", Variant: "variant", Type: TRAINING_SET_VARIANT}: true,
+		{Name: "grouped-model", Type: MODEL}:                              true,
+	}
+	if !reflect.DeepEqual(nodes, expectedNodes) {
+		t.Fatalf("Wrong feature group lineage: %v", nodes)
+	}
+
+	inconsistencies, err := client.CheckIntegrity(bg, false, false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %s", err)
+	}
+	if len(inconsistencies) != 0 {
+		t.Fatalf("Expected no inconsistencies: %v", inconsistencies)
+	}
+}
+
+func TestFeatureGroupInvalid(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	invalid := map[string]struct {
+		def  FeatureGroupDef
+		code codes.Code
+	}{
+		"no features": {
+			FeatureGroupDef{Name: "group", Variant: "empty", Owner: "Featureform", Entity: "user"},
+			codes.InvalidArgument,
+		},
+		"other entity": {
+			FeatureGroupDef{Name: "group", Variant: "item", Owner: "Featureform", Entity: "item", Features: NameVariants{{Name: "feature", Variant: "variant"}}},
+			codes.InvalidArgument,
+		},
+		"repeated feature": {
+			FeatureGroupDef{Name: "group", Variant: "repeated", Owner: "Featureform", Entity: "user", Features: NameVariants{{Name: "feature", Variant: "variant"}, {Name: "feature", Variant: "variant"}}},
+			codes.InvalidArgument,
+		},
+		"missing feature": {
+			FeatureGroupDef{Name: "group", Variant: "missing", Owner: "Featureform", Entity: "user", Features: NameVariants{{Name: "feature", Variant: "missing"}}},
+			codes.NotFound,
+		},
+	}
+	for name, test := range invalid {
+		if err := client.CreateFeatureGroupVariant(bg, test.def); status.Code(err) != test.code {
+			t.Fatalf("Expected %s group to fail with %s: %v", name, test.code, err)
+		}
+	}
+	ts := TrainingSetDef{
+		Name:          "grouped",
+		Variant:       "variant",
+		Provider:      "mockOffline",
+		Owner:         "Featureform",
+		Label:         NameVariant{Name: "label", Variant: "variant"},
+		FeatureGroups: NameVariants{{Name: "group", Variant: "missing"}},
+	}
+	if err := client.CreateTrainingSetVariant(bg, ts); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected training set with a missing group to fail: %v", err)
+	}
+}
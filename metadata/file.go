@@ -0,0 +1,412 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// FileStorageProvider keeps metadata in log files under Dir so that it
+// survives restarts without an external store.
+type FileStorageProvider struct {
+	Dir string
+}
+
+func (sp FileStorageProvider) GetResourceLookup() (ResourceLookup, error) {
+	lookup, err := OpenFileResourceLookup(filepath.Join(sp.Dir, "resources.log"))
+	if err != nil {
+		return nil, fmt.Errorf("could not open resource log: %v", err)
+	}
+	return lookup, nil
+}
+
+func (sp FileStorageProvider) GetAuditLog() (AuditLog, error) {
+	auditLog, err := OpenFileAuditLog(filepath.Join(sp.Dir, "audit.log"))
+	if err != nil {
+		return nil, fmt.Errorf("could not open audit log: %v", err)
+	}
+	return auditLog, nil
+}
+
+// openLogFile opens an append-only log of newline separated records, calling
+// apply on each existing record in order. A partial record at the end, left
+// by a crash mid-write, is truncated.
+func openLogFile(path string, apply func(record []byte) error) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		record, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if err := apply(bytes.TrimSuffix(record, []byte("\n"))); err != nil {
+			file.Close()
+			return nil, errors.Wrap(err, fmt.Sprintf("corrupt record at offset %d of %s", offset, path))
+		}
+		offset += int64(len(record))
+	}
+	if err := file.Truncate(offset); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return file, nil
+}
+
+func appendLogRecord(file *os.File, record []byte) error {
+	if _, err := file.Write(append(record, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+type fileLogOp string
+
+const (
+	fileLogSet    fileLogOp = "SET"
+	fileLogDelete fileLogOp = "DELETE"
+)
+
+type fileLogEntry struct {
+	Op       fileLogOp
+	Resource ResourceID
+	Message  []byte `json:",omitempty"`
+}
+
+// The log is compacted once it has this many entries and most of them are
+// stale.
+const minCompactEntries = 1000
+
+// FileResourceLookup keeps resources in memory and appends every change to
+// a log file, which is replayed when it's opened. Jobs aren't stored, as
+// there's no coordinator to run them.
+type FileResourceLookup struct {
+	mtx       sync.RWMutex
+	path      string
+	file      *os.File
+	resources LocalResourceLookup
+	entries   int
+}
+
+func OpenFileResourceLookup(path string) (*FileResourceLookup, error) {
+	lookup := &FileResourceLookup{
+		path:      path,
+		resources: make(LocalResourceLookup),
+	}
+	file, err := openLogFile(path, lookup.replay)
+	if err != nil {
+		return nil, err
+	}
+	lookup.file = file
+	if err := lookup.compactIfStale(); err != nil {
+		lookup.file.Close()
+		return nil, err
+	}
+	return lookup, nil
+}
+
+func (lookup *FileResourceLookup) replay(record []byte) error {
+	var entry fileLogEntry
+	if err := json.Unmarshal(record, &entry); err != nil {
+		return err
+	}
+	switch entry.Op {
+	case fileLogSet:
+		res, err := unmarshalResource(entry.Resource.Type, entry.Message)
+		if err != nil {
+			return err
+		}
+		lookup.resources[entry.Resource] = res
+	case fileLogDelete:
+		delete(lookup.resources, entry.Resource)
+	default:
+		return fmt.Errorf("unknown log op: %s", entry.Op)
+	}
+	lookup.entries++
+	return nil
+}
+
+func setEntry(id ResourceID, res Resource) ([]byte, error) {
+	p, err := proto.Marshal(res.Proto())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fileLogEntry{Op: fileLogSet, Resource: id, Message: p})
+}
+
+// write appends record to the log and then calls apply to make the same
+// change in memory, before the log is compacted from memory. The change is
+// stored once it's appended, so a failed compaction is only logged and is
+// tried again on the next write.
+func (lookup *FileResourceLookup) write(record []byte, apply func()) error {
+	if err := appendLogRecord(lookup.file, record); err != nil {
+		return errors.Wrap(err, "could not write resource log")
+	}
+	apply()
+	lookup.entries++
+	if err := lookup.compactIfStale(); err != nil {
+		fmt.Printf("Could not compact resource log %s: %v\n", lookup.path, err)
+	}
+	return nil
+}
+
+func (lookup *FileResourceLookup) compactIfStale() error {
+	if lookup.entries < minCompactEntries || lookup.entries < 2*len(lookup.resources) {
+		return nil
+	}
+	return lookup.compact()
+}
+
+// compact rewrites the log with only the current version of each resource.
+// The new log replaces the old one with a rename, so a crash leaves one or
+// the other.
+func (lookup *FileResourceLookup) compact() error {
+	tmpPath := lookup.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(tmp)
+	for id, res := range lookup.resources {
+		record, err := setEntry(id, res)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		writer.Write(append(record, '\n'))
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, lookup.path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(lookup.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	lookup.file.Close()
+	lookup.file = file
+	lookup.entries = len(lookup.resources)
+	return nil
+}
+
+// Close closes the log file.
+func (lookup *FileResourceLookup) Close() error {
+	lookup.mtx.Lock()
+	defer lookup.mtx.Unlock()
+	return lookup.file.Close()
+}
+
+func (lookup *FileResourceLookup) Lookup(id ResourceID) (Resource, error) {
+	lookup.mtx.RLock()
+	defer lookup.mtx.RUnlock()
+	return lookup.resources.Lookup(id)
+}
+
+func (lookup *FileResourceLookup) Has(id ResourceID) (bool, error) {
+	lookup.mtx.RLock()
+	defer lookup.mtx.RUnlock()
+	return lookup.resources.Has(id)
+}
+
+func (lookup *FileResourceLookup) Set(id ResourceID, res Resource) error {
+	lookup.mtx.Lock()
+	defer lookup.mtx.Unlock()
+	return lookup.set(id, res)
+}
+
+func (lookup *FileResourceLookup) SetIfRevision(id ResourceID, res Resource, revision int64) error {
+	lookup.mtx.Lock()
+	defer lookup.mtx.Unlock()
+	if actual := resourceRevision(lookup.resources[id]); actual != revision {
+		return &RevisionConflict{ID: id, Expected: revision, Actual: actual}
+	}
+	return lookup.set(id, res)
+}
+
+// set stores res at the revision after the stored one. Revisions are kept
+// in the log with the rest of the resource, and res keeps its old revision
+// if it can't be written.
+func (lookup *FileResourceLookup) set(id ResourceID, res Resource) error {
+	previous := resourceRevision(res)
+	setRevision(res, resourceRevision(lookup.resources[id])+1)
+	record, err := setEntry(id, res)
+	if err == nil {
+		err = lookup.write(record, func() { lookup.resources[id] = res })
+	}
+	if err != nil {
+		setRevision(res, previous)
+	}
+	return err
+}
+
+func (lookup *FileResourceLookup) Delete(id ResourceID) error {
+	lookup.mtx.Lock()
+	defer lookup.mtx.Unlock()
+	if _, has := lookup.resources[id]; !has {
+		return &ResourceNotFound{id, nil}
+	}
+	record, err := json.Marshal(fileLogEntry{Op: fileLogDelete, Resource: id})
+	if err != nil {
+		return err
+	}
+	return lookup.write(record, func() { delete(lookup.resources, id) })
+}
+
+func (lookup *FileResourceLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
+	lookup.mtx.RLock()
+	defer lookup.mtx.RUnlock()
+	return lookup.resources.Submap(ids)
+}
+
+func (lookup *FileResourceLookup) ListForType(t ResourceType) ([]Resource, error) {
+	lookup.mtx.RLock()
+	defer lookup.mtx.RUnlock()
+	return lookup.resources.ListForType(t)
+}
+
+func (lookup *FileResourceLookup) ListRange(t ResourceType, start, end string, descending bool, limit int) ([]Resource, error) {
+	lookup.mtx.RLock()
+	defer lookup.mtx.RUnlock()
+	return lookup.resources.ListRange(t, start, end, descending, limit)
+}
+
+func (lookup *FileResourceLookup) List() ([]Resource, error) {
+	lookup.mtx.RLock()
+	defer lookup.mtx.RUnlock()
+	return lookup.resources.List()
+}
+
+func (lookup *FileResourceLookup) HasJob(id ResourceID) (bool, error) {
+	return false, nil
+}
+
+func (lookup *FileResourceLookup) SetJob(id ResourceID, schedule string) error {
+	return nil
+}
+
+func (lookup *FileResourceLookup) DeleteJob(id ResourceID) error {
+	return nil
+}
+
+// update applies fn to a copy of a resource, so that it's left unchanged if
+// the log can't be written.
+func (lookup *FileResourceLookup) update(id ResourceID, fn func(Resource) error) error {
+	lookup.mtx.Lock()
+	defer lookup.mtx.Unlock()
+	res, has := lookup.resources[id]
+	if !has {
+		return &ResourceNotFound{id, nil}
+	}
+	updated, err := copyResource(res)
+	if err != nil {
+		return err
+	}
+	if err := fn(updated); err != nil {
+		return err
+	}
+	return lookup.set(id, updated)
+}
+
+func (lookup *FileResourceLookup) SetStatus(id ResourceID, status *pb.ResourceStatus) error {
+	return lookup.update(id, func(res Resource) error {
+		return res.UpdateStatus(status)
+	})
+}
+
+func (lookup *FileResourceLookup) SetSchedule(id ResourceID, schedule string) error {
+	return lookup.update(id, func(res Resource) error {
+		return res.UpdateSchedule(schedule)
+	})
+}
+
+// FileAuditLog appends audit events to a log file and keeps them in memory
+// for queries.
+type FileAuditLog struct {
+	mtx    sync.Mutex
+	file   *os.File
+	events *LocalAuditLog
+}
+
+func OpenFileAuditLog(path string) (*FileAuditLog, error) {
+	events := NewLocalAuditLog()
+	file, err := openLogFile(path, func(record []byte) error {
+		event := &pb.AuditEvent{}
+		if err := protojson.Unmarshal(record, event); err != nil {
+			return err
+		}
+		return events.Append(event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditLog{file: file, events: events}, nil
+}
+
+func (auditLog *FileAuditLog) Append(event *pb.AuditEvent) error {
+	record, err := protojson.Marshal(event)
+	if err != nil {
+		return err
+	}
+	auditLog.mtx.Lock()
+	defer auditLog.mtx.Unlock()
+	if err := appendLogRecord(auditLog.file, record); err != nil {
+		return errors.Wrap(err, "could not write audit log")
+	}
+	return auditLog.events.Append(event)
+}
+
+func (auditLog *FileAuditLog) History(id ResourceID) ([]*pb.AuditEvent, error) {
+	return auditLog.events.History(id)
+}
+
+func (auditLog *FileAuditLog) Range(start, end time.Time) ([]*pb.AuditEvent, error) {
+	return auditLog.events.Range(start, end)
+}
+
+// Watch watches the events in memory. Revisions aren't written to the log,
+// as replaying it numbers the events the same way again.
+func (auditLog *FileAuditLog) Watch(ctx context.Context, startRevision int64, fn func(*pb.AuditEvent) error) error {
+	return auditLog.events.Watch(ctx, startRevision, fn)
+}
+
+// Close closes the log file.
+func (auditLog *FileAuditLog) Close() error {
+	auditLog.mtx.Lock()
+	defer auditLog.mtx.Unlock()
+	return auditLog.file.Close()
+}
@@ -0,0 +1,196 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func openTestFileLookup(t *testing.T, path string) *FileResourceLookup {
+	lookup, err := OpenFileResourceLookup(path)
+	if err != nil {
+		t.Fatalf("Failed to open resource log: %s", err)
+	}
+	return lookup
+}
+
+func TestFileResourceLookupReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata", "resources.log")
+	lookup := openTestFileLookup(t, path)
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	res := &featureVariantResource{&pb.FeatureVariant{
+		Name:    "feature",
+		Variant: "variant",
+		Status:  &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+	}}
+	user := &userResource{&pb.User{Name: "user"}}
+	for _, r := range []Resource{res, user} {
+		if err := lookup.Set(r.ID(), r); err != nil {
+			t.Fatalf("Failed to set: %s", err)
+		}
+	}
+	if err := lookup.SetStatus(id, &pb.ResourceStatus{Status: pb.ResourceStatus_READY}); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	if err := lookup.Delete(user.ID()); err != nil {
+		t.Fatalf("Failed to delete: %s", err)
+	}
+	if err := lookup.Close(); err != nil {
+		t.Fatalf("Failed to close: %s", err)
+	}
+
+	reopened := openTestFileLookup(t, path)
+	defer reopened.Close()
+	found, err := reopened.Lookup(id)
+	if err != nil {
+		t.Fatalf("Resource lost on reopen: %s", err)
+	}
+	if status := found.Proto().(*pb.FeatureVariant).Status.Status; status != pb.ResourceStatus_READY {
+		t.Fatalf("Status lost on reopen: %s", status)
+	}
+	if has, _ := reopened.Has(user.ID()); has {
+		t.Fatalf("Deleted resource came back on reopen")
+	}
+	all, _ := reopened.List()
+	if len(all) != 1 {
+		t.Fatalf("Wrong resources after reopen: %v", all)
+	}
+}
+
+func TestFileResourceLookupPartialRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.log")
+	lookup := openTestFileLookup(t, path)
+	user := &userResource{&pb.User{Name: "user"}}
+	if err := lookup.Set(user.ID(), user); err != nil {
+		t.Fatalf("Failed to set: %s", err)
+	}
+	lookup.Close()
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open log: %s", err)
+	}
+	file.WriteString(`{"Op":"SET","Resou`)
+	file.Close()
+
+	reopened := openTestFileLookup(t, path)
+	other := &userResource{&pb.User{Name: "other"}}
+	if err := reopened.Set(other.ID(), other); err != nil {
+		t.Fatalf("Failed to set: %s", err)
+	}
+	reopened.Close()
+	reopened = openTestFileLookup(t, path)
+	defer reopened.Close()
+	users, _ := reopened.ListForType(USER)
+	if len(users) != 2 {
+		t.Fatalf("Expected both users after truncating the partial record, got %v", users)
+	}
+}
+
+func TestFileResourceLookupCorruptRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.log")
+	if err := os.WriteFile(path, []byte("not json\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %s", err)
+	}
+	if _, err := OpenFileResourceLookup(path); err == nil {
+		t.Fatalf("Expected corrupt log to fail to open")
+	}
+}
+
+func TestFileResourceLookupCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.log")
+	lookup := openTestFileLookup(t, path)
+	var user *userResource
+	for i := 0; i < minCompactEntries; i++ {
+		user = &userResource{&pb.User{Name: "user", Tags: &pb.Tags{Tag: []string{fmt.Sprintf("tag-%d", i)}}}}
+		if err := lookup.Set(user.ID(), user); err != nil {
+			t.Fatalf("Failed to set: %s", err)
+		}
+	}
+	if lookup.entries != 1 {
+		t.Fatalf("Log not compacted: %d entries", lookup.entries)
+	}
+	lookup.Close()
+	reopened := openTestFileLookup(t, path)
+	defer reopened.Close()
+	found, err := reopened.Lookup(user.ID())
+	if err != nil {
+		t.Fatalf("Resource lost in compaction: %s", err)
+	}
+	if tags := found.Proto().(*pb.User).Tags.Tag; len(tags) != 1 || tags[0] != fmt.Sprintf("tag-%d", minCompactEntries-1) {
+		t.Fatalf("Wrong version kept in compaction: %v", tags)
+	}
+}
+
+func TestFileResourceLookupCompactionFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.log")
+	lookup := openTestFileLookup(t, path)
+	defer lookup.Close()
+	// A directory in the way of the compacted log makes compaction fail.
+	if err := os.Mkdir(path+".tmp", 0755); err != nil {
+		t.Fatalf("Failed to make directory: %s", err)
+	}
+	lookup.entries = minCompactEntries
+	user := &userResource{&pb.User{Name: "user"}}
+	if err := lookup.Set(user.ID(), user); err != nil {
+		t.Fatalf("Expected the write to succeed without compaction: %s", err)
+	}
+	if _, err := lookup.Lookup(user.ID()); err != nil {
+		t.Fatalf("Resource lost: %s", err)
+	}
+}
+
+func TestFileResourceLookupFailedWriteRevision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.log")
+	lookup := openTestFileLookup(t, path)
+	user := &userResource{&pb.User{Name: "user"}}
+	if err := lookup.Set(user.ID(), user); err != nil {
+		t.Fatalf("Failed to set: %s", err)
+	}
+	lookup.Close()
+	updated := &userResource{&pb.User{Name: "user", Revision: 1}}
+	if err := lookup.Set(updated.ID(), updated); err == nil {
+		t.Fatalf("Expected a write to a closed log to fail")
+	}
+	if revision := resourceRevision(updated); revision != 1 {
+		t.Fatalf("Failed write changed the revision to %d", revision)
+	}
+	if found, _ := lookup.Lookup(user.ID()); resourceRevision(found) != 1 {
+		t.Fatalf("Failed write was applied: %v", found)
+	}
+}
+
+func TestFileAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	auditLog, err := OpenFileAuditLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %s", err)
+	}
+	id := ResourceID{Name: "user", Type: USER}
+	event := &pb.AuditEvent{
+		ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+		Action:     pb.AuditEvent_CREATE,
+		Timestamp:  tspb.New(time.Now()),
+	}
+	if err := auditLog.Append(event); err != nil {
+		t.Fatalf("Failed to append: %s", err)
+	}
+	auditLog.Close()
+	reopened, err := OpenFileAuditLog(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %s", err)
+	}
+	defer reopened.Close()
+	history, err := reopened.History(id)
+	if err != nil || len(history) != 1 || history[0].Action != pb.AuditEvent_CREATE {
+		t.Fatalf("Wrong history after reopen: %v %v", history, err)
+	}
+}
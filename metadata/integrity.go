@@ -0,0 +1,402 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pb "github.com/featureform/metadata/proto"
+	"golang.org/x/exp/slices"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// dependantFields are the fields resources list their dependants of each
+// type in.
+var dependantFields = map[ResourceType]protoreflect.Name{
+	SOURCE_VARIANT:        "sources",
+	FEATURE_VARIANT:       "features",
+	LABEL_VARIANT:         "labels",
+	TRAINING_SET_VARIANT:  "trainingsets",
+	FEATURE_GROUP_VARIANT: "feature_groups",
+	MODEL_VARIANT:         "models",
+}
+
+// keepsDependants are the types that list their dependants. Training sets,
+// models and feature groups have the same fields, but for the resources they
+// use.
+var keepsDependants = map[ResourceType]bool{
+	USER:            true,
+	PROVIDER:        true,
+	ENTITY:          true,
+	SOURCE_VARIANT:  true,
+	FEATURE_VARIANT: true,
+	LABEL_VARIANT:   true,
+}
+
+// listedDependants returns the dependants a resource lists.
+func listedDependants(res Resource) map[ResourceID]struct{} {
+	listed := make(map[ResourceID]struct{})
+	if !keepsDependants[res.ID().Type] {
+		return listed
+	}
+	msg := res.Proto().ProtoReflect()
+	for t, name := range dependantFields {
+		field := msg.Descriptor().Fields().ByName(name)
+		if field == nil {
+			continue
+		}
+		list := msg.Get(field).List()
+		for i := 0; i < list.Len(); i++ {
+			nv := list.Get(i).Message().Interface().(*pb.NameVariant)
+			listed[ResourceID{Name: nv.Name, Variant: nv.Variant, Type: t, Namespace: nv.Namespace}] = struct{}{}
+		}
+	}
+	return listed
+}
+
+// removeDependant removes every entry for dependant from a resource's lists.
+func removeDependant(res Resource, dependant ResourceID) {
+	msg := res.Proto().ProtoReflect()
+	field := msg.Descriptor().Fields().ByName(dependantFields[dependant.Type])
+	if field == nil {
+		return
+	}
+	list := msg.Mutable(field).List()
+	kept := make([]protoreflect.Value, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		nv := list.Get(i).Message().Interface().(*pb.NameVariant)
+		if nv.Name != dependant.Name || nv.Variant != dependant.Variant || nv.Namespace != dependant.Namespace {
+			kept = append(kept, list.Get(i))
+		}
+	}
+	list.Truncate(0)
+	for _, value := range kept {
+		list.Append(value)
+	}
+}
+
+// parentVariants returns the variant fields of a parent resource.
+func parentVariants(res Resource) (variants *[]string, defaultVariant *string, ok bool) {
+	switch serialized := res.Proto().(type) {
+	case *pb.Feature:
+		return &serialized.Variants, &serialized.DefaultVariant, true
+	case *pb.Label:
+		return &serialized.Variants, &serialized.DefaultVariant, true
+	case *pb.Source:
+		return &serialized.Variants, &serialized.DefaultVariant, true
+	case *pb.TrainingSet:
+		return &serialized.Variants, &serialized.DefaultVariant, true
+	case *pb.FeatureGroup:
+		return &serialized.Variants, &serialized.DefaultVariant, true
+	case *pb.Model:
+		return &serialized.Variants, &serialized.DefaultVariant, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// newParent makes a parent for variants that lost theirs, with the last one
+// as the default.
+func newParent(id ResourceID, variants []string) (Resource, error) {
+	parent, err := emptyResource(id.Type)
+	if err != nil {
+		return nil, err
+	}
+	variantList, defaultVariant, ok := parentVariants(parent)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a parent type", id.Type)
+	}
+	msg := parent.Proto().ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	msg.Set(fields.ByName("name"), protoreflect.ValueOfString(id.Name))
+	msg.Set(fields.ByName("namespace"), protoreflect.ValueOfString(id.Namespace))
+	*variantList = variants
+	*defaultVariant = variants[len(variants)-1]
+	return parent, nil
+}
+
+// referenceLookup leaves out resources that don't exist instead of failing,
+// and records every resource that's asked for. Resources' Dependencies with
+// it return the ones that exist and record all of their references.
+type referenceLookup struct {
+	LocalResourceLookup
+	requested []ResourceID
+}
+
+func (lookup *referenceLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
+	lookup.requested = append(lookup.requested, ids...)
+	found := make(LocalResourceLookup)
+	for _, id := range ids {
+		if res, has := lookup.LocalResourceLookup[id]; has {
+			found[id] = res
+		}
+	}
+	return found, nil
+}
+
+// references returns every resource that res refers to, whether it exists
+// or not.
+func (lookup *referenceLookup) references(res Resource) ([]ResourceID, error) {
+	lookup.requested = nil
+	if _, err := res.Dependencies(lookup); err != nil {
+		return nil, err
+	}
+	return append([]ResourceID{}, lookup.requested...), nil
+}
+
+// integrityCheck collects inconsistencies, repairing them as they're found
+// if it's asked to.
+type integrityCheck struct {
+	serv            *MetadataServer
+	ctx             context.Context
+	repair          bool
+	resources       LocalResourceLookup
+	inconsistencies []*pb.Inconsistency
+}
+
+func (check *integrityCheck) report(kind pb.Inconsistency_Kind, id, reference ResourceID, description string, repair func() error) {
+	inconsistency := &pb.Inconsistency{
+		Kind:        kind,
+		ResourceId:  &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+		Description: description,
+	}
+	if reference.Name != "" {
+		inconsistency.Reference = &pb.ResourceID{Resource: reference.Proto(), ResourceType: reference.Type.Serialized()}
+	}
+	if check.repair && repair != nil {
+		if err := repair(); err != nil {
+			check.serv.Logger.Errorw("Could not repair inconsistency", "resource", id.String(), "kind", kind.String(), "error", err)
+		} else {
+			inconsistency.Repaired = true
+		}
+	}
+	check.inconsistencies = append(check.inconsistencies, inconsistency)
+}
+
+// update repairs a resource and audits the change.
+func (check *integrityCheck) update(id ResourceID, fn func(Resource) error) error {
+	before, after, err := updateResource(check.serv.lookup, id, 0, fn)
+	if err != nil {
+		return err
+	}
+	check.serv.audit(check.ctx, pb.AuditEvent_UPDATE, id, before, after.Proto())
+	return nil
+}
+
+// checkReferences checks that everything res refers to exists and lists it
+// as a dependant or variant.
+func (check *integrityCheck) checkReferences(refs *referenceLookup, res Resource, missingParents map[ResourceID][]string) error {
+	id := res.ID()
+	references, err := refs.references(res)
+	if err != nil {
+		return err
+	}
+	parentID, hasParent := id.Parent()
+	for _, ref := range references {
+		target, exists := check.resources[ref]
+		isParent := hasParent && ref == parentID
+		switch {
+		case !exists && isParent:
+			missingParents[ref] = append(missingParents[ref], id.Variant)
+		case !exists:
+			check.report(pb.Inconsistency_MISSING_REFERENCE, id, ref, fmt.Sprintf("%s refers to %s, which doesn't exist", id, ref), nil)
+		case isParent:
+			variants, _, _ := parentVariants(target)
+			if !slices.Contains(*variants, id.Variant) {
+				check.report(pb.Inconsistency_MISSING_VARIANT, ref, id, fmt.Sprintf("%s doesn't list its variant %s", ref, id.Variant), func() error {
+					return check.update(ref, func(parent Resource) error {
+						variants, _, _ := parentVariants(parent)
+						if !slices.Contains(*variants, id.Variant) {
+							*variants = append(*variants, id.Variant)
+						}
+						return nil
+					})
+				})
+			}
+		default:
+			if err := check.checkDependant(refs, target, res); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkDependant checks that target lists res, if it keeps track of
+// dependants like it.
+func (check *integrityCheck) checkDependant(refs *referenceLookup, target, res Resource) error {
+	id, targetID := res.ID(), target.ID()
+	if _, listed := listedDependants(target)[id]; listed {
+		return nil
+	}
+	notified, err := copyResource(target)
+	if err != nil {
+		return err
+	}
+	if err := notified.Notify(refs, create_op, res); err != nil {
+		return err
+	}
+	if _, listed := listedDependants(notified)[id]; !listed {
+		return nil
+	}
+	check.report(pb.Inconsistency_MISSING_DEPENDANT, targetID, id, fmt.Sprintf("%s doesn't list its dependant %s", targetID, id), func() error {
+		return check.update(targetID, func(current Resource) error {
+			if _, listed := listedDependants(current)[id]; listed {
+				return nil
+			}
+			return current.Notify(refs, create_op, res)
+		})
+	})
+	return nil
+}
+
+// checkListed checks that the dependants and variants res lists exist.
+func (check *integrityCheck) checkListed(res Resource) {
+	id := res.ID()
+	for dependant := range listedDependants(res) {
+		if _, exists := check.resources[dependant]; exists {
+			continue
+		}
+		check.report(pb.Inconsistency_STALE_DEPENDANT, id, dependant, fmt.Sprintf("%s lists %s, which doesn't exist", id, dependant), func() error {
+			return check.update(id, func(current Resource) error {
+				removeDependant(current, dependant)
+				return nil
+			})
+		})
+	}
+	variants, defaultVariant, isParent := parentVariants(res)
+	if !isParent {
+		return
+	}
+	var variantType ResourceType
+	for child, parent := range parentMapping {
+		if parent == id.Type {
+			variantType = child
+		}
+	}
+	stale := make([]string, 0)
+	for _, variant := range *variants {
+		if _, exists := check.resources[ResourceID{Name: id.Name, Variant: variant, Type: variantType, Namespace: id.Namespace}]; !exists {
+			stale = append(stale, variant)
+		}
+	}
+	if *defaultVariant != "" && !slices.Contains(stale, *defaultVariant) {
+		if _, exists := check.resources[ResourceID{Name: id.Name, Variant: *defaultVariant, Type: variantType, Namespace: id.Namespace}]; !exists {
+			stale = append(stale, *defaultVariant)
+		}
+	}
+	for _, variant := range stale {
+		variant := variant
+		variantID := ResourceID{Name: id.Name, Variant: variant, Type: variantType, Namespace: id.Namespace}
+		check.report(pb.Inconsistency_STALE_VARIANT, id, variantID, fmt.Sprintf("%s lists its variant %s, which doesn't exist", id, variant), func() error {
+			return check.update(id, func(current Resource) error {
+				variants, defaultVariant, _ := parentVariants(current)
+				*variants, *defaultVariant = removeVariant(*variants, *defaultVariant, variant)
+				return nil
+			})
+		})
+	}
+}
+
+// checkJob checks that resources have a coordinator job while, and only
+// while, they're waiting on one. Jobs of ready resources are left alone, as
+// the coordinator deletes them once it's done with them.
+func (check *integrityCheck) checkJob(res Resource) error {
+	if !check.serv.needsJob(res) {
+		return nil
+	}
+	id := res.ID()
+	hasJob, err := check.serv.lookup.HasJob(id)
+	if err != nil {
+		return err
+	}
+	switch status := resourceStatus(res).GetStatus(); {
+	case status == pb.ResourceStatus_ARCHIVED && hasJob:
+		check.report(pb.Inconsistency_STALE_JOB, id, ResourceID{}, fmt.Sprintf("%s is archived but has a job", id), func() error {
+			return check.serv.lookup.DeleteJob(id)
+		})
+	case status == pb.ResourceStatus_READY && hasJob:
+		check.report(pb.Inconsistency_STALE_JOB, id, ResourceID{}, fmt.Sprintf("%s is ready but has a job", id), nil)
+	case (status == pb.ResourceStatus_NO_STATUS || status == pb.ResourceStatus_CREATED || status == pb.ResourceStatus_PENDING) && !hasJob:
+		check.report(pb.Inconsistency_MISSING_JOB, id, ResourceID{}, fmt.Sprintf("%s is %s but has no job", id, status), func() error {
+			return check.serv.lookup.SetJob(id, res.Schedule())
+		})
+	}
+	return nil
+}
+
+// CheckIntegrity scans every resource for references that are inconsistent
+// with each other, repairing them if it's asked to. References to resources
+// that don't exist can't be repaired.
+func (serv *MetadataServer) CheckIntegrity(ctx context.Context, req *pb.IntegrityRequest) (*pb.IntegrityReport, error) {
+	serv.Logger.Infow("Checking integrity", "repair", req.Repair, "check_jobs", req.CheckJobs)
+	list, err := serv.lookup.List()
+	if err != nil {
+		return nil, err
+	}
+	check := &integrityCheck{
+		serv:      serv,
+		ctx:       ctx,
+		repair:    req.Repair,
+		resources: make(LocalResourceLookup, len(list)),
+	}
+	for _, res := range list {
+		check.resources[res.ID()] = res
+	}
+	refs := &referenceLookup{LocalResourceLookup: check.resources}
+	missingParents := make(map[ResourceID][]string)
+	for _, res := range list {
+		if err := check.checkReferences(refs, res, missingParents); err != nil {
+			return nil, err
+		}
+		check.checkListed(res)
+		if req.CheckJobs {
+			if err := check.checkJob(res); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for parentID, variants := range missingParents {
+		parentID, variants := parentID, variants
+		sort.Strings(variants)
+		check.report(pb.Inconsistency_MISSING_PARENT, parentID, ResourceID{}, fmt.Sprintf("%s doesn't exist but has variants %v", parentID, variants), func() error {
+			parent, err := newParent(parentID, variants)
+			if err != nil {
+				return err
+			}
+			if err := serv.lookup.SetIfRevision(parentID, parent, 0); err != nil {
+				return err
+			}
+			serv.audit(ctx, pb.AuditEvent_CREATE, parentID, nil, parent.Proto())
+			return nil
+		})
+	}
+	sort.Slice(check.inconsistencies, func(i, j int) bool {
+		a, b := check.inconsistencies[i], check.inconsistencies[j]
+		aID, bID := resourceIDFromProto(a.ResourceId).String(), resourceIDFromProto(b.ResourceId).String()
+		if aID != bID {
+			return aID < bID
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return resourceIDFromProto(a.Reference).String() < resourceIDFromProto(b.Reference).String()
+	})
+	serv.Logger.Infow("Checked integrity", "inconsistencies", len(check.inconsistencies))
+	return &pb.IntegrityReport{Inconsistencies: check.inconsistencies}, nil
+}
+
+// CheckIntegrity reports the inconsistencies between resources, repairing
+// the ones it can with repair set. Coordinator jobs are checked with
+// checkJobs, which should only be set if the storage keeps them.
+func (client *Client) CheckIntegrity(ctx context.Context, repair, checkJobs bool) ([]*pb.Inconsistency, error) {
+	resp, err := client.GrpcConn.CheckIntegrity(ctx, &pb.IntegrityRequest{Repair: repair, CheckJobs: checkJobs})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Inconsistencies, nil
+}
@@ -0,0 +1,51 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Checks the metadata server's resources for inconsistent references, and
+// repairs what it can with -repair. It exits with 1 if anything is left
+// inconsistent.
+//
+//	integrity [-repair] [-jobs]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	help "github.com/featureform/helpers"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "repair the inconsistencies that can be")
+	checkJobs := flag.Bool("jobs", false, "check coordinator jobs, for storage that keeps them")
+	flag.Parse()
+	logger := logging.NewLogger("metadata-integrity")
+	addr := fmt.Sprintf("%s:%s", help.GetEnv("METADATA_HOST", "localhost"), help.GetEnv("METADATA_PORT", "8080"))
+	client, err := metadata.NewClient(addr, logger)
+	if err != nil {
+		logger.Panicw("Failed to connect", "Err", err)
+	}
+	defer client.Close()
+	inconsistencies, err := client.CheckIntegrity(context.Background(), *repair, *checkJobs)
+	if err != nil {
+		logger.Fatalw("Integrity check failed", "Err", err)
+	}
+	remaining := 0
+	for _, inconsistency := range inconsistencies {
+		if inconsistency.Repaired {
+			logger.Infow("Repaired", "kind", inconsistency.Kind.String(), "description", inconsistency.Description)
+			continue
+		}
+		remaining++
+		logger.Warnw("Inconsistent", "kind", inconsistency.Kind.String(), "description", inconsistency.Description)
+	}
+	logger.Infow("Checked integrity", "inconsistencies", len(inconsistencies), "remaining", remaining)
+	if remaining > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,205 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+type inconsistencyKey struct {
+	Kind     pb.Inconsistency_Kind
+	Resource ResourceID
+}
+
+func inconsistencyKeys(inconsistencies []*pb.Inconsistency) map[inconsistencyKey]bool {
+	keys := make(map[inconsistencyKey]bool)
+	for _, inconsistency := range inconsistencies {
+		keys[inconsistencyKey{inconsistency.Kind, resourceIDFromProto(inconsistency.ResourceId)}] = inconsistency.Repaired
+	}
+	return keys
+}
+
+func TestIntegrityClean(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	inconsistencies, err := client.CheckIntegrity(context.Background(), false, false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %s", err)
+	}
+	if len(inconsistencies) != 0 {
+		t.Fatalf("Expected no inconsistencies: %v", inconsistencies)
+	}
+}
+
+func TestIntegrityRepair(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	lookup := ctx.serv.lookup
+	corrupt := func(id ResourceID, fn func(Resource)) {
+		res, err := lookup.Lookup(id)
+		if err != nil {
+			t.Fatalf("Failed to lookup %s: %s", id, err)
+		}
+		fn(res)
+		if err := lookup.Set(id, res); err != nil {
+			t.Fatalf("Failed to set %s: %s", id, err)
+		}
+	}
+	provider := ResourceID{Name: "mockOnline", Type: PROVIDER}
+	corrupt(provider, func(res Resource) {
+		removeDependant(res, ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT})
+	})
+	user := ResourceID{Name: "Featureform", Type: USER}
+	corrupt(user, func(res Resource) {
+		serialized := res.Proto().(*pb.User)
+		serialized.Features = append(serialized.Features, &pb.NameVariant{Name: "missing", Variant: "variant"})
+	})
+	feature := ResourceID{Name: "feature", Type: FEATURE}
+	corrupt(feature, func(res Resource) {
+		serialized := res.Proto().(*pb.Feature)
+		serialized.Variants = append(serialized.Variants, "missing")
+	})
+	label := ResourceID{Name: "label", Type: LABEL}
+	corrupt(label, func(res Resource) {
+		serialized := res.Proto().(*pb.Label)
+		serialized.Variants = nil
+	})
+	trainingSet := ResourceID{Name: "training-set", Type: TRAINING_SET}
+	if err := lookup.Delete(trainingSet); err != nil {
+		t.Fatalf("Failed to delete %s: %s", trainingSet, err)
+	}
+	featureVariant := ResourceID{Name: "feature", Variant: "variant2", Type: FEATURE_VARIANT}
+	corrupt(featureVariant, func(res Resource) {
+		res.Proto().(*pb.FeatureVariant).Entity = "missing"
+	})
+
+	// Each inconsistency and whether it can be repaired.
+	repairable := map[inconsistencyKey]bool{
+		{pb.Inconsistency_MISSING_DEPENDANT, provider}:       true,
+		{pb.Inconsistency_STALE_DEPENDANT, user}:             true,
+		{pb.Inconsistency_STALE_VARIANT, feature}:            true,
+		{pb.Inconsistency_MISSING_VARIANT, label}:            true,
+		{pb.Inconsistency_MISSING_PARENT, trainingSet}:       true,
+		{pb.Inconsistency_MISSING_REFERENCE, featureVariant}: false,
+	}
+	expected := make(map[inconsistencyKey]bool)
+	for key := range repairable {
+		expected[key] = false
+	}
+	inconsistencies, err := client.CheckIntegrity(bg, false, false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %s", err)
+	}
+	if found := inconsistencyKeys(inconsistencies); !reflect.DeepEqual(found, expected) {
+		t.Fatalf("Expected %v found %v", expected, found)
+	}
+	expected = repairable
+	inconsistencies, err = client.CheckIntegrity(bg, true, false)
+	if err != nil {
+		t.Fatalf("Failed to repair integrity: %s", err)
+	}
+	if found := inconsistencyKeys(inconsistencies); !reflect.DeepEqual(found, expected) {
+		t.Fatalf("Expected %v found %v", expected, found)
+	}
+
+	inconsistencies, err = client.CheckIntegrity(bg, false, false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %s", err)
+	}
+	remaining := map[inconsistencyKey]bool{{pb.Inconsistency_MISSING_REFERENCE, featureVariant}: false}
+	if found := inconsistencyKeys(inconsistencies); !reflect.DeepEqual(found, remaining) {
+		t.Fatalf("Expected only the missing reference to remain: %v", found)
+	}
+	parent, err := client.GetTrainingSet(bg, "training-set")
+	if err != nil {
+		t.Fatalf("Failed to get recreated parent: %s", err)
+	}
+	if variants := parent.Variants(); !reflect.DeepEqual(variants, []string{"variant", "variant2"}) {
+		t.Fatalf("Expected the recreated parent to have both variants: %v", variants)
+	}
+}
+
+// jobLookup keeps coordinator jobs, which the local lookup doesn't.
+type jobLookup struct {
+	ResourceLookup
+	jobs map[ResourceID]string
+}
+
+func (lookup *jobLookup) HasJob(id ResourceID) (bool, error) {
+	_, has := lookup.jobs[id]
+	return has, nil
+}
+
+func (lookup *jobLookup) SetJob(id ResourceID, schedule string) error {
+	lookup.jobs[id] = schedule
+	return nil
+}
+
+func (lookup *jobLookup) DeleteJob(id ResourceID) error {
+	delete(lookup.jobs, id)
+	return nil
+}
+
+func TestIntegrityJobs(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	if _, err := ctx.Create(t); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	jobs := &jobLookup{ResourceLookup: ctx.serv.lookup, jobs: make(map[ResourceID]string)}
+	ctx.serv.lookup = jobs
+	label := ResourceID{Name: "label", Variant: "variant", Type: LABEL_VARIANT}
+
+	report, err := ctx.serv.CheckIntegrity(bg, &pb.IntegrityRequest{Repair: true, CheckJobs: true})
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %s", err)
+	}
+	if repaired := inconsistencyKeys(report.Inconsistencies); !repaired[inconsistencyKey{pb.Inconsistency_MISSING_JOB, label}] {
+		t.Fatalf("Expected a missing job to be repaired: %v", report.Inconsistencies)
+	}
+	for _, inconsistency := range report.Inconsistencies {
+		if inconsistency.Kind != pb.Inconsistency_MISSING_JOB {
+			t.Fatalf("Expected only missing jobs: %v", inconsistency)
+		}
+	}
+	if _, has := jobs.jobs[ResourceID{Name: "feature3", Variant: "on-demand", Type: FEATURE_VARIANT}]; has {
+		t.Fatalf("Expected on demand features to have no job")
+	}
+
+	if err := jobs.SetStatus(label, &pb.ResourceStatus{Status: pb.ResourceStatus_ARCHIVED}); err != nil {
+		t.Fatalf("Failed to archive: %s", err)
+	}
+	report, err = ctx.serv.CheckIntegrity(bg, &pb.IntegrityRequest{Repair: true, CheckJobs: true})
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %s", err)
+	}
+	expected := map[inconsistencyKey]bool{{pb.Inconsistency_STALE_JOB, label}: true}
+	if found := inconsistencyKeys(report.Inconsistencies); !reflect.DeepEqual(found, expected) {
+		t.Fatalf("Expected %v found %v", expected, found)
+	}
+	if has, _ := jobs.HasJob(label); has {
+		t.Fatalf("Expected the archived label's job to be deleted")
+	}
+}
@@ -0,0 +1,242 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DeprecationWarningKey is the trailer the feature server sets with a warning
+// for each deprecated variant it serves.
+const DeprecationWarningKey = "deprecation-warning"
+
+type LifecycleState int32
+
+const (
+	ACTIVE     LifecycleState = LifecycleState(pb.Lifecycle_ACTIVE)
+	DEPRECATED                = LifecycleState(pb.Lifecycle_DEPRECATED)
+	RETIRED                   = LifecycleState(pb.Lifecycle_RETIRED)
+)
+
+func (s LifecycleState) String() string {
+	return pb.Lifecycle_State_name[int32(s)]
+}
+
+// Lifecycle is where a feature or training set variant is in its lifecycle.
+// Deprecated variants are retired at their sunset, if they have one.
+type Lifecycle struct {
+	State       LifecycleState
+	Sunset      time.Time
+	Replacement NameVariant
+	Reason      string
+}
+
+func (lifecycle Lifecycle) Serialize() *pb.Lifecycle {
+	serialized := &pb.Lifecycle{
+		State:  pb.Lifecycle_State(lifecycle.State),
+		Reason: lifecycle.Reason,
+	}
+	if !lifecycle.Sunset.IsZero() {
+		serialized.Sunset = tspb.New(lifecycle.Sunset)
+	}
+	if lifecycle.Replacement.Name != "" {
+		serialized.Replacement = lifecycle.Replacement.Serialize()
+	}
+	return serialized
+}
+
+func parseLifecycle(serialized *pb.Lifecycle) Lifecycle {
+	lifecycle := Lifecycle{
+		State:  LifecycleState(serialized.GetState()),
+		Reason: serialized.GetReason(),
+	}
+	if serialized.GetSunset() != nil {
+		lifecycle.Sunset = serialized.GetSunset().AsTime()
+	}
+	if serialized.GetReplacement() != nil {
+		lifecycle.Replacement = parseNameVariant(serialized.GetReplacement())
+	}
+	return lifecycle
+}
+
+// StateAt returns the state of the lifecycle at t, which is retired for
+// deprecated variants past their sunset.
+func (lifecycle Lifecycle) StateAt(t time.Time) LifecycleState {
+	if lifecycle.State == DEPRECATED && !lifecycle.Sunset.IsZero() && !t.Before(lifecycle.Sunset) {
+		return RETIRED
+	}
+	return lifecycle.State
+}
+
+// Warning describes a deprecated lifecycle to the users of id.
+func (lifecycle Lifecycle) Warning(id ResourceID) string {
+	warning := fmt.Sprintf("%s is deprecated", id)
+	if !lifecycle.Sunset.IsZero() {
+		warning += fmt.Sprintf(" and will be retired at %s", lifecycle.Sunset.UTC().Format(time.RFC3339))
+	}
+	if lifecycle.Replacement.Name != "" {
+		warning += fmt.Sprintf("; use %s (%s) instead", lifecycle.Replacement.Name, lifecycle.Replacement.Variant)
+	}
+	if lifecycle.Reason != "" {
+		warning += fmt.Sprintf(": %s", lifecycle.Reason)
+	}
+	return warning
+}
+
+// Resource returns the lifecycle as the dashboard shows it.
+func (lifecycle Lifecycle) Resource() LifecycleResource {
+	resource := LifecycleResource{
+		State:       lifecycle.StateAt(time.Now()).String(),
+		Replacement: lifecycle.Replacement,
+		Reason:      lifecycle.Reason,
+	}
+	if !lifecycle.Sunset.IsZero() {
+		resource.Sunset = lifecycle.Sunset.UTC().Format(time.RFC3339)
+	}
+	return resource
+}
+
+// VariantNotActive is returned when a training set is created with features
+// that are retired, or deprecated without allowing it.
+type VariantNotActive struct {
+	ID        ResourceID
+	Lifecycle Lifecycle
+}
+
+func (err *VariantNotActive) Error() string {
+	if err.Lifecycle.StateAt(time.Now()) == RETIRED {
+		return fmt.Sprintf("%s is retired", err.ID)
+	}
+	return fmt.Sprintf("%s; set allow_deprecated to use it anyway", err.Lifecycle.Warning(err.ID))
+}
+
+func (err *VariantNotActive) GRPCStatus() *status.Status {
+	return status.New(codes.FailedPrecondition, err.Error())
+}
+
+func (client *Client) SetLifecycle(ctx context.Context, id ResourceID, lifecycle Lifecycle) error {
+	return client.SetLifecycleIfRevision(ctx, id, lifecycle, 0)
+}
+
+// SetLifecycleIfRevision fails with an Aborted status if the variant isn't at
+// revision. A revision of 0 sets the lifecycle whatever it's at.
+func (client *Client) SetLifecycleIfRevision(ctx context.Context, id ResourceID, lifecycle Lifecycle, revision int64) error {
+	_, err := client.GrpcConn.SetLifecycle(ctx, &pb.SetLifecycleRequest{
+		ResourceId:       &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+		Lifecycle:        lifecycle.Serialize(),
+		ExpectedRevision: revision,
+	})
+	return err
+}
+
+// lifecycleOf returns the lifecycle of variants that have one.
+func lifecycleOf(res Resource) (*pb.Lifecycle, bool) {
+	switch serialized := res.Proto().(type) {
+	case *pb.FeatureVariant:
+		return serialized.GetLifecycle(), true
+	case *pb.TrainingSetVariant:
+		return serialized.GetLifecycle(), true
+	default:
+		return nil, false
+	}
+}
+
+// SetLifecycle moves a feature or training set variant through its
+// lifecycle. Retired variants stay retired, and replacements have to be
+// registered variants of the same type that aren't retired.
+func (serv *MetadataServer) SetLifecycle(ctx context.Context, req *pb.SetLifecycleRequest) (*pb.Empty, error) {
+	id := resourceIDFromProto(req.GetResourceId())
+	serv.Logger.Infow("Setting lifecycle", "id", id.String(), "lifecycle", req.GetLifecycle().String())
+	if id.Type != FEATURE_VARIANT && id.Type != TRAINING_SET_VARIANT {
+		return nil, status.Errorf(codes.InvalidArgument, "%s has no lifecycle", id.Type)
+	}
+	lifecycle := proto.Clone(req.GetLifecycle()).(*pb.Lifecycle)
+	if lifecycle == nil {
+		lifecycle = &pb.Lifecycle{}
+	}
+	switch lifecycle.State {
+	case pb.Lifecycle_ACTIVE:
+		lifecycle = &pb.Lifecycle{}
+	case pb.Lifecycle_RETIRED:
+		lifecycle.Sunset = nil
+	}
+	if replacement := lifecycle.GetReplacement(); replacement != nil {
+		if err := serv.checkReplacement(id, replacement); err != nil {
+			return nil, err
+		}
+	}
+	before, res, err := updateResource(serv.lookup, id, req.GetExpectedRevision(), func(res Resource) error {
+		current, _ := lifecycleOf(res)
+		if parseLifecycle(current).StateAt(time.Now()) == RETIRED {
+			return status.Errorf(codes.FailedPrecondition, "%s is retired", id)
+		}
+		switch serialized := res.Proto().(type) {
+		case *pb.FeatureVariant:
+			serialized.Lifecycle = lifecycle
+		case *pb.TrainingSetVariant:
+			serialized.Lifecycle = lifecycle
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	serv.audit(ctx, pb.AuditEvent_LIFECYCLE_CHANGE, id, before, res.Proto())
+	return &pb.Empty{}, nil
+}
+
+func (serv *MetadataServer) checkReplacement(id ResourceID, replacement *pb.NameVariant) error {
+	// References are always to the same namespace.
+	replacement.Namespace = id.Namespace
+	replacementID := ResourceID{Name: replacement.Name, Variant: replacement.Variant, Type: id.Type, Namespace: id.Namespace}
+	if replacementID == id {
+		return status.Errorf(codes.InvalidArgument, "%s cannot replace itself", id)
+	}
+	res, err := serv.lookup.Lookup(replacementID)
+	if err != nil {
+		return err
+	}
+	lifecycle, _ := lifecycleOf(res)
+	if parseLifecycle(lifecycle).StateAt(time.Now()) == RETIRED {
+		return status.Errorf(codes.FailedPrecondition, "replacement %s is retired", replacementID)
+	}
+	return nil
+}
+
+// checkTrainingSetFeatures rejects a new training set with retired features,
+// or deprecated ones unless it allows them.
+func (serv *MetadataServer) checkTrainingSetFeatures(variant *pb.TrainingSetVariant) error {
+	id := (&trainingSetVariantResource{variant}).ID()
+	if exists, err := serv.lookup.Has(id); err != nil || exists {
+		return err
+	}
+	now := time.Now()
+	for _, feature := range variant.Features {
+		featureID := ResourceID{Name: feature.Name, Variant: feature.Variant, Type: FEATURE_VARIANT, Namespace: variant.Namespace}
+		res, err := serv.lookup.Lookup(featureID)
+		if _, isNotFound := err.(*ResourceNotFound); isNotFound {
+			// Missing features fail the create on their own.
+			continue
+		} else if err != nil {
+			return err
+		}
+		lifecycle := parseLifecycle(res.Proto().(*pb.FeatureVariant).GetLifecycle())
+		switch state := lifecycle.StateAt(now); {
+		case state == RETIRED, state == DEPRECATED && !variant.AllowDeprecated:
+			return &VariantNotActive{ID: featureID, Lifecycle: lifecycle}
+		case state == DEPRECATED:
+			serv.Logger.Warnw("Training set uses deprecated feature", "training_set", id.String(), "feature", featureID.String())
+		}
+	}
+	return nil
+}
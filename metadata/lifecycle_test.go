@@ -0,0 +1,156 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLifecycleStateAt(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		Name      string
+		Lifecycle Lifecycle
+		Expected  LifecycleState
+	}{
+		{"Active", Lifecycle{}, ACTIVE},
+		{"No Sunset", Lifecycle{State: DEPRECATED}, DEPRECATED},
+		{"Before Sunset", Lifecycle{State: DEPRECATED, Sunset: now.Add(time.Hour)}, DEPRECATED},
+		{"At Sunset", Lifecycle{State: DEPRECATED, Sunset: now}, RETIRED},
+		{"Retired", Lifecycle{State: RETIRED}, RETIRED},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if state := test.Lifecycle.StateAt(now); state != test.Expected {
+				t.Fatalf("Expected %s found %s", test.Expected, state)
+			}
+		})
+	}
+}
+
+func TestLifecycleTransitions(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+
+	missing := Lifecycle{State: DEPRECATED, Replacement: NameVariant{Name: "feature", Variant: "missing"}}
+	if err := client.SetLifecycle(bg, id, missing); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected a missing replacement to fail: %v", err)
+	}
+	itself := Lifecycle{State: DEPRECATED, Replacement: NameVariant{Name: "feature", Variant: "variant"}}
+	if err := client.SetLifecycle(bg, id, itself); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected a variant replacing itself to fail: %v", err)
+	}
+
+	sunset := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	deprecated := Lifecycle{
+		State:       DEPRECATED,
+		Sunset:      sunset,
+		Replacement: NameVariant{Name: "feature", Variant: "variant2"},
+		Reason:      "wrong window",
+	}
+	if err := client.SetLifecycle(bg, id, deprecated); err != nil {
+		t.Fatalf("Failed to deprecate: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(bg, NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.Lifecycle() != deprecated {
+		t.Fatalf("Expected %v found %v", deprecated, feature.Lifecycle())
+	}
+	history, err := client.GetResourceHistory(bg, id)
+	if err != nil {
+		t.Fatalf("Failed to get history: %s", err)
+	}
+	if last := history[len(history)-1]; last.Action != pb.AuditEvent_LIFECYCLE_CHANGE {
+		t.Fatalf("Expected a lifecycle change event: %v", last)
+	}
+
+	if err := client.SetLifecycle(bg, id, Lifecycle{State: RETIRED, Sunset: sunset}); err != nil {
+		t.Fatalf("Failed to retire: %s", err)
+	}
+	feature, err = client.GetFeatureVariant(bg, NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if lifecycle := feature.Lifecycle(); lifecycle.State != RETIRED || !lifecycle.Sunset.IsZero() {
+		t.Fatalf("Expected retired without a sunset: %v", lifecycle)
+	}
+	if err := client.SetLifecycle(bg, id, Lifecycle{State: ACTIVE}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected retired variant to stay retired: %v", err)
+	}
+	replaced := Lifecycle{State: DEPRECATED, Replacement: NameVariant{Name: "feature", Variant: "variant"}}
+	replacedID := ResourceID{Name: "feature", Variant: "variant2", Type: FEATURE_VARIANT}
+	if err := client.SetLifecycle(bg, replacedID, replaced); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected a retired replacement to fail: %v", err)
+	}
+	entity := ResourceID{Name: "user", Type: ENTITY}
+	if err := client.SetLifecycle(bg, entity, deprecated); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected entities to have no lifecycle: %v", err)
+	}
+}
+
+func TestLifecycleTrainingSetFeatures(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	deprecatedID := ResourceID{Name: "feature", Variant: "variant2", Type: FEATURE_VARIANT}
+	if err := client.SetLifecycle(bg, deprecatedID, Lifecycle{State: DEPRECATED}); err != nil {
+		t.Fatalf("Failed to deprecate: %s", err)
+	}
+	def := TrainingSetDef{
+		Name:     "training-set",
+		Variant:  "deprecated",
+		Provider: "mockOffline",
+		Label:    NameVariant{Name: "label", Variant: "variant"},
+		Features: NameVariants{
+			{Name: "feature", Variant: "variant"},
+			{Name: "feature", Variant: "variant2"},
+		},
+		Owner: "Featureform",
+	}
+	if err := client.Create(bg, def); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected a deprecated feature to fail: %v", err)
+	}
+	def.AllowDeprecated = true
+	if err := client.Create(bg, def); err != nil {
+		t.Fatalf("Failed to create with deprecated features allowed: %s", err)
+	}
+
+	// Existing training sets can still be reapplied.
+	existing := filledResourceDefs()[len(filledResourceDefs())-3]
+	if err := client.Create(bg, existing); err != nil {
+		t.Fatalf("Failed to reapply %v: %s", existing, err)
+	}
+
+	retiredID := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.SetLifecycle(bg, retiredID, Lifecycle{State: RETIRED}); err != nil {
+		t.Fatalf("Failed to retire: %s", err)
+	}
+	def.Variant = "retired"
+	if err := client.Create(bg, def); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected a retired feature to fail: %v", err)
+	}
+}
@@ -0,0 +1,222 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+type lineageEdge struct {
+	Upstream   ResourceID
+	Downstream ResourceID
+}
+
+func nameVariantIDs(t ResourceType, nameVariants []*pb.NameVariant) []ResourceID {
+	ids := make([]ResourceID, 0, len(nameVariants))
+	for _, nv := range nameVariants {
+		ids = append(ids, ResourceID{Name: nv.GetName(), Variant: nv.GetVariant(), Type: t})
+	}
+	return ids
+}
+
+// variantIDs returns the variants of a parent resource.
+func variantIDs(parent Resource) []ResourceID {
+	withVariants, ok := parent.Proto().(interface{ GetVariants() []string })
+	if !ok {
+		return nil
+	}
+	id := parent.ID()
+	ids := make([]ResourceID, 0)
+	for variantType, parentType := range parentMapping {
+		if parentType != id.Type {
+			continue
+		}
+		for _, variant := range withVariants.GetVariants() {
+			ids = append(ids, ResourceID{Name: id.Name, Variant: variant, Type: variantType, Namespace: id.Namespace})
+		}
+	}
+	return ids
+}
+
+// lineageUpstream returns the resources that the data of res comes from.
+// Owners aren't part of lineage.
+func lineageUpstream(res Resource) []ResourceID {
+	ids := make([]ResourceID, 0)
+	add := func(t ResourceType, name, variant string) {
+		if name != "" {
+			ids = append(ids, ResourceID{Name: name, Variant: variant, Type: t})
+		}
+	}
+	switch serialized := res.Proto().(type) {
+	case *pb.SourceVariant:
+		add(PROVIDER, serialized.Provider, "")
+		if transformation := serialized.GetTransformation(); transformation != nil {
+			inputs := transformation.GetSQLTransformation().GetSource()
+			if df := transformation.GetDFTransformation(); df != nil {
+				inputs = df.GetInputs()
+			}
+			ids = append(ids, nameVariantIDs(SOURCE_VARIANT, inputs)...)
+		}
+	case *pb.FeatureVariant:
+		add(SOURCE_VARIANT, serialized.GetSource().GetName(), serialized.GetSource().GetVariant())
+		add(ENTITY, serialized.Entity, "")
+		add(PROVIDER, serialized.Provider, "")
+	case *pb.LabelVariant:
+		add(SOURCE_VARIANT, serialized.GetSource().GetName(), serialized.GetSource().GetVariant())
+		add(ENTITY, serialized.Entity, "")
+		add(PROVIDER, serialized.Provider, "")
+	case *pb.TrainingSetVariant:
+		add(LABEL_VARIANT, serialized.GetLabel().GetName(), serialized.GetLabel().GetVariant())
+		ids = append(ids, nameVariantIDs(FEATURE_VARIANT, serialized.Features)...)
+		ids = append(ids, nameVariantIDs(FEATURE_GROUP_VARIANT, serialized.FeatureGroups)...)
+		add(PROVIDER, serialized.Provider, "")
+	case *pb.FeatureGroupVariant:
+		ids = append(ids, nameVariantIDs(FEATURE_VARIANT, serialized.Features)...)
+		add(ENTITY, serialized.Entity, "")
+	case *pb.Model:
+		ids = append(ids, nameVariantIDs(FEATURE_VARIANT, serialized.Features)...)
+		ids = append(ids, nameVariantIDs(FEATURE_GROUP_VARIANT, serialized.FeatureGroups)...)
+		ids = append(ids, nameVariantIDs(LABEL_VARIANT, serialized.Labels)...)
+		ids = append(ids, nameVariantIDs(TRAINING_SET_VARIANT, serialized.Trainingsets)...)
+	case *pb.ModelVariant:
+		add(TRAINING_SET_VARIANT, serialized.GetTrainingSet().GetName(), serialized.GetTrainingSet().GetVariant())
+	}
+	return inNamespace(ids, res.ID().Namespace)
+}
+
+// lineageGraph returns the upstream and downstream edges of every resource.
+func (serv *MetadataServer) lineageGraph() (upstream, downstream map[ResourceID][]ResourceID, err error) {
+	resources, err := serv.lookup.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	upstream = make(map[ResourceID][]ResourceID)
+	downstream = make(map[ResourceID][]ResourceID)
+	for _, res := range resources {
+		id := res.ID()
+		for _, up := range lineageUpstream(res) {
+			upstream[id] = append(upstream[id], up)
+			downstream[up] = append(downstream[up], id)
+		}
+	}
+	return upstream, downstream, nil
+}
+
+// walkLineage does a breadth first walk from roots along next, up to depth
+// steps away or everywhere if depth isn't positive, calling visit for every
+// edge it follows.
+func walkLineage(roots []ResourceID, next map[ResourceID][]ResourceID, depth int, visit func(from, to ResourceID)) {
+	visited := make(map[ResourceID]struct{})
+	for _, root := range roots {
+		visited[root] = struct{}{}
+	}
+	frontier := roots
+	for step := 0; len(frontier) > 0 && (depth <= 0 || step < depth); step++ {
+		nextFrontier := make([]ResourceID, 0)
+		for _, from := range frontier {
+			for _, to := range next[from] {
+				visit(from, to)
+				if _, has := visited[to]; has {
+					continue
+				}
+				visited[to] = struct{}{}
+				nextFrontier = append(nextFrontier, to)
+			}
+		}
+		frontier = nextFrontier
+	}
+}
+
+func (serv *MetadataServer) GetLineage(ctx context.Context, req *pb.LineageRequest) (*pb.Lineage, error) {
+	root := resourceIDFromProto(req.GetResourceId())
+	serv.Logger.Infow("Getting lineage", "id", root.String(), "direction", req.Direction.String(), "depth", req.Depth)
+	res, err := serv.lookup.Lookup(root)
+	if err != nil {
+		return nil, err
+	}
+	upstream, downstream, err := serv.lineageGraph()
+	if err != nil {
+		serv.Logger.Errorw("Could not build lineage graph", "error", err)
+		return nil, err
+	}
+	nodes := map[ResourceID]struct{}{root: {}}
+	edges := make(map[lineageEdge]struct{})
+	// A parent's lineage is that of all of its variants.
+	starts := []ResourceID{root}
+	for _, variant := range variantIDs(res) {
+		nodes[variant] = struct{}{}
+		edges[lineageEdge{Upstream: variant, Downstream: root}] = struct{}{}
+		starts = append(starts, variant)
+	}
+	direction := req.GetDirection()
+	if direction != pb.LineageRequest_DOWNSTREAM {
+		walkLineage(starts, upstream, int(req.Depth), func(from, to ResourceID) {
+			nodes[to] = struct{}{}
+			edges[lineageEdge{Upstream: to, Downstream: from}] = struct{}{}
+		})
+	}
+	if direction != pb.LineageRequest_UPSTREAM {
+		walkLineage(starts, downstream, int(req.Depth), func(from, to ResourceID) {
+			nodes[to] = struct{}{}
+			edges[lineageEdge{Upstream: from, Downstream: to}] = struct{}{}
+		})
+	}
+	return serializeLineage(root, nodes, edges), nil
+}
+
+func serializeLineage(root ResourceID, nodes map[ResourceID]struct{}, edges map[lineageEdge]struct{}) *pb.Lineage {
+	nodeList := make([]ResourceID, 0, len(nodes))
+	for id := range nodes {
+		nodeList = append(nodeList, id)
+	}
+	sort.Slice(nodeList, func(i, j int) bool { return nodeList[i].String() < nodeList[j].String() })
+	edgeList := make([]lineageEdge, 0, len(edges))
+	for edge := range edges {
+		edgeList = append(edgeList, edge)
+	}
+	sort.Slice(edgeList, func(i, j int) bool {
+		if edgeList[i].Upstream != edgeList[j].Upstream {
+			return edgeList[i].Upstream.String() < edgeList[j].Upstream.String()
+		}
+		return edgeList[i].Downstream.String() < edgeList[j].Downstream.String()
+	})
+	serializedEdges := make([]*pb.LineageEdge, len(edgeList))
+	for i, edge := range edgeList {
+		serializedEdges[i] = &pb.LineageEdge{
+			Upstream:   &pb.ResourceID{Resource: edge.Upstream.Proto(), ResourceType: edge.Upstream.Type.Serialized()},
+			Downstream: &pb.ResourceID{Resource: edge.Downstream.Proto(), ResourceType: edge.Downstream.Type.Serialized()},
+		}
+	}
+	return &pb.Lineage{
+		Nodes: resourceIDsToProto(nodeList),
+		Edges: serializedEdges,
+		Dot:   lineageDOT(root, nodeList, edgeList),
+	}
+}
+
+// lineageDOT renders a lineage graph as Graphviz DOT with data flowing left
+// to right and the requested resource in bold.
+func lineageDOT(root ResourceID, nodes []ResourceID, edges []lineageEdge) string {
+	var dot strings.Builder
+	dot.WriteString("digraph lineage {\n\trankdir=LR;\n")
+	for _, id := range nodes {
+		attrs := ""
+		if id == root {
+			attrs = " [style=bold]"
+		}
+		fmt.Fprintf(&dot, "\t%s%s;\n", strconv.Quote(id.String()), attrs)
+	}
+	for _, edge := range edges {
+		fmt.Fprintf(&dot, "\t%s -> %s;\n", strconv.Quote(edge.Upstream.String()), strconv.Quote(edge.Downstream.String()))
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
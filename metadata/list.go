@@ -0,0 +1,500 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// NextPageTokenKey is the trailer set by List RPCs when there are more
+// resources to list.
+const NextPageTokenKey = "next-page-token"
+
+type listPageToken struct {
+	Name    string
+	Variant string `json:",omitempty"`
+	Created int64  `json:",omitempty"`
+}
+
+func encodePageToken(token listPageToken) (string, error) {
+	serialized, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(serialized), nil
+}
+
+func decodePageToken(encoded string) (listPageToken, error) {
+	token := listPageToken{}
+	if encoded == "" {
+		return token, nil
+	}
+	serialized, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return token, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	if err := json.Unmarshal(serialized, &token); err != nil {
+		return token, status.Error(codes.InvalidArgument, "invalid page token")
+	}
+	return token, nil
+}
+
+// listFields are the fields of a resource that lists filter and sort on.
+// Resources without a field have its zero value.
+type listFields struct {
+	owner    string
+	provider string
+	status   pb.ResourceStatus_Status
+	tags     []string
+	created  *tspb.Timestamp
+}
+
+func resourceListFields(res Resource) listFields {
+	switch serialized := res.Proto().(type) {
+	case *pb.FeatureVariant:
+		return listFields{
+			owner:    serialized.Owner,
+			provider: serialized.Provider,
+			status:   serialized.GetStatus().GetStatus(),
+			tags:     serialized.GetTags().GetTag(),
+			created:  serialized.GetCreated(),
+		}
+	case *pb.LabelVariant:
+		return listFields{
+			owner:    serialized.Owner,
+			provider: serialized.Provider,
+			status:   serialized.GetStatus().GetStatus(),
+			tags:     serialized.GetTags().GetTag(),
+			created:  serialized.GetCreated(),
+		}
+	case *pb.SourceVariant:
+		return listFields{
+			owner:    serialized.Owner,
+			provider: serialized.Provider,
+			status:   serialized.GetStatus().GetStatus(),
+			tags:     serialized.GetTags().GetTag(),
+			created:  serialized.GetCreated(),
+		}
+	case *pb.TrainingSetVariant:
+		return listFields{
+			owner:    serialized.Owner,
+			provider: serialized.Provider,
+			status:   serialized.GetStatus().GetStatus(),
+			tags:     serialized.GetTags().GetTag(),
+			created:  serialized.GetCreated(),
+		}
+	case *pb.FeatureGroupVariant:
+		return listFields{
+			owner:   serialized.Owner,
+			status:  serialized.GetStatus().GetStatus(),
+			tags:    serialized.GetTags().GetTag(),
+			created: serialized.GetCreated(),
+		}
+	case *pb.ModelVariant:
+		return listFields{
+			owner:   serialized.Owner,
+			status:  serialized.GetStatus().GetStatus(),
+			tags:    serialized.GetTags().GetTag(),
+			created: serialized.GetCreated(),
+		}
+	case *pb.User:
+		return listFields{status: serialized.GetStatus().GetStatus(), tags: serialized.GetTags().GetTag()}
+	case *pb.Provider:
+		return listFields{status: serialized.GetStatus().GetStatus(), tags: serialized.GetTags().GetTag()}
+	case *pb.Entity:
+		return listFields{status: serialized.GetStatus().GetStatus(), tags: serialized.GetTags().GetTag()}
+	case *pb.Model:
+		return listFields{tags: serialized.GetTags().GetTag()}
+	}
+	return listFields{}
+}
+
+func isEmptyFilter(filter *pb.ListFilter) bool {
+	return filter.GetOwner() == "" && len(filter.GetStatuses()) == 0 && filter.GetProvider() == "" &&
+		filter.GetTag() == "" && filter.GetCreatedAfter() == nil
+}
+
+func (fields listFields) matches(filter *pb.ListFilter) bool {
+	if filter.GetOwner() != "" && fields.owner != filter.Owner {
+		return false
+	}
+	if filter.GetProvider() != "" && fields.provider != filter.Provider {
+		return false
+	}
+	if len(filter.GetStatuses()) > 0 {
+		found := false
+		for _, want := range filter.Statuses {
+			if fields.status == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.GetTag() != "" {
+		found := false
+		for _, tag := range fields.tags {
+			if tag == filter.Tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.GetCreatedAfter() != nil && !fields.created.AsTime().After(filter.CreatedAfter.AsTime()) {
+		return false
+	}
+	return true
+}
+
+// listEntry is a resource that matched a list's filter, with what it's
+// sorted by. Resources without a creation time sort as created at the epoch.
+type listEntry struct {
+	res     Resource
+	id      ResourceID
+	created int64
+}
+
+func (entry listEntry) token() listPageToken {
+	return listPageToken{Name: entry.id.Name, Variant: entry.id.Variant, Created: entry.created}
+}
+
+// matchListEntry returns the entry for res if it matches filter, or nil if it
+// doesn't. Resources with variants match if any of their variants do, and
+// are created when their default variant was. Their variants are looked up
+// in variants, which is nil when neither the filter nor the sort needs them.
+func matchListEntry(res Resource, filter *pb.ListFilter, variants ResourceLookup) (*listEntry, error) {
+	entry := &listEntry{res: res, id: res.ID()}
+	ids := variantIDs(res)
+	if len(ids) == 0 {
+		fields := resourceListFields(res)
+		if !fields.matches(filter) {
+			return nil, nil
+		}
+		entry.created = fields.created.AsTime().UnixNano()
+		return entry, nil
+	}
+	if variants == nil {
+		return entry, nil
+	}
+	defaultVariant := res.Proto().(interface{ GetDefaultVariant() string }).GetDefaultVariant()
+	matched := false
+	for _, id := range ids {
+		variant, err := variants.Lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		fields := resourceListFields(variant)
+		if fields.matches(filter) {
+			matched = true
+		}
+		if id.Variant == defaultVariant {
+			entry.created = fields.created.AsTime().UnixNano()
+		}
+	}
+	if !matched {
+		return nil, nil
+	}
+	return entry, nil
+}
+
+// matchListEntries returns the entries of the resources that match filter.
+// The variants they need are read in one Submap, rather than one per
+// resource, unless variants has already been read.
+func (serv *MetadataServer) matchListEntries(resources []Resource, filter *pb.ListFilter, variants ResourceLookup) ([]listEntry, error) {
+	if variants == nil && !isEmptyFilter(filter) {
+		ids := make([]ResourceID, 0)
+		for _, res := range resources {
+			ids = append(ids, variantIDs(res)...)
+		}
+		if len(ids) > 0 {
+			var err error
+			if variants, err = serv.lookup.Submap(ids); err != nil {
+				return nil, err
+			}
+		}
+	}
+	entries := make([]listEntry, 0, len(resources))
+	for _, res := range resources {
+		entry, err := matchListEntry(res, filter, variants)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, *entry)
+		}
+	}
+	return entries, nil
+}
+
+// listVariantsOfType reads every variant of resources of type t in one
+// range read per variant type.
+func (serv *MetadataServer) listVariantsOfType(t ResourceType) (ResourceLookup, error) {
+	variants := make(LocalResourceLookup)
+	for variantType, parentType := range parentMapping {
+		if parentType != t {
+			continue
+		}
+		resources, err := serv.lookup.ListForType(variantType)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range resources {
+			variants[res.ID()] = res
+		}
+	}
+	return variants, nil
+}
+
+// listResources returns a page of the resources of type t and the token of
+// the next page, if there might be one.
+func (serv *MetadataServer) listResources(t ResourceType, req *pb.ListRequest) ([]Resource, string, error) {
+	token, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, "", err
+	}
+	pageSize := int(req.GetPageSize())
+	if pageSize < 0 {
+		return nil, "", status.Error(codes.InvalidArgument, "page size cannot be negative")
+	}
+	page, more, err := serv.listSorted(t, req, token, pageSize)
+	if err != nil {
+		return nil, "", err
+	}
+	resources := make([]Resource, len(page))
+	for i, entry := range page {
+		resources[i] = entry.res
+	}
+	if !more || len(page) == 0 {
+		return resources, "", nil
+	}
+	next, err := encodePageToken(page[len(page)-1].token())
+	if err != nil {
+		return nil, "", err
+	}
+	return resources, next, nil
+}
+
+// listSorted returns a page of the entries of type t after the token, and
+// whether there might be more.
+func (serv *MetadataServer) listSorted(t ResourceType, req *pb.ListRequest, token listPageToken, pageSize int) ([]listEntry, bool, error) {
+	if req.GetSortBy() == pb.ListRequest_CREATED {
+		return serv.listByCreated(t, req, token, pageSize)
+	}
+	return serv.listByName(t, req, token, pageSize)
+}
+
+func listEntryLess(byCreated, descending bool) func(a, b listEntry) bool {
+	less := func(a, b listEntry) bool {
+		if byCreated && a.created != b.created {
+			return a.created < b.created
+		}
+		if a.id.Name != b.id.Name {
+			return a.id.Name < b.id.Name
+		}
+		return a.id.Variant < b.id.Variant
+	}
+	if descending {
+		return func(a, b listEntry) bool { return less(b, a) }
+	}
+	return less
+}
+
+// listByCreated reads every resource of the type to sort them, since when
+// they were created isn't part of their keys. Variants are read for when
+// their resource was created, all in one range read.
+func (serv *MetadataServer) listByCreated(t ResourceType, req *pb.ListRequest, token listPageToken, pageSize int) ([]listEntry, bool, error) {
+	all, err := serv.lookup.ListForType(t)
+	if err != nil {
+		return nil, false, err
+	}
+	variants, err := serv.listVariantsOfType(t)
+	if err != nil {
+		return nil, false, err
+	}
+	namespace := namespaceOf(t, req.GetFilter().GetNamespace())
+	resources := make([]Resource, 0, len(all))
+	for _, res := range all {
+		if res.ID().Namespace == namespace {
+			resources = append(resources, res)
+		}
+	}
+	entries, err := serv.matchListEntries(resources, req.GetFilter(), variants)
+	if err != nil {
+		return nil, false, err
+	}
+	less := listEntryLess(true, req.GetDescending())
+	sort.Slice(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+	if token.Name != "" {
+		last := listEntry{id: ResourceID{Name: token.Name, Variant: token.Variant}, created: token.Created}
+		start := sort.Search(len(entries), func(i int) bool { return less(last, entries[i]) })
+		entries = entries[start:]
+	}
+	if pageSize > 0 && len(entries) > pageSize {
+		return entries[:pageSize], true, nil
+	}
+	return entries, false, nil
+}
+
+// listByName reads the keys after the token a page at a time until it has a
+// page of entries that no unread key can sort before. Variants are only read
+// when the filter needs them.
+func (serv *MetadataServer) listByName(t ResourceType, req *pb.ListRequest, token listPageToken, pageSize int) ([]listEntry, bool, error) {
+	namespace := namespaceOf(t, req.GetFilter().GetNamespace())
+	descending := req.GetDescending()
+	less := listEntryLess(false, descending)
+	keys := newNameKeyRange(t, namespace, token, descending)
+	var last *listEntry
+	if token.Name != "" {
+		last = &listEntry{id: ResourceID{Name: token.Name, Variant: token.Variant}}
+	}
+	limit := 0
+	if pageSize > 0 {
+		limit = pageSize + 1
+	}
+	entries := make([]listEntry, 0)
+	for {
+		resources, err := serv.lookup.ListRange(t, keys.start, keys.end, descending, limit)
+		if err != nil {
+			return nil, false, err
+		}
+		unread := make([]Resource, 0, len(resources))
+		for _, res := range resources {
+			if res.ID().Namespace != namespace || (last != nil && !less(*last, listEntry{id: res.ID()})) {
+				continue
+			}
+			unread = append(unread, res)
+		}
+		matched, err := serv.matchListEntries(unread, req.GetFilter(), nil)
+		if err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, matched...)
+		sort.Slice(entries, func(i, j int) bool { return less(entries[i], entries[j]) })
+		if limit == 0 || len(resources) < limit {
+			if pageSize > 0 && len(entries) > pageSize {
+				return entries[:pageSize], true, nil
+			}
+			return entries, false, nil
+		}
+		keys.read(createKey(resources[len(resources)-1].ID()))
+		settled := 0
+		for settled < len(entries) && keys.settled(entries[settled].id.Name) {
+			settled++
+		}
+		if settled >= pageSize {
+			return entries[:pageSize], true, nil
+		}
+	}
+}
+
+// nameKeyRange is the range of keys left to read for a page of resources by
+// name. Keys are the name, "__" and the variant, so they don't quite sort
+// like names: "feature2__v" comes before "feature__v". A key only sorts
+// after the key of a greater name if its name is a prefix of that name.
+type nameKeyRange struct {
+	prefix     string
+	start, end string
+	descending bool
+	// last is the last key read.
+	last string
+}
+
+func newNameKeyRange(t ResourceType, namespace string, token listPageToken, descending bool) *nameKeyRange {
+	prefix := typeKeyPrefix(t) + keyName(ResourceID{Namespace: namespace})
+	keys := &nameKeyRange{prefix: prefix, start: prefix, end: prefixRangeEnd(prefix), descending: descending}
+	if token.Name == "" {
+		return keys
+	}
+	if !descending {
+		// Every greater name's keys are at or after its name.
+		keys.start = prefix + token.Name
+		return keys
+	}
+	keys.end = prefix + token.Name + "__" + token.Variant
+	if short, ok := shortestKeyPrefix(token.Name); ok && prefixRangeEnd(prefix+short+"__") > keys.end {
+		keys.end = prefixRangeEnd(prefix + short + "__")
+	}
+	return keys
+}
+
+// prefixRangeEnd is the first key after every key starting with prefix.
+// Prefixes all end in "__", and "`" comes right after "_".
+func prefixRangeEnd(prefix string) string {
+	return prefix[:len(prefix)-1] + "`"
+}
+
+// shortestKeyPrefix returns the shortest prefix of name whose keys can sort
+// after name's, if it has one.
+func shortestKeyPrefix(name string) (string, bool) {
+	for i := 1; i < len(name); i++ {
+		if name[i:] < "_`" {
+			return name[:i], true
+		}
+	}
+	return "", false
+}
+
+// read moves the range past key.
+func (keys *nameKeyRange) read(key string) {
+	keys.last = key
+	if keys.descending {
+		keys.end = key
+	} else {
+		keys.start = key + "\x00"
+	}
+}
+
+// settled reports whether every key that sorts before name's resources has
+// been read.
+func (keys *nameKeyRange) settled(name string) bool {
+	if keys.descending {
+		// Only greater names starting with name have keys before name's.
+		return keys.last <= keys.prefix+name
+	}
+	short, ok := shortestKeyPrefix(name)
+	return !ok || prefixRangeEnd(keys.prefix+short+"__") <= keys.last
+}
+
+// genericList sends a page of the resources of type t, setting the next
+// page token trailer if there are more.
+func (serv *MetadataServer) genericList(stream grpc.ServerStream, t ResourceType, req *pb.ListRequest, send sendFn) error {
+	resources, next, err := serv.listResources(t, req)
+	if err != nil {
+		serv.Logger.Errorw("Could not list resources", "type", t.String(), "error", err)
+		return err
+	}
+	for _, res := range resources {
+		if err := send(res.Proto()); err != nil {
+			return err
+		}
+	}
+	if next != "" {
+		stream.SetTrailer(grpcmd.Pairs(NextPageTokenKey, next))
+	}
+	return nil
+}
+
+// nextPageToken returns the next page token of a finished List stream.
+func nextPageToken(stream grpc.ClientStream) string {
+	tokens := stream.Trailer().Get(NextPageTokenKey)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[0]
+}
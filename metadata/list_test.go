@@ -0,0 +1,228 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func featureNames(features []*Feature) []string {
+	names := make([]string, len(features))
+	for i, feature := range features {
+		names[i] = feature.Name()
+	}
+	return names
+}
+
+func listAllFeaturePages(t *testing.T, client *Client, opts ListOptions) ([]string, int) {
+	names := make([]string, 0)
+	pages := 0
+	for {
+		features, next, err := client.ListFeaturesPage(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("Failed to list features: %s", err)
+		}
+		pages++
+		names = append(names, featureNames(features)...)
+		if next == "" {
+			return names, pages
+		}
+		opts.PageToken = next
+	}
+}
+
+func TestListPages(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	all, err := client.ListFeatures(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to list features: %s", err)
+	}
+	expected := []string{"feature", "feature2", "feature3"}
+	if names := featureNames(all); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Wrong features listed: %v", names)
+	}
+	names, pages := listAllFeaturePages(t, client, ListOptions{PageSize: 1})
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Wrong features paged: %v", names)
+	}
+	if pages < len(expected) {
+		t.Fatalf("Expected a page per feature, got %d pages", pages)
+	}
+	// Each feature is created after the last, by its default variant.
+	names, _ = listAllFeaturePages(t, client, ListOptions{PageSize: 2, SortBy: SortByCreated, Descending: true})
+	if !reflect.DeepEqual(names, []string{"feature3", "feature2", "feature"}) {
+		t.Fatalf("Wrong features by created: %v", names)
+	}
+	names, _ = listAllFeaturePages(t, client, ListOptions{PageSize: 2, Descending: true})
+	if !reflect.DeepEqual(names, []string{"feature3", "feature2", "feature"}) {
+		t.Fatalf("Wrong features by name descending: %v", names)
+	}
+	if _, _, err := client.ListFeaturesPage(context.Background(), ListOptions{PageToken: "not a token"}); err == nil {
+		t.Fatalf("Expected an invalid page token to fail")
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	names, _ := listAllFeaturePages(t, client, ListOptions{Provider: "mockOnline"})
+	if !reflect.DeepEqual(names, []string{"feature", "feature2"}) {
+		t.Fatalf("Wrong features on provider: %v", names)
+	}
+	names, _ = listAllFeaturePages(t, client, ListOptions{Provider: "mockOnline", PageSize: 1})
+	if !reflect.DeepEqual(names, []string{"feature", "feature2"}) {
+		t.Fatalf("Wrong features paged on provider: %v", names)
+	}
+	if err := client.SetStatus(context.Background(), ResourceID{Name: "feature2", Variant: "variant", Type: FEATURE_VARIANT}, READY, ""); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	names, _ = listAllFeaturePages(t, client, ListOptions{Statuses: []ResourceStatus{READY, FAILED}})
+	if !reflect.DeepEqual(names, []string{"feature2"}) {
+		t.Fatalf("Wrong ready features: %v", names)
+	}
+
+	// Only one variant of the training set is owned by Other.
+	trainingSets, _, err := client.ListTrainingSetsPage(context.Background(), ListOptions{Owner: "Other"})
+	if err != nil {
+		t.Fatalf("Failed to list training sets: %s", err)
+	}
+	if len(trainingSets) != 1 || trainingSets[0].Name() != "training-set" {
+		t.Fatalf("Wrong training sets owned by Other: %v", trainingSets)
+	}
+	trainingSets, _, err = client.ListTrainingSetsPage(context.Background(), ListOptions{Owner: "Nobody"})
+	if err != nil || len(trainingSets) != 0 {
+		t.Fatalf("Expected no training sets owned by Nobody: %v %v", trainingSets, err)
+	}
+	users, _, err := client.ListUsersPage(context.Background(), ListOptions{Tag: "missing"})
+	if err != nil || len(users) != 0 {
+		t.Fatalf("Expected no users with tag: %v %v", users, err)
+	}
+}
+
+func TestListByNameKeyOrder(t *testing.T) {
+	// Keys sort differently to names when a name is a prefix of another.
+	names := []string{"a", "a-b", "a.b", "a1", "a2b", "a_b", "a__b", "ab", "b", "b_", "b__"}
+	local := make(LocalResourceLookup)
+	sql := SQLResourceLookup{Connection: sqliteStorageProvider(t).Storage}
+	for _, name := range names {
+		user := &userResource{&pb.User{Name: name}}
+		local[user.ID()] = user
+		if err := sql.Set(user.ID(), user); err != nil {
+			t.Fatalf("Failed to set user: %s", err)
+		}
+	}
+	for lookupName, lookup := range map[string]ResourceLookup{"local": local, "sql": sql} {
+		listUsersByName(t, &MetadataServer{lookup: lookup}, lookupName, names)
+	}
+}
+
+func listUsersByName(t *testing.T, serv *MetadataServer, lookupName string, names []string) {
+	for _, descending := range []bool{false, true} {
+		expected := append([]string{}, names...)
+		sort.Strings(expected)
+		if descending {
+			sort.Sort(sort.Reverse(sort.StringSlice(expected)))
+		}
+		for pageSize := 0; pageSize <= len(names); pageSize++ {
+			req := &pb.ListRequest{PageSize: int32(pageSize), Descending: descending}
+			listed := make([]string, 0)
+			for {
+				page, next, err := serv.listResources(USER, req)
+				if err != nil {
+					t.Fatalf("Failed to list users: %s", err)
+				}
+				for _, res := range page {
+					listed = append(listed, res.ID().Name)
+				}
+				if next == "" {
+					break
+				}
+				req.PageToken = next
+			}
+			if !reflect.DeepEqual(listed, expected) {
+				t.Fatalf("Wrong %s users with page size %d, descending %v: %v", lookupName, pageSize, descending, listed)
+			}
+		}
+	}
+}
+
+// countingLookup counts the lookups of resources by ID, and the reads of
+// ranges of them, made through it.
+type countingLookup struct {
+	ResourceLookup
+	lookups, ranges int
+}
+
+func (lookup *countingLookup) Lookup(id ResourceID) (Resource, error) {
+	lookup.lookups++
+	return lookup.ResourceLookup.Lookup(id)
+}
+
+func (lookup *countingLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
+	lookup.lookups++
+	return lookup.ResourceLookup.Submap(ids)
+}
+
+func (lookup *countingLookup) ListForType(t ResourceType) ([]Resource, error) {
+	lookup.ranges++
+	return lookup.ResourceLookup.ListForType(t)
+}
+
+func (lookup *countingLookup) ListRange(t ResourceType, start, end string, descending bool, limit int) ([]Resource, error) {
+	lookup.ranges++
+	return lookup.ResourceLookup.ListRange(t, start, end, descending, limit)
+}
+
+func TestListVariantReads(t *testing.T) {
+	local := make(LocalResourceLookup)
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("feature-%02d", i)
+		feature := &featureResource{&pb.Feature{Name: name, DefaultVariant: "a", Variants: []string{"a", "b"}}}
+		local[feature.ID()] = feature
+		for _, variant := range feature.serialized.Variants {
+			res := &featureVariantResource{&pb.FeatureVariant{Name: name, Variant: variant, Owner: "alice", Created: tspb.Now()}}
+			local[res.ID()] = res
+		}
+	}
+	for _, sortBy := range []pb.ListRequest_SortBy{pb.ListRequest_NAME, pb.ListRequest_CREATED} {
+		lookup := &countingLookup{ResourceLookup: local}
+		serv := &MetadataServer{lookup: lookup}
+		req := &pb.ListRequest{PageSize: 5, SortBy: sortBy, Filter: &pb.ListFilter{Owner: "alice"}}
+		page, _, err := serv.listResources(FEATURE, req)
+		if err != nil {
+			t.Fatalf("Failed to list features: %s", err)
+		}
+		if len(page) != 5 {
+			t.Fatalf("Wrong page by %s: %v", sortBy, page)
+		}
+		// Variants are read at most once for each range of features read,
+		// not once per feature.
+		if lookup.lookups > lookup.ranges {
+			t.Fatalf("Listing by %s made %d lookups for %d range reads", sortBy, lookup.lookups, lookup.ranges)
+		}
+	}
+}
@@ -14,10 +14,12 @@ import (
 func main() {
 	logger := zap.NewExample().Sugar()
 	addr := help.GetEnv("METADATA_PORT", "8080")
+	dir := help.GetEnv("METADATA_DIR", ".featureform/metadata")
+	logger.Infow("Storing metadata on disk", "dir", dir)
 	config := &metadata.Config{
 		Logger:          logger,
 		Address:         fmt.Sprintf(":%s", addr),
-		StorageProvider: metadata.LocalStorageProvider{},
+		StorageProvider: metadata.FileStorageProvider{Dir: dir},
 	}
 	server, err := metadata.NewMetadataServer(config)
 	if err != nil {
@@ -0,0 +1,750 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestVersion is the version of the manifest format that's written.
+const ManifestVersion = 1
+
+type ManifestFormat string
+
+const (
+	ManifestYAML ManifestFormat = "yaml"
+	ManifestJSON ManifestFormat = "json"
+)
+
+// A Manifest is the resources of a registry as data, so that they can be
+// kept in version control and applied to another registry. Fields that the
+// server sets, like statuses and the lists of resources using each one, are
+// left out, and provider secrets are redacted.
+type Manifest struct {
+	Users         []*pb.User
+	Providers     []*pb.Provider
+	Entities      []*pb.Entity
+	Sources       []*pb.SourceVariant
+	Features      []*pb.FeatureVariant
+	Labels        []*pb.LabelVariant
+	FeatureGroups []*pb.FeatureGroupVariant
+	TrainingSets  []*pb.TrainingSetVariant
+	Models        []*pb.Model
+	ModelVariants []*pb.ModelVariant
+}
+
+// manifestDoc is how a manifest is written. Resources are their protos as
+// JSON, with proto field names, and provider configs are written out as
+// data rather than as bytes.
+type manifestDoc struct {
+	Version       int                      `yaml:"version" json:"version"`
+	Users         []map[string]interface{} `yaml:"users,omitempty" json:"users,omitempty"`
+	Providers     []map[string]interface{} `yaml:"providers,omitempty" json:"providers,omitempty"`
+	Entities      []map[string]interface{} `yaml:"entities,omitempty" json:"entities,omitempty"`
+	Sources       []map[string]interface{} `yaml:"sources,omitempty" json:"sources,omitempty"`
+	Features      []map[string]interface{} `yaml:"features,omitempty" json:"features,omitempty"`
+	Labels        []map[string]interface{} `yaml:"labels,omitempty" json:"labels,omitempty"`
+	FeatureGroups []map[string]interface{} `yaml:"feature_groups,omitempty" json:"feature_groups,omitempty"`
+	TrainingSets  []map[string]interface{} `yaml:"training_sets,omitempty" json:"training_sets,omitempty"`
+	Models        []map[string]interface{} `yaml:"models,omitempty" json:"models,omitempty"`
+	ModelVariants []map[string]interface{} `yaml:"model_variants,omitempty" json:"model_variants,omitempty"`
+}
+
+func (doc *manifestDoc) section(t ResourceType) *[]map[string]interface{} {
+	switch t {
+	case USER:
+		return &doc.Users
+	case PROVIDER:
+		return &doc.Providers
+	case ENTITY:
+		return &doc.Entities
+	case SOURCE_VARIANT:
+		return &doc.Sources
+	case FEATURE_VARIANT:
+		return &doc.Features
+	case LABEL_VARIANT:
+		return &doc.Labels
+	case FEATURE_GROUP_VARIANT:
+		return &doc.FeatureGroups
+	case TRAINING_SET_VARIANT:
+		return &doc.TrainingSets
+	case MODEL:
+		return &doc.Models
+	case MODEL_VARIANT:
+		return &doc.ModelVariants
+	default:
+		return nil
+	}
+}
+
+// manifestTypes are the types in a manifest, in the order they're created
+// so that each resource comes after the ones it depends on.
+var manifestTypes = []ResourceType{
+	USER,
+	PROVIDER,
+	ENTITY,
+	SOURCE_VARIANT,
+	FEATURE_VARIANT,
+	LABEL_VARIANT,
+	FEATURE_GROUP_VARIANT,
+	TRAINING_SET_VARIANT,
+	MODEL,
+	MODEL_VARIANT,
+}
+
+func (m *Manifest) add(res Resource) error {
+	switch serialized := res.Proto().(type) {
+	case *pb.User:
+		m.Users = append(m.Users, serialized)
+	case *pb.Provider:
+		m.Providers = append(m.Providers, serialized)
+	case *pb.Entity:
+		m.Entities = append(m.Entities, serialized)
+	case *pb.SourceVariant:
+		m.Sources = append(m.Sources, serialized)
+	case *pb.FeatureVariant:
+		m.Features = append(m.Features, serialized)
+	case *pb.LabelVariant:
+		m.Labels = append(m.Labels, serialized)
+	case *pb.FeatureGroupVariant:
+		m.FeatureGroups = append(m.FeatureGroups, serialized)
+	case *pb.TrainingSetVariant:
+		m.TrainingSets = append(m.TrainingSets, serialized)
+	case *pb.Model:
+		m.Models = append(m.Models, serialized)
+	case *pb.ModelVariant:
+		m.ModelVariants = append(m.ModelVariants, serialized)
+	default:
+		return fmt.Errorf("%s cannot be in a manifest", res.ID().Type)
+	}
+	return nil
+}
+
+// resources returns the resources of m in the order they're written.
+func (m *Manifest) resources() []Resource {
+	resources := make([]Resource, 0)
+	for _, serialized := range m.Users {
+		resources = append(resources, &userResource{serialized})
+	}
+	for _, serialized := range m.Providers {
+		resources = append(resources, &providerResource{serialized})
+	}
+	for _, serialized := range m.Entities {
+		resources = append(resources, &entityResource{serialized})
+	}
+	for _, serialized := range m.Sources {
+		resources = append(resources, &sourceVariantResource{serialized})
+	}
+	for _, serialized := range m.Features {
+		resources = append(resources, &featureVariantResource{serialized})
+	}
+	for _, serialized := range m.Labels {
+		resources = append(resources, &labelVariantResource{serialized})
+	}
+	for _, serialized := range m.FeatureGroups {
+		resources = append(resources, &featureGroupVariantResource{serialized})
+	}
+	for _, serialized := range m.TrainingSets {
+		resources = append(resources, &trainingSetVariantResource{serialized})
+	}
+	for _, serialized := range m.Models {
+		resources = append(resources, &modelResource{serialized})
+	}
+	for _, serialized := range m.ModelVariants {
+		resources = append(resources, &modelVariantResource{serialized})
+	}
+	return resources
+}
+
+// applyOrder returns the resources of m in an order they can be created in.
+// Sources are the only type that can depend on their own type, so
+// transformations are moved after the sources they read.
+func (m *Manifest) applyOrder() []Resource {
+	resources := make([]Resource, 0)
+	sources := make(map[ResourceID]Resource)
+	for _, res := range m.resources() {
+		if res.ID().Type == SOURCE_VARIANT {
+			sources[res.ID()] = res
+		}
+	}
+	visited := make(map[ResourceID]bool)
+	var visit func(res Resource)
+	visit = func(res Resource) {
+		id := res.ID()
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+		transformation := res.Proto().(*pb.SourceVariant).GetTransformation()
+		inputs := make([]*pb.NameVariant, 0)
+		inputs = append(inputs, transformation.GetSQLTransformation().GetSource()...)
+		inputs = append(inputs, transformation.GetDFTransformation().GetInputs()...)
+		for _, input := range inputs {
+			// References are always to the same namespace.
+			inputID := ResourceID{Name: input.Name, Variant: input.Variant, Type: SOURCE_VARIANT, Namespace: id.Namespace}
+			if source, has := sources[inputID]; has {
+				visit(source)
+			}
+		}
+		resources = append(resources, res)
+	}
+	for _, res := range m.resources() {
+		if res.ID().Type == SOURCE_VARIANT {
+			visit(res)
+		} else {
+			resources = append(resources, res)
+		}
+	}
+	return resources
+}
+
+// Definitions returns the resources of m in an order they can be created in
+// with CreateAll.
+func (m *Manifest) Definitions() []ResourceDef {
+	resources := m.applyOrder()
+	defs := make([]ResourceDef, len(resources))
+	for i, res := range resources {
+		defs[i] = DefinitionDef{resourceDefinition(res)}
+	}
+	return defs
+}
+
+func resourceDefinition(res Resource) *pb.ResourceDefinition {
+	definition := &pb.ResourceDefinition{}
+	switch serialized := res.Proto().(type) {
+	case *pb.User:
+		definition.Resource = &pb.ResourceDefinition_User{User: serialized}
+	case *pb.Provider:
+		definition.Resource = &pb.ResourceDefinition_Provider{Provider: serialized}
+	case *pb.Entity:
+		definition.Resource = &pb.ResourceDefinition_Entity{Entity: serialized}
+	case *pb.SourceVariant:
+		definition.Resource = &pb.ResourceDefinition_SourceVariant{SourceVariant: serialized}
+	case *pb.FeatureVariant:
+		definition.Resource = &pb.ResourceDefinition_FeatureVariant{FeatureVariant: serialized}
+	case *pb.LabelVariant:
+		definition.Resource = &pb.ResourceDefinition_LabelVariant{LabelVariant: serialized}
+	case *pb.FeatureGroupVariant:
+		definition.Resource = &pb.ResourceDefinition_FeatureGroupVariant{FeatureGroupVariant: serialized}
+	case *pb.TrainingSetVariant:
+		definition.Resource = &pb.ResourceDefinition_TrainingSetVariant{TrainingSetVariant: serialized}
+	case *pb.Model:
+		definition.Resource = &pb.ResourceDefinition_Model{Model: serialized}
+	case *pb.ModelVariant:
+		definition.Resource = &pb.ResourceDefinition_ModelVariant{ModelVariant: serialized}
+	}
+	return definition
+}
+
+// serverFields are set by the server as resources are created and used, so
+// they're left out of manifests.
+var serverFields = map[ResourceType][]protoreflect.Name{
+	USER:                  {"revision", "status", "features", "labels", "trainingsets", "sources"},
+	PROVIDER:              {"revision", "status", "sources", "features", "trainingsets", "labels"},
+	ENTITY:                {"revision", "status", "features", "labels", "trainingsets"},
+	SOURCE_VARIANT:        {"revision", "status", "created", "last_updated", "table", "trainingsets", "features", "labels"},
+	FEATURE_VARIANT:       {"revision", "status", "created", "last_updated", "trainingsets", "lifecycle", "feature_groups"},
+	LABEL_VARIANT:         {"revision", "status", "created", "trainingsets"},
+	FEATURE_GROUP_VARIANT: {"revision", "status", "created", "trainingsets"},
+	TRAINING_SET_VARIANT:  {"revision", "status", "created", "last_updated", "lifecycle", "models"},
+	MODEL:                 {"revision", "default_variant", "variants"},
+	MODEL_VARIANT:         {"revision", "status", "created", "features", "label", "feature_groups"},
+}
+
+// emptyFields are cleared when they're set to an empty message, so that a
+// resource registered without tags looks the same as one with no tags.
+var emptyFields = []protoreflect.Name{"tags", "properties"}
+
+func clearServerFields(res Resource) {
+	msg := res.Proto().ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	for _, name := range serverFields[res.ID().Type] {
+		msg.Clear(fields.ByName(name))
+	}
+	for _, name := range emptyFields {
+		field := fields.ByName(name)
+		if field != nil && msg.Has(field) && proto.Size(msg.Get(field).Message().Interface()) == 0 {
+			msg.Clear(field)
+		}
+	}
+}
+
+const redactedSecret = "<redacted>"
+
+// secretConfigKeys are parts of the names of provider config fields that
+// hold credentials. Their values are redacted from manifests.
+var secretConfigKeys = []string{
+	"password",
+	"secret",
+	"token",
+	"credential",
+	"apikey",
+	"accesskey",
+	"accountkey",
+	"privatekey",
+	"connectionstring",
+}
+
+func isSecretConfigKey(key string) bool {
+	normalized := strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+	for _, secret := range secretConfigKeys {
+		if strings.Contains(normalized, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactConfig replaces the secrets in a provider config. Configs that
+// aren't JSON can't be redacted field by field, so they're redacted whole.
+func redactConfig(config []byte) []byte {
+	if len(config) == 0 {
+		return config
+	}
+	var fields map[string]interface{}
+	if err := decodeJSON(config, &fields); err != nil {
+		return []byte(strconv.Quote(redactedSecret))
+	}
+	redacted, err := encodeJSON(redactSecrets(fields), "")
+	if err != nil {
+		return []byte(strconv.Quote(redactedSecret))
+	}
+	return redacted
+}
+
+func redactSecrets(value interface{}) interface{} {
+	switch casted := value.(type) {
+	case map[string]interface{}:
+		for key, field := range casted {
+			if isSecretConfigKey(key) && field != nil && field != "" {
+				casted[key] = redactedSecret
+			} else {
+				casted[key] = redactSecrets(field)
+			}
+		}
+	case []interface{}:
+		for i, elem := range casted {
+			casted[i] = redactSecrets(elem)
+		}
+	}
+	return value
+}
+
+// hasRedactedSecrets returns true if config came from a manifest without its
+// secrets filled back in.
+func hasRedactedSecrets(config []byte) bool {
+	return bytes.Contains(config, []byte(strconv.Quote(redactedSecret)))
+}
+
+// decodeJSON keeps numbers as they're written, so that large integers in
+// configs aren't rounded.
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// encodeJSON doesn't escape HTML characters, so that redacted secrets read
+// the same in every format.
+func encodeJSON(v interface{}, indent string) ([]byte, error) {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(encoded.Bytes(), []byte("\n")), nil
+}
+
+var manifestMarshaler = protojson.MarshalOptions{UseProtoNames: true}
+
+func manifestEntry(res Resource) (map[string]interface{}, error) {
+	serialized, err := manifestMarshaler.Marshal(res.Proto())
+	if err != nil {
+		return nil, fmt.Errorf("could not serialize %s: %w", res.ID(), err)
+	}
+	entry := make(map[string]interface{})
+	if err := decodeJSON(serialized, &entry); err != nil {
+		return nil, fmt.Errorf("could not serialize %s: %w", res.ID(), err)
+	}
+	if provider, ok := res.Proto().(*pb.Provider); ok && len(provider.SerializedConfig) > 0 {
+		var config interface{}
+		if err := decodeJSON(provider.SerializedConfig, &config); err == nil {
+			delete(entry, "serialized_config")
+			entry["config"] = config
+		}
+	}
+	return entry, nil
+}
+
+func resourceFromManifestEntry(t ResourceType, entry map[string]interface{}) (Resource, error) {
+	if config, has := entry["config"]; has {
+		serialized, err := encodeJSON(config, "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid provider config: %w", err)
+		}
+		entry["serialized_config"] = base64.StdEncoding.EncodeToString(serialized)
+		delete(entry, "config")
+	}
+	serialized, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	res, err := emptyResource(t)
+	if err != nil {
+		return nil, err
+	}
+	if err := protojson.Unmarshal(serialized, res.Proto()); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Encode writes m in format. Resources are written in the order they're in,
+// and the fields of each are sorted, so exports of the same registry are the
+// same.
+func (m *Manifest) Encode(format ManifestFormat) ([]byte, error) {
+	doc := &manifestDoc{Version: ManifestVersion}
+	for _, res := range m.resources() {
+		entry, err := manifestEntry(res)
+		if err != nil {
+			return nil, err
+		}
+		section := doc.section(res.ID().Type)
+		*section = append(*section, entry)
+	}
+	switch format {
+	case ManifestYAML:
+		return yaml.Marshal(doc)
+	case ManifestJSON:
+		return encodeJSON(doc, "  ")
+	default:
+		return nil, fmt.Errorf("unknown manifest format: %s", format)
+	}
+}
+
+// ParseManifest reads a YAML or JSON manifest.
+func ParseManifest(data []byte) (*Manifest, error) {
+	doc := &manifestDoc{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("could not parse manifest: %v", err)
+	}
+	if doc.Version < 1 || doc.Version > ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version: %d", doc.Version)
+	}
+	manifest := &Manifest{}
+	for _, t := range manifestTypes {
+		for i, entry := range *doc.section(t) {
+			res, err := resourceFromManifestEntry(t, entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s %d: %v", t, i, err)
+			}
+			if err := manifest.add(res); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return manifest, nil
+}
+
+// ExportManifest returns every user and provider, and the other resources in
+// the client's namespace, sorted by name and variant.
+func (client *Client) ExportManifest(ctx context.Context) (*Manifest, error) {
+	resources, err := client.exportResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		a, b := resources[i].ID(), resources[j].ID()
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Variant < b.Variant
+	})
+	manifest := &Manifest{}
+	for _, res := range resources {
+		clearServerFields(res)
+		if provider, ok := res.Proto().(*pb.Provider); ok {
+			provider.SerializedConfig = redactConfig(provider.SerializedConfig)
+		}
+		if err := manifest.add(res); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, nil
+}
+
+func (client *Client) exportResources(ctx context.Context) ([]Resource, error) {
+	resources := make([]Resource, 0)
+	users, err := client.ListUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	for _, user := range users {
+		resources = append(resources, &userResource{user.serialized})
+	}
+	providers, err := client.ListProviders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list providers: %w", err)
+	}
+	for _, provider := range providers {
+		resources = append(resources, &providerResource{provider.serialized})
+	}
+	entities, err := client.ListEntities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list entities: %w", err)
+	}
+	for _, entity := range entities {
+		resources = append(resources, &entityResource{entity.serialized})
+	}
+	sources, err := client.ListSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list sources: %w", err)
+	}
+	if ids := sourceVariantIDs(sources); len(ids) > 0 {
+		variants, err := client.GetSourceVariants(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range variants {
+			resources = append(resources, &sourceVariantResource{variant.serialized})
+		}
+	}
+	features, err := client.ListFeatures(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list features: %w", err)
+	}
+	ids := make(NameVariants, 0)
+	for _, feature := range features {
+		ids = append(ids, feature.NameVariants()...)
+	}
+	if len(ids) > 0 {
+		variants, err := client.GetFeatureVariants(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range variants {
+			resources = append(resources, &featureVariantResource{variant.serialized})
+		}
+	}
+	labels, err := client.ListLabels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	ids = make(NameVariants, 0)
+	for _, label := range labels {
+		ids = append(ids, label.NameVariants()...)
+	}
+	if len(ids) > 0 {
+		variants, err := client.GetLabelVariants(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range variants {
+			resources = append(resources, &labelVariantResource{variant.serialized})
+		}
+	}
+	trainingSets, err := client.ListTrainingSets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list training sets: %w", err)
+	}
+	ids = make(NameVariants, 0)
+	for _, trainingSet := range trainingSets {
+		ids = append(ids, trainingSet.NameVariants()...)
+	}
+	if len(ids) > 0 {
+		variants, err := client.GetTrainingSetVariants(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, variant := range variants {
+			resources = append(resources, &trainingSetVariantResource{variant.serialized})
+		}
+	}
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+	for _, model := range models {
+		resources = append(resources, &modelResource{model.serialized})
+	}
+	return resources, nil
+}
+
+func sourceVariantIDs(sources []*Source) NameVariants {
+	ids := make(NameVariants, 0)
+	for _, source := range sources {
+		ids = append(ids, source.NameVariants()...)
+	}
+	return ids
+}
+
+// ManifestImport is what importing a manifest did, or would have done if it
+// had no conflicts.
+type ManifestImport struct {
+	// Applied are the resources created, or updated with the manifest's tags
+	// and properties if they already existed.
+	Applied []ResourceID
+	// Skipped are existing providers whose configs in the manifest have
+	// redacted secrets. They're left as they are.
+	Skipped   []ResourceID
+	Conflicts []ManifestConflict
+}
+
+// A ManifestConflict is a resource in a manifest that can't be applied,
+// usually because it differs from the registered resource in a field that
+// can't be updated.
+type ManifestConflict struct {
+	ID ResourceID
+	// Fields are the proto field names that differ, if any.
+	Fields []string
+	Reason string
+}
+
+func (conflict ManifestConflict) String() string {
+	if len(conflict.Fields) == 0 {
+		return fmt.Sprintf("%s: %s", conflict.ID, conflict.Reason)
+	}
+	return fmt.Sprintf("%s: %s (%s)", conflict.ID, conflict.Reason, strings.Join(conflict.Fields, ", "))
+}
+
+// updatableFields are the JSON names of the fields that creating an existing
+// resource updates, so they can differ from what's registered.
+var updatableFields = map[ResourceType]map[string]bool{
+	PROVIDER: {"description": true, "serializedConfig": true},
+	MODEL:    {"features": true, "trainingsets": true},
+}
+
+// conflictingFields returns the proto names of the fields of update that
+// differ from existing and that can't be updated.
+func conflictingFields(existing, update Resource) ([]string, error) {
+	changes, err := diffResources(existing.Proto(), update.Proto())
+	if err != nil {
+		return nil, err
+	}
+	t := update.ID().Type
+	descriptor := update.Proto().ProtoReflect().Descriptor().Fields()
+	fields := make([]string, 0)
+	for _, change := range changes {
+		if change.Field == "tags" || change.Field == "properties" || updatableFields[t][change.Field] {
+			continue
+		}
+		name := change.Field
+		if field := descriptor.ByJSONName(change.Field); field != nil {
+			name = string(field.Name())
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// namespaces returns the namespaces that resources in m are in.
+func (m *Manifest) namespaces() []string {
+	seen := map[string]bool{DefaultNamespace: true}
+	namespaces := []string{DefaultNamespace}
+	for _, res := range m.resources() {
+		if namespace := res.ID().Namespace; !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// ImportManifest creates the resources in manifest with CreateAll. Resources
+// that are already registered may only differ in what creating them again
+// updates, like their tags and properties. If any resource conflicts with
+// what's registered, or would otherwise fail to be created, nothing is
+// applied and the conflicts are returned along with an error.
+func (client *Client) ImportManifest(ctx context.Context, manifest *Manifest) (*ManifestImport, error) {
+	registered := make(map[ResourceID]Resource)
+	for _, namespace := range manifest.namespaces() {
+		resources, err := client.InNamespace(namespace).exportResources(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range resources {
+			clearServerFields(res)
+			registered[res.ID()] = res
+		}
+	}
+	result := &ManifestImport{
+		Applied:   make([]ResourceID, 0),
+		Skipped:   make([]ResourceID, 0),
+		Conflicts: make([]ManifestConflict, 0),
+	}
+	defs := make([]ResourceDef, 0)
+	for _, manifestRes := range manifest.applyOrder() {
+		res, err := copyResource(manifestRes)
+		if err != nil {
+			return nil, err
+		}
+		clearServerFields(res)
+		if err := qualifyReferences(res); err != nil {
+			result.Conflicts = append(result.Conflicts, ManifestConflict{ID: res.ID(), Reason: err.Error()})
+			continue
+		}
+		id := res.ID()
+		existing, has := registered[id]
+		if has {
+			fields, err := conflictingFields(existing, res)
+			if err != nil {
+				return nil, err
+			}
+			if len(fields) > 0 {
+				result.Conflicts = append(result.Conflicts, ManifestConflict{ID: id, Fields: fields, Reason: "differs from the registered resource"})
+				continue
+			}
+		}
+		if provider, ok := res.Proto().(*pb.Provider); ok && hasRedactedSecrets(provider.SerializedConfig) {
+			if has {
+				result.Skipped = append(result.Skipped, id)
+			} else {
+				result.Conflicts = append(result.Conflicts, ManifestConflict{ID: id, Fields: []string{"config"}, Reason: "config has redacted secrets"})
+			}
+			continue
+		}
+		defs = append(defs, DefinitionDef{resourceDefinition(res)})
+		result.Applied = append(result.Applied, id)
+	}
+	if len(result.Conflicts) == 0 {
+		plans, err := client.Plan(ctx, defs)
+		if err != nil {
+			return nil, err
+		}
+		for i, plan := range plans {
+			if plan.Action != pb.ResourcePlan_REJECT {
+				continue
+			}
+			fields := make([]string, len(plan.Changes))
+			for j, change := range plan.Changes {
+				fields[j] = change.Field
+			}
+			result.Conflicts = append(result.Conflicts, ManifestConflict{ID: result.Applied[i], Fields: fields, Reason: plan.Error})
+		}
+	}
+	if len(result.Conflicts) > 0 {
+		result.Applied = make([]ResourceID, 0)
+		return result, fmt.Errorf("manifest has %d conflicts with the registry", len(result.Conflicts))
+	}
+	if err := client.CreateAll(ctx, defs); err != nil {
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Exports the metadata server's resources to a manifest, or imports one:
+//
+//	manifest export [-namespace ns] [-format yaml|json] > registry.yaml
+//	manifest import registry.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	help "github.com/featureform/helpers"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: manifest export [-namespace ns] [-format yaml|json]")
+	fmt.Fprintln(os.Stderr, "       manifest import FILE")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	logger := logging.NewLogger("metadata-manifest")
+	addr := fmt.Sprintf("%s:%s", help.GetEnv("METADATA_HOST", "localhost"), help.GetEnv("METADATA_PORT", "8080"))
+	client, err := metadata.NewClient(addr, logger)
+	if err != nil {
+		logger.Panicw("Failed to connect", "Err", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "export":
+		flags := flag.NewFlagSet("export", flag.ExitOnError)
+		namespace := flags.String("namespace", metadata.DefaultNamespace, "namespace to export resources from")
+		format := flags.String("format", string(metadata.ManifestYAML), "yaml or json")
+		flags.Parse(os.Args[2:])
+		manifest, err := client.InNamespace(*namespace).ExportManifest(ctx)
+		if err != nil {
+			logger.Fatalw("Export failed", "Err", err)
+		}
+		encoded, err := manifest.Encode(metadata.ManifestFormat(*format))
+		if err != nil {
+			logger.Fatalw("Failed to encode manifest", "Err", err)
+		}
+		os.Stdout.Write(encoded)
+	case "import":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		file, err := os.ReadFile(os.Args[2])
+		if err != nil {
+			logger.Fatalw("Failed to read manifest", "Err", err)
+		}
+		manifest, err := metadata.ParseManifest(file)
+		if err != nil {
+			logger.Fatalw("Invalid manifest", "Err", err)
+		}
+		result, err := client.ImportManifest(ctx, manifest)
+		if result != nil {
+			for _, conflict := range result.Conflicts {
+				logger.Errorw("Conflict", "resource", conflict.ID.String(), "fields", conflict.Fields, "reason", conflict.Reason)
+			}
+			for _, id := range result.Skipped {
+				logger.Infow("Skipped provider with redacted secrets", "resource", id.String())
+			}
+		}
+		if err != nil {
+			logger.Fatalw("Import failed", "Err", err)
+		}
+		logger.Infow("Imported manifest", "resources", len(result.Applied))
+	default:
+		usage()
+	}
+}
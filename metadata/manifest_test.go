@@ -0,0 +1,200 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+func exportYAML(t *testing.T, client *Client) []byte {
+	manifest, err := client.ExportManifest(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to export manifest: %s", err)
+	}
+	encoded, err := manifest.Encode(ManifestYAML)
+	if err != nil {
+		t.Fatalf("Failed to encode manifest: %s", err)
+	}
+	return encoded
+}
+
+// fillProviderConfigs puts back the secrets redacted from an export.
+func fillProviderConfigs(manifest *Manifest, defs []ResourceDef) {
+	configs := make(map[string][]byte)
+	for _, def := range defs {
+		if provider, ok := def.(ProviderDef); ok {
+			configs[provider.Name] = provider.SerializedConfig
+		}
+	}
+	for _, provider := range manifest.Providers {
+		provider.SerializedConfig = configs[provider.Name]
+	}
+}
+
+func TestManifestExport(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	encoded := exportYAML(t, client)
+	if !bytes.Equal(encoded, exportYAML(t, client)) {
+		t.Fatalf("Exports of the same registry differ")
+	}
+	text := string(encoded)
+	if strings.Contains(text, "Password: root") || strings.Contains(text, "Password: password") {
+		t.Fatalf("Secrets in export:\n%s", text)
+	}
+	if !strings.Contains(text, "Password: <redacted>") || !strings.Contains(text, "Addr: 0.0.0.0") {
+		t.Fatalf("Expected provider configs with only secrets redacted:\n%s", text)
+	}
+	for _, field := range []string{"status:", "created:", "trainingsets:"} {
+		if strings.Contains(text, field) {
+			t.Fatalf("Server set field %s in export:\n%s", field, text)
+		}
+	}
+
+	manifest, err := ParseManifest(encoded)
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %s", err)
+	}
+	if reencoded, err := manifest.Encode(ManifestYAML); err != nil || !bytes.Equal(encoded, reencoded) {
+		t.Fatalf("Manifest changed after parsing: %v\n%s", err, reencoded)
+	}
+	asJSON, err := manifest.Encode(ManifestJSON)
+	if err != nil {
+		t.Fatalf("Failed to encode JSON: %s", err)
+	}
+	fromJSON, err := ParseManifest(asJSON)
+	if err != nil {
+		t.Fatalf("Failed to parse JSON manifest: %s", err)
+	}
+	if reencoded, err := fromJSON.Encode(ManifestYAML); err != nil || !bytes.Equal(encoded, reencoded) {
+		t.Fatalf("JSON manifest differs from YAML one: %v\n%s", err, reencoded)
+	}
+	if _, err := ParseManifest([]byte("version: 2")); err == nil {
+		t.Fatalf("Expected an unknown version to fail")
+	}
+}
+
+func TestManifestImport(t *testing.T) {
+	defs := filledResourceDefs()
+	source := testContext{Defs: defs}
+	sourceClient, err := source.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer source.Destroy()
+	exported := exportYAML(t, sourceClient)
+	manifest, err := ParseManifest(exported)
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %s", err)
+	}
+
+	target := testContext{}
+	client, err := target.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to start target: %s", err)
+	}
+	defer target.Destroy()
+	bg := context.Background()
+	result, err := client.ImportManifest(bg, manifest)
+	if err == nil || len(result.Conflicts) != 2 || result.Conflicts[0].Reason != "config has redacted secrets" {
+		t.Fatalf("Expected new providers with redacted secrets to conflict: %v %v", err, result)
+	}
+	if users, _ := client.ListUsers(bg); len(users) != 0 {
+		t.Fatalf("Conflicting import applied resources")
+	}
+
+	fillProviderConfigs(manifest, defs)
+	result, err = client.ImportManifest(bg, manifest)
+	if err != nil {
+		t.Fatalf("Failed to import manifest: %s %v", err, result)
+	}
+	if len(result.Applied) != len(manifest.resources()) {
+		t.Fatalf("Wrong resources applied: %v", result.Applied)
+	}
+	if imported := exportYAML(t, client); !bytes.Equal(exported, imported) {
+		t.Fatalf("Import differs from export:\n%s\n%s", exported, imported)
+	}
+
+	// Reimporting the redacted export leaves the providers as they are.
+	manifest, err = ParseManifest(exported)
+	if err != nil {
+		t.Fatalf("Failed to parse manifest: %s", err)
+	}
+	manifest.Features[0].Tags = &pb.Tags{Tag: []string{"imported"}}
+	result, err = client.ImportManifest(bg, manifest)
+	if err != nil {
+		t.Fatalf("Failed to reimport manifest: %s %v", err, result)
+	}
+	if len(result.Skipped) != 2 {
+		t.Fatalf("Expected providers to be skipped: %v", result.Skipped)
+	}
+	feature, err := client.GetFeatureVariant(bg, NameVariant{Name: manifest.Features[0].Name, Variant: manifest.Features[0].Variant})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if !reflect.DeepEqual(feature.Tags(), Tags{"imported"}) {
+		t.Fatalf("Expected tags to be updated: %v", feature.Tags())
+	}
+
+	manifest.Features[0].Type = "float64"
+	manifest.Features[0].Description = "Changed"
+	manifest.Entities[0].Description = "Changed"
+	result, err = client.ImportManifest(bg, manifest)
+	if err == nil || len(result.Conflicts) != 2 {
+		t.Fatalf("Expected changed variants to conflict: %v %v", err, result)
+	}
+	conflict := result.Conflicts[1]
+	if conflict.ID.Type != FEATURE_VARIANT || !reflect.DeepEqual(conflict.Fields, []string{"description", "type"}) {
+		t.Fatalf("Wrong conflict: %s", conflict)
+	}
+	if len(result.Applied) != 0 {
+		t.Fatalf("Conflicting import applied resources: %v", result.Applied)
+	}
+}
+
+func TestManifestApplyOrder(t *testing.T) {
+	transformation := &pb.SourceVariant{
+		Name:    "a_transformation",
+		Variant: "v",
+		Definition: &pb.SourceVariant_Transformation{Transformation: &pb.Transformation{
+			Type: &pb.Transformation_SQLTransformation{SQLTransformation: &pb.SQLTransformation{
+				Source: []*pb.NameVariant{{Name: "z_primary", Variant: "v"}},
+			}},
+		}},
+	}
+	primary := &pb.SourceVariant{Name: "z_primary", Variant: "v"}
+	manifest := &Manifest{
+		Features: []*pb.FeatureVariant{{Name: "feature", Variant: "v"}},
+		Sources:  []*pb.SourceVariant{transformation, primary},
+		Users:    []*pb.User{{Name: "user"}},
+	}
+	types := make([]ResourceType, 0)
+	names := make([]string, 0)
+	for _, def := range manifest.Definitions() {
+		types = append(types, def.ResourceType())
+		if source := def.(DefinitionDef).Definition.GetSourceVariant(); source != nil {
+			names = append(names, source.Name)
+		}
+	}
+	if !reflect.DeepEqual(types, []ResourceType{USER, SOURCE_VARIANT, SOURCE_VARIANT, FEATURE_VARIANT}) {
+		t.Fatalf("Wrong type order: %v", types)
+	}
+	if !reflect.DeepEqual(names, []string{"z_primary", "a_transformation"}) {
+		t.Fatalf("Transformation before its source: %v", names)
+	}
+}
@@ -6,9 +6,11 @@ package metadata
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,6 +18,8 @@ import (
 
 	slices "golang.org/x/exp/slices"
 
+	ss "github.com/featureform/helpers/string_set"
+	"github.com/featureform/metadata/notify"
 	pb "github.com/featureform/metadata/proto"
 	"github.com/featureform/metadata/search"
 	pc "github.com/featureform/provider/provider_config"
@@ -34,23 +38,27 @@ type operation int
 
 const (
 	create_op operation = iota
+	delete_op
 )
 
 type ResourceType int32
 
 const (
-	FEATURE              ResourceType = ResourceType(pb.ResourceType_FEATURE)
-	FEATURE_VARIANT                   = ResourceType(pb.ResourceType_FEATURE_VARIANT)
-	LABEL                             = ResourceType(pb.ResourceType_LABEL)
-	LABEL_VARIANT                     = ResourceType(pb.ResourceType_LABEL_VARIANT)
-	USER                              = ResourceType(pb.ResourceType_USER)
-	ENTITY                            = ResourceType(pb.ResourceType_ENTITY)
-	PROVIDER                          = ResourceType(pb.ResourceType_PROVIDER)
-	SOURCE                            = ResourceType(pb.ResourceType_SOURCE)
-	SOURCE_VARIANT                    = ResourceType(pb.ResourceType_SOURCE_VARIANT)
-	TRAINING_SET                      = ResourceType(pb.ResourceType_TRAINING_SET)
-	TRAINING_SET_VARIANT              = ResourceType(pb.ResourceType_TRAINING_SET_VARIANT)
-	MODEL                             = ResourceType(pb.ResourceType_MODEL)
+	FEATURE               ResourceType = ResourceType(pb.ResourceType_FEATURE)
+	FEATURE_VARIANT                    = ResourceType(pb.ResourceType_FEATURE_VARIANT)
+	LABEL                              = ResourceType(pb.ResourceType_LABEL)
+	LABEL_VARIANT                      = ResourceType(pb.ResourceType_LABEL_VARIANT)
+	USER                               = ResourceType(pb.ResourceType_USER)
+	ENTITY                             = ResourceType(pb.ResourceType_ENTITY)
+	PROVIDER                           = ResourceType(pb.ResourceType_PROVIDER)
+	SOURCE                             = ResourceType(pb.ResourceType_SOURCE)
+	SOURCE_VARIANT                     = ResourceType(pb.ResourceType_SOURCE_VARIANT)
+	TRAINING_SET                       = ResourceType(pb.ResourceType_TRAINING_SET)
+	TRAINING_SET_VARIANT               = ResourceType(pb.ResourceType_TRAINING_SET_VARIANT)
+	MODEL                              = ResourceType(pb.ResourceType_MODEL)
+	FEATURE_GROUP                      = ResourceType(pb.ResourceType_FEATURE_GROUP)
+	FEATURE_GROUP_VARIANT              = ResourceType(pb.ResourceType_FEATURE_GROUP_VARIANT)
+	MODEL_VARIANT                      = ResourceType(pb.ResourceType_MODEL_VARIANT)
 )
 
 func (r ResourceType) String() string {
@@ -69,6 +77,7 @@ const (
 	PENDING                  = ResourceStatus(pb.ResourceStatus_PENDING)
 	READY                    = ResourceStatus(pb.ResourceStatus_READY)
 	FAILED                   = ResourceStatus(pb.ResourceStatus_FAILED)
+	ARCHIVED                 = ResourceStatus(pb.ResourceStatus_ARCHIVED)
 )
 
 func (r ResourceStatus) String() string {
@@ -95,10 +104,12 @@ func (cm ComputationMode) String() string {
 }
 
 var parentMapping = map[ResourceType]ResourceType{
-	FEATURE_VARIANT:      FEATURE,
-	LABEL_VARIANT:        LABEL,
-	SOURCE_VARIANT:       SOURCE,
-	TRAINING_SET_VARIANT: TRAINING_SET,
+	FEATURE_VARIANT:       FEATURE,
+	LABEL_VARIANT:         LABEL,
+	SOURCE_VARIANT:        SOURCE,
+	TRAINING_SET_VARIANT:  TRAINING_SET,
+	FEATURE_GROUP_VARIANT: FEATURE_GROUP,
+	MODEL_VARIANT:         MODEL,
 }
 
 func (serv *MetadataServer) needsJob(res Resource) bool {
@@ -122,12 +133,16 @@ type ResourceID struct {
 	Name    string
 	Variant string
 	Type    ResourceType
+	// Namespace is empty for resources in the default namespace, and for
+	// users and providers, which aren't namespaced.
+	Namespace string
 }
 
 func (id ResourceID) Proto() *pb.NameVariant {
 	return &pb.NameVariant{
-		Name:    id.Name,
-		Variant: id.Variant,
+		Name:      id.Name,
+		Variant:   id.Variant,
+		Namespace: id.Namespace,
 	}
 }
 
@@ -137,16 +152,21 @@ func (id ResourceID) Parent() (ResourceID, bool) {
 		return ResourceID{}, false
 	}
 	return ResourceID{
-		Name: id.Name,
-		Type: parentType,
+		Name:      id.Name,
+		Type:      parentType,
+		Namespace: id.Namespace,
 	}, true
 }
 
 func (id ResourceID) String() string {
-	if id.Variant == "" {
-		return fmt.Sprintf("%s %s", id.Type, id.Name)
+	str := fmt.Sprintf("%s %s", id.Type, id.Name)
+	if id.Variant != "" {
+		str += fmt.Sprintf(" (%s)", id.Variant)
 	}
-	return fmt.Sprintf("%s %s (%s)", id.Type, id.Name, id.Variant)
+	if id.Namespace != DefaultNamespace {
+		str += fmt.Sprintf(" in namespace %s", id.Namespace)
+	}
+	return str
 }
 
 var bannedStrings = [...]string{"__"}
@@ -154,6 +174,9 @@ var bannedPrefixes = [...]string{"_"}
 var bannedSuffixes = [...]string{"_"}
 
 func resourceNamedSafely(id ResourceID) error {
+	if err := checkNamespace(id); err != nil {
+		return err
+	}
 	for _, substr := range bannedStrings {
 		if strings.Contains(id.Name, substr) {
 			return fmt.Errorf("resource name %s contains banned string %s", id.Name, substr)
@@ -219,13 +242,30 @@ func (err *ResourceExists) GRPCStatus() *status.Status {
 	return status.New(codes.AlreadyExists, err.Error())
 }
 
+type ResourceHasDependants struct {
+	ID         ResourceID
+	Dependants []ResourceID
+}
+
+func (err *ResourceHasDependants) Error() string {
+	dependants := make([]string, len(err.Dependants))
+	for i, id := range err.Dependants {
+		dependants[i] = id.String()
+	}
+	return fmt.Sprintf("%s has dependants: %s", err.ID, strings.Join(dependants, ", "))
+}
+
+func (err *ResourceHasDependants) GRPCStatus() *status.Status {
+	return status.New(codes.FailedPrecondition, err.Error())
+}
+
 type Resource interface {
 	Notify(ResourceLookup, operation, Resource) error
 	ID() ResourceID
 	Schedule() string
 	Dependencies(ResourceLookup) (ResourceLookup, error)
 	Proto() proto.Message
-	UpdateStatus(pb.ResourceStatus) error
+	UpdateStatus(*pb.ResourceStatus) error
 	UpdateSchedule(string) error
 	Update(ResourceLookup, Resource) error
 }
@@ -243,12 +283,21 @@ type ResourceLookup interface {
 	Lookup(ResourceID) (Resource, error)
 	Has(ResourceID) (bool, error)
 	Set(ResourceID, Resource) error
+	// SetIfRevision sets a resource only if what's stored is still at
+	// revision, returning a *RevisionConflict if it isn't.
+	SetIfRevision(ResourceID, Resource, int64) error
+	Delete(ResourceID) error
 	Submap([]ResourceID) (ResourceLookup, error)
 	ListForType(ResourceType) ([]Resource, error)
+	// ListRange lists up to limit resources of a type with keys from start
+	// up to, but not including, end. They're in key order, or reverse key
+	// order if descending. A limit of 0 lists all of them.
+	ListRange(t ResourceType, start, end string, descending bool, limit int) ([]Resource, error)
 	List() ([]Resource, error)
 	HasJob(ResourceID) (bool, error)
 	SetJob(ResourceID, string) error
-	SetStatus(ResourceID, pb.ResourceStatus) error
+	DeleteJob(ResourceID) error
+	SetStatus(ResourceID, *pb.ResourceStatus) error
 	SetSchedule(ResourceID, string) error
 }
 
@@ -261,14 +310,96 @@ func (wrapper SearchWrapper) Set(id ResourceID, res Resource) error {
 	if err := wrapper.ResourceLookup.Set(id, res); err != nil {
 		return err
 	}
-	doc := search.ResourceDoc{
-		Name:    id.Name,
-		Type:    id.Type.String(),
-		Variant: id.Variant,
+	return wrapper.Searcher.Upsert(SearchDocument(id, res))
+}
+
+func (wrapper SearchWrapper) SetIfRevision(id ResourceID, res Resource, revision int64) error {
+	if err := wrapper.ResourceLookup.SetIfRevision(id, res, revision); err != nil {
+		return err
+	}
+	return wrapper.Searcher.Upsert(SearchDocument(id, res))
+}
+
+// SetStatus reindexes the resource, since its status is a search field.
+func (wrapper SearchWrapper) SetStatus(id ResourceID, status *pb.ResourceStatus) error {
+	if err := wrapper.ResourceLookup.SetStatus(id, status); err != nil {
+		return err
+	}
+	res, err := wrapper.ResourceLookup.Lookup(id)
+	if err != nil {
+		return err
 	}
-	return wrapper.Searcher.Upsert(doc)
+	return wrapper.Searcher.Upsert(SearchDocument(id, res))
 }
 
+func (wrapper SearchWrapper) Delete(id ResourceID) error {
+	if err := wrapper.ResourceLookup.Delete(id); err != nil {
+		return err
+	}
+	return wrapper.Searcher.Delete(searchDocumentID(id))
+}
+
+// searchDocumentID has the fields of a resource's search document that
+// identify it.
+func searchDocumentID(id ResourceID) search.ResourceDoc {
+	return search.ResourceDoc{
+		Name:      id.Name,
+		Type:      id.Type.String(),
+		Variant:   id.Variant,
+		Namespace: id.Namespace,
+	}
+}
+
+// SearchDocument is what's indexed in search for res.
+func SearchDocument(id ResourceID, res Resource) search.ResourceDoc {
+	doc := searchDocumentID(id)
+	if tagged, ok := res.Proto().(interface{ GetTags() *pb.Tags }); ok {
+		doc.Tags = tagged.GetTags().GetTag()
+	}
+	if described, ok := res.Proto().(interface{ GetDescription() string }); ok {
+		doc.Description = described.GetDescription()
+	}
+	if properties, ok := res.Proto().(propertiesGetter); ok {
+		doc.Properties = fetchPropertiesFn{properties}.Properties()
+	}
+	fields := resourceListFields(res)
+	doc.Owner = fields.owner
+	doc.Provider = fields.provider
+	if resourceStatus(res) != nil {
+		doc.Status = fields.status.String()
+	}
+	return doc
+}
+
+// IndexResources upserts every resource in lookup into searcher, to fill an
+// index that doesn't persist across restarts or to bring documents indexed
+// by older versions up to date.
+func IndexResources(lookup ResourceLookup, searcher search.Searcher) error {
+	resources, err := lookup.List()
+	if err != nil {
+		return err
+	}
+	docs := make([]search.ResourceDoc, len(resources))
+	for i, res := range resources {
+		docs[i] = SearchDocument(res.ID(), res)
+	}
+	return searcher.UpsertAll(docs)
+}
+
+// IndexChange applies a watched change to a resource in lookup to searcher.
+func IndexChange(lookup ResourceLookup, searcher search.Searcher, event *pb.AuditEvent) error {
+	id := resourceIDFromProto(event.ResourceId)
+	if event.Action == pb.AuditEvent_DELETE {
+		return searcher.Delete(searchDocumentID(id))
+	}
+	res, err := lookup.Lookup(id)
+	if err != nil {
+		return err
+	}
+	return searcher.Upsert(SearchDocument(id, res))
+}
+
+// LocalResourceLookup counts revisions up from 1 for each resource.
 type LocalResourceLookup map[ResourceID]Resource
 
 func (lookup LocalResourceLookup) Lookup(id ResourceID) (Resource, error) {
@@ -285,10 +416,26 @@ func (lookup LocalResourceLookup) Has(id ResourceID) (bool, error) {
 }
 
 func (lookup LocalResourceLookup) Set(id ResourceID, res Resource) error {
+	setRevision(res, resourceRevision(lookup[id])+1)
 	lookup[id] = res
 	return nil
 }
 
+func (lookup LocalResourceLookup) SetIfRevision(id ResourceID, res Resource, revision int64) error {
+	if actual := resourceRevision(lookup[id]); actual != revision {
+		return &RevisionConflict{ID: id, Expected: revision, Actual: actual}
+	}
+	return lookup.Set(id, res)
+}
+
+func (lookup LocalResourceLookup) Delete(id ResourceID) error {
+	if _, has := lookup[id]; !has {
+		return &ResourceNotFound{id, nil}
+	}
+	delete(lookup, id)
+	return nil
+}
+
 func (lookup LocalResourceLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
 	resources := make(LocalResourceLookup, len(ids))
 	for _, id := range ids {
@@ -311,6 +458,25 @@ func (lookup LocalResourceLookup) ListForType(t ResourceType) ([]Resource, error
 	return resources, nil
 }
 
+func (lookup LocalResourceLookup) ListRange(t ResourceType, start, end string, descending bool, limit int) ([]Resource, error) {
+	resources := make([]Resource, 0)
+	for id, res := range lookup {
+		if key := createKey(id); id.Type == t && key >= start && key < end {
+			resources = append(resources, res)
+		}
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if descending {
+			i, j = j, i
+		}
+		return createKey(resources[i].ID()) < createKey(resources[j].ID())
+	})
+	if limit > 0 && len(resources) > limit {
+		resources = resources[:limit]
+	}
+	return resources, nil
+}
+
 func (lookup LocalResourceLookup) List() ([]Resource, error) {
 	resources := make([]Resource, 0, len(lookup))
 	for _, res := range lookup {
@@ -319,7 +485,7 @@ func (lookup LocalResourceLookup) List() ([]Resource, error) {
 	return resources, nil
 }
 
-func (lookup LocalResourceLookup) SetStatus(id ResourceID, status pb.ResourceStatus) error {
+func (lookup LocalResourceLookup) SetStatus(id ResourceID, status *pb.ResourceStatus) error {
 	res, has := lookup[id]
 	if !has {
 		return &ResourceNotFound{id, nil}
@@ -327,14 +493,17 @@ func (lookup LocalResourceLookup) SetStatus(id ResourceID, status pb.ResourceSta
 	if err := res.UpdateStatus(status); err != nil {
 		return err
 	}
-	lookup[id] = res
-	return nil
+	return lookup.Set(id, res)
 }
 
 func (lookup LocalResourceLookup) SetJob(id ResourceID, schedule string) error {
 	return nil
 }
 
+func (lookup LocalResourceLookup) DeleteJob(id ResourceID) error {
+	return nil
+}
+
 func (lookup LocalResourceLookup) SetSchedule(id ResourceID, schedule string) error {
 	res, has := lookup[id]
 	if !has {
@@ -343,8 +512,7 @@ func (lookup LocalResourceLookup) SetSchedule(id ResourceID, schedule string) er
 	if err := res.UpdateSchedule(schedule); err != nil {
 		return err
 	}
-	lookup[id] = res
-	return nil
+	return lookup.Set(id, res)
 }
 
 func (lookup LocalResourceLookup) HasJob(id ResourceID) (bool, error) {
@@ -357,8 +525,9 @@ type SourceResource struct {
 
 func (resource *SourceResource) ID() ResourceID {
 	return ResourceID{
-		Name: resource.serialized.Name,
-		Type: SOURCE,
+		Name:      resource.serialized.Name,
+		Type:      SOURCE,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -380,6 +549,10 @@ func (this *SourceResource) Notify(lookup ResourceLookup, op operation, that Res
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants, this.serialized.DefaultVariant = removeVariant(this.serialized.Variants, this.serialized.DefaultVariant, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -388,8 +561,8 @@ func (this *SourceResource) Notify(lookup ResourceLookup, op operation, that Res
 	return nil
 }
 
-func (resource *SourceResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *SourceResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -407,9 +580,10 @@ type sourceVariantResource struct {
 
 func (resource *sourceVariantResource) ID() ResourceID {
 	return ResourceID{
-		Name:    resource.serialized.Name,
-		Variant: resource.serialized.Variant,
-		Type:    SOURCE_VARIANT,
+		Name:      resource.serialized.Name,
+		Variant:   resource.serialized.Variant,
+		Type:      SOURCE_VARIANT,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -433,7 +607,7 @@ func (resource *sourceVariantResource) Dependencies(lookup ResourceLookup) (Reso
 			Type: SOURCE,
 		},
 	}
-	deps, err := lookup.Submap(depIds)
+	deps, err := lookup.Submap(inNamespace(depIds, serialized.Namespace))
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("could not create submap for IDs: %v", depIds))
 	}
@@ -451,18 +625,18 @@ func (this *sourceVariantResource) Notify(lookup ResourceLookup, op operation, t
 	serialized := this.serialized
 	switch t {
 	case TRAINING_SET_VARIANT:
-		serialized.Trainingsets = append(serialized.Trainingsets, key)
+		serialized.Trainingsets = updateNameVariants(op, serialized.Trainingsets, key)
 	case FEATURE_VARIANT:
-		serialized.Features = append(serialized.Features, key)
+		serialized.Features = updateNameVariants(op, serialized.Features, key)
 	case LABEL_VARIANT:
-		serialized.Labels = append(serialized.Labels, key)
+		serialized.Labels = updateNameVariants(op, serialized.Labels, key)
 	}
 	return nil
 }
 
-func (resource *sourceVariantResource) UpdateStatus(status pb.ResourceStatus) error {
+func (resource *sourceVariantResource) UpdateStatus(status *pb.ResourceStatus) error {
 	resource.serialized.LastUpdated = tspb.Now()
-	resource.serialized.Status = &status
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -488,8 +662,9 @@ type featureResource struct {
 
 func (resource *featureResource) ID() ResourceID {
 	return ResourceID{
-		Name: resource.serialized.Name,
-		Type: FEATURE,
+		Name:      resource.serialized.Name,
+		Type:      FEATURE,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -511,6 +686,10 @@ func (this *featureResource) Notify(lookup ResourceLookup, op operation, that Re
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants, this.serialized.DefaultVariant = removeVariant(this.serialized.Variants, this.serialized.DefaultVariant, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -519,8 +698,8 @@ func (this *featureResource) Notify(lookup ResourceLookup, op operation, that Re
 	return nil
 }
 
-func (resource *featureResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *featureResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -538,9 +717,10 @@ type featureVariantResource struct {
 
 func (resource *featureVariantResource) ID() ResourceID {
 	return ResourceID{
-		Name:    resource.serialized.Name,
-		Variant: resource.serialized.Variant,
-		Type:    FEATURE_VARIANT,
+		Name:      resource.serialized.Name,
+		Variant:   resource.serialized.Variant,
+		Type:      FEATURE_VARIANT,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -575,7 +755,7 @@ func (resource *featureVariantResource) Dependencies(lookup ResourceLookup) (Res
 				Type: PROVIDER,
 			})
 	}
-	deps, err := lookup.Submap(depIds)
+	deps, err := lookup.Submap(inNamespace(depIds, serialized.Namespace))
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("could not create submap for IDs: %v", depIds))
 	}
@@ -587,22 +767,25 @@ func (resource *featureVariantResource) Proto() proto.Message {
 }
 
 func (this *featureVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	id := that.ID()
+	key := id.Proto()
+	if id.Type == FEATURE_GROUP_VARIANT {
+		this.serialized.FeatureGroups = updateNameVariants(op, this.serialized.FeatureGroups, key)
+		return nil
+	}
 	if !PRECOMPUTED.Equals(this.serialized.Mode) {
 		return nil
 	}
-	id := that.ID()
-	relevantOp := op == create_op && id.Type == TRAINING_SET_VARIANT
-	if !relevantOp {
+	if id.Type != TRAINING_SET_VARIANT {
 		return nil
 	}
-	key := id.Proto()
-	this.serialized.Trainingsets = append(this.serialized.Trainingsets, key)
+	this.serialized.Trainingsets = updateNameVariants(op, this.serialized.Trainingsets, key)
 	return nil
 }
 
-func (resource *featureVariantResource) UpdateStatus(status pb.ResourceStatus) error {
+func (resource *featureVariantResource) UpdateStatus(status *pb.ResourceStatus) error {
 	resource.serialized.LastUpdated = tspb.Now()
-	resource.serialized.Status = &status
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -628,8 +811,9 @@ type labelResource struct {
 
 func (resource *labelResource) ID() ResourceID {
 	return ResourceID{
-		Name: resource.serialized.Name,
-		Type: LABEL,
+		Name:      resource.serialized.Name,
+		Type:      LABEL,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -651,6 +835,10 @@ func (this *labelResource) Notify(lookup ResourceLookup, op operation, that Reso
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants, this.serialized.DefaultVariant = removeVariant(this.serialized.Variants, this.serialized.DefaultVariant, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -659,8 +847,8 @@ func (this *labelResource) Notify(lookup ResourceLookup, op operation, that Reso
 	return nil
 }
 
-func (resource *labelResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *labelResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -678,9 +866,10 @@ type labelVariantResource struct {
 
 func (resource *labelVariantResource) ID() ResourceID {
 	return ResourceID{
-		Name:    resource.serialized.Name,
-		Variant: resource.serialized.Variant,
-		Type:    LABEL_VARIANT,
+		Name:      resource.serialized.Name,
+		Variant:   resource.serialized.Variant,
+		Type:      LABEL_VARIANT,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -713,7 +902,7 @@ func (resource *labelVariantResource) Dependencies(lookup ResourceLookup) (Resou
 			Type: LABEL,
 		},
 	}
-	deps, err := lookup.Submap(depIds)
+	deps, err := lookup.Submap(inNamespace(depIds, serialized.Namespace))
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("could not create submap for IDs: %v", depIds))
 	}
@@ -726,17 +915,16 @@ func (resource *labelVariantResource) Proto() proto.Message {
 
 func (this *labelVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
 	id := that.ID()
-	releventOp := op == create_op && id.Type == TRAINING_SET_VARIANT
-	if !releventOp {
+	if id.Type != TRAINING_SET_VARIANT {
 		return nil
 	}
 	key := id.Proto()
-	this.serialized.Trainingsets = append(this.serialized.Trainingsets, key)
+	this.serialized.Trainingsets = updateNameVariants(op, this.serialized.Trainingsets, key)
 	return nil
 }
 
-func (resource *labelVariantResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *labelVariantResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -761,8 +949,9 @@ type trainingSetResource struct {
 
 func (resource *trainingSetResource) ID() ResourceID {
 	return ResourceID{
-		Name: resource.serialized.Name,
-		Type: TRAINING_SET,
+		Name:      resource.serialized.Name,
+		Type:      TRAINING_SET,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -784,6 +973,10 @@ func (this *trainingSetResource) Notify(lookup ResourceLookup, op operation, tha
 	if !isVariant {
 		return nil
 	}
+	if op == delete_op {
+		this.serialized.Variants, this.serialized.DefaultVariant = removeVariant(this.serialized.Variants, this.serialized.DefaultVariant, otherId.Variant)
+		return nil
+	}
 	if slices.Contains(this.serialized.Variants, otherId.Variant) {
 		fmt.Printf("source %s already has variant %s\n", this.serialized.Name, otherId.Variant)
 		return nil
@@ -792,8 +985,8 @@ func (this *trainingSetResource) Notify(lookup ResourceLookup, op operation, tha
 	return nil
 }
 
-func (resource *trainingSetResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *trainingSetResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -811,9 +1004,10 @@ type trainingSetVariantResource struct {
 
 func (resource *trainingSetVariantResource) ID() ResourceID {
 	return ResourceID{
-		Name:    resource.serialized.Name,
-		Variant: resource.serialized.Variant,
-		Type:    TRAINING_SET_VARIANT,
+		Name:      resource.serialized.Name,
+		Variant:   resource.serialized.Variant,
+		Type:      TRAINING_SET_VARIANT,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -849,7 +1043,14 @@ func (resource *trainingSetVariantResource) Dependencies(lookup ResourceLookup)
 			Type:    FEATURE_VARIANT,
 		})
 	}
-	deps, err := lookup.Submap(depIds)
+	for _, group := range serialized.FeatureGroups {
+		depIds = append(depIds, ResourceID{
+			Name:    group.Name,
+			Variant: group.Variant,
+			Type:    FEATURE_GROUP_VARIANT,
+		})
+	}
+	deps, err := lookup.Submap(inNamespace(depIds, serialized.Namespace))
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("could not create submap for IDs: %v", depIds))
 	}
@@ -861,12 +1062,18 @@ func (resource *trainingSetVariantResource) Proto() proto.Message {
 }
 
 func (this *trainingSetVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	id := that.ID()
+	if id.Type != MODEL_VARIANT {
+		return nil
+	}
+	key := id.Proto()
+	this.serialized.Models = updateNameVariants(op, this.serialized.Models, key)
 	return nil
 }
 
-func (resource *trainingSetVariantResource) UpdateStatus(status pb.ResourceStatus) error {
+func (resource *trainingSetVariantResource) UpdateStatus(status *pb.ResourceStatus) error {
 	resource.serialized.LastUpdated = tspb.Now()
-	resource.serialized.Status = &status
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -892,8 +1099,9 @@ type modelResource struct {
 
 func (resource *modelResource) ID() ResourceID {
 	return ResourceID{
-		Name: resource.serialized.Name,
-		Type: MODEL,
+		Name:      resource.serialized.Name,
+		Type:      MODEL,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -925,7 +1133,14 @@ func (resource *modelResource) Dependencies(lookup ResourceLookup) (ResourceLook
 			Type:    TRAINING_SET_VARIANT,
 		})
 	}
-	deps, err := lookup.Submap(depIds)
+	for _, group := range serialized.FeatureGroups {
+		depIds = append(depIds, ResourceID{
+			Name:    group.Name,
+			Variant: group.Variant,
+			Type:    FEATURE_GROUP_VARIANT,
+		})
+	}
+	deps, err := lookup.Submap(inNamespace(depIds, serialized.Namespace))
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("could not create submap for IDs: %v", depIds))
 	}
@@ -937,10 +1152,27 @@ func (resource *modelResource) Proto() proto.Message {
 }
 
 func (this *modelResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	otherId := that.ID()
+	isVariant := otherId.Type == MODEL_VARIANT && otherId.Name == this.serialized.Name
+	if !isVariant {
+		return nil
+	}
+	if op == delete_op {
+		this.serialized.Variants, this.serialized.DefaultVariant = removeVariant(this.serialized.Variants, this.serialized.DefaultVariant, otherId.Variant)
+		return nil
+	}
+	if slices.Contains(this.serialized.Variants, otherId.Variant) {
+		return nil
+	}
+	this.serialized.Variants = append(this.serialized.Variants, otherId.Variant)
+	// Models registered by name before their first variant have no default.
+	if this.serialized.DefaultVariant == "" {
+		this.serialized.DefaultVariant = otherId.Variant
+	}
 	return nil
 }
 
-func (resource *modelResource) UpdateStatus(status pb.ResourceStatus) error {
+func (resource *modelResource) UpdateStatus(status *pb.ResourceStatus) error {
 	return nil
 }
 
@@ -956,11 +1188,241 @@ func (resource *modelResource) Update(lookup ResourceLookup, updateRes Resource)
 	}
 	resource.serialized.Features = unionNameVariants(resource.serialized.Features, modelUpdate.Features)
 	resource.serialized.Trainingsets = unionNameVariants(resource.serialized.Trainingsets, modelUpdate.Trainingsets)
+	resource.serialized.FeatureGroups = unionNameVariants(resource.serialized.FeatureGroups, modelUpdate.FeatureGroups)
 	resource.serialized.Tags = UnionTags(resource.serialized.Tags, modelUpdate.Tags)
 	resource.serialized.Properties = mergeProperties(resource.serialized.Properties, modelUpdate.Properties)
 	return nil
 }
 
+type modelVariantResource struct {
+	serialized *pb.ModelVariant
+}
+
+func (resource *modelVariantResource) ID() ResourceID {
+	return ResourceID{
+		Name:      resource.serialized.Name,
+		Variant:   resource.serialized.Variant,
+		Type:      MODEL_VARIANT,
+		Namespace: resource.serialized.Namespace,
+	}
+}
+
+func (resource *modelVariantResource) Schedule() string {
+	return ""
+}
+
+func (resource *modelVariantResource) Dependencies(lookup ResourceLookup) (ResourceLookup, error) {
+	serialized := resource.serialized
+	depIds := []ResourceID{
+		{
+			Name: serialized.Owner,
+			Type: USER,
+		},
+		{
+			Name: serialized.Name,
+			Type: MODEL,
+		},
+		{
+			Name:    serialized.GetTrainingSet().GetName(),
+			Variant: serialized.GetTrainingSet().GetVariant(),
+			Type:    TRAINING_SET_VARIANT,
+		},
+		{
+			Name:    serialized.GetLabel().GetName(),
+			Variant: serialized.GetLabel().GetVariant(),
+			Type:    LABEL_VARIANT,
+		},
+	}
+	for _, feature := range serialized.Features {
+		depIds = append(depIds, ResourceID{
+			Name:    feature.Name,
+			Variant: feature.Variant,
+			Type:    FEATURE_VARIANT,
+		})
+	}
+	for _, group := range serialized.FeatureGroups {
+		depIds = append(depIds, ResourceID{
+			Name:    group.Name,
+			Variant: group.Variant,
+			Type:    FEATURE_GROUP_VARIANT,
+		})
+	}
+	deps, err := lookup.Submap(inNamespace(depIds, serialized.Namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not create submap for IDs: %v", depIds))
+	}
+	return deps, nil
+}
+
+func (resource *modelVariantResource) Proto() proto.Message {
+	return resource.serialized
+}
+
+func (this *modelVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	return nil
+}
+
+func (resource *modelVariantResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
+	return nil
+}
+
+func (resource *modelVariantResource) UpdateSchedule(schedule string) error {
+	return fmt.Errorf("not implemented")
+}
+
+// Update only changes tags and properties. A variant can't move to another
+// training set, since it's what the variant was trained on.
+func (resource *modelVariantResource) Update(lookup ResourceLookup, updateRes Resource) error {
+	deserialized := updateRes.Proto()
+	variantUpdate, ok := deserialized.(*pb.ModelVariant)
+	if !ok {
+		return errors.New("failed to deserialize existing model variant record")
+	}
+	current, update := resource.serialized.GetTrainingSet(), variantUpdate.GetTrainingSet()
+	if current.GetName() != update.GetName() || current.GetVariant() != update.GetVariant() {
+		return &ResourceExists{updateRes.ID()}
+	}
+	resource.serialized.Tags = UnionTags(resource.serialized.Tags, variantUpdate.Tags)
+	resource.serialized.Properties = mergeProperties(resource.serialized.Properties, variantUpdate.Properties)
+	return nil
+}
+
+type featureGroupResource struct {
+	serialized *pb.FeatureGroup
+}
+
+func (resource *featureGroupResource) ID() ResourceID {
+	return ResourceID{
+		Name:      resource.serialized.Name,
+		Type:      FEATURE_GROUP,
+		Namespace: resource.serialized.Namespace,
+	}
+}
+
+func (resource *featureGroupResource) Schedule() string {
+	return ""
+}
+
+func (resource *featureGroupResource) Dependencies(lookup ResourceLookup) (ResourceLookup, error) {
+	return make(LocalResourceLookup), nil
+}
+
+func (resource *featureGroupResource) Proto() proto.Message {
+	return resource.serialized
+}
+
+func (this *featureGroupResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	otherId := that.ID()
+	isVariant := otherId.Type == FEATURE_GROUP_VARIANT && otherId.Name == this.serialized.Name
+	if !isVariant {
+		return nil
+	}
+	if op == delete_op {
+		this.serialized.Variants, this.serialized.DefaultVariant = removeVariant(this.serialized.Variants, this.serialized.DefaultVariant, otherId.Variant)
+		return nil
+	}
+	if slices.Contains(this.serialized.Variants, otherId.Variant) {
+		return nil
+	}
+	this.serialized.Variants = append(this.serialized.Variants, otherId.Variant)
+	return nil
+}
+
+func (resource *featureGroupResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
+	return nil
+}
+
+func (resource *featureGroupResource) UpdateSchedule(schedule string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (resource *featureGroupResource) Update(lookup ResourceLookup, updateRes Resource) error {
+	return &ResourceExists{updateRes.ID()}
+}
+
+type featureGroupVariantResource struct {
+	serialized *pb.FeatureGroupVariant
+}
+
+func (resource *featureGroupVariantResource) ID() ResourceID {
+	return ResourceID{
+		Name:      resource.serialized.Name,
+		Variant:   resource.serialized.Variant,
+		Type:      FEATURE_GROUP_VARIANT,
+		Namespace: resource.serialized.Namespace,
+	}
+}
+
+func (resource *featureGroupVariantResource) Schedule() string {
+	return ""
+}
+
+func (resource *featureGroupVariantResource) Dependencies(lookup ResourceLookup) (ResourceLookup, error) {
+	serialized := resource.serialized
+	depIds := []ResourceID{
+		{
+			Name: serialized.Owner,
+			Type: USER,
+		},
+		{
+			Name: serialized.Entity,
+			Type: ENTITY,
+		},
+		{
+			Name: serialized.Name,
+			Type: FEATURE_GROUP,
+		},
+	}
+	for _, feature := range serialized.Features {
+		depIds = append(depIds, ResourceID{
+			Name:    feature.Name,
+			Variant: feature.Variant,
+			Type:    FEATURE_VARIANT,
+		})
+	}
+	deps, err := lookup.Submap(inNamespace(depIds, serialized.Namespace))
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not create submap for IDs: %v", depIds))
+	}
+	return deps, nil
+}
+
+func (resource *featureGroupVariantResource) Proto() proto.Message {
+	return resource.serialized
+}
+
+func (this *featureGroupVariantResource) Notify(lookup ResourceLookup, op operation, that Resource) error {
+	id := that.ID()
+	if id.Type != TRAINING_SET_VARIANT {
+		return nil
+	}
+	key := id.Proto()
+	this.serialized.Trainingsets = updateNameVariants(op, this.serialized.Trainingsets, key)
+	return nil
+}
+
+func (resource *featureGroupVariantResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
+	return nil
+}
+
+func (resource *featureGroupVariantResource) UpdateSchedule(schedule string) error {
+	return fmt.Errorf("not implemented")
+}
+
+func (resource *featureGroupVariantResource) Update(lookup ResourceLookup, updateRes Resource) error {
+	deserialized := updateRes.Proto()
+	variantUpdate, ok := deserialized.(*pb.FeatureGroupVariant)
+	if !ok {
+		return errors.New("failed to deserialize existing feature group variant record")
+	}
+	resource.serialized.Tags = UnionTags(resource.serialized.Tags, variantUpdate.Tags)
+	resource.serialized.Properties = mergeProperties(resource.serialized.Properties, variantUpdate.Properties)
+	return nil
+}
+
 type userResource struct {
 	serialized *pb.User
 }
@@ -996,19 +1458,19 @@ func (this *userResource) Notify(lookup ResourceLookup, op operation, that Resou
 	serialized := this.serialized
 	switch t {
 	case TRAINING_SET_VARIANT:
-		serialized.Trainingsets = append(serialized.Trainingsets, key)
+		serialized.Trainingsets = updateNameVariants(op, serialized.Trainingsets, key)
 	case FEATURE_VARIANT:
-		serialized.Features = append(serialized.Features, key)
+		serialized.Features = updateNameVariants(op, serialized.Features, key)
 	case LABEL_VARIANT:
-		serialized.Labels = append(serialized.Labels, key)
+		serialized.Labels = updateNameVariants(op, serialized.Labels, key)
 	case SOURCE_VARIANT:
-		serialized.Sources = append(serialized.Sources, key)
+		serialized.Sources = updateNameVariants(op, serialized.Sources, key)
 	}
 	return nil
 }
 
-func (resource *userResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *userResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -1024,6 +1486,9 @@ func (resource *userResource) Update(lookup ResourceLookup, updateRes Resource)
 	}
 	resource.serialized.Tags = UnionTags(resource.serialized.Tags, userUpdate.Tags)
 	resource.serialized.Properties = mergeProperties(resource.serialized.Properties, userUpdate.Properties)
+	if len(userUpdate.Notifications) > 0 {
+		resource.serialized.Notifications = userUpdate.Notifications
+	}
 	return nil
 }
 
@@ -1062,19 +1527,19 @@ func (this *providerResource) Notify(lookup ResourceLookup, op operation, that R
 	serialized := this.serialized
 	switch t {
 	case SOURCE_VARIANT:
-		serialized.Sources = append(serialized.Sources, key)
+		serialized.Sources = updateNameVariants(op, serialized.Sources, key)
 	case FEATURE_VARIANT:
-		serialized.Features = append(serialized.Features, key)
+		serialized.Features = updateNameVariants(op, serialized.Features, key)
 	case TRAINING_SET_VARIANT:
-		serialized.Trainingsets = append(serialized.Trainingsets, key)
+		serialized.Trainingsets = updateNameVariants(op, serialized.Trainingsets, key)
 	case LABEL_VARIANT:
-		serialized.Labels = append(serialized.Labels, key)
+		serialized.Labels = updateNameVariants(op, serialized.Labels, key)
 	}
 	return nil
 }
 
-func (resource *providerResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *providerResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -1102,33 +1567,40 @@ func (resource *providerResource) Update(lookup ResourceLookup, resourceUpdate R
 }
 
 func (resource *providerResource) isValidConfigUpdate(configUpdate pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(resource.configDiff(configUpdate))
+}
+
+// configDiff returns the config fields that an update changes and the fields
+// that are allowed to change. Providers without config diffs return nil sets
+// and can be changed freely.
+func (resource *providerResource) configDiff(configUpdate pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	switch pt.Type(resource.serialized.Type) {
 	case pt.BigQueryOffline:
-		return isValidBigQueryConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return bigQueryConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.CassandraOnline:
-		return isValidCassandraConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return cassandraConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.DynamoDBOnline:
-		return isValidDynamoConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return dynamoConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.FirestoreOnline:
-		return isValidFirestoreConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return firestoreConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.MongoDBOnline:
-		return isValidMongoConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return mongoConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.PostgresOffline:
-		return isValidPostgresConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return postgresConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.RedisOnline:
-		return isValidRedisConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return redisConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.SnowflakeOffline:
-		return isValidSnowflakeConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return snowflakeConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.RedshiftOffline:
-		return isValidRedshiftConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return redshiftConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.K8sOffline:
-		return isValidK8sConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return k8sConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.SparkOffline:
-		return isValidSparkConfigUpdate(resource.serialized.SerializedConfig, configUpdate)
+		return sparkConfigDiff(resource.serialized.SerializedConfig, configUpdate)
 	case pt.S3, pt.HDFS, pt.GCS, pt.AZURE, pt.BlobOnline:
-		return true, nil
+		return nil, nil, nil
 	default:
-		return false, fmt.Errorf("unable to update config for provider. Provider type %s not found", resource.serialized.Type)
+		return nil, nil, fmt.Errorf("unable to update config for provider. Provider type %s not found", resource.serialized.Type)
 	}
 }
 
@@ -1138,8 +1610,9 @@ type entityResource struct {
 
 func (resource *entityResource) ID() ResourceID {
 	return ResourceID{
-		Name: resource.serialized.Name,
-		Type: ENTITY,
+		Name:      resource.serialized.Name,
+		Type:      ENTITY,
+		Namespace: resource.serialized.Namespace,
 	}
 }
 
@@ -1162,17 +1635,17 @@ func (this *entityResource) Notify(lookup ResourceLookup, op operation, that Res
 	serialized := this.serialized
 	switch t {
 	case TRAINING_SET_VARIANT:
-		serialized.Trainingsets = append(serialized.Trainingsets, key)
+		serialized.Trainingsets = updateNameVariants(op, serialized.Trainingsets, key)
 	case FEATURE_VARIANT:
-		serialized.Features = append(serialized.Features, key)
+		serialized.Features = updateNameVariants(op, serialized.Features, key)
 	case LABEL_VARIANT:
-		serialized.Labels = append(serialized.Labels, key)
+		serialized.Labels = updateNameVariants(op, serialized.Labels, key)
 	}
 	return nil
 }
 
-func (resource *entityResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.Status = &status
+func (resource *entityResource) UpdateStatus(status *pb.ResourceStatus) error {
+	resource.serialized.Status = status
 	return nil
 }
 
@@ -1197,6 +1670,10 @@ type MetadataServer struct {
 	address    string
 	grpcServer *grpc.Server
 	listener   net.Listener
+	cleaner    ResourceCleaner
+	auditLog   AuditLog
+	policy     Policy
+	notifier   *notify.Notifier
 	pb.UnimplementedMetadataServer
 }
 
@@ -1207,20 +1684,42 @@ func NewMetadataServer(config *Config) (*MetadataServer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not configure storage provider: %v", err)
 	}
-	if config.SearchParams != nil {
+	auditLog, err := config.StorageProvider.GetAuditLog()
+	if err != nil {
+		return nil, fmt.Errorf("could not configure audit log: %v", err)
+	}
+	if config.EmbeddedSearch {
+		searcher := search.NewEmbeddedSearch()
+		if err := IndexResources(lookup, searcher); err != nil {
+			return nil, fmt.Errorf("could not index resources: %v", err)
+		}
+		lookup = &SearchWrapper{
+			Searcher:       searcher,
+			ResourceLookup: lookup,
+		}
+	} else if config.SearchParams != nil {
 		searcher, errInitializeSearch := search.NewMeilisearch(config.SearchParams)
 		if errInitializeSearch != nil {
 			return nil, errInitializeSearch
 		}
+		// Documents indexed before a field was added don't have it, so they'd
+		// never match queries filtering on it.
+		if err := IndexResources(lookup, searcher); err != nil {
+			return nil, fmt.Errorf("could not reindex resources: %v", err)
+		}
 		lookup = &SearchWrapper{
 			Searcher:       searcher,
 			ResourceLookup: lookup,
 		}
 	}
 	return &MetadataServer{
-		lookup:  lookup,
-		address: config.Address,
-		Logger:  config.Logger,
+		lookup:   lookup,
+		address:  config.Address,
+		Logger:   config.Logger,
+		cleaner:  config.ResourceCleaner,
+		policy:   config.Policy,
+		notifier: config.Notifier,
+		auditLog: auditLog,
 	}, nil
 }
 
@@ -1266,6 +1765,30 @@ func (serv *MetadataServer) Stop() error {
 
 type StorageProvider interface {
 	GetResourceLookup() (ResourceLookup, error)
+	GetAuditLog() (AuditLog, error)
+}
+
+// JobStore is where the coordinator finds the jobs that the metadata server
+// sets, and claims them so that each is run by one coordinator at a time.
+type JobStore interface {
+	// Watch calls fn with each job whose key has prefix, and again each time
+	// one is put, until ctx is done.
+	Watch(ctx context.Context, prefix string, fn func(key, value string)) error
+	// Has reports whether any job's key has prefix.
+	Has(prefix string) (bool, error)
+	// Lock claims the job at key, waiting for whoever has it to unlock it.
+	Lock(key string) (JobLock, error)
+}
+
+// JobLock reads and writes a claimed job. Each of them fails if the claim
+// has been lost.
+type JobLock interface {
+	// Get returns the job, or false if it doesn't exist.
+	Get() (string, bool, error)
+	Put(value string) error
+	// Delete returns false if the job was already deleted.
+	Delete() (bool, error)
+	Unlock() error
 }
 
 type LocalStorageProvider struct {
@@ -1276,6 +1799,10 @@ func (sp LocalStorageProvider) GetResourceLookup() (ResourceLookup, error) {
 	return lookup, nil
 }
 
+func (sp LocalStorageProvider) GetAuditLog() (AuditLog, error) {
+	return NewLocalAuditLog(), nil
+}
+
 type EtcdStorageProvider struct {
 	Config EtcdConfig
 }
@@ -1294,42 +1821,79 @@ func (sp EtcdStorageProvider) GetResourceLookup() (ResourceLookup, error) {
 	return lookup, nil
 }
 
+func (sp EtcdStorageProvider) GetAuditLog() (AuditLog, error) {
+	client, err := sp.Config.InitClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not init etcd client: %v", err)
+	}
+	return EtcdAuditLog{Connection: EtcdStorage{Client: client}}, nil
+}
+
 type Config struct {
-	Logger          *zap.SugaredLogger
-	SearchParams    *search.MeilisearchParams
+	Logger       *zap.SugaredLogger
+	SearchParams *search.MeilisearchParams
+	// EmbeddedSearch indexes resources in memory instead of in Meilisearch.
+	// The index is rebuilt from storage on startup.
+	EmbeddedSearch  bool
 	StorageProvider StorageProvider
 	Address         string
+	// ResourceCleaner removes provider data when a delete asks for cleanup.
+	ResourceCleaner ResourceCleaner
+	// Policy checks resources before they're created, if set.
+	Policy Policy
+	// Notifier tells owners about their resources failing and recovering, if
+	// set.
+	Notifier *notify.Notifier
 }
 
 func (serv *MetadataServer) RequestScheduleChange(ctx context.Context, req *pb.ScheduleChangeRequest) (*pb.Empty, error) {
-	resID := ResourceID{Name: req.ResourceId.Resource.Name, Variant: req.ResourceId.Resource.Variant, Type: ResourceType(req.ResourceId.ResourceType)}
-	err := serv.lookup.SetSchedule(resID, req.Schedule)
-	return &pb.Empty{}, err
+	resID := resourceIDFromProto(req.ResourceId)
+	res, err := serv.lookup.Lookup(resID)
+	if err != nil {
+		return nil, err
+	}
+	oldSchedule := res.Schedule()
+	if err := serv.lookup.SetSchedule(resID, req.Schedule); err != nil {
+		return nil, err
+	}
+	oldValue, _ := json.Marshal(oldSchedule)
+	newValue, _ := json.Marshal(req.Schedule)
+	serv.recordAuditEvent(ctx, pb.AuditEvent_SCHEDULE_CHANGE, resID, []*pb.FieldChange{
+		{Field: "schedule", OldValue: string(oldValue), NewValue: string(newValue)},
+	})
+	return &pb.Empty{}, nil
 }
 
 func (serv *MetadataServer) SetResourceStatus(ctx context.Context, req *pb.SetStatusRequest) (*pb.Empty, error) {
 	serv.Logger.Infow("Setting resource status", "request", req.String())
-	resID := ResourceID{Name: req.ResourceId.Resource.Name, Variant: req.ResourceId.Resource.Variant, Type: ResourceType(req.ResourceId.ResourceType)}
-	err := serv.lookup.SetStatus(resID, *req.Status)
+	resID := resourceIDFromProto(req.ResourceId)
+	before, after, err := updateResource(serv.lookup, resID, req.ExpectedRevision, func(res Resource) error {
+		return res.UpdateStatus(nextStatus(resourceStatus(res), req.Status))
+	})
 	if err != nil {
 		serv.Logger.Errorw("Could not set resource status", "error", err.Error())
+		return &pb.Empty{}, err
 	}
-
-	return &pb.Empty{}, err
+	serv.audit(ctx, pb.AuditEvent_STATUS_CHANGE, resID, before, after.Proto())
+	serv.notifyOwner(after, before)
+	return &pb.Empty{}, nil
 }
 
-func (serv *MetadataServer) ListFeatures(_ *pb.Empty, stream pb.Metadata_ListFeaturesServer) error {
-	return serv.genericList(FEATURE, func(msg proto.Message) error {
+func (serv *MetadataServer) ListFeatures(req *pb.ListRequest, stream pb.Metadata_ListFeaturesServer) error {
+	return serv.genericList(stream, FEATURE, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Feature))
 	})
 }
 
 func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, variant *pb.FeatureVariant) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
-	return serv.genericCreate(ctx, &featureVariantResource{variant}, func(name, variant string) Resource {
+	// Variants start out active and move through their lifecycle with SetLifecycle.
+	variant.Lifecycle = nil
+	return serv.genericCreate(ctx, &featureVariantResource{variant}, func(namespace, name, variant string) Resource {
 		return &featureResource{
 			&pb.Feature{
 				Name:           name,
+				Namespace:      namespace,
 				DefaultVariant: variant,
 				// This will be set when the change is propagated to dependencies.
 				Variants: []string{},
@@ -1350,18 +1914,19 @@ func (serv *MetadataServer) GetFeatureVariants(stream pb.Metadata_GetFeatureVari
 	})
 }
 
-func (serv *MetadataServer) ListLabels(_ *pb.Empty, stream pb.Metadata_ListLabelsServer) error {
-	return serv.genericList(LABEL, func(msg proto.Message) error {
+func (serv *MetadataServer) ListLabels(req *pb.ListRequest, stream pb.Metadata_ListLabelsServer) error {
+	return serv.genericList(stream, LABEL, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Label))
 	})
 }
 
 func (serv *MetadataServer) CreateLabelVariant(ctx context.Context, variant *pb.LabelVariant) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
-	return serv.genericCreate(ctx, &labelVariantResource{variant}, func(name, variant string) Resource {
+	return serv.genericCreate(ctx, &labelVariantResource{variant}, func(namespace, name, variant string) Resource {
 		return &labelResource{
 			&pb.Label{
 				Name:           name,
+				Namespace:      namespace,
 				DefaultVariant: variant,
 				// This will be set when the change is propagated to dependencies.
 				Variants: []string{},
@@ -1382,18 +1947,30 @@ func (serv *MetadataServer) GetLabelVariants(stream pb.Metadata_GetLabelVariants
 	})
 }
 
-func (serv *MetadataServer) ListTrainingSets(_ *pb.Empty, stream pb.Metadata_ListTrainingSetsServer) error {
-	return serv.genericList(TRAINING_SET, func(msg proto.Message) error {
+func (serv *MetadataServer) ListTrainingSets(req *pb.ListRequest, stream pb.Metadata_ListTrainingSetsServer) error {
+	return serv.genericList(stream, TRAINING_SET, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.TrainingSet))
 	})
 }
 
 func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, variant *pb.TrainingSetVariant) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
-	return serv.genericCreate(ctx, &trainingSetVariantResource{variant}, func(name, variant string) Resource {
+	if err := qualifyReferences(&trainingSetVariantResource{variant}); err != nil {
+		return nil, err
+	}
+	if err := expandFeatureGroups(serv.lookup, variant); err != nil {
+		return nil, err
+	}
+	if err := serv.checkTrainingSetFeatures(variant); err != nil {
+		return nil, err
+	}
+	variant.AllowDeprecated = false
+	variant.Lifecycle = nil
+	return serv.genericCreate(ctx, &trainingSetVariantResource{variant}, func(namespace, name, variant string) Resource {
 		return &trainingSetResource{
 			&pb.TrainingSet{
 				Name:           name,
+				Namespace:      namespace,
 				DefaultVariant: variant,
 				// This will be set when the change is propagated to dependencies.
 				Variants: []string{},
@@ -1414,18 +1991,19 @@ func (serv *MetadataServer) GetTrainingSetVariants(stream pb.Metadata_GetTrainin
 	})
 }
 
-func (serv *MetadataServer) ListSources(_ *pb.Empty, stream pb.Metadata_ListSourcesServer) error {
-	return serv.genericList(SOURCE, func(msg proto.Message) error {
+func (serv *MetadataServer) ListSources(req *pb.ListRequest, stream pb.Metadata_ListSourcesServer) error {
+	return serv.genericList(stream, SOURCE, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Source))
 	})
 }
 
 func (serv *MetadataServer) CreateSourceVariant(ctx context.Context, variant *pb.SourceVariant) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
-	return serv.genericCreate(ctx, &sourceVariantResource{variant}, func(name, variant string) Resource {
+	return serv.genericCreate(ctx, &sourceVariantResource{variant}, func(namespace, name, variant string) Resource {
 		return &SourceResource{
 			&pb.Source{
 				Name:           name,
+				Namespace:      namespace,
 				DefaultVariant: variant,
 				// This will be set when the change is propagated to dependencies.
 				Variants: []string{},
@@ -1447,13 +2025,16 @@ func (serv *MetadataServer) GetSourceVariants(stream pb.Metadata_GetSourceVarian
 	})
 }
 
-func (serv *MetadataServer) ListUsers(_ *pb.Empty, stream pb.Metadata_ListUsersServer) error {
-	return serv.genericList(USER, func(msg proto.Message) error {
+func (serv *MetadataServer) ListUsers(req *pb.ListRequest, stream pb.Metadata_ListUsersServer) error {
+	return serv.genericList(stream, USER, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.User))
 	})
 }
 
 func (serv *MetadataServer) CreateUser(ctx context.Context, user *pb.User) (*pb.Empty, error) {
+	if err := validateSubscriptions(user.Notifications); err != nil {
+		return nil, err
+	}
 	return serv.genericCreate(ctx, &userResource{user}, nil)
 }
 
@@ -1463,8 +2044,8 @@ func (serv *MetadataServer) GetUsers(stream pb.Metadata_GetUsersServer) error {
 	})
 }
 
-func (serv *MetadataServer) ListProviders(_ *pb.Empty, stream pb.Metadata_ListProvidersServer) error {
-	return serv.genericList(PROVIDER, func(msg proto.Message) error {
+func (serv *MetadataServer) ListProviders(req *pb.ListRequest, stream pb.Metadata_ListProvidersServer) error {
+	return serv.genericList(stream, PROVIDER, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Provider))
 	})
 }
@@ -1479,8 +2060,8 @@ func (serv *MetadataServer) GetProviders(stream pb.Metadata_GetProvidersServer)
 	})
 }
 
-func (serv *MetadataServer) ListEntities(_ *pb.Empty, stream pb.Metadata_ListEntitiesServer) error {
-	return serv.genericList(ENTITY, func(msg proto.Message) error {
+func (serv *MetadataServer) ListEntities(req *pb.ListRequest, stream pb.Metadata_ListEntitiesServer) error {
+	return serv.genericList(stream, ENTITY, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Entity))
 	})
 }
@@ -1495,22 +2076,167 @@ func (serv *MetadataServer) GetEntities(stream pb.Metadata_GetEntitiesServer) er
 	})
 }
 
-func (serv *MetadataServer) ListModels(_ *pb.Empty, stream pb.Metadata_ListModelsServer) error {
-	return serv.genericList(MODEL, func(msg proto.Message) error {
+func (serv *MetadataServer) ListModels(req *pb.ListRequest, stream pb.Metadata_ListModelsServer) error {
+	return serv.genericList(stream, MODEL, req, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Model))
 	})
 }
 
 func (serv *MetadataServer) CreateModel(ctx context.Context, model *pb.Model) (*pb.Empty, error) {
+	// Variants are set when they're created.
+	model.Variants = nil
+	model.DefaultVariant = ""
 	return serv.genericCreate(ctx, &modelResource{model}, nil)
 }
 
+// CreateModelVariant creates a variant of a model trained on a training set
+// variant. Its features, label and feature groups are copied from the
+// training set, and its owner defaults to the training set's.
+func (serv *MetadataServer) CreateModelVariant(ctx context.Context, variant *pb.ModelVariant) (*pb.Empty, error) {
+	variant.Created = tspb.New(time.Now())
+	// Model variants don't have jobs, so they're ready as soon as they're created.
+	variant.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_READY}
+	if variant.GetTrainingSet().GetName() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "model %s variant %s has no training set", variant.Name, variant.Variant)
+	}
+	if err := qualifyReferences(&modelVariantResource{variant}); err != nil {
+		return nil, err
+	}
+	tsID := ResourceID{Name: variant.TrainingSet.Name, Variant: variant.TrainingSet.Variant, Type: TRAINING_SET_VARIANT, Namespace: variant.Namespace}
+	ts, err := serv.lookup.Lookup(tsID)
+	if err != nil {
+		return nil, err
+	}
+	trainingSet := ts.Proto().(*pb.TrainingSetVariant)
+	variant.Features = trainingSet.Features
+	variant.Label = trainingSet.Label
+	variant.FeatureGroups = trainingSet.FeatureGroups
+	if variant.Owner == "" {
+		variant.Owner = trainingSet.Owner
+	}
+	return serv.genericCreate(ctx, &modelVariantResource{variant}, func(namespace, name, variant string) Resource {
+		return &modelResource{
+			&pb.Model{
+				Name:           name,
+				Namespace:      namespace,
+				DefaultVariant: variant,
+				// This will be set when the change is propagated to dependencies.
+				Variants: []string{},
+			},
+		}
+	})
+}
+
 func (serv *MetadataServer) GetModels(stream pb.Metadata_GetModelsServer) error {
 	return serv.genericGet(stream, MODEL, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.Model))
 	})
 }
 
+func (serv *MetadataServer) GetModelVariants(stream pb.Metadata_GetModelVariantsServer) error {
+	return serv.genericGet(stream, MODEL_VARIANT, func(msg proto.Message) error {
+		return stream.Send(msg.(*pb.ModelVariant))
+	})
+}
+
+func (serv *MetadataServer) ListFeatureGroups(req *pb.ListRequest, stream pb.Metadata_ListFeatureGroupsServer) error {
+	return serv.genericList(stream, FEATURE_GROUP, req, func(msg proto.Message) error {
+		return stream.Send(msg.(*pb.FeatureGroup))
+	})
+}
+
+// CreateFeatureGroupVariant creates a variant of a feature group. A variant's
+// features are fixed once it's created, so changing the features of a group
+// means creating a new variant.
+func (serv *MetadataServer) CreateFeatureGroupVariant(ctx context.Context, variant *pb.FeatureGroupVariant) (*pb.Empty, error) {
+	variant.Created = tspb.New(time.Now())
+	// Groups don't have jobs, so they're ready as soon as they're created.
+	variant.Status = &pb.ResourceStatus{Status: pb.ResourceStatus_READY}
+	if err := qualifyReferences(&featureGroupVariantResource{variant}); err != nil {
+		return nil, err
+	}
+	if err := serv.checkFeatureGroupFeatures(variant); err != nil {
+		return nil, err
+	}
+	return serv.genericCreate(ctx, &featureGroupVariantResource{variant}, func(namespace, name, variant string) Resource {
+		return &featureGroupResource{
+			&pb.FeatureGroup{
+				Name:           name,
+				Namespace:      namespace,
+				DefaultVariant: variant,
+				// This will be set when the change is propagated to dependencies.
+				Variants: []string{},
+			},
+		}
+	})
+}
+
+// checkFeatureGroupFeatures checks that a group has features and that they
+// all exist and share the group's entity.
+func (serv *MetadataServer) checkFeatureGroupFeatures(variant *pb.FeatureGroupVariant) error {
+	id := (&featureGroupVariantResource{variant}).ID()
+	if len(variant.Features) == 0 {
+		return status.Errorf(codes.InvalidArgument, "%s has no features", id)
+	}
+	seen := make(map[string]bool, len(variant.Features))
+	for _, feature := range variant.Features {
+		featureID := ResourceID{Name: feature.Name, Variant: feature.Variant, Type: FEATURE_VARIANT, Namespace: variant.Namespace}
+		if seen[featureID.String()] {
+			return status.Errorf(codes.InvalidArgument, "%s has %s more than once", id, featureID)
+		}
+		seen[featureID.String()] = true
+		res, err := serv.lookup.Lookup(featureID)
+		if err != nil {
+			return err
+		}
+		if entity := res.Proto().(*pb.FeatureVariant).Entity; entity != variant.Entity {
+			return status.Errorf(codes.InvalidArgument, "%s has entity %s but %s has entity %s", featureID, entity, id, variant.Entity)
+		}
+	}
+	return nil
+}
+
+// expandFeatureGroups adds the features of a training set's groups to its
+// features, skipping the ones it already has.
+func expandFeatureGroups(lookup ResourceLookup, variant *pb.TrainingSetVariant) error {
+	type nameVariant struct {
+		Name    string
+		Variant string
+	}
+	has := make(map[nameVariant]bool, len(variant.Features))
+	for _, feature := range variant.Features {
+		has[nameVariant{feature.Name, feature.Variant}] = true
+	}
+	for _, group := range variant.FeatureGroups {
+		groupID := ResourceID{Name: group.Name, Variant: group.Variant, Type: FEATURE_GROUP_VARIANT, Namespace: variant.Namespace}
+		res, err := lookup.Lookup(groupID)
+		if err != nil {
+			return err
+		}
+		for _, feature := range res.Proto().(*pb.FeatureGroupVariant).Features {
+			key := nameVariant{feature.Name, feature.Variant}
+			if has[key] {
+				continue
+			}
+			has[key] = true
+			variant.Features = append(variant.Features, &pb.NameVariant{Name: feature.Name, Variant: feature.Variant, Namespace: variant.Namespace})
+		}
+	}
+	return nil
+}
+
+func (serv *MetadataServer) GetFeatureGroups(stream pb.Metadata_GetFeatureGroupsServer) error {
+	return serv.genericGet(stream, FEATURE_GROUP, func(msg proto.Message) error {
+		return stream.Send(msg.(*pb.FeatureGroup))
+	})
+}
+
+func (serv *MetadataServer) GetFeatureGroupVariants(stream pb.Metadata_GetFeatureGroupVariantsServer) error {
+	return serv.genericGet(stream, FEATURE_GROUP_VARIANT, func(msg proto.Message) error {
+		return stream.Send(msg.(*pb.FeatureGroupVariant))
+	})
+}
+
 type nameStream interface {
 	Recv() (*pb.Name, error)
 }
@@ -1521,7 +2247,7 @@ type variantStream interface {
 
 type sendFn func(proto.Message) error
 
-type initParentFn func(name, variant string) Resource
+type initParentFn func(namespace, name, variant string) Resource
 
 func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, init initParentFn) (*pb.Empty, error) {
 	serv.Logger.Info("Creating Generic Resource", res.ID().Name, res.ID().Variant)
@@ -1529,19 +2255,41 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 	if err := resourceNamedSafely(id); err != nil {
 		return nil, err
 	}
+	if err := qualifyReferences(res); err != nil {
+		return nil, err
+	}
+	if err := checkProvidersVisible(serv.lookup, res); err != nil {
+		return nil, err
+	}
+	if err := serv.checkPolicy(ctx, res); err != nil {
+		return nil, err
+	}
+	// A revision on the resource is the one it's expected to be at.
+	expected := resourceRevision(res)
 	existing, err := serv.lookup.Lookup(id)
 	if _, isResourceError := err.(*ResourceNotFound); err != nil && !isResourceError {
 		return nil, err
 	}
+	revision := resourceRevision(existing)
+	if expected != 0 && expected != revision {
+		return nil, &RevisionConflict{ID: id, Expected: expected, Actual: revision}
+	}
+	var before proto.Message
 	if existing != nil {
+		before = proto.Clone(existing.Proto())
 		if err := existing.Update(serv.lookup, res); err != nil {
 			return nil, err
 		}
 		res = existing
 	}
-	if err := serv.lookup.Set(id, res); err != nil {
+	if err := serv.lookup.SetIfRevision(id, res, revision); err != nil {
 		return nil, err
 	}
+	if existing == nil {
+		serv.audit(ctx, pb.AuditEvent_CREATE, id, nil, res.Proto())
+	} else {
+		serv.audit(ctx, pb.AuditEvent_UPDATE, id, before, res.Proto())
+	}
 	if serv.needsJob(res) && existing == nil {
 		serv.Logger.Info("Creating Job", res.ID().Name, res.ID().Variant)
 		if err := serv.lookup.SetJob(id, res.Schedule()); err != nil {
@@ -1557,14 +2305,20 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 		}
 
 		if !parentExists {
-			parent := init(id.Name, id.Variant)
-			err = serv.lookup.Set(parentId, parent)
+			parent := init(id.Namespace, id.Name, id.Variant)
+			err = serv.lookup.SetIfRevision(parentId, parent, 0)
+			// A concurrent create of another variant made the parent first.
+			if _, isConflict := err.(*RevisionConflict); isConflict {
+				err = nil
+			} else if err == nil {
+				serv.audit(ctx, pb.AuditEvent_CREATE, parentId, nil, parent.Proto())
+			}
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
-	if err := serv.propagateChange(res); err != nil {
+	if err := serv.propagateChange(ctx, res, create_op); err != nil {
 		err := errors.Wrap(err, fmt.Sprintf("failed to update parent resources for: %s", res.ID().String()))
 		serv.Logger.Error(errors.WithStack(err))
 		return nil, err
@@ -1572,7 +2326,7 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 	return &pb.Empty{}, nil
 }
 
-func (serv *MetadataServer) propagateChange(newRes Resource) error {
+func (serv *MetadataServer) propagateChange(ctx context.Context, newRes Resource, op operation) error {
 	visited := make(map[ResourceID]struct{})
 	// We have to make it a var so that the anonymous function can call itself.
 	var propagateChange func(parent Resource) error
@@ -1591,13 +2345,14 @@ func (serv *MetadataServer) propagateChange(newRes Resource) error {
 				continue
 			}
 			visited[id] = struct{}{}
-			if err := res.Notify(serv.lookup, create_op, newRes); err != nil {
+			before, updated, err := updateResource(serv.lookup, id, 0, func(res Resource) error {
+				return res.Notify(serv.lookup, op, newRes)
+			})
+			if err != nil {
 				return err
 			}
-			if err := serv.lookup.Set(res.ID(), res); err != nil {
-				return nil
-			}
-			if err := propagateChange(res); err != nil {
+			serv.audit(ctx, pb.AuditEvent_UPDATE, id, before, updated.Proto())
+			if err := propagateChange(updated); err != nil {
 				return err
 			}
 		}
@@ -1615,16 +2370,18 @@ func (serv *MetadataServer) genericGet(stream interface{}, t ResourceType, send
 			req, err := casted.Recv()
 			recvErr = err
 			id = ResourceID{
-				Name: req.GetName(),
-				Type: t,
+				Name:      req.GetName(),
+				Type:      t,
+				Namespace: req.GetNamespace(),
 			}
 		case variantStream:
 			req, err := casted.Recv()
 			recvErr = err
 			id = ResourceID{
-				Name:    req.GetName(),
-				Variant: req.GetVariant(),
-				Type:    t,
+				Name:      req.GetName(),
+				Variant:   req.GetVariant(),
+				Type:      t,
+				Namespace: req.GetNamespace(),
 			}
 		default:
 			return fmt.Errorf("Invalid Stream for Get: %T", casted)
@@ -1649,33 +2406,22 @@ func (serv *MetadataServer) genericGet(stream interface{}, t ResourceType, send
 	}
 }
 
-func (serv *MetadataServer) genericList(t ResourceType, send sendFn) error {
-	resources, err := serv.lookup.ListForType(t)
-	if err != nil {
-		return err
-	}
-	for _, res := range resources {
-		serialized := res.Proto()
-		if err := send(serialized); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 type TrainingSetVariantResource struct {
-	Created     time.Time                           `json:"created"`
-	Description string                              `json:"description"`
-	Name        string                              `json:"name"`
-	Owner       string                              `json:"owner"`
-	Provider    string                              `json:"provider"`
-	Variant     string                              `json:"variant"`
-	Label       NameVariant                         `json:"label"`
-	Features    map[string][]FeatureVariantResource `json:"features"`
-	Status      string                              `json:"status"`
-	Error       string                              `json:"error"`
-	Tags        Tags                                `json:"tags"`
-	Properties  Properties                          `json:"properties"`
+	Created      time.Time                           `json:"created"`
+	Description  string                              `json:"description"`
+	Name         string                              `json:"name"`
+	Owner        string                              `json:"owner"`
+	Provider     string                              `json:"provider"`
+	Variant      string                              `json:"variant"`
+	Label        NameVariant                         `json:"label"`
+	Features     map[string][]FeatureVariantResource `json:"features"`
+	Status       string                              `json:"status"`
+	Error        string                              `json:"error"`
+	Failure      ResourceErrorResource               `json:"failure"`
+	ErrorHistory []ResourceErrorResource             `json:"error-history"`
+	Tags         Tags                                `json:"tags"`
+	Properties   Properties                          `json:"properties"`
+	Lifecycle    LifecycleResource                   `json:"lifecycle"`
 }
 
 type FeatureVariantResource struct {
@@ -1689,6 +2435,8 @@ type FeatureVariantResource struct {
 	Variant      string                                  `json:"variant"`
 	Status       string                                  `json:"status"`
 	Error        string                                  `json:"error"`
+	Failure      ResourceErrorResource                   `json:"failure"`
+	ErrorHistory []ResourceErrorResource                 `json:"error-history"`
 	Location     map[string]string                       `json:"location"`
 	Source       NameVariant                             `json:"source"`
 	TrainingSets map[string][]TrainingSetVariantResource `json:"training-sets"`
@@ -1696,6 +2444,27 @@ type FeatureVariantResource struct {
 	Properties   Properties                              `json:"properties"`
 	Mode         string                                  `json:"mode"`
 	IsOnDemand   bool                                    `json:"is-on-demand"`
+	Lifecycle    LifecycleResource                       `json:"lifecycle"`
+}
+
+// LifecycleResource is a variant's lifecycle as of when it was read, so
+// deprecated variants past their sunset are retired. The sunset is empty if
+// there isn't one.
+type LifecycleResource struct {
+	State       string      `json:"state"`
+	Sunset      string      `json:"sunset"`
+	Replacement NameVariant `json:"replacement"`
+	Reason      string      `json:"reason"`
+}
+
+// ResourceErrorResource is why a resource failed. The time it failed is empty
+// if it isn't known.
+type ResourceErrorResource struct {
+	Message string `json:"message"`
+	Attempt int    `json:"attempt"`
+	Failed  string `json:"failed"`
+	Runner  string `json:"runner"`
+	Logs    string `json:"logs"`
 }
 
 type LabelVariantResource struct {
@@ -1712,6 +2481,8 @@ type LabelVariantResource struct {
 	TrainingSets map[string][]TrainingSetVariantResource `json:"training-sets"`
 	Status       string                                  `json:"status"`
 	Error        string                                  `json:"error"`
+	Failure      ResourceErrorResource                   `json:"failure"`
+	ErrorHistory []ResourceErrorResource                 `json:"error-history"`
 	Tags         Tags                                    `json:"tags"`
 	Properties   Properties                              `json:"properties"`
 }
@@ -1735,6 +2506,8 @@ type SourceVariantResource struct {
 	Properties     Properties                              `json:"properties"`
 	SourceType     string                                  `json:"source-type"`
 	Error          string                                  `json:"error"`
+	Failure        ResourceErrorResource                   `json:"failure"`
+	ErrorHistory   []ResourceErrorResource                 `json:"error-history"`
 	Specifications map[string]string                       `json:"specifications"`
 	Inputs         []NameVariant                           `json:"inputs"`
 }
@@ -1780,6 +2553,8 @@ func SourceShallowMap(variant *SourceVariant) SourceVariantResource {
 		SourceType:     getSourceType(variant),
 		Properties:     variant.Properties(),
 		Error:          variant.Error(),
+		Failure:        variant.Failure().Resource(),
+		ErrorHistory:   variant.ErrorHistory().Resource(),
 		Specifications: getSourceArgs(variant),
 	}
 }
@@ -104,7 +104,7 @@ func (m MetadataServerMock) GetSourceVariants(ctx context.Context, opts ...grpc.
 	}, nil
 }
 
-func (MetadataServerMock) ListFeatures(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListFeaturesClient, error) {
+func (MetadataServerMock) ListFeatures(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListFeaturesClient, error) {
 	return nil, nil
 }
 
@@ -118,7 +118,19 @@ func (MetadataServerMock) GetFeatures(ctx context.Context, opts ...grpc.CallOpti
 func (MetadataServerMock) GetFeatureVariants(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetFeatureVariantsClient, error) {
 	return nil, nil
 }
-func (MetadataServerMock) ListLabels(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListLabelsClient, error) {
+func (MetadataServerMock) ListFeatureGroups(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListFeatureGroupsClient, error) {
+	return nil, nil
+}
+func (MetadataServerMock) CreateFeatureGroupVariant(ctx context.Context, in *pb.FeatureGroupVariant, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) GetFeatureGroups(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetFeatureGroupsClient, error) {
+	return nil, nil
+}
+func (MetadataServerMock) GetFeatureGroupVariants(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetFeatureGroupVariantsClient, error) {
+	return nil, nil
+}
+func (MetadataServerMock) ListLabels(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListLabelsClient, error) {
 	return nil, nil
 }
 func (MetadataServerMock) CreateLabelVariant(ctx context.Context, in *pb.LabelVariant, opts ...grpc.CallOption) (*pb.Empty, error) {
@@ -130,7 +142,7 @@ func (MetadataServerMock) GetLabels(ctx context.Context, opts ...grpc.CallOption
 func (MetadataServerMock) GetLabelVariants(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetLabelVariantsClient, error) {
 	return nil, nil
 }
-func (MetadataServerMock) ListTrainingSets(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListTrainingSetsClient, error) {
+func (MetadataServerMock) ListTrainingSets(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListTrainingSetsClient, error) {
 	return nil, nil
 }
 func (MetadataServerMock) CreateTrainingSetVariant(ctx context.Context, in *pb.TrainingSetVariant, opts ...grpc.CallOption) (*pb.Empty, error) {
@@ -142,7 +154,7 @@ func (MetadataServerMock) GetTrainingSets(ctx context.Context, opts ...grpc.Call
 func (MetadataServerMock) GetTrainingSetVariants(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetTrainingSetVariantsClient, error) {
 	return nil, nil
 }
-func (MetadataServerMock) ListSources(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListSourcesClient, error) {
+func (MetadataServerMock) ListSources(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListSourcesClient, error) {
 	return nil, nil
 }
 func (MetadataServerMock) CreateSourceVariant(ctx context.Context, in *pb.SourceVariant, opts ...grpc.CallOption) (*pb.Empty, error) {
@@ -159,7 +171,7 @@ func (MetadataServerMock) GetProviders(ctx context.Context, opts ...grpc.CallOpt
 	}, nil
 }
 
-func (MetadataServerMock) ListUsers(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListUsersClient, error) {
+func (MetadataServerMock) ListUsers(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListUsersClient, error) {
 	return nil, nil
 }
 func (MetadataServerMock) CreateUser(ctx context.Context, in *pb.User, opts ...grpc.CallOption) (*pb.Empty, error) {
@@ -168,14 +180,14 @@ func (MetadataServerMock) CreateUser(ctx context.Context, in *pb.User, opts ...g
 func (MetadataServerMock) GetUsers(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetUsersClient, error) {
 	return nil, nil
 }
-func (MetadataServerMock) ListProviders(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListProvidersClient, error) {
+func (MetadataServerMock) ListProviders(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListProvidersClient, error) {
 	return nil, nil
 }
 func (MetadataServerMock) CreateProvider(ctx context.Context, in *pb.Provider, opts ...grpc.CallOption) (*pb.Empty, error) {
 	return nil, nil
 }
 
-func (MetadataServerMock) ListEntities(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListEntitiesClient, error) {
+func (MetadataServerMock) ListEntities(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListEntitiesClient, error) {
 	return nil, nil
 }
 
@@ -185,7 +197,7 @@ func (MetadataServerMock) CreateEntity(ctx context.Context, in *pb.Entity, opts
 func (MetadataServerMock) GetEntities(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetEntitiesClient, error) {
 	return nil, nil
 }
-func (MetadataServerMock) ListModels(ctx context.Context, in *pb.Empty, opts ...grpc.CallOption) (pb.Metadata_ListModelsClient, error) {
+func (MetadataServerMock) ListModels(ctx context.Context, in *pb.ListRequest, opts ...grpc.CallOption) (pb.Metadata_ListModelsClient, error) {
 	return nil, nil
 }
 func (MetadataServerMock) CreateModel(ctx context.Context, in *pb.Model, opts ...grpc.CallOption) (*pb.Empty, error) {
@@ -194,9 +206,51 @@ func (MetadataServerMock) CreateModel(ctx context.Context, in *pb.Model, opts ..
 func (MetadataServerMock) GetModels(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetModelsClient, error) {
 	return nil, nil
 }
+func (MetadataServerMock) CreateModelVariant(ctx context.Context, in *pb.ModelVariant, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) GetModelVariants(ctx context.Context, opts ...grpc.CallOption) (pb.Metadata_GetModelVariantsClient, error) {
+	return nil, nil
+}
 func (MetadataServerMock) SetResourceStatus(ctx context.Context, in *pb.SetStatusRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
 	return nil, nil
 }
 func (MetadataServerMock) RequestScheduleChange(ctx context.Context, in *pb.ScheduleChangeRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
 	return nil, nil
 }
+func (MetadataServerMock) DeleteResource(ctx context.Context, in *pb.DeleteResourceRequest, opts ...grpc.CallOption) (*pb.DeleteResourceResponse, error) {
+	return nil, nil
+}
+func (MetadataServerMock) ArchiveResource(ctx context.Context, in *pb.ArchiveResourceRequest, opts ...grpc.CallOption) (*pb.ArchiveResourceResponse, error) {
+	return nil, nil
+}
+func (MetadataServerMock) SetLifecycle(ctx context.Context, in *pb.SetLifecycleRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) SetTags(ctx context.Context, in *pb.SetTagsRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) CheckIntegrity(ctx context.Context, in *pb.IntegrityRequest, opts ...grpc.CallOption) (*pb.IntegrityReport, error) {
+	return nil, nil
+}
+func (MetadataServerMock) SetNotifications(ctx context.Context, in *pb.NotificationsRequest, opts ...grpc.CallOption) (*pb.Empty, error) {
+	return nil, nil
+}
+func (MetadataServerMock) QueryResources(ctx context.Context, in *pb.ResourceQuery, opts ...grpc.CallOption) (*pb.ResourceQueryResult, error) {
+	return nil, nil
+}
+func (MetadataServerMock) GetResourceHistory(ctx context.Context, in *pb.ResourceID, opts ...grpc.CallOption) (pb.Metadata_GetResourceHistoryClient, error) {
+	return nil, nil
+}
+func (MetadataServerMock) ListAuditEvents(ctx context.Context, in *pb.AuditEventsRequest, opts ...grpc.CallOption) (pb.Metadata_ListAuditEventsClient, error) {
+	return nil, nil
+}
+func (MetadataServerMock) Watch(ctx context.Context, in *pb.WatchRequest, opts ...grpc.CallOption) (pb.Metadata_WatchClient, error) {
+	return nil, nil
+}
+func (MetadataServerMock) Plan(ctx context.Context, in *pb.PlanRequest, opts ...grpc.CallOption) (*pb.PlanResponse, error) {
+	return nil, nil
+}
+func (MetadataServerMock) GetLineage(ctx context.Context, in *pb.LineageRequest, opts ...grpc.CallOption) (*pb.Lineage, error) {
+	return nil, nil
+}
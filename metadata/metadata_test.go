@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/metadata/search"
 	"golang.org/x/exp/slices"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 
@@ -16,6 +19,8 @@ import (
 	pt "github.com/featureform/provider/provider_type"
 	"github.com/google/uuid"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const PythonFunc = `def average_user_transaction(transactions):
@@ -23,14 +28,16 @@ const PythonFunc = `def average_user_transaction(transactions):
 
 func TestResourceTypes(t *testing.T) {
 	typeMapping := map[ResourceType]ResourceDef{
-		USER:                 UserDef{},
-		PROVIDER:             ProviderDef{},
-		ENTITY:               EntityDef{},
-		SOURCE_VARIANT:       SourceDef{},
-		FEATURE_VARIANT:      FeatureDef{},
-		LABEL_VARIANT:        LabelDef{},
-		TRAINING_SET_VARIANT: TrainingSetDef{},
-		MODEL:                ModelDef{},
+		USER:                  UserDef{},
+		PROVIDER:              ProviderDef{},
+		ENTITY:                EntityDef{},
+		SOURCE_VARIANT:        SourceDef{},
+		FEATURE_VARIANT:       FeatureDef{},
+		LABEL_VARIANT:         LabelDef{},
+		TRAINING_SET_VARIANT:  TrainingSetDef{},
+		MODEL:                 ModelDef{},
+		FEATURE_GROUP_VARIANT: FeatureGroupDef{},
+		MODEL_VARIANT:         ModelVariantDef{},
 	}
 	for typ, def := range typeMapping {
 		if def.ResourceType() != typ {
@@ -137,7 +144,7 @@ func filledResourceDefs() []ResourceDef {
 			Entity:      "user",
 			Type:        "float",
 			Description: "Feature variant",
-			Source:      NameVariant{"mockSource", "var"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:       "Featureform",
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -156,7 +163,7 @@ func filledResourceDefs() []ResourceDef {
 			Entity:      "user",
 			Type:        "int",
 			Description: "Feature variant2",
-			Source:      NameVariant{"mockSource", "var2"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var2"},
 			Owner:       "Featureform",
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -175,7 +182,7 @@ func filledResourceDefs() []ResourceDef {
 			Entity:      "user",
 			Type:        "string",
 			Description: "Feature2 variant",
-			Source:      NameVariant{"mockSource", "var"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:       "Featureform",
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -207,7 +214,7 @@ func filledResourceDefs() []ResourceDef {
 			Description: "label variant",
 			Provider:    "mockOffline",
 			Entity:      "user",
-			Source:      NameVariant{"mockSource", "var"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:       "Other",
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -222,10 +229,10 @@ func filledResourceDefs() []ResourceDef {
 			Variant:     "variant",
 			Provider:    "mockOffline",
 			Description: "training-set variant",
-			Label:       NameVariant{"label", "variant"},
+			Label:       NameVariant{Name: "label", Variant: "variant"},
 			Features: NameVariants{
-				{"feature", "variant"},
-				{"feature", "variant2"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			Owner:      "Other",
 			Tags:       Tags{},
@@ -236,10 +243,10 @@ func filledResourceDefs() []ResourceDef {
 			Variant:     "variant2",
 			Provider:    "mockOffline",
 			Description: "training-set variant2",
-			Label:       NameVariant{"label", "variant"},
+			Label:       NameVariant{Name: "label", Variant: "variant"},
 			Features: NameVariants{
-				{"feature2", "variant"},
-				{"feature", "variant2"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			Owner:      "Featureform",
 			Tags:       Tags{},
@@ -595,28 +602,28 @@ func expectedUsers() ResourceTests {
 			Name:   "Featureform",
 			Labels: []NameVariant{},
 			Features: []NameVariant{
-				{"feature", "variant"},
-				{"feature2", "variant"},
-				{"feature", "variant2"},
-				{"feature3", "on-demand"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
+				{Name: "feature3", Variant: "on-demand"},
 			},
 			Sources: []NameVariant{
-				{"mockSource", "var"},
-				{"mockSource", "var2"},
+				{Name: "mockSource", Variant: "var"},
+				{Name: "mockSource", Variant: "var2"},
 			},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 		},
 		UserTest{
 			Name: "Other",
 			Labels: []NameVariant{
-				{"label", "variant"},
+				{Name: "label", Variant: "variant"},
 			},
 			Features: []NameVariant{},
 			Sources:  []NameVariant{},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
+				{Name: "training-set", Variant: "variant"},
 			},
 		},
 	}
@@ -643,17 +650,17 @@ func expectedUserUpdates() ResourceTests {
 			Name:   "Featureform",
 			Labels: []NameVariant{},
 			Features: []NameVariant{
-				{"feature", "variant"},
-				{"feature2", "variant"},
-				{"feature", "variant2"},
-				{"feature3", "on-demand"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
+				{Name: "feature3", Variant: "on-demand"},
 			},
 			Sources: []NameVariant{
-				{"mockSource", "var"},
-				{"mockSource", "var2"},
+				{Name: "mockSource", Variant: "var"},
+				{Name: "mockSource", Variant: "var2"},
 			},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			Tags:       Tags{"primary_user"},
 			Properties: Properties{"usr_key_1": "usr_val_1"},
@@ -662,17 +669,17 @@ func expectedUserUpdates() ResourceTests {
 			Name:   "Featureform",
 			Labels: []NameVariant{},
 			Features: []NameVariant{
-				{"feature", "variant"},
-				{"feature2", "variant"},
-				{"feature", "variant2"},
-				{"feature3", "on-demand"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
+				{Name: "feature3", Variant: "on-demand"},
 			},
 			Sources: []NameVariant{
-				{"mockSource", "var"},
-				{"mockSource", "var2"},
+				{Name: "mockSource", Variant: "var"},
+				{Name: "mockSource", Variant: "var2"},
 			},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			Tags:       Tags{"primary_user", "active"},
 			Properties: Properties{"usr_key_1": "user_value_1"},
@@ -753,9 +760,9 @@ func expectedProviders() ResourceTests {
 			SerializedConfig: redisConfig.Serialized(),
 			Labels:           []NameVariant{},
 			Features: []NameVariant{
-				{"feature", "variant"},
-				{"feature2", "variant"},
-				{"feature", "variant2"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			Sources:      []NameVariant{},
 			TrainingSets: []NameVariant{},
@@ -770,16 +777,16 @@ func expectedProviders() ResourceTests {
 			Team:             "recommendations",
 			SerializedConfig: snowflakeConfig.Serialize(),
 			Labels: []NameVariant{
-				{"label", "variant"},
+				{Name: "label", Variant: "variant"},
 			},
 			Features: []NameVariant{},
 			Sources: []NameVariant{
-				{"mockSource", "var"},
-				{"mockSource", "var2"},
+				{Name: "mockSource", Variant: "var"},
+				{Name: "mockSource", Variant: "var2"},
 			},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			Tags:       Tags{},
 			Properties: Properties{},
@@ -851,9 +858,9 @@ func expectedUpdatedProviders() ResourceTests {
 			SerializedConfig: redisConfig.Serialized(),
 			Labels:           []NameVariant{},
 			Features: []NameVariant{
-				{"feature", "variant"},
-				{"feature2", "variant"},
-				{"feature", "variant2"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			Sources:      []NameVariant{},
 			TrainingSets: []NameVariant{},
@@ -868,16 +875,16 @@ func expectedUpdatedProviders() ResourceTests {
 			Team:             "recommendations",
 			SerializedConfig: snowflakeConfig.Serialize(),
 			Labels: []NameVariant{
-				{"label", "variant"},
+				{Name: "label", Variant: "variant"},
 			},
 			Features: []NameVariant{},
 			Sources: []NameVariant{
-				{"mockSource", "var"},
-				{"mockSource", "var2"},
+				{Name: "mockSource", Variant: "var"},
+				{Name: "mockSource", Variant: "var2"},
 			},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			Tags:       Tags{"offline"},
 			Properties: Properties{},
@@ -925,16 +932,16 @@ func expectedEntities() ResourceTests {
 			Name:        "user",
 			Description: "A user entity",
 			Labels: []NameVariant{
-				{"label", "variant"},
+				{Name: "label", Variant: "variant"},
 			},
 			Features: []NameVariant{
-				{"feature", "variant"},
-				{"feature2", "variant"},
-				{"feature", "variant2"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 		},
 		EntityTest{
@@ -970,7 +977,7 @@ type SourceVariantTest struct {
 }
 
 func (test SourceVariantTest) NameVariant() NameVariant {
-	return NameVariant{test.Name, test.Variant}
+	return NameVariant{Name: test.Name, Variant: test.Variant}
 }
 
 func (test SourceVariantTest) Test(t *testing.T, client *Client, res interface{}, shouldFetch bool) {
@@ -1040,15 +1047,15 @@ func expectedSourceVariants() ResourceTests {
 			Owner:       "Featureform",
 			Provider:    "mockOffline",
 			Labels: []NameVariant{
-				{"label", "variant"},
+				{Name: "label", Variant: "variant"},
 			},
 			Features: []NameVariant{
-				{"feature", "variant"},
-				{"feature2", "variant"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature2", Variant: "variant"},
 			},
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			IsTransformation:        true,
 			IsSQLTransformation:     true,
@@ -1068,7 +1075,7 @@ func expectedSourceVariants() ResourceTests {
 			Provider:    "mockOffline",
 			Labels:      []NameVariant{},
 			Features: []NameVariant{
-				{"feature", "variant2"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			IsTransformation:         false,
 			IsSQLTransformation:      false,
@@ -1077,8 +1084,8 @@ func expectedSourceVariants() ResourceTests {
 			PrimaryDataSQLTableName:  "mockPrimary",
 			SQLTransformationSources: nil,
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 		},
 	}
@@ -1150,7 +1157,7 @@ type FeatureVariantTest struct {
 }
 
 func (test FeatureVariantTest) NameVariant() NameVariant {
-	return NameVariant{test.Name, test.Variant}
+	return NameVariant{Name: test.Name, Variant: test.Variant}
 }
 
 func (test FeatureVariantTest) Test(t *testing.T, client *Client, res interface{}, shouldFetch bool) {
@@ -1193,10 +1200,10 @@ func expectedFeatureVariants() ResourceTests {
 			Entity:      "user",
 			Type:        "float",
 			Description: "Feature variant",
-			Source:      NameVariant{"mockSource", "var"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:       "Featureform",
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
+				{Name: "training-set", Variant: "variant"},
 			},
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -1212,11 +1219,11 @@ func expectedFeatureVariants() ResourceTests {
 			Entity:      "user",
 			Type:        "int",
 			Description: "Feature variant2",
-			Source:      NameVariant{"mockSource", "var2"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var2"},
 			Owner:       "Featureform",
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -1232,10 +1239,10 @@ func expectedFeatureVariants() ResourceTests {
 			Entity:      "user",
 			Type:        "string",
 			Description: "Feature2 variant",
-			Source:      NameVariant{"mockSource", "var"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:       "Featureform",
 			TrainingSets: []NameVariant{
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -1312,7 +1319,7 @@ type LabelVariantTest struct {
 }
 
 func (test LabelVariantTest) NameVariant() NameVariant {
-	return NameVariant{test.Name, test.Variant}
+	return NameVariant{Name: test.Name, Variant: test.Variant}
 }
 
 func (test LabelVariantTest) Test(t *testing.T, client *Client, res interface{}, shouldFetch bool) {
@@ -1345,11 +1352,11 @@ func expectedLabelVariants() ResourceTests {
 			Description: "label variant",
 			Provider:    "mockOffline",
 			Entity:      "user",
-			Source:      NameVariant{"mockSource", "var"},
+			Source:      NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:       "Other",
 			TrainingSets: []NameVariant{
-				{"training-set", "variant"},
-				{"training-set", "variant2"},
+				{Name: "training-set", Variant: "variant"},
+				{Name: "training-set", Variant: "variant2"},
 			},
 			Location: ResourceVariantColumns{
 				Entity: "col1",
@@ -1411,7 +1418,7 @@ type TrainingSetVariantTest struct {
 }
 
 func (test TrainingSetVariantTest) NameVariant() NameVariant {
-	return NameVariant{test.Name, test.Variant}
+	return NameVariant{Name: test.Name, Variant: test.Variant}
 }
 
 func (test TrainingSetVariantTest) Test(t *testing.T, client *Client, resource interface{}, shouldFetch bool) {
@@ -1438,10 +1445,10 @@ func expectedTrainingSetVariants() ResourceTests {
 			Variant:     "variant",
 			Provider:    "mockOffline",
 			Description: "training-set variant",
-			Label:       NameVariant{"label", "variant"},
+			Label:       NameVariant{Name: "label", Variant: "variant"},
 			Features: NameVariants{
-				{"feature", "variant"},
-				{"feature", "variant2"},
+				{Name: "feature", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			Owner: "Other",
 		},
@@ -1450,10 +1457,10 @@ func expectedTrainingSetVariants() ResourceTests {
 			Variant:     "variant2",
 			Provider:    "mockOffline",
 			Description: "training-set variant2",
-			Label:       NameVariant{"label", "variant"},
+			Label:       NameVariant{Name: "label", Variant: "variant"},
 			Features: NameVariants{
-				{"feature2", "variant"},
-				{"feature", "variant2"},
+				{Name: "feature2", Variant: "variant"},
+				{Name: "feature", Variant: "variant2"},
 			},
 			Owner: "Featureform",
 		},
@@ -1669,7 +1676,7 @@ func (test ParentResourceTest) Test(t *testing.T, client *Client, resource inter
 	assertEqual(t, parentRes.DefaultVariant(), test.Default)
 	nameVars := make(NameVariants, len(test.Variants))
 	for i, variant := range test.Variants {
-		nameVars[i] = NameVariant{test.Name, variant}
+		nameVars[i] = NameVariant{Name: test.Name, Variant: variant}
 	}
 	assertEqual(t, parentRes.NameVariants(), nameVars)
 }
@@ -1725,7 +1732,7 @@ func (tests ResourceTests) Test(t *testing.T, client *Client, resources interfac
 		res := reflected.Index(i).Interface()
 		switch casted := res.(type) {
 		case NameAndVariant:
-			key = NameVariant{casted.Name(), casted.Variant()}
+			key = NameVariant{Name: casted.Name(), Variant: casted.Variant()}
 		case NameOnly:
 			key = NameVariant{Name: casted.Name()}
 		default:
@@ -1777,7 +1784,7 @@ func testGetResources(t *testing.T, typ ResourceType, tests ResourceTests) {
 		t.Fatalf("Got resources when expected none: %+v", noResources)
 	}
 
-	if res, err := get(client, typ, NameVariant{uuid.NewString(), uuid.NewString()}); err == nil {
+	if res, err := get(client, typ, NameVariant{Name: uuid.NewString(), Variant: uuid.NewString()}); err == nil {
 		t.Fatalf("Succeeded in getting random resource: %+v", res)
 	}
 }
@@ -1925,31 +1932,31 @@ func testFetchProvider(t *testing.T, client *Client, fetcher providerFetcher) {
 }
 
 func TestBannedStrings(t *testing.T) {
-	resourceInvalidName := ResourceID{"nam__e", "variant", FEATURE}
-	resourceInvalidVariant := ResourceID{"name", "varian__t", FEATURE}
+	resourceInvalidName := ResourceID{Name: "nam__e", Variant: "variant", Type: FEATURE}
+	resourceInvalidVariant := ResourceID{Name: "name", Variant: "varian__t", Type: FEATURE}
 	if err := resourceNamedSafely(resourceInvalidName); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource name")
 	}
 	if err := resourceNamedSafely(resourceInvalidVariant); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource name")
 	}
-	invalidNamePrefix := ResourceID{"_name", "variant", FEATURE}
-	invalidVariantPrefix := ResourceID{"name", "_variant", FEATURE}
+	invalidNamePrefix := ResourceID{Name: "_name", Variant: "variant", Type: FEATURE}
+	invalidVariantPrefix := ResourceID{Name: "name", Variant: "_variant", Type: FEATURE}
 	if err := resourceNamedSafely(invalidNamePrefix); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource prefix")
 	}
 	if err := resourceNamedSafely(invalidVariantPrefix); err == nil {
 		t.Fatalf("testing didn't catch error on valid variant prefix")
 	}
-	invalidNameSuffix := ResourceID{"name_", "variant", FEATURE}
-	invalidVariantSuffix := ResourceID{"name", "variant_", FEATURE}
+	invalidNameSuffix := ResourceID{Name: "name_", Variant: "variant", Type: FEATURE}
+	invalidVariantSuffix := ResourceID{Name: "name", Variant: "variant_", Type: FEATURE}
 	if err := resourceNamedSafely(invalidNameSuffix); err == nil {
 		t.Fatalf("testing didn't catch error on valid resource prefix")
 	}
 	if err := resourceNamedSafely(invalidVariantSuffix); err == nil {
 		t.Fatalf("testing didn't catch error on valid variant prefix")
 	}
-	validName := ResourceID{"name", "variant", FEATURE}
+	validName := ResourceID{Name: "name", Variant: "variant", Type: FEATURE}
 	if err := resourceNamedSafely(validName); err != nil {
 		t.Fatalf("valid resource triggered an error")
 	}
@@ -1988,6 +1995,10 @@ func (mocker) GetLastUpdated() *tspb.Timestamp {
 	return tspb.Now()
 }
 
+func (mocker) GetStatus() *pb.ResourceStatus {
+	return &pb.ResourceStatus{Status: pb.ResourceStatus_READY}
+}
+
 func (mocker) GetTags() *pb.Tags {
 	return &pb.Tags{Tag: []string{"test.active", "test.inactive"}}
 }
@@ -2007,6 +2018,7 @@ func getSourceVariant() *SourceVariant {
 		fetchTrainingSetsFns: fetchTrainingSetsFns{},
 		createdFn:            createdFn{getter: mocker{}},
 		lastUpdatedFn:        lastUpdatedFn{getter: mocker{}},
+		fetchStatusErrorFn:   fetchStatusErrorFn{getter: mocker{}},
 		fetchTagsFn:          fetchTagsFn{getter: mocker{}},
 		fetchPropertiesFn:    fetchPropertiesFn{getter: mocker{}},
 		protoStringer:        protoStringer{},
@@ -2027,3 +2039,552 @@ func TestSourceShallowMapOK(t *testing.T) {
 	assertEqual(t, slices.Contains(sourceVariantResource.Tags, "test.inactive"), true)
 	assertEqual(t, sv.Properties()["test.map.key"], sourceVariantResource.Properties["test.map.key"])
 }
+
+type recordingCleaner struct {
+	cleaned []ResourceID
+}
+
+func (cleaner *recordingCleaner) Cleanup(res Resource, lookup ResourceLookup) error {
+	cleaner.cleaned = append(cleaner.cleaned, res.ID())
+	return nil
+}
+
+func TestDeleteResource(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	cleaner := &recordingCleaner{}
+	ctx.serv.cleaner = cleaner
+	bg := context.Background()
+
+	featureVariant := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if _, err := client.DeleteResource(bg, featureVariant, false, false); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected dependants to block delete, got %v", err)
+	}
+	missing := ResourceID{Name: "missing", Variant: "variant", Type: FEATURE_VARIANT}
+	if _, err := client.DeleteResource(bg, missing, false, false); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+
+	trainingSetVariant := ResourceID{Name: "training-set", Variant: "variant", Type: TRAINING_SET_VARIANT}
+	deleted, err := client.DeleteResource(bg, trainingSetVariant, false, false)
+	if err != nil {
+		t.Fatalf("Failed to delete training set variant: %s", err)
+	}
+	assertEqual(t, deleted, []ResourceID{trainingSetVariant})
+	if len(cleaner.cleaned) != 0 {
+		t.Fatalf("Cleaned up without cleanup set: %v", cleaner.cleaned)
+	}
+	trainingSet, err := client.GetTrainingSet(bg, "training-set")
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	assertEqual(t, trainingSet.Variants(), []string{"variant2"})
+	assertEqual(t, trainingSet.DefaultVariant(), "variant2")
+	variant, err := client.GetFeatureVariant(bg, NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature variant: %s", err)
+	}
+	if len(variant.TrainingSets()) != 0 {
+		t.Fatalf("Deleted training set still referenced: %v", variant.TrainingSets())
+	}
+
+	// Deleting the feature takes its variants, the training set variant that
+	// uses one of them and the now empty training set with it.
+	deleted, err = client.DeleteResource(bg, ResourceID{Name: "feature", Type: FEATURE}, true, true)
+	if err != nil {
+		t.Fatalf("Failed to force delete feature: %s", err)
+	}
+	expected := []ResourceID{
+		{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT},
+		{Name: "training-set", Variant: "variant2", Type: TRAINING_SET_VARIANT},
+		{Name: "training-set", Type: TRAINING_SET},
+		{Name: "feature", Variant: "variant2", Type: FEATURE_VARIANT},
+		{Name: "feature", Type: FEATURE},
+	}
+	if len(deleted) != len(expected) {
+		t.Fatalf("Wrong resources deleted\nActual: %v\nExpected: %v", deleted, expected)
+	}
+	for _, id := range expected {
+		if !slices.Contains(deleted, id) {
+			t.Fatalf("%s not deleted: %v", id, deleted)
+		}
+	}
+	if len(cleaner.cleaned) != 3 {
+		t.Fatalf("Expected three variants cleaned up: %v", cleaner.cleaned)
+	}
+	if _, err := client.GetFeature(bg, "feature"); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected feature to be deleted, got %v", err)
+	}
+	if _, err := client.GetTrainingSet(bg, "training-set"); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected training set to be deleted, got %v", err)
+	}
+	user, err := client.GetUser(bg, "Featureform")
+	if err != nil {
+		t.Fatalf("Failed to get user: %s", err)
+	}
+	for _, feature := range user.Features() {
+		if feature.Name == "feature" {
+			t.Fatalf("Deleted feature still owned by user: %v", user.Features())
+		}
+	}
+}
+
+func TestArchiveResource(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	label := ResourceID{Name: "label", Variant: "variant", Type: LABEL_VARIANT}
+	if _, err := client.ArchiveResource(bg, label, false); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected dependants to block archive, got %v", err)
+	}
+	archived, err := client.ArchiveResource(bg, label, true)
+	if err != nil {
+		t.Fatalf("Failed to archive label: %s", err)
+	}
+	if len(archived) != 3 || archived[len(archived)-1] != label {
+		t.Fatalf("Wrong resources archived: %v", archived)
+	}
+	for _, variant := range []string{"variant", "variant2"} {
+		trainingSet, err := client.GetTrainingSetVariant(bg, NameVariant{Name: "training-set", Variant: variant})
+		if err != nil {
+			t.Fatalf("Failed to get training set variant: %s", err)
+		}
+		if trainingSet.Status() != ARCHIVED {
+			t.Fatalf("Training set variant %s not archived: %s", variant, trainingSet.Status())
+		}
+	}
+	// Archived dependants don't block archiving.
+	if _, err := client.ArchiveResource(bg, ResourceID{Name: "feature2", Variant: "variant", Type: FEATURE_VARIANT}, false); err != nil {
+		t.Fatalf("Failed to archive feature variant: %s", err)
+	}
+}
+
+func TestResourceHistory(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := ContextWithUser(context.Background(), "alice")
+
+	start := time.Now()
+	update := EntityDef{Name: "user", Description: "A user entity", Tags: Tags{"pii"}, Properties: Properties{}}
+	if err := client.Create(bg, update); err != nil {
+		t.Fatalf("Failed to update entity: %s", err)
+	}
+	feature := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.SetStatus(bg, feature, READY, ""); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	if err := client.SetTags(bg, feature, Tags{"pii"}); err != nil {
+		t.Fatalf("Failed to set tags: %s", err)
+	}
+
+	history, err := client.GetResourceHistory(bg, ResourceID{Name: "user", Type: ENTITY})
+	if err != nil {
+		t.Fatalf("Failed to get history: %s", err)
+	}
+	// Creating its features, labels and training sets also updated it.
+	if len(history) < 2 {
+		t.Fatalf("Expected a create and an update, got %v", history)
+	}
+	if history[0].Action != pb.AuditEvent_CREATE || history[0].User != "" {
+		t.Fatalf("Wrong create event: %v", history[0])
+	}
+	updated := history[len(history)-1]
+	if updated.Action != pb.AuditEvent_UPDATE || updated.User != "alice" {
+		t.Fatalf("Wrong update event: %v", updated)
+	}
+	if len(updated.Changes) != 1 || updated.Changes[0].Field != "tags" || updated.Changes[0].OldValue != "{}" {
+		t.Fatalf("Wrong update diff: %v", updated.Changes)
+	}
+
+	history, err = client.GetResourceHistory(bg, feature)
+	if err != nil {
+		t.Fatalf("Failed to get history: %s", err)
+	}
+	tagged := history[len(history)-1]
+	if tagged.Action != pb.AuditEvent_UPDATE || tagged.User != "alice" || len(tagged.Changes) != 1 || tagged.Changes[0].Field != "tags" {
+		t.Fatalf("Wrong set tags event: %v", tagged)
+	}
+
+	events, err := client.ListAuditEvents(bg, start, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to list audit events: %s", err)
+	}
+	actions := make(map[pb.AuditEvent_Action]int)
+	for _, event := range events {
+		if event.Timestamp.AsTime().Before(start) {
+			t.Fatalf("Event before range start: %v", event)
+		}
+		actions[event.Action]++
+	}
+	if actions[pb.AuditEvent_UPDATE] != 2 || actions[pb.AuditEvent_STATUS_CHANGE] != 1 || actions[pb.AuditEvent_CREATE] != 0 {
+		t.Fatalf("Wrong events in range: %v", events)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan *pb.AuditEvent)
+	errs := make(chan error, 1)
+	go func() {
+		// Replaying from the start means the watch can't miss the status
+		// change, however long it takes to start.
+		opts := WatchOptions{Types: []ResourceType{FEATURE_VARIANT}, Names: []string{"feature"}, StartRevision: 1}
+		errs <- client.Watch(watchCtx, opts, func(event *pb.AuditEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+	feature := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.SetStatus(context.Background(), feature, READY, ""); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	var last int64
+	for {
+		select {
+		case event := <-events:
+			id := event.ResourceId
+			if id.ResourceType != pb.ResourceType_FEATURE_VARIANT || id.Resource.Name != "feature" {
+				t.Fatalf("Watch sent an unselected event: %v", event)
+			}
+			if event.Revision <= last {
+				t.Fatalf("Revisions out of order: %d after %d", event.Revision, last)
+			}
+			last = event.Revision
+			if event.Action == pb.AuditEvent_STATUS_CHANGE {
+				cancel()
+				if err := <-errs; err != context.Canceled {
+					t.Fatalf("Expected the watch to be canceled: %v", err)
+				}
+				return
+			}
+		case err := <-errs:
+			t.Fatalf("Watch stopped: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for the status change")
+		}
+	}
+}
+
+func TestLocalAuditLogWatchBehind(t *testing.T) {
+	auditLog := NewLocalAuditLog()
+	auditLog.Append(&pb.AuditEvent{Timestamp: tspb.Now()})
+	started := make(chan bool)
+	release := make(chan bool)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- auditLog.Watch(context.Background(), 1, func(event *pb.AuditEvent) error {
+			if event.Revision == 1 {
+				close(started)
+				<-release
+			}
+			return nil
+		})
+	}()
+	<-started
+	for i := 0; i <= watchBufferSize; i++ {
+		auditLog.Append(&pb.AuditEvent{Timestamp: tspb.Now()})
+	}
+	close(release)
+	if err := <-errs; err != ErrWatchBehind {
+		t.Fatalf("Expected the watch to fall behind: %v", err)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	newPassword := pc.RedisConfig{Addr: "0.0.0.0", Password: "new", DB: 0}
+	newAddr := pc.RedisConfig{Addr: "1.1.1.1", Password: "root", DB: 0}
+	redisProvider := func(config pc.RedisConfig) ProviderDef {
+		return ProviderDef{
+			Name:             "mockOnline",
+			Description:      "A mock online provider",
+			Type:             string(pt.RedisOnline),
+			Software:         "redis",
+			Team:             "fraud",
+			SerializedConfig: config.Serialized(),
+			Tags:             Tags{},
+			Properties:       Properties{},
+		}
+	}
+	feature := func(name, entity string, source NameVariant) FeatureDef {
+		return FeatureDef{
+			Name:     name,
+			Variant:  "variant",
+			Provider: "mockOnline",
+			Entity:   entity,
+			Type:     "float",
+			Source:   source,
+			Owner:    "Featureform",
+			Location: ResourceVariantColumns{
+				Entity: "col1",
+				Value:  "col2",
+				TS:     "col3",
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       PRECOMPUTED,
+		}
+	}
+	defs := []ResourceDef{
+		EntityDef{Name: "user", Description: "A user entity", Tags: Tags{}, Properties: Properties{}},
+		EntityDef{Name: "user", Description: "A user entity", Tags: Tags{"pii"}, Properties: Properties{}},
+		EntityDef{Name: "store", Description: "A store entity", Tags: Tags{}, Properties: Properties{}},
+		// Depends on an entity that only exists in the plan.
+		feature("store_feature", "store", NameVariant{Name: "mockSource", Variant: "var"}),
+		feature("missing_source", "user", NameVariant{Name: "missing", Variant: "var"}),
+		redisProvider(newPassword),
+		redisProvider(newAddr),
+	}
+	plans, err := client.Plan(bg, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	expected := []pb.ResourcePlan_Action{
+		pb.ResourcePlan_NO_OP,
+		pb.ResourcePlan_UPDATE,
+		pb.ResourcePlan_CREATE,
+		pb.ResourcePlan_CREATE,
+		pb.ResourcePlan_REJECT,
+		pb.ResourcePlan_UPDATE,
+		pb.ResourcePlan_REJECT,
+	}
+	if len(plans) != len(expected) {
+		t.Fatalf("Expected %d plans, got %v", len(expected), plans)
+	}
+	for i, action := range expected {
+		if plans[i].Action != action {
+			t.Fatalf("Expected %s for plan %d, got %v", action, i, plans[i])
+		}
+	}
+	if changes := plans[1].Changes; len(changes) != 1 || changes[0].Field != "tags" {
+		t.Fatalf("Wrong entity update changes: %v", changes)
+	}
+	if plans[4].Error == "" {
+		t.Fatalf("Rejected plan has no error: %v", plans[4])
+	}
+	if changes := plans[5].Changes; len(changes) != 1 || changes[0].Field != "serializedConfig.Password" || changes[0].NewValue != redactedValue {
+		t.Fatalf("Wrong provider update changes: %v", changes)
+	}
+	// The previous plan already changed the password.
+	if changes := plans[6].Changes; len(changes) != 2 || changes[0].Field != "serializedConfig.Addr" {
+		t.Fatalf("Wrong provider rejection changes: %v", changes)
+	}
+
+	// Nothing was written.
+	if _, err := client.GetEntity(bg, "store"); err == nil {
+		t.Fatalf("Plan created an entity")
+	}
+	entity, err := client.GetEntity(bg, "user")
+	if err != nil {
+		t.Fatalf("Failed to get entity: %s", err)
+	}
+	if len(entity.Tags()) != 0 {
+		t.Fatalf("Plan updated an entity: %v", entity.Tags())
+	}
+}
+
+func TestLineage(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	source := ResourceID{Name: "mockSource", Variant: "var", Type: SOURCE_VARIANT}
+	feature := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	label := ResourceID{Name: "label", Variant: "variant", Type: LABEL_VARIANT}
+	trainingSet := ResourceID{Name: "training-set", Variant: "variant", Type: TRAINING_SET_VARIANT}
+	trainingSet2 := ResourceID{Name: "training-set", Variant: "variant2", Type: TRAINING_SET_VARIANT}
+	nodeSet := func(lineage *pb.Lineage) map[ResourceID]bool {
+		nodes := make(map[ResourceID]bool)
+		for _, node := range lineage.Nodes {
+			nodes[resourceIDFromProto(node)] = true
+		}
+		return nodes
+	}
+
+	direct, err := client.GetLineage(bg, source, pb.LineageRequest_DOWNSTREAM, 1)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	expected := map[ResourceID]bool{
+		source:  true,
+		feature: true,
+		{Name: "feature2", Variant: "variant", Type: FEATURE_VARIANT}: true,
+		label: true,
+	}
+	if nodes := nodeSet(direct); !reflect.DeepEqual(nodes, expected) {
+		t.Fatalf("Wrong direct downstream nodes: %v", nodes)
+	}
+	if len(direct.Edges) != 3 {
+		t.Fatalf("Expected 3 edges, got %v", direct.Edges)
+	}
+	if edge := fmt.Sprintf("%q -> %q;", source.String(), feature.String()); !strings.Contains(direct.Dot, edge) {
+		t.Fatalf("DOT missing %s:\n%s", edge, direct.Dot)
+	}
+
+	all, err := client.GetLineage(bg, source, pb.LineageRequest_DOWNSTREAM, 0)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	if nodes := nodeSet(all); !nodes[trainingSet] || !nodes[trainingSet2] {
+		t.Fatalf("Training sets missing from downstream nodes: %v", nodes)
+	}
+
+	upstream, err := client.GetLineage(bg, trainingSet, pb.LineageRequest_UPSTREAM, 0)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	nodes := nodeSet(upstream)
+	for _, id := range []ResourceID{label, feature, source, {Name: "mockName", Variant: "mockVariant", Type: SOURCE_VARIANT}} {
+		if !nodes[id] {
+			t.Fatalf("%s missing from upstream nodes: %v", id, nodes)
+		}
+	}
+	if nodes[trainingSet2] {
+		t.Fatalf("Upstream lineage contains downstream resources: %v", nodes)
+	}
+
+	// A parent's lineage is that of its variants.
+	parent, err := client.GetLineage(bg, ResourceID{Name: "mockSource", Type: SOURCE}, pb.LineageRequest_DOWNSTREAM, 0)
+	if err != nil {
+		t.Fatalf("Failed to get lineage: %s", err)
+	}
+	if nodes := nodeSet(parent); !nodes[source] || !nodes[trainingSet] {
+		t.Fatalf("Wrong parent lineage nodes: %v", nodes)
+	}
+}
+
+func searchNames(t *testing.T, searcher search.Searcher, q string) []string {
+	results, err := searcher.RunSearch(q)
+	if err != nil {
+		t.Fatalf("Failed to search: %s", err)
+	}
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = fmt.Sprintf("%s %s %s", result.Type, result.Name, result.Variant)
+	}
+	return names
+}
+
+func TestEmbeddedSearch(t *testing.T) {
+	dir := t.TempDir()
+	existing := openTestFileLookup(t, filepath.Join(dir, "resources.log"))
+	user := &userResource{&pb.User{Name: "Featureform", Tags: &pb.Tags{Tag: []string{"admin"}}}}
+	if err := existing.Set(user.ID(), user); err != nil {
+		t.Fatalf("Failed to set: %s", err)
+	}
+	existing.Close()
+
+	serv, err := NewMetadataServer(&Config{
+		Logger:          zaptest.NewLogger(t).Sugar(),
+		StorageProvider: FileStorageProvider{Dir: dir},
+		EmbeddedSearch:  true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %s", err)
+	}
+	wrapper := serv.lookup.(*SearchWrapper)
+	if names := searchNames(t, wrapper.Searcher, "admin"); !reflect.DeepEqual(names, []string{"USER Featureform "}) {
+		t.Fatalf("Stored resources not indexed on startup: %v", names)
+	}
+
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go serv.ServeOnListener(lis)
+	defer serv.Stop()
+	client := client(t, lis.Addr().String())
+	defer client.Close()
+	if err := client.CreateAll(context.Background(), filledResourceDefs()[1:]); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	// Close matches, like feature, follow the exact ones.
+	expected := []string{"FEATURE_VARIANT feature2 variant", "FEATURE feature2 "}
+	if names := searchNames(t, wrapper.Searcher, "feature2"); !reflect.DeepEqual(names[:2], expected) {
+		t.Fatalf("Created resources not indexed: %v", names)
+	}
+	if names := searchNames(t, wrapper.Searcher, "snowflake"); len(names) != 0 {
+		t.Fatalf("Expected provider software not to be indexed: %v", names)
+	}
+
+	// Statuses are search fields, so setting one reindexes the resource.
+	failed := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := wrapper.SetStatus(failed, &pb.ResourceStatus{Status: pb.ResourceStatus_FAILED}); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	query, err := search.ParseQuery("status:failed")
+	if err != nil {
+		t.Fatalf("Failed to parse query: %s", err)
+	}
+	docs, err := wrapper.Searcher.RunQuery(query)
+	if err != nil {
+		t.Fatalf("Failed to run query: %s", err)
+	}
+	if len(docs) != 1 || docs[0].Name != failed.Name || docs[0].Variant != failed.Variant {
+		t.Fatalf("Set status not indexed: %v", docs)
+	}
+
+	// A second index follows the first through watched changes.
+	follower := search.NewEmbeddedSearch()
+	if err := IndexResources(wrapper.ResourceLookup, follower); err != nil {
+		t.Fatalf("Failed to index resources: %s", err)
+	}
+	if before, after := searchNames(t, wrapper.Searcher, ""), searchNames(t, follower, ""); !reflect.DeepEqual(before, after) {
+		t.Fatalf("Rebuilt index differs:\n%v\n%v", before, after)
+	}
+	feature := ResourceID{Name: "feature2", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := wrapper.ResourceLookup.Delete(feature); err != nil {
+		t.Fatalf("Failed to delete: %s", err)
+	}
+	deleted := &pb.AuditEvent{ResourceId: resourceIDsToProto([]ResourceID{feature})[0], Action: pb.AuditEvent_DELETE}
+	if err := IndexChange(wrapper.ResourceLookup, follower, deleted); err != nil {
+		t.Fatalf("Failed to index change: %s", err)
+	}
+	if names := searchNames(t, follower, "feature2"); names[0] != "FEATURE feature2 " || slices.Contains(names, expected[0]) {
+		t.Fatalf("Deleted resource still indexed: %v", names)
+	}
+}
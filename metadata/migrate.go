@@ -0,0 +1,112 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pb "github.com/featureform/metadata/proto"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// EtcdMigration counts the rows copied by MigrateEtcdToSQL.
+type EtcdMigration struct {
+	Resources   int
+	Jobs        int
+	AuditEvents int
+	// Skipped counts the keys other services keep in etcd, like coordinator
+	// locks, which aren't metadata.
+	Skipped int
+}
+
+// nonMetadataPrefixes are the prefixes of keys that the coordinator and
+// workers keep in the metadata keyspace.
+var nonMetadataPrefixes = []string{
+	"LOCK_",
+	"UPDATE_EVENT_",
+}
+
+// MigrateEtcdToSQL copies a metadata keyspace from etcd into a SQL database
+// in a single transaction. Rows that already exist are overwritten, so a
+// failed migration can be rerun.
+func MigrateEtcdToSQL(source EtcdStorage, dest SQLStorage) (EtcdMigration, error) {
+	resp, err := source.genericGet("", true)
+	if err != nil {
+		return EtcdMigration{}, errors.Wrap(err, "could not read etcd keyspace")
+	}
+	return migrateKeyValues(resp.Kvs, dest)
+}
+
+func migrateKeyValues(kvs []*mvccpb.KeyValue, dest SQLStorage) (EtcdMigration, error) {
+	migration := EtcdMigration{}
+	err := dest.Transaction(func(tx *sql.Tx) error {
+		for _, kv := range kvs {
+			key := string(kv.Key)
+			if hasAnyPrefix(key, nonMetadataPrefixes) {
+				migration.Skipped++
+				continue
+			}
+			// Time keys are copies of events that are also in histories.
+			if strings.HasPrefix(key, auditTimeKeyPrefix) {
+				continue
+			}
+			// Jobs are stored as plain JSON rather than in a row wrapper.
+			if strings.HasPrefix(key, "JOB__") || strings.HasPrefix(key, "SCHEDULEJOB__") {
+				if err := dest.putJob(tx, key, string(kv.Value)); err != nil {
+					return errors.Wrap(err, fmt.Sprintf("could not migrate job: %s", key))
+				}
+				migration.Jobs++
+				continue
+			}
+			var row EtcdRowTemp
+			if err := json.Unmarshal(kv.Value, &row); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("could not parse: %s", key))
+			}
+			switch row.StorageType {
+			case RESOURCE:
+				res, err := unmarshalResource(row.ResourceType, row.Message)
+				if err != nil {
+					return errors.Wrap(err, fmt.Sprintf("could not parse resource: %s", key))
+				}
+				// Revisions in etcd are its own, so they start over.
+				setRevision(res, 1)
+				if err := dest.putResource(tx, res); err != nil {
+					return errors.Wrap(err, fmt.Sprintf("could not migrate resource: %s", key))
+				}
+				migration.Resources++
+			case AUDIT:
+				event := &pb.AuditEvent{}
+				if err := proto.Unmarshal(row.Message, event); err != nil {
+					return errors.Wrap(err, fmt.Sprintf("could not parse audit event: %s", key))
+				}
+				if err := dest.putAuditEvent(tx, key, event); err != nil {
+					return errors.Wrap(err, fmt.Sprintf("could not migrate audit event: %s", key))
+				}
+				migration.AuditEvents++
+			default:
+				return fmt.Errorf("unknown storage type %q: %s", row.StorageType, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return EtcdMigration{}, err
+	}
+	return migration, nil
+}
+
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Copies the metadata stored in etcd into a SQL database. The metadata server
+// can't be switched over to it until the coordinator can read jobs from SQL.
+package main
+
+import (
+	help "github.com/featureform/helpers"
+	"github.com/featureform/logging"
+	"github.com/featureform/metadata"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	logger := logging.NewLogger("metadata-migrate")
+	etcdConfig := metadata.EtcdConfig{
+		Nodes: []metadata.EtcdNode{
+			{help.GetEnv("ETCD_HOST", "localhost"), help.GetEnv("ETCD_PORT", "2379")},
+		},
+	}
+	client, err := etcdConfig.InitClient()
+	if err != nil {
+		logger.Panicw("Failed to connect to etcd", "Err", err)
+	}
+	defer client.Close()
+	sqlConfig := metadata.SQLConfig{
+		Driver:     metadata.SQLDriver(help.GetEnv("METADATA_SQL_DRIVER", string(metadata.PostgresDriver))),
+		DataSource: help.GetEnv("METADATA_SQL_DSN", ""),
+	}
+	storage, err := sqlConfig.Open()
+	if err != nil {
+		logger.Panicw("Failed to open metadata database", "Err", err)
+	}
+	defer storage.DB.Close()
+	migration, err := metadata.MigrateEtcdToSQL(metadata.EtcdStorage{Client: client}, storage)
+	if err != nil {
+		logger.Panicw("Migration failed", "Err", err)
+	}
+	logger.Infow("Migrated metadata", "resources", migration.Resources, "jobs", migration.Jobs, "audit_events", migration.AuditEvents, "skipped", migration.Skipped)
+}
@@ -0,0 +1,71 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestMigrateKeyValues(t *testing.T) {
+	row := func(storageType StorageType, resourceType ResourceType, msg proto.Message) []byte {
+		p, err := proto.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Failed to marshal: %s", err)
+		}
+		value, err := json.Marshal(EtcdRowTemp{ResourceType: resourceType, StorageType: storageType, Message: p})
+		if err != nil {
+			t.Fatalf("Failed to marshal row: %s", err)
+		}
+		return value
+	}
+	user := ResourceID{Name: "Featureform", Type: USER}
+	event := &pb.AuditEvent{
+		ResourceId: &pb.ResourceID{Resource: user.Proto(), ResourceType: user.Type.Serialized()},
+		Action:     pb.AuditEvent_CREATE,
+		Timestamp:  tspb.Now(),
+	}
+	eventRow := row(AUDIT, USER, event)
+	nanos := event.Timestamp.AsTime().UnixNano()
+	kvs := []*mvccpb.KeyValue{
+		{Key: []byte(createKey(user)), Value: row(RESOURCE, USER, &pb.User{Name: user.Name})},
+		{Key: []byte(auditEventKey(user, nanos, "suffix")), Value: eventRow},
+		{Key: []byte(auditTimeKey(nanos, "suffix")), Value: eventRow},
+		{Key: []byte(GetJobKey(user)), Value: []byte(`{"Attempts":0}`)},
+		// The coordinator's job locks and the workers' update events aren't
+		// metadata, and aren't rows either.
+		{Key: []byte("LOCK_JOB__USER__Featureform__/694d8a1b2c3d"), Value: []byte{}},
+		{Key: []byte("UPDATE_EVENT_name__variant__FEATURE_VARIANT__id"), Value: []byte(`{"ResourceID":{}}`)},
+	}
+	dest := sqliteStorageProvider(t).Storage
+	migration, err := migrateKeyValues(kvs, dest)
+	if err != nil {
+		t.Fatalf("Failed to migrate: %s", err)
+	}
+	expected := EtcdMigration{Resources: 1, Jobs: 1, AuditEvents: 1, Skipped: 2}
+	if migration != expected {
+		t.Fatalf("Wrong migration counts: %+v\nExpected: %+v", migration, expected)
+	}
+	history, err := SQLAuditLog{Connection: dest}.History(user)
+	if err != nil {
+		t.Fatalf("Failed to get history: %s", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected the audit event to be migrated once: %v", history)
+	}
+	if _, err := (SQLResourceLookup{Connection: dest}).Lookup(user); err != nil {
+		t.Fatalf("Failed to look up migrated user: %s", err)
+	}
+	// Unknown keys still fail the migration.
+	unknown := append(kvs, &mvccpb.KeyValue{Key: []byte("UNKNOWN"), Value: []byte("not a row")})
+	if _, err := migrateKeyValues(unknown, dest); err == nil {
+		t.Fatalf("Expected an unknown key to fail the migration")
+	}
+}
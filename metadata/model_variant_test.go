@@ -0,0 +1,137 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestModelVariant(t *testing.T) {
+	model := NameVariant{Name: "fraud", Variant: "v1"}
+	trainingSet := NameVariant{Name: "training-set", Variant: "variant"}
+	defs := append(filledResourceDefs(),
+		ModelVariantDef{
+			Name:        model.Name,
+			Variant:     model.Variant,
+			Description: "fraud model trained on training-set",
+			TrainingSet: trainingSet,
+			Tags:        Tags{"tag"},
+		},
+	)
+	ctx := testContext{
+		Defs: defs,
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	variant, err := client.GetModelVariant(bg, model)
+	if err != nil {
+		t.Fatalf("Failed to get model variant: %s", err)
+	}
+	if variant.Status() != READY {
+		t.Fatalf("Expected model variant to be ready: %s", variant.Status())
+	}
+	// The features, label and owner are the training set's.
+	expectedFeatures := NameVariants{
+		{Name: "feature", Variant: "variant"},
+		{Name: "feature", Variant: "variant2"},
+	}
+	if !reflect.DeepEqual(variant.Features(), expectedFeatures) {
+		t.Fatalf("Expected model variant features %v, found %v", expectedFeatures, variant.Features())
+	}
+	if variant.Label() != (NameVariant{Name: "label", Variant: "variant"}) {
+		t.Fatalf("Wrong model variant label: %v", variant.Label())
+	}
+	if variant.Owner() != "Other" {
+		t.Fatalf("Expected model variant to be owned by the training set's owner: %s", variant.Owner())
+	}
+	if variant.TrainingSet() != trainingSet {
+		t.Fatalf("Wrong model variant training set: %v", variant.TrainingSet())
+	}
+
+	parent, err := client.GetModel(bg, model.Name)
+	if err != nil {
+		t.Fatalf("Failed to get model: %s", err)
+	}
+	if parent.DefaultVariant() != model.Variant || !reflect.DeepEqual(parent.Variants(), []string{model.Variant}) {
+		t.Fatalf("Wrong model variants: %s", parent)
+	}
+	if parent.Description() != "fraud model" {
+		t.Fatalf("Expected model to keep its description: %s", parent.Description())
+	}
+	ts, err := client.GetTrainingSetVariant(bg, trainingSet)
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	if !reflect.DeepEqual(ts.Models(), NameVariants{model}) {
+		t.Fatalf("Wrong training set models: %v", ts.Models())
+	}
+
+	// Creating the variant again only merges tags, and it can't move to
+	// another training set.
+	again := ModelVariantDef{Name: model.Name, Variant: model.Variant, TrainingSet: trainingSet, Tags: Tags{"other"}}
+	if err := client.CreateModelVariant(bg, again); err != nil {
+		t.Fatalf("Failed to recreate model variant: %s", err)
+	}
+	variant, err = client.GetModelVariant(bg, model)
+	if err != nil {
+		t.Fatalf("Failed to get model variant: %s", err)
+	}
+	if !reflect.DeepEqual(variant.Tags(), Tags{"tag", "other"}) {
+		t.Fatalf("Wrong model variant tags: %v", variant.Tags())
+	}
+	again.TrainingSet = NameVariant{Name: "training-set", Variant: "variant2"}
+	if err := client.CreateModelVariant(bg, again); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("Expected moving a model variant to another training set to fail: %v", err)
+	}
+
+	inconsistencies, err := client.CheckIntegrity(bg, false, false)
+	if err != nil {
+		t.Fatalf("Failed to check integrity: %s", err)
+	}
+	if len(inconsistencies) != 0 {
+		t.Fatalf("Expected no inconsistencies: %v", inconsistencies)
+	}
+}
+
+func TestModelVariantInvalid(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	invalid := map[string]struct {
+		def  ModelVariantDef
+		code codes.Code
+	}{
+		"no training set": {
+			ModelVariantDef{Name: "model", Variant: "v1", Owner: "Featureform"},
+			codes.InvalidArgument,
+		},
+		"missing training set": {
+			ModelVariantDef{Name: "model", Variant: "v1", Owner: "Featureform", TrainingSet: NameVariant{Name: "training-set", Variant: "missing"}},
+			codes.NotFound,
+		},
+	}
+	for name, test := range invalid {
+		if err := client.CreateModelVariant(bg, test.def); status.Code(err) != test.code {
+			t.Fatalf("Expected model with %s to fail with %s: %v", name, test.code, err)
+		}
+	}
+}
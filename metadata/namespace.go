@@ -0,0 +1,145 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+// DefaultNamespace is the namespace of resources created without one. Its
+// resources are stored as they were before namespaces existed.
+const DefaultNamespace = ""
+
+// Namespaces can't contain underscores, so that they can't be confused with
+// resource names in keys and provider table names.
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// isNamespaced is false for the types shared by every namespace.
+func isNamespaced(t ResourceType) bool {
+	return t != USER && t != PROVIDER
+}
+
+// namespaceOf is the namespace a resource of type t in namespace is stored
+// in, which is the default one for types that aren't namespaced.
+func namespaceOf(t ResourceType, namespace string) string {
+	if !isNamespaced(t) {
+		return DefaultNamespace
+	}
+	return namespace
+}
+
+func checkNamespace(id ResourceID) error {
+	if id.Namespace == DefaultNamespace {
+		return nil
+	}
+	if !isNamespaced(id.Type) {
+		return fmt.Errorf("%s %s cannot be in a namespace", id.Type, id.Name)
+	}
+	if !namespacePattern.MatchString(id.Namespace) {
+		return fmt.Errorf("invalid namespace %q: namespaces are lowercase letters, digits and hyphens", id.Namespace)
+	}
+	return nil
+}
+
+// inNamespace puts the namespaced ids, which are references from a resource
+// in namespace, in that namespace.
+func inNamespace(ids []ResourceID, namespace string) []ResourceID {
+	for i := range ids {
+		ids[i].Namespace = namespaceOf(ids[i].Type, namespace)
+	}
+	return ids
+}
+
+// QualifiedName is the name a resource's tables are given in providers.
+// Resource names can't start with an underscore, so names in other namespaces
+// can't clash with ones in the default namespace.
+func QualifiedName(namespace, name string) string {
+	if namespace == DefaultNamespace {
+		return name
+	}
+	return fmt.Sprintf("_%s_%s", namespace, name)
+}
+
+// providerVisible returns whether resources in namespace can use provider.
+func providerVisible(provider *pb.Provider, namespace string) bool {
+	if namespace == DefaultNamespace || len(provider.Namespaces) == 0 {
+		return true
+	}
+	for _, visible := range provider.Namespaces {
+		if visible == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// checkProvidersVisible returns an error if res uses a provider that isn't
+// visible to its namespace.
+func checkProvidersVisible(lookup ResourceLookup, res Resource) error {
+	namespace := res.ID().Namespace
+	if namespace == DefaultNamespace {
+		return nil
+	}
+	withProvider, ok := res.Proto().(interface{ GetProvider() string })
+	if !ok || withProvider.GetProvider() == "" {
+		return nil
+	}
+	dep, err := lookup.Lookup(ResourceID{Name: withProvider.GetProvider(), Type: PROVIDER})
+	if err != nil {
+		return err
+	}
+	provider := dep.Proto().(*pb.Provider)
+	if !providerVisible(provider, namespace) {
+		return fmt.Errorf("provider %s is not visible to namespace %s", provider.Name, namespace)
+	}
+	return nil
+}
+
+// qualifyReferences puts the references res makes to other resources, which
+// are always to its own namespace, in that namespace, so that they can be
+// followed without knowing where they're from.
+func qualifyReferences(res Resource) error {
+	namespace := res.ID().Namespace
+	refs := make([]*pb.NameVariant, 0)
+	switch serialized := res.Proto().(type) {
+	case *pb.FeatureVariant:
+		refs = append(refs, serialized.Source)
+	case *pb.LabelVariant:
+		refs = append(refs, serialized.Source)
+	case *pb.TrainingSetVariant:
+		refs = append(refs, serialized.Label)
+		refs = append(refs, serialized.Features...)
+		refs = append(refs, serialized.FeatureGroups...)
+	case *pb.FeatureGroupVariant:
+		refs = append(refs, serialized.Features...)
+	case *pb.SourceVariant:
+		if transformation := serialized.GetTransformation(); transformation != nil {
+			refs = append(refs, transformation.GetSQLTransformation().GetSource()...)
+			refs = append(refs, transformation.GetDFTransformation().GetInputs()...)
+		}
+	case *pb.Model:
+		refs = append(refs, serialized.Features...)
+		refs = append(refs, serialized.Labels...)
+		refs = append(refs, serialized.Trainingsets...)
+		refs = append(refs, serialized.FeatureGroups...)
+	case *pb.ModelVariant:
+		refs = append(refs, serialized.TrainingSet, serialized.Label)
+		refs = append(refs, serialized.Features...)
+		refs = append(refs, serialized.FeatureGroups...)
+	}
+	for _, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		if ref.Namespace != DefaultNamespace && ref.Namespace != namespace {
+			return fmt.Errorf("%s cannot use %s from namespace %s", res.ID(), ref.Name, ref.Namespace)
+		}
+		ref.Namespace = namespace
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func namespacedResourceDefs(namespace string) []ResourceDef {
+	return []ResourceDef{
+		EntityDef{
+			Name:        "user",
+			Namespace:   namespace,
+			Description: "A user entity",
+			Tags:        Tags{},
+			Properties:  Properties{},
+		},
+		SourceDef{
+			Name:        "mockSource",
+			Variant:     "var",
+			Namespace:   namespace,
+			Description: "A team's primary source",
+			Definition: PrimaryDataSource{
+				Location: SQLTable{
+					Name: "teamPrimary",
+				},
+			},
+			Owner:      "Featureform",
+			Provider:   "mockOffline",
+			Tags:       Tags{},
+			Properties: Properties{},
+		},
+		FeatureDef{
+			Name:        "feature",
+			Variant:     "variant",
+			Namespace:   namespace,
+			Provider:    "mockOnline",
+			Entity:      "user",
+			Type:        "string",
+			Description: "A team's feature",
+			Source:      NameVariant{Name: "mockSource", Variant: "var"},
+			Owner:       "Featureform",
+			Location: ResourceVariantColumns{
+				Entity: "col1",
+				Value:  "col2",
+				TS:     "col3",
+			},
+			Tags:       Tags{},
+			Properties: Properties{},
+			Mode:       PRECOMPUTED,
+		},
+	}
+}
+
+func TestNamespaceIsolation(t *testing.T) {
+	ctx := testContext{
+		Defs: append(filledResourceDefs(), namespacedResourceDefs("team-a")...),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	variant, err := client.GetFeatureVariant(bg, NameVariant{Name: "feature", Variant: "variant", Namespace: "team-a"})
+	if err != nil {
+		t.Fatalf("Failed to get namespaced feature: %s", err)
+	}
+	if variant.Description() != "A team's feature" || variant.Namespace() != "team-a" {
+		t.Fatalf("Wrong namespaced feature: %v", variant)
+	}
+	if source := variant.Source(); source.Namespace != "team-a" {
+		t.Fatalf("Source reference not in feature's namespace: %v", source)
+	}
+	variant, err = client.GetFeatureVariant(bg, NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get default feature: %s", err)
+	}
+	if variant.Description() != "Feature variant" || variant.Namespace() != DefaultNamespace {
+		t.Fatalf("Wrong default feature: %v", variant)
+	}
+
+	teamFeatures, err := client.InNamespace("team-a").ListFeatures(bg)
+	if err != nil {
+		t.Fatalf("Failed to list namespaced features: %s", err)
+	}
+	if names := featureNames(teamFeatures); !reflect.DeepEqual(names, []string{"feature"}) {
+		t.Fatalf("Wrong namespaced features listed: %v", names)
+	}
+	if variants := teamFeatures[0].Variants(); !reflect.DeepEqual(variants, []string{"variant"}) {
+		t.Fatalf("Wrong namespaced feature variants: %v", variants)
+	}
+	defaultFeatures, err := client.ListFeatures(bg)
+	if err != nil {
+		t.Fatalf("Failed to list features: %s", err)
+	}
+	if names := featureNames(defaultFeatures); !reflect.DeepEqual(names, []string{"feature", "feature2", "feature3"}) {
+		t.Fatalf("Wrong default features listed: %v", names)
+	}
+	if _, err := client.InNamespace("team-b").GetFeature(bg, "feature"); err == nil {
+		t.Fatalf("Expected feature to be missing from another namespace")
+	}
+}
+
+func TestNamespaceReferences(t *testing.T) {
+	ctx := testContext{
+		Defs: append(filledResourceDefs(), namespacedResourceDefs("team-a")...),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	crossNamespace := namespacedResourceDefs("team-a")[2].(FeatureDef)
+	crossNamespace.Variant = "cross"
+	crossNamespace.Source.Namespace = "team-b"
+	if err := client.Create(bg, crossNamespace); err == nil {
+		t.Fatalf("Expected a reference to another namespace to fail")
+	}
+	// The source only exists in team-a.
+	missingSource := namespacedResourceDefs("team-b")[2].(FeatureDef)
+	if err := client.CreateAll(bg, []ResourceDef{namespacedResourceDefs("team-b")[0], missingSource}); err == nil {
+		t.Fatalf("Expected a reference to a missing source to fail")
+	}
+	invalid := namespacedResourceDefs("Team_A")[0]
+	if err := client.Create(bg, invalid); err == nil {
+		t.Fatalf("Expected an invalid namespace to fail")
+	}
+}
+
+func TestNamespaceProviderVisibility(t *testing.T) {
+	private := ProviderDef{
+		Name:             "teamBOnline",
+		Description:      "An online provider only team-b can use",
+		Type:             string(pt.RedisOnline),
+		Software:         "redis",
+		Namespaces:       []string{"team-b"},
+		SerializedConfig: []byte{},
+		Tags:             Tags{},
+		Properties:       Properties{},
+	}
+	ctx := testContext{
+		Defs: append(append(filledResourceDefs(), private), namespacedResourceDefs("team-a")...),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	hidden := namespacedResourceDefs("team-a")[2].(FeatureDef)
+	hidden.Variant = "hidden"
+	hidden.Provider = "teamBOnline"
+	if err := client.Create(bg, hidden); err == nil {
+		t.Fatalf("Expected a provider hidden from the namespace to fail")
+	}
+	visible := namespacedResourceDefs("team-b")
+	visible[2] = func(def FeatureDef) FeatureDef {
+		def.Provider = "teamBOnline"
+		return def
+	}(visible[2].(FeatureDef))
+	if err := client.CreateAll(bg, visible); err != nil {
+		t.Fatalf("Failed to use a provider visible to the namespace: %s", err)
+	}
+}
+
+func TestNamespaceKeys(t *testing.T) {
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if key := createKey(id); key != "FEATURE_VARIANT__feature__variant" {
+		t.Fatalf("Default namespace key changed: %s", key)
+	}
+	id.Namespace = "team-a"
+	if key := createKey(id); key != "FEATURE_VARIANT___team-a__feature__variant" {
+		t.Fatalf("Wrong namespaced key: %s", key)
+	}
+	if name := QualifiedName("team-a", "feature"); name != "_team-a_feature" {
+		t.Fatalf("Wrong qualified name: %s", name)
+	}
+	if name := QualifiedName(DefaultNamespace, "feature"); name != "feature" {
+		t.Fatalf("Default namespace name changed: %s", name)
+	}
+}
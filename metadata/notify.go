@@ -0,0 +1,147 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"time"
+
+	"github.com/featureform/metadata/notify"
+	pb "github.com/featureform/metadata/proto"
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func serializeSubscriptions(subs []notify.Subscription) []*pb.NotificationSubscription {
+	serialized := make([]*pb.NotificationSubscription, len(subs))
+	for i, sub := range subs {
+		events := make([]string, len(sub.Events))
+		for j, event := range sub.Events {
+			events[j] = string(event)
+		}
+		serialized[i] = &pb.NotificationSubscription{Sink: sub.Sink, Address: sub.Address, Events: events}
+	}
+	return serialized
+}
+
+func parseSubscriptions(serialized []*pb.NotificationSubscription) []notify.Subscription {
+	subs := make([]notify.Subscription, len(serialized))
+	for i, sub := range serialized {
+		events := make([]notify.EventKind, len(sub.Events))
+		for j, event := range sub.Events {
+			events[j] = notify.EventKind(event)
+		}
+		subs[i] = notify.Subscription{Sink: sub.Sink, Address: sub.Address, Events: events}
+	}
+	return subs
+}
+
+func validateSubscriptions(subs []*pb.NotificationSubscription) error {
+	for _, sub := range subs {
+		if !slices.Contains(notify.SinkNames, sub.Sink) {
+			return status.Errorf(codes.InvalidArgument, "unknown notification sink %q: expected one of %v", sub.Sink, notify.SinkNames)
+		}
+		if sub.Sink == notify.SMTPSinkName && sub.Address == "" {
+			return status.Errorf(codes.InvalidArgument, "email notifications need an address")
+		}
+		for _, event := range sub.Events {
+			if !slices.Contains(notify.EventKinds, notify.EventKind(event)) {
+				return status.Errorf(codes.InvalidArgument, "unknown notification event %q: expected one of %v", event, notify.EventKinds)
+			}
+		}
+	}
+	return nil
+}
+
+// statusEvent returns the event for a resource's status going from before to
+// after, if its owner should be told about it. Resources have recovered when
+// they're ready after failing, which they're still marked as while they're
+// retried.
+func statusEvent(id ResourceID, before, after *pb.ResourceStatus) (notify.Event, bool) {
+	event := notify.Event{
+		ResourceType: id.Type.String(),
+		Name:         id.Name,
+		Variant:      id.Variant,
+		Time:         time.Now(),
+	}
+	switch {
+	case after.GetStatus() == pb.ResourceStatus_FAILED:
+		failure := parseResourceError(after.GetError())
+		event.Kind = notify.Failed
+		event.Message = failure.Message
+		event.Runner = failure.Runner
+		event.Attempt = failure.Attempt
+		if !failure.Failed.IsZero() {
+			event.Time = failure.Failed
+		}
+	case after.GetStatus() == pb.ResourceStatus_READY && before.GetError() != nil:
+		event.Kind = notify.Recovered
+	default:
+		return notify.Event{}, false
+	}
+	return event, true
+}
+
+// notifyOwner tells the owner of a resource with a job about it failing or
+// recovering, through the sinks they've subscribed to.
+func (serv *MetadataServer) notifyOwner(res Resource, before proto.Message) {
+	if serv.notifier == nil || !serv.needsJob(res) {
+		return
+	}
+	var previous *pb.ResourceStatus
+	if getter, ok := before.(statusGetter); ok {
+		previous = getter.GetStatus()
+	}
+	event, notable := statusEvent(res.ID(), previous, resourceStatus(res))
+	if !notable {
+		return
+	}
+	owned, ok := res.Proto().(interface{ GetOwner() string })
+	if !ok || owned.GetOwner() == "" {
+		return
+	}
+	event.Owner = owned.GetOwner()
+	user, err := serv.lookup.Lookup(ResourceID{Name: event.Owner, Type: USER})
+	if err != nil {
+		serv.Logger.Warnw("Could not look up owner to notify", "owner", event.Owner, "resource", res.ID().String(), "error", err)
+		return
+	}
+	subs := parseSubscriptions(user.Proto().(*pb.User).GetNotifications())
+	serv.notifier.Notify(subs, event)
+}
+
+// SetNotifications replaces the notification subscriptions of a user.
+func (serv *MetadataServer) SetNotifications(ctx context.Context, req *pb.NotificationsRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Setting notifications", "user", req.User)
+	if err := validateSubscriptions(req.Notifications); err != nil {
+		return nil, err
+	}
+	id := ResourceID{Name: req.User, Type: USER}
+	before, after, err := updateResource(serv.lookup, id, req.ExpectedRevision, func(res Resource) error {
+		res.Proto().(*pb.User).Notifications = req.Notifications
+		return nil
+	})
+	if err != nil {
+		serv.Logger.Errorw("Could not set notifications", "user", req.User, "error", err)
+		return nil, err
+	}
+	serv.audit(ctx, pb.AuditEvent_UPDATE, id, before, after.Proto())
+	return &pb.Empty{}, nil
+}
+
+// SetNotifications replaces how a user is notified of their resources
+// failing and recovering.
+func (client *Client) SetNotifications(ctx context.Context, user string, subs []notify.Subscription) error {
+	_, err := client.GrpcConn.SetNotifications(ctx, &pb.NotificationsRequest{User: user, Notifications: serializeSubscriptions(subs)})
+	return err
+}
+
+// Notifications returns how the user is notified of their resources failing
+// and recovering.
+func (user *User) Notifications() []notify.Subscription {
+	return parseSubscriptions(user.serialized.GetNotifications())
+}
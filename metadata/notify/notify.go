@@ -0,0 +1,167 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package notify tells resource owners when their resources' jobs fail or
+// recover, through sinks like email or a webhook.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type EventKind string
+
+const (
+	// Failed is sent each time a resource's job fails.
+	Failed EventKind = "failed"
+	// Recovered is sent when a resource that failed becomes ready.
+	Recovered EventKind = "recovered"
+)
+
+// EventKinds are all of the kinds of event, in the order they're documented.
+var EventKinds = []EventKind{Failed, Recovered}
+
+// Event is a change to a resource that its owner is notified of.
+type Event struct {
+	Kind         EventKind `json:"kind"`
+	ResourceType string    `json:"resource_type"`
+	Name         string    `json:"name"`
+	Variant      string    `json:"variant"`
+	Owner        string    `json:"owner"`
+	Time         time.Time `json:"time"`
+	// Why the resource failed. They're empty for recoveries.
+	Message string `json:"message,omitempty"`
+	Runner  string `json:"runner,omitempty"`
+	Attempt int    `json:"attempt,omitempty"`
+	// Suppressed is how many notifications to the same recipient were
+	// dropped by the rate limit before this one.
+	Suppressed int `json:"suppressed,omitempty"`
+}
+
+// Subject is a one line summary of the event.
+func (event Event) Subject() string {
+	resource := event.Name
+	if event.Variant != "" {
+		resource = fmt.Sprintf("%s (%s)", event.Name, event.Variant)
+	}
+	resourceType := strings.ReplaceAll(strings.ToLower(event.ResourceType), "_", " ")
+	return fmt.Sprintf("[Featureform] %s %s %s", resourceType, resource, event.Kind)
+}
+
+// Text describes the event in full.
+func (event Event) Text() string {
+	lines := []string{event.Subject()}
+	if event.Message != "" {
+		lines = append(lines, fmt.Sprintf("Error: %s", event.Message))
+	}
+	if event.Runner != "" {
+		lines = append(lines, fmt.Sprintf("Runner: %s", event.Runner))
+	}
+	if event.Attempt != 0 {
+		lines = append(lines, fmt.Sprintf("Attempt: %d", event.Attempt))
+	}
+	lines = append(lines, fmt.Sprintf("Time: %s", event.Time.UTC().Format(time.RFC3339)))
+	if event.Suppressed != 0 {
+		lines = append(lines, fmt.Sprintf("%d earlier notifications were suppressed", event.Suppressed))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// A Sink delivers notifications. Address is where the subscriber wants them,
+// like an email address; sinks with a fixed destination pass it along.
+type Sink interface {
+	Send(ctx context.Context, address string, event Event) error
+}
+
+// The names subscriptions use for each kind of sink.
+const (
+	WebhookSinkName = "webhook"
+	SMTPSinkName    = "smtp"
+	SlackSinkName   = "slack"
+)
+
+// SinkNames are the sinks a subscription can name.
+var SinkNames = []string{WebhookSinkName, SMTPSinkName, SlackSinkName}
+
+// Subscription is a user asking for events through a sink. It's for every
+// kind of event if Events is empty.
+type Subscription struct {
+	Sink    string
+	Address string
+	Events  []EventKind
+}
+
+func (sub Subscription) Wants(kind EventKind) bool {
+	if len(sub.Events) == 0 {
+		return true
+	}
+	for _, wanted := range sub.Events {
+		if wanted == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier sends events to the subscriptions that want them. Sinks that
+// aren't configured are skipped.
+type Notifier struct {
+	Sinks  map[string]Sink
+	Logger *zap.SugaredLogger
+	// Limiter limits how often each recipient is notified, if set.
+	Limiter *RateLimiter
+	// Timeout is how long each send has. It's DefaultTimeout if zero.
+	Timeout time.Duration
+	wg      sync.WaitGroup
+}
+
+const DefaultTimeout = 10 * time.Second
+
+// Notify sends event to subs in the background. Failures to send are logged.
+func (notifier *Notifier) Notify(subs []Subscription, event Event) {
+	for _, sub := range subs {
+		if !sub.Wants(event.Kind) {
+			continue
+		}
+		sink, has := notifier.Sinks[sub.Sink]
+		if !has {
+			notifier.Logger.Warnw("Notification sink isn't configured", "sink", sub.Sink, "owner", event.Owner)
+			continue
+		}
+		recipient := fmt.Sprintf("%s:%s:%s", sub.Sink, event.Owner, sub.Address)
+		event := event
+		if notifier.Limiter != nil {
+			allowed, suppressed := notifier.Limiter.Allow(recipient)
+			if !allowed {
+				notifier.Logger.Debugw("Notification rate limited", "recipient", recipient, "subject", event.Subject())
+				continue
+			}
+			event.Suppressed = suppressed
+		}
+		notifier.wg.Add(1)
+		go func(sink Sink, address string) {
+			defer notifier.wg.Done()
+			timeout := notifier.Timeout
+			if timeout == 0 {
+				timeout = DefaultTimeout
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := sink.Send(ctx, address, event); err != nil {
+				notifier.Logger.Errorw("Could not send notification", "recipient", recipient, "subject", event.Subject(), "error", err)
+			}
+		}(sink, sub.Address)
+	}
+}
+
+// Wait waits for the notifications being sent.
+func (notifier *Notifier) Wait() {
+	notifier.wg.Wait()
+}
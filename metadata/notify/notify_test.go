@@ -0,0 +1,154 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+var testEvent = Event{
+	Kind:         Failed,
+	ResourceType: "FEATURE_VARIANT",
+	Name:         "avg_transactions",
+	Variant:      "default",
+	Owner:        "featureformer",
+	Time:         time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+	Message:      "job failed while running",
+	Runner:       "Materialize",
+	Attempt:      2,
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(2, time.Minute)
+	limiter.now = func() time.Time { return now }
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.Allow("a"); !allowed {
+			t.Fatalf("Expected event %d to be allowed", i)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if allowed, _ := limiter.Allow("a"); allowed {
+			t.Fatalf("Expected events over the limit to be dropped")
+		}
+	}
+	if allowed, suppressed := limiter.Allow("b"); !allowed || suppressed != 0 {
+		t.Fatalf("Expected keys to be limited separately")
+	}
+	now = now.Add(time.Minute)
+	allowed, suppressed := limiter.Allow("a")
+	if !allowed || suppressed != 3 {
+		t.Fatalf("Expected an event after the window with 3 suppressed, found %v %d", allowed, suppressed)
+	}
+}
+
+func TestWebhookSinks(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode payload: %s", err)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	if err := (WebhookSink{URL: server.URL}).Send(ctx, "ops", testEvent); err != nil {
+		t.Fatalf("Failed to send webhook: %s", err)
+	}
+	event := body["event"].(map[string]interface{})
+	if body["recipient"] != "ops" || event["kind"] != "failed" || event["runner"] != "Materialize" {
+		t.Fatalf("Unexpected webhook payload: %v", body)
+	}
+
+	if err := (SlackSink{URL: server.URL}).Send(ctx, "#alerts", testEvent); err != nil {
+		t.Fatalf("Failed to send to slack: %s", err)
+	}
+	text, _ := body["text"].(string)
+	if body["channel"] != "#alerts" || !strings.HasPrefix(text, "[Featureform] feature variant avg_transactions (default) failed") {
+		t.Fatalf("Unexpected slack payload: %v", body)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer failing.Close()
+	if err := (SlackSink{URL: failing.URL}).Send(ctx, "", testEvent); err == nil || !strings.Contains(err.Error(), "no such hook") {
+		t.Fatalf("Expected the webhook's error: %v", err)
+	}
+}
+
+func TestSMTPSink(t *testing.T) {
+	var to []string
+	var msg string
+	sink := SMTPSink{
+		Addr: "mail:25",
+		From: "featureform@example.com",
+		sendMail: func(addr string, auth smtp.Auth, from string, recipients []string, body []byte) error {
+			to, msg = recipients, string(body)
+			return nil
+		},
+	}
+	ctx := context.Background()
+	event := testEvent
+	event.Name = "injected\r\nBcc: everyone@example.com"
+	if err := sink.Send(ctx, "owner@example.com", event); err != nil {
+		t.Fatalf("Failed to send email: %s", err)
+	}
+	headers := msg[:strings.Index(msg, "\r\n\r\n")]
+	if len(to) != 1 || to[0] != "owner@example.com" || strings.Contains(headers, "\r\nBcc:") {
+		t.Fatalf("Unexpected email to %v:\n%s", to, msg)
+	}
+	if !strings.Contains(msg, "Runner: Materialize\r\nAttempt: 2") {
+		t.Fatalf("Expected the failure in the email:\n%s", msg)
+	}
+	if err := sink.Send(ctx, "", event); err == nil {
+		t.Fatalf("Expected an email without an address to fail")
+	}
+}
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (sink *recordingSink) Send(ctx context.Context, address string, event Event) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.events = append(sink.events, event)
+	return nil
+}
+
+func TestNotifierSubscriptions(t *testing.T) {
+	sink := &recordingSink{}
+	notifier := &Notifier{
+		Sinks:   map[string]Sink{WebhookSinkName: sink},
+		Logger:  zaptest.NewLogger(t).Sugar(),
+		Limiter: NewRateLimiter(1, time.Hour),
+	}
+	subs := []Subscription{
+		{Sink: WebhookSinkName, Address: "failures", Events: []EventKind{Failed}},
+		{Sink: WebhookSinkName, Address: "recoveries", Events: []EventKind{Recovered}},
+		{Sink: SMTPSinkName, Address: "unconfigured@example.com"},
+	}
+	notifier.Notify(subs, testEvent)
+	notifier.Notify(subs, testEvent)
+	recovered := testEvent
+	recovered.Kind = Recovered
+	notifier.Notify(subs, recovered)
+	notifier.Wait()
+	if len(sink.events) != 2 {
+		t.Fatalf("Expected a failure and a recovery past the rate limit, found %v", sink.events)
+	}
+}
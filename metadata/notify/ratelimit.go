@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package notify
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter allows each key Limit events in any Window, so a batch of
+// failing jobs doesn't flood their owner. It counts what it drops so the
+// next event let through can say so.
+type RateLimiter struct {
+	Limit  int
+	Window time.Duration
+	mu     sync.Mutex
+	sent   map[string][]time.Time
+	// dropped counts the events dropped for each key since one was allowed.
+	dropped map[string]int
+	now     func() time.Time
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		Limit:   limit,
+		Window:  window,
+		sent:    make(map[string][]time.Time),
+		dropped: make(map[string]int),
+		now:     time.Now,
+	}
+}
+
+// Allow records an event for key if it's under the limit. It returns how
+// many events for key were dropped since the last one allowed.
+func (limiter *RateLimiter) Allow(key string) (allowed bool, suppressed int) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	now := limiter.now()
+	sent := limiter.sent[key]
+	for len(sent) > 0 && now.Sub(sent[0]) >= limiter.Window {
+		sent = sent[1:]
+	}
+	if len(sent) >= limiter.Limit {
+		limiter.sent[key] = sent
+		limiter.dropped[key]++
+		return false, 0
+	}
+	limiter.sent[key] = append(sent, now)
+	suppressed = limiter.dropped[key]
+	delete(limiter.dropped, key)
+	return true, suppressed
+}
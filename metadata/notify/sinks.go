@@ -0,0 +1,115 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"strings"
+)
+
+// WebhookSink posts each event as JSON to a URL, along with the address it's
+// for.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+type webhookPayload struct {
+	Recipient string `json:"recipient"`
+	Event     Event  `json:"event"`
+}
+
+func (sink WebhookSink) Send(ctx context.Context, address string, event Event) error {
+	return postJSON(ctx, sink.Client, sink.URL, webhookPayload{Recipient: address, Event: event})
+}
+
+// SlackSink posts each event to a Slack incoming webhook, or anything that
+// takes the same payload. Addresses are channels to post in, overriding the
+// webhook's own.
+type SlackSink struct {
+	URL    string
+	Client *http.Client
+}
+
+type slackPayload struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+func (sink SlackSink) Send(ctx context.Context, address string, event Event) error {
+	return postJSON(ctx, sink.Client, sink.URL, slackPayload{Text: event.Text(), Channel: address})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// SMTPSink emails each event to its address.
+type SMTPSink struct {
+	// Addr is the host:port of the mail server.
+	Addr string
+	From string
+	// Auth is nil for servers without authentication.
+	Auth smtp.Auth
+	// sendMail is smtp.SendMail, other than in tests.
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// headerReplacer keeps resource names from adding headers to emails.
+var headerReplacer = strings.NewReplacer("\r", " ", "\n", " ")
+
+func (sink SMTPSink) Send(ctx context.Context, address string, event Event) error {
+	if address == "" {
+		return fmt.Errorf("no email address to notify")
+	}
+	if strings.ContainsAny(address, "\r\n") {
+		return fmt.Errorf("invalid email address: %q", address)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		sink.From, address, headerReplacer.Replace(event.Subject()), strings.ReplaceAll(event.Text(), "\n", "\r\n"))
+	send := sink.sendMail
+	if send == nil {
+		send = smtp.SendMail
+	}
+	// net/smtp doesn't take a context, so sends that time out are abandoned
+	// rather than stopped.
+	done := make(chan error, 1)
+	go func() {
+		done <- send(sink.Addr, sink.Auth, sink.From, []string{address}, []byte(msg))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,149 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/featureform/metadata/notify"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (sink *recordingSink) Send(ctx context.Context, address string, event notify.Event) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	sink.events = append(sink.events, event)
+	return nil
+}
+
+func (sink *recordingSink) kinds() []notify.EventKind {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	kinds := make([]notify.EventKind, len(sink.events))
+	for i, event := range sink.events {
+		kinds[i] = event.Kind
+	}
+	return kinds
+}
+
+func TestSetNotifications(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	invalid := [][]notify.Subscription{
+		{{Sink: "pager"}},
+		{{Sink: notify.SMTPSinkName}},
+		{{Sink: notify.WebhookSinkName, Events: []notify.EventKind{"deleted"}}},
+	}
+	for _, subs := range invalid {
+		if err := client.SetNotifications(bg, "Featureform", subs); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Expected %v to be invalid: %v", subs, err)
+		}
+	}
+	if err := client.SetNotifications(bg, "missing", nil); status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected a missing user to fail: %v", err)
+	}
+	subs := []notify.Subscription{
+		{Sink: notify.SMTPSinkName, Address: "owner@example.com", Events: []notify.EventKind{notify.Failed}},
+		{Sink: notify.SlackSinkName, Address: "#alerts", Events: []notify.EventKind{}},
+	}
+	if err := client.SetNotifications(bg, "Featureform", subs); err != nil {
+		t.Fatalf("Failed to set notifications: %s", err)
+	}
+	user, err := client.GetUser(bg, "Featureform")
+	if err != nil {
+		t.Fatalf("Failed to get user: %s", err)
+	}
+	if !reflect.DeepEqual(user.Notifications(), subs) {
+		t.Fatalf("Expected %v found %v", subs, user.Notifications())
+	}
+
+	// Reapplying a user keeps their subscriptions.
+	if err := client.CreateUser(bg, UserDef{Name: "Featureform"}); err != nil {
+		t.Fatalf("Failed to reapply user: %s", err)
+	}
+	user, err = client.GetUser(bg, "Featureform")
+	if err != nil {
+		t.Fatalf("Failed to get user: %s", err)
+	}
+	if len(user.Notifications()) != 2 {
+		t.Fatalf("Expected subscriptions to be kept: %v", user.Notifications())
+	}
+}
+
+func TestNotifyOwner(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	sink := &recordingSink{}
+	notifier := &notify.Notifier{
+		Sinks:  map[string]notify.Sink{notify.WebhookSinkName: sink},
+		Logger: zaptest.NewLogger(t).Sugar(),
+	}
+	ctx.serv.notifier = notifier
+	subs := []notify.Subscription{{Sink: notify.WebhookSinkName}}
+	if err := client.SetNotifications(bg, "Featureform", subs); err != nil {
+		t.Fatalf("Failed to set notifications: %s", err)
+	}
+
+	id := ResourceID{Name: "feature", Variant: "variant2", Type: FEATURE_VARIANT}
+	for _, status := range []ResourceStatus{PENDING, READY} {
+		if err := client.SetStatus(bg, id, status, ""); err != nil {
+			t.Fatalf("Failed to set %s: %s", status, err)
+		}
+	}
+	failure := ResourceError{Message: "job failed", Attempt: 1, Runner: "Materialize"}
+	if err := client.SetFailed(bg, id, failure); err != nil {
+		t.Fatalf("Failed to set failed: %s", err)
+	}
+	for _, status := range []ResourceStatus{PENDING, READY, PENDING, READY} {
+		if err := client.SetStatus(bg, id, status, ""); err != nil {
+			t.Fatalf("Failed to set %s: %s", status, err)
+		}
+	}
+	// Other owners and resources without jobs aren't notified.
+	label := ResourceID{Name: "label", Variant: "variant", Type: LABEL_VARIANT}
+	if err := client.SetFailed(bg, label, failure); err != nil {
+		t.Fatalf("Failed to set failed: %s", err)
+	}
+	onDemand := ResourceID{Name: "feature3", Variant: "on-demand", Type: FEATURE_VARIANT}
+	if err := client.SetFailed(bg, onDemand, failure); err != nil {
+		t.Fatalf("Failed to set failed: %s", err)
+	}
+	notifier.Wait()
+
+	expected := []notify.EventKind{notify.Failed, notify.Recovered}
+	if kinds := sink.kinds(); !reflect.DeepEqual(kinds, expected) {
+		t.Fatalf("Expected %v found %v", expected, kinds)
+	}
+	failed := sink.events[0]
+	if failed.Owner != "Featureform" || failed.Name != "feature" || failed.Variant != "variant2" ||
+		failed.Message != "job failed" || failed.Runner != "Materialize" || failed.Attempt != 1 {
+		t.Fatalf("Unexpected failure event: %v", failed)
+	}
+}
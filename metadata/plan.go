@@ -0,0 +1,351 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// planLookup reads through to another lookup but keeps every write to
+// itself, so that creates can be simulated without changing anything.
+// Resources are copied on first read because creates update them in place.
+type planLookup struct {
+	base    ResourceLookup
+	written LocalResourceLookup
+}
+
+func newPlanLookup(base ResourceLookup) *planLookup {
+	return &planLookup{base: base, written: make(LocalResourceLookup)}
+}
+
+func copyResource(res Resource) (Resource, error) {
+	copied, err := emptyResource(res.ID().Type)
+	if err != nil {
+		return nil, err
+	}
+	proto.Merge(copied.Proto(), res.Proto())
+	return copied, nil
+}
+
+func (lookup *planLookup) Lookup(id ResourceID) (Resource, error) {
+	if res, has := lookup.written[id]; has {
+		return res, nil
+	}
+	res, err := lookup.base.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	copied, err := copyResource(res)
+	if err != nil {
+		return nil, err
+	}
+	lookup.written[id] = copied
+	return copied, nil
+}
+
+func (lookup *planLookup) Has(id ResourceID) (bool, error) {
+	if _, has := lookup.written[id]; has {
+		return true, nil
+	}
+	return lookup.base.Has(id)
+}
+
+func (lookup *planLookup) Set(id ResourceID, res Resource) error {
+	lookup.written[id] = res
+	return nil
+}
+
+func (lookup *planLookup) SetIfRevision(id ResourceID, res Resource, revision int64) error {
+	current, err := lookup.Lookup(id)
+	if _, isNotFound := err.(*ResourceNotFound); err != nil && !isNotFound {
+		return err
+	}
+	if actual := resourceRevision(current); actual != revision {
+		return &RevisionConflict{ID: id, Expected: revision, Actual: actual}
+	}
+	return lookup.Set(id, res)
+}
+
+func (lookup *planLookup) Delete(id ResourceID) error {
+	return fmt.Errorf("cannot delete %s in a plan", id)
+}
+
+func (lookup *planLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
+	resources := make(LocalResourceLookup, len(ids))
+	for _, id := range ids {
+		res, err := lookup.Lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		resources[id] = res
+	}
+	return resources, nil
+}
+
+func (lookup *planLookup) merge(resources []Resource, t *ResourceType) ([]Resource, error) {
+	merged := make([]Resource, 0, len(resources))
+	seen := make(map[ResourceID]struct{})
+	for _, res := range resources {
+		copied, err := lookup.Lookup(res.ID())
+		if err != nil {
+			return nil, err
+		}
+		seen[res.ID()] = struct{}{}
+		merged = append(merged, copied)
+	}
+	for id, res := range lookup.written {
+		if _, has := seen[id]; has || (t != nil && id.Type != *t) {
+			continue
+		}
+		merged = append(merged, res)
+	}
+	return merged, nil
+}
+
+func (lookup *planLookup) ListForType(t ResourceType) ([]Resource, error) {
+	resources, err := lookup.base.ListForType(t)
+	if err != nil {
+		return nil, err
+	}
+	return lookup.merge(resources, &t)
+}
+
+func (lookup *planLookup) ListRange(t ResourceType, start, end string, descending bool, limit int) ([]Resource, error) {
+	resources, err := lookup.base.ListRange(t, start, end, descending, limit)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := lookup.merge(resources, &t)
+	if err != nil {
+		return nil, err
+	}
+	inRange := make(LocalResourceLookup, len(merged))
+	for _, res := range merged {
+		inRange[res.ID()] = res
+	}
+	return inRange.ListRange(t, start, end, descending, limit)
+}
+
+func (lookup *planLookup) List() ([]Resource, error) {
+	resources, err := lookup.base.List()
+	if err != nil {
+		return nil, err
+	}
+	return lookup.merge(resources, nil)
+}
+
+func (lookup *planLookup) HasJob(id ResourceID) (bool, error) {
+	return lookup.base.HasJob(id)
+}
+
+func (lookup *planLookup) SetJob(id ResourceID, schedule string) error {
+	return nil
+}
+
+func (lookup *planLookup) DeleteJob(id ResourceID) error {
+	return nil
+}
+
+func (lookup *planLookup) SetStatus(id ResourceID, status *pb.ResourceStatus) error {
+	res, err := lookup.Lookup(id)
+	if err != nil {
+		return err
+	}
+	return res.UpdateStatus(status)
+}
+
+func (lookup *planLookup) SetSchedule(id ResourceID, schedule string) error {
+	res, err := lookup.Lookup(id)
+	if err != nil {
+		return err
+	}
+	return res.UpdateSchedule(schedule)
+}
+
+// snapshot copies the written resources so a rejected create can be undone.
+func (lookup *planLookup) snapshot() (LocalResourceLookup, error) {
+	snapshot := make(LocalResourceLookup, len(lookup.written))
+	for id, res := range lookup.written {
+		copied, err := copyResource(res)
+		if err != nil {
+			return nil, err
+		}
+		snapshot[id] = copied
+	}
+	return snapshot, nil
+}
+
+// Plan reports what creating each definition would do, applying them in
+// order against a copy of the registry.
+func (serv *MetadataServer) Plan(ctx context.Context, req *pb.PlanRequest) (*pb.PlanResponse, error) {
+	serv.Logger.Infow("Planning resources", "count", len(req.Definitions))
+	lookup := newPlanLookup(serv.lookup)
+	// The planner checks the same policy as a real create, but doesn't
+	// notify anyone of resources it only pretends to create.
+	planner := &MetadataServer{
+		Logger:   serv.Logger,
+		lookup:   lookup,
+		auditLog: NewLocalAuditLog(),
+		policy:   serv.policy,
+		notifier: nil,
+	}
+	plans := make([]*pb.ResourcePlan, len(req.Definitions))
+	for i, def := range req.Definitions {
+		plan, err := planner.planDefinition(ctx, lookup, def)
+		if err != nil {
+			serv.Logger.Errorw("Could not plan resource", "definition", def.String(), "error", err)
+			return nil, err
+		}
+		plans[i] = plan
+	}
+	return &pb.PlanResponse{Plans: plans}, nil
+}
+
+// planDefinition creates a definition against lookup and compares the result
+// with what was there before. Rejected creates are rolled back.
+func (serv *MetadataServer) planDefinition(ctx context.Context, lookup *planLookup, def *pb.ResourceDefinition) (*pb.ResourcePlan, error) {
+	var res Resource
+	var create func() (*pb.Empty, error)
+	switch casted := def.Resource.(type) {
+	case *pb.ResourceDefinition_User:
+		res = &userResource{casted.User}
+		create = func() (*pb.Empty, error) { return serv.CreateUser(ctx, casted.User) }
+	case *pb.ResourceDefinition_Provider:
+		res = &providerResource{casted.Provider}
+		create = func() (*pb.Empty, error) { return serv.CreateProvider(ctx, casted.Provider) }
+	case *pb.ResourceDefinition_Entity:
+		res = &entityResource{casted.Entity}
+		create = func() (*pb.Empty, error) { return serv.CreateEntity(ctx, casted.Entity) }
+	case *pb.ResourceDefinition_SourceVariant:
+		res = &sourceVariantResource{casted.SourceVariant}
+		create = func() (*pb.Empty, error) { return serv.CreateSourceVariant(ctx, casted.SourceVariant) }
+	case *pb.ResourceDefinition_FeatureVariant:
+		res = &featureVariantResource{casted.FeatureVariant}
+		create = func() (*pb.Empty, error) { return serv.CreateFeatureVariant(ctx, casted.FeatureVariant) }
+	case *pb.ResourceDefinition_LabelVariant:
+		label := casted.LabelVariant
+		// The API server fills in the provider on create, from a source that
+		// may only exist in this plan.
+		if label.Provider == "" {
+			source, err := lookup.Lookup(ResourceID{Name: label.Source.GetName(), Variant: label.Source.GetVariant(), Type: SOURCE_VARIANT, Namespace: label.Namespace})
+			if err == nil {
+				label.Provider = source.Proto().(*pb.SourceVariant).Provider
+			}
+		}
+		res = &labelVariantResource{label}
+		create = func() (*pb.Empty, error) { return serv.CreateLabelVariant(ctx, label) }
+	case *pb.ResourceDefinition_TrainingSetVariant:
+		trainingSet := casted.TrainingSetVariant
+		if trainingSet.Provider == "" {
+			label, err := lookup.Lookup(ResourceID{Name: trainingSet.Label.GetName(), Variant: trainingSet.Label.GetVariant(), Type: LABEL_VARIANT, Namespace: trainingSet.Namespace})
+			if err == nil {
+				trainingSet.Provider = label.Proto().(*pb.LabelVariant).Provider
+			}
+		}
+		res = &trainingSetVariantResource{trainingSet}
+		create = func() (*pb.Empty, error) { return serv.CreateTrainingSetVariant(ctx, trainingSet) }
+	case *pb.ResourceDefinition_FeatureGroupVariant:
+		res = &featureGroupVariantResource{casted.FeatureGroupVariant}
+		create = func() (*pb.Empty, error) { return serv.CreateFeatureGroupVariant(ctx, casted.FeatureGroupVariant) }
+	case *pb.ResourceDefinition_Model:
+		res = &modelResource{casted.Model}
+		create = func() (*pb.Empty, error) { return serv.CreateModel(ctx, casted.Model) }
+	case *pb.ResourceDefinition_ModelVariant:
+		res = &modelVariantResource{casted.ModelVariant}
+		create = func() (*pb.Empty, error) { return serv.CreateModelVariant(ctx, casted.ModelVariant) }
+	default:
+		return nil, fmt.Errorf("unknown resource definition: %T", casted)
+	}
+	id := res.ID()
+	plan := &pb.ResourcePlan{
+		ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+	}
+	var before proto.Message
+	existing, err := lookup.Lookup(id)
+	if _, isNotFound := err.(*ResourceNotFound); err != nil && !isNotFound {
+		return nil, err
+	}
+	if existing != nil {
+		before = proto.Clone(existing.Proto())
+	}
+	var configChanges []*pb.FieldChange
+	if provider, ok := before.(*pb.Provider); ok {
+		configChanges = providerConfigChanges(provider, res.Proto().(*pb.Provider))
+	}
+	snapshot, err := lookup.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := create(); err != nil {
+		lookup.written = snapshot
+		plan.Action = pb.ResourcePlan_REJECT
+		plan.Error = err.Error()
+		plan.Changes = configChanges
+		return plan, nil
+	}
+	after, err := lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := diffResources(before, after.Proto())
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case before == nil:
+		plan.Action = pb.ResourcePlan_CREATE
+	case len(changes) == 0:
+		plan.Action = pb.ResourcePlan_NO_OP
+	default:
+		plan.Action = pb.ResourcePlan_UPDATE
+	}
+	if configChanges != nil {
+		changes = replaceConfigChange(changes, configChanges)
+	}
+	plan.Changes = changes
+	return plan, nil
+}
+
+// providerConfigChanges lists the config fields that differ between an
+// existing provider and its update. It returns nil for providers whose
+// configs can't be compared field by field. Config values may be secret, so
+// they're never included.
+func providerConfigChanges(existing, update *pb.Provider) []*pb.FieldChange {
+	differing, _, err := (&providerResource{existing}).configDiff(update.SerializedConfig)
+	if err != nil || differing == nil {
+		return nil
+	}
+	fields := make([]string, 0, len(differing))
+	for field := range differing {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	changes := make([]*pb.FieldChange, len(fields))
+	for i, field := range fields {
+		changes[i] = &pb.FieldChange{
+			Field:    fmt.Sprintf("serializedConfig.%s", field),
+			OldValue: redactedValue,
+			NewValue: redactedValue,
+		}
+	}
+	return changes
+}
+
+func replaceConfigChange(changes, configChanges []*pb.FieldChange) []*pb.FieldChange {
+	replaced := make([]*pb.FieldChange, 0, len(changes)+len(configChanges))
+	for _, change := range changes {
+		if change.Field == "serializedConfig" {
+			replaced = append(replaced, configChanges...)
+		} else {
+			replaced = append(replaced, change)
+		}
+	}
+	return replaced
+}
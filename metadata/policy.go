@@ -0,0 +1,305 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"github.com/gorhill/cronexpr"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyWarningKey is the trailer set by create RPCs with each policy
+// warning about the resource created.
+const PolicyWarningKey = "policy-warning"
+
+// A Policy checks resources as they're registered. Resources with violations
+// of PolicyError severity are rejected; the others are created with warnings.
+type Policy interface {
+	Check(res Resource, lookup ResourceLookup) []PolicyViolation
+}
+
+type PolicySeverity string
+
+const (
+	PolicyError   PolicySeverity = "error"
+	PolicyWarning PolicySeverity = "warn"
+)
+
+type PolicyViolation struct {
+	Rule     string
+	Field    string
+	Severity PolicySeverity
+	Message  string
+}
+
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// PolicyViolations returns the violations that caused a create to be
+// rejected, or nil if err isn't a policy error.
+func PolicyViolations(err error) []*errdetails.BadRequest_FieldViolation {
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			return badRequest.GetFieldViolations()
+		}
+	}
+	return nil
+}
+
+// RulePolicy is a Policy of rules, usually loaded from a file with
+// LoadRulePolicy.
+type RulePolicy struct {
+	Rules []*PolicyRule `yaml:"rules"`
+}
+
+// A PolicyRule applies each of the checks it sets to resources of its types.
+// A resource's team, for AllowedProviders, is its owner's team property.
+type PolicyRule struct {
+	Name string `yaml:"name"`
+	// Types are resource type names, like FEATURE_VARIANT. Rules apply to
+	// every type if none are given.
+	Types    []string       `yaml:"types"`
+	Severity PolicySeverity `yaml:"severity"`
+	// RequiredFields are the proto field names, like owner and description,
+	// that must be set. Fields a type doesn't have are ignored.
+	RequiredFields   []string            `yaml:"required_fields"`
+	NamePattern      string              `yaml:"name_pattern"`
+	VariantPattern   string              `yaml:"variant_pattern"`
+	AllowedProviders map[string][]string `yaml:"allowed_providers"`
+	// MinScheduleInterval is the least time allowed between scheduled runs.
+	MinScheduleInterval time.Duration `yaml:"min_schedule_interval"`
+	RequiredTags        []string      `yaml:"required_tags"`
+
+	types          map[ResourceType]bool
+	namePattern    *regexp.Regexp
+	variantPattern *regexp.Regexp
+}
+
+// LoadRulePolicy reads a YAML or JSON policy file.
+func LoadRulePolicy(path string) (*RulePolicy, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read policy: %v", err)
+	}
+	return ParseRulePolicy(file)
+}
+
+func ParseRulePolicy(config []byte) (*RulePolicy, error) {
+	policy := &RulePolicy{}
+	if err := yaml.Unmarshal(config, policy); err != nil {
+		return nil, fmt.Errorf("could not parse policy: %v", err)
+	}
+	for i, rule := range policy.Rules {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("invalid policy rule %d (%s): %v", i, rule.Name, err)
+		}
+	}
+	return policy, nil
+}
+
+func (rule *PolicyRule) compile() error {
+	if rule.Name == "" {
+		return fmt.Errorf("rules must be named")
+	}
+	switch rule.Severity {
+	case "":
+		rule.Severity = PolicyError
+	case PolicyError, PolicyWarning:
+	default:
+		return fmt.Errorf("unknown severity: %s", rule.Severity)
+	}
+	rule.types = make(map[ResourceType]bool, len(rule.Types))
+	for _, name := range rule.Types {
+		t, has := pb.ResourceType_value[strings.ToUpper(name)]
+		if !has {
+			return fmt.Errorf("unknown resource type: %s", name)
+		}
+		rule.types[ResourceType(t)] = true
+	}
+	var err error
+	if rule.NamePattern != "" {
+		if rule.namePattern, err = regexp.Compile(rule.NamePattern); err != nil {
+			return fmt.Errorf("invalid name pattern: %v", err)
+		}
+	}
+	if rule.VariantPattern != "" {
+		if rule.variantPattern, err = regexp.Compile(rule.VariantPattern); err != nil {
+			return fmt.Errorf("invalid variant pattern: %v", err)
+		}
+	}
+	return nil
+}
+
+func (policy *RulePolicy) Check(res Resource, lookup ResourceLookup) []PolicyViolation {
+	violations := make([]PolicyViolation, 0)
+	for _, rule := range policy.Rules {
+		if len(rule.types) > 0 && !rule.types[res.ID().Type] {
+			continue
+		}
+		for _, failure := range rule.check(res, lookup) {
+			violations = append(violations, PolicyViolation{
+				Rule:     rule.Name,
+				Field:    failure.field,
+				Severity: rule.Severity,
+				Message:  failure.message,
+			})
+		}
+	}
+	return violations
+}
+
+type ruleFailure struct {
+	field, message string
+}
+
+func (rule *PolicyRule) check(res Resource, lookup ResourceLookup) []ruleFailure {
+	failures := make([]ruleFailure, 0)
+	id := res.ID()
+	msg := res.Proto().ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	for _, name := range rule.RequiredFields {
+		field := fields.ByName(protoreflect.Name(name))
+		if field != nil && !msg.Has(field) {
+			failures = append(failures, ruleFailure{name, fmt.Sprintf("%s is required", name)})
+		}
+	}
+	if rule.namePattern != nil && !rule.namePattern.MatchString(id.Name) {
+		failures = append(failures, ruleFailure{"name", fmt.Sprintf("name %s must match %s", id.Name, rule.NamePattern)})
+	}
+	if rule.variantPattern != nil && id.Variant != "" && !rule.variantPattern.MatchString(id.Variant) {
+		failures = append(failures, ruleFailure{"variant", fmt.Sprintf("variant %s must match %s", id.Variant, rule.VariantPattern)})
+	}
+	if len(rule.AllowedProviders) > 0 {
+		if failure, failed := rule.checkProvider(res, lookup); failed {
+			failures = append(failures, failure)
+		}
+	}
+	if rule.MinScheduleInterval > 0 && res.Schedule() != "" {
+		if failure, failed := rule.checkSchedule(res.Schedule()); failed {
+			failures = append(failures, failure)
+		}
+	}
+	if len(rule.RequiredTags) > 0 {
+		tags := make(map[string]bool)
+		if tagged, ok := res.Proto().(interface{ GetTags() *pb.Tags }); ok {
+			for _, tag := range tagged.GetTags().GetTag() {
+				tags[tag] = true
+			}
+		}
+		for _, tag := range rule.RequiredTags {
+			if !tags[tag] {
+				failures = append(failures, ruleFailure{"tags", fmt.Sprintf("tag %s is required", tag)})
+			}
+		}
+	}
+	return failures
+}
+
+func (rule *PolicyRule) checkProvider(res Resource, lookup ResourceLookup) (ruleFailure, bool) {
+	serialized, ok := res.Proto().(interface {
+		GetProvider() string
+		GetOwner() string
+	})
+	if !ok || serialized.GetProvider() == "" {
+		return ruleFailure{}, false
+	}
+	team := ownerTeam(serialized.GetOwner(), lookup)
+	allowed, restricted := rule.AllowedProviders[team]
+	if !restricted {
+		return ruleFailure{}, false
+	}
+	for _, provider := range allowed {
+		if provider == serialized.GetProvider() {
+			return ruleFailure{}, false
+		}
+	}
+	return ruleFailure{"provider", fmt.Sprintf("team %s may not use provider %s", team, serialized.GetProvider())}, true
+}
+
+// ownerTeam returns the team property of owner, or an empty string if it
+// doesn't have one.
+func ownerTeam(owner string, lookup ResourceLookup) string {
+	user, err := lookup.Lookup(ResourceID{Name: owner, Type: USER})
+	if err != nil {
+		return ""
+	}
+	properties := user.Proto().(*pb.User).GetProperties().GetProperty()
+	return properties["team"].GetStringValue()
+}
+
+// scheduleRuns is how many upcoming runs of a schedule are compared to find
+// how often it runs.
+const scheduleRuns = 16
+
+func (rule *PolicyRule) checkSchedule(schedule string) (ruleFailure, bool) {
+	expr, err := cronexpr.Parse(schedule)
+	if err != nil {
+		return ruleFailure{"schedule", fmt.Sprintf("invalid schedule %s: %v", schedule, err)}, true
+	}
+	runs := expr.NextN(time.Now(), scheduleRuns)
+	for i := 1; i < len(runs); i++ {
+		if interval := runs[i].Sub(runs[i-1]); interval < rule.MinScheduleInterval {
+			return ruleFailure{"schedule", fmt.Sprintf("schedule %s runs every %s, more often than every %s", schedule, interval, rule.MinScheduleInterval)}, true
+		}
+	}
+	return ruleFailure{}, false
+}
+
+// checkPolicy rejects res if it violates the server's policy, and otherwise
+// sets the policy warning trailer with any warnings about it.
+func (serv *MetadataServer) checkPolicy(ctx context.Context, res Resource) error {
+	if serv.policy == nil {
+		return nil
+	}
+	errs := make([]PolicyViolation, 0)
+	warnings := make([]string, 0)
+	for _, violation := range serv.policy.Check(res, serv.lookup) {
+		if violation.Severity == PolicyWarning {
+			serv.Logger.Warnw("Policy warning", "resource", res.ID().String(), "rule", violation.Rule, "message", violation.Message)
+			warnings = append(warnings, violation.String())
+			continue
+		}
+		errs = append(errs, violation)
+	}
+	if len(errs) > 0 {
+		return policyError(res.ID(), errs)
+	}
+	if len(warnings) > 0 {
+		// Only fails when called outside of an RPC, where there's no one to warn.
+		grpc.SetTrailer(ctx, grpcmd.MD{PolicyWarningKey: warnings})
+	}
+	return nil
+}
+
+func policyError(id ResourceID, violations []PolicyViolation) error {
+	messages := make([]string, len(violations))
+	fieldViolations := make([]*errdetails.BadRequest_FieldViolation, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+		fieldViolations[i] = &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.String(),
+		}
+	}
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("%s violates policy: %s", id, strings.Join(messages, "; ")))
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: fieldViolations}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,197 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testPolicy = `
+rules:
+  - name: documented
+    types: [FEATURE_VARIANT, label_variant]
+    required_fields: [owner, description]
+  - name: snake-case
+    types: [FEATURE_VARIANT]
+    name_pattern: '^[a-z][a-z0-9_]*$'
+    variant_pattern: '^v[0-9]+$'
+    severity: warn
+  - name: fraud-providers
+    allowed_providers:
+      fraud: [mockOnline]
+  - name: hourly
+    min_schedule_interval: 1h
+  - name: reviewed
+    types: [TRAINING_SET_VARIANT]
+    required_tags: [reviewed]
+`
+
+func violatedRules(violations []PolicyViolation) []string {
+	rules := make([]string, len(violations))
+	for i, violation := range violations {
+		rules[i] = violation.Rule + " " + violation.Field
+	}
+	return rules
+}
+
+func TestParseRulePolicy(t *testing.T) {
+	policy, err := ParseRulePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Failed to parse policy: %s", err)
+	}
+	if len(policy.Rules) != 5 || policy.Rules[0].Severity != PolicyError || policy.Rules[1].Severity != PolicyWarning {
+		t.Fatalf("Wrong rules parsed: %v", policy.Rules)
+	}
+	if _, err := ParseRulePolicy([]byte(`{"rules": [{"name": "json", "required_fields": ["owner"]}]}`)); err != nil {
+		t.Fatalf("Failed to parse JSON policy: %s", err)
+	}
+	invalid := map[string]string{
+		"Unnamed":          `rules: [{required_fields: [owner]}]`,
+		"Unknown Type":     `rules: [{name: a, types: [FEATURE_VARIANTS]}]`,
+		"Unknown Severity": `rules: [{name: a, severity: fatal}]`,
+		"Bad Pattern":      `rules: [{name: a, name_pattern: "("}]`,
+		"Bad Interval":     `rules: [{name: a, min_schedule_interval: often}]`,
+	}
+	for name, config := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseRulePolicy([]byte(config)); err == nil {
+				t.Fatalf("Expected policy to be invalid: %s", config)
+			}
+		})
+	}
+}
+
+func TestRulePolicyCheck(t *testing.T) {
+	policy, err := ParseRulePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Failed to parse policy: %s", err)
+	}
+	fraudster := &userResource{&pb.User{
+		Name: "fraudster",
+		Properties: &pb.Properties{Property: map[string]*pb.Property{
+			"team": {Value: &pb.Property_StringValue{StringValue: "fraud"}},
+		}},
+	}}
+	lookup := LocalResourceLookup{fraudster.ID(): fraudster}
+	feature := &featureVariantResource{&pb.FeatureVariant{
+		Name:        "avg_txn",
+		Variant:     "v1",
+		Owner:       "fraudster",
+		Description: "Average transaction",
+		Provider:    "mockOnline",
+		Schedule:    "0 * * * *",
+	}}
+	if violations := policy.Check(feature, lookup); len(violations) != 0 {
+		t.Fatalf("Expected feature to follow policy: %v", violations)
+	}
+	feature.serialized.Name = "AvgTxn"
+	feature.serialized.Variant = "default"
+	feature.serialized.Description = ""
+	feature.serialized.Provider = "mockOffline"
+	feature.serialized.Schedule = "*/5 * * * *"
+	expected := []string{
+		"documented description",
+		"snake-case name",
+		"snake-case variant",
+		"fraud-providers provider",
+		"hourly schedule",
+	}
+	if rules := violatedRules(policy.Check(feature, lookup)); !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Wrong violations: %v", rules)
+	}
+	// Only the fraud team is restricted.
+	feature = &featureVariantResource{&pb.FeatureVariant{
+		Name:        "avg_txn",
+		Variant:     "v1",
+		Owner:       "someone",
+		Description: "Average transaction",
+		Provider:    "mockOffline",
+	}}
+	if violations := policy.Check(feature, lookup); len(violations) != 0 {
+		t.Fatalf("Expected owner without a team to use any provider: %v", violations)
+	}
+	trainingSet := &trainingSetVariantResource{&pb.TrainingSetVariant{
+		Name:    "ts",
+		Variant: "default",
+		Tags:    &pb.Tags{Tag: []string{"draft"}},
+	}}
+	if rules := violatedRules(policy.Check(trainingSet, lookup)); !reflect.DeepEqual(rules, []string{"reviewed tags"}) {
+		t.Fatalf("Wrong training set violations: %v", rules)
+	}
+}
+
+func TestPolicyCreate(t *testing.T) {
+	policy, err := ParseRulePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Failed to parse policy: %s", err)
+	}
+	serv, err := NewMetadataServer(&Config{
+		Logger:          zaptest.NewLogger(t).Sugar(),
+		StorageProvider: LocalStorageProvider{},
+		Policy:          policy,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go serv.ServeOnListener(lis)
+	defer serv.Stop()
+	client := client(t, lis.Addr().String())
+	defer client.Close()
+	bg := context.Background()
+	defs := filledResourceDefs()
+	if err := client.CreateAll(bg, defs[:8]); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+
+	undocumented := defs[8].(FeatureDef)
+	undocumented.Description = ""
+	err = client.Create(bg, undocumented)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected undocumented feature to be rejected: %v", err)
+	}
+	violations := PolicyViolations(err)
+	if len(violations) != 1 || violations[0].Field != "description" {
+		t.Fatalf("Wrong violation details: %v", violations)
+	}
+	if _, err := client.GetFeatureVariant(bg, NameVariant{Name: undocumented.Name, Variant: undocumented.Variant}); err == nil {
+		t.Fatalf("Rejected feature was created")
+	}
+
+	// Plans are checked against the policy too.
+	plans, err := client.Plan(bg, []ResourceDef{undocumented, defs[8]})
+	if err != nil {
+		t.Fatalf("Failed to plan: %s", err)
+	}
+	if plans[0].Action != pb.ResourcePlan_REJECT || plans[1].Action != pb.ResourcePlan_CREATE {
+		t.Fatalf("Wrong plans: %v", plans)
+	}
+
+	// The variant breaks the warned about naming convention.
+	var trailer grpcmd.MD
+	serialized, err := defs[8].(FeatureDef).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize: %s", err)
+	}
+	if _, err := client.GrpcConn.CreateFeatureVariant(bg, serialized, grpc.Trailer(&trailer)); err != nil {
+		t.Fatalf("Failed to create feature with warnings: %s", err)
+	}
+	if warnings := trailer.Get(PolicyWarningKey); len(warnings) != 1 {
+		t.Fatalf("Wrong policy warnings: %v", warnings)
+	}
+}
@@ -1,195 +1,254 @@
 package metadata
 
-import pc "github.com/featureform/provider/provider_config"
+import (
+	ss "github.com/featureform/helpers/string_set"
+	pc "github.com/featureform/provider/provider_config"
+)
+
+// isValidConfigUpdate checks that an update only changes mutable fields.
+func isValidConfigUpdate(differing, mutable ss.StringSet, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+	return mutable.Contains(differing), nil
+}
 
 func isValidBigQueryConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(bigQueryConfigDiff(sa, sb))
+}
+
+func bigQueryConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.BigQueryConfig{}
 	b := pc.BigQueryConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidCassandraConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(cassandraConfigDiff(sa, sb))
+}
+
+func cassandraConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.CassandraConfig{}
 	b := pc.CassandraConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidDynamoConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(dynamoConfigDiff(sa, sb))
+}
+
+func dynamoConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.DynamodbConfig{}
 	b := pc.DynamodbConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidFirestoreConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(firestoreConfigDiff(sa, sb))
+}
+
+func firestoreConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.FirestoreConfig{}
 	b := pc.FirestoreConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidMongoConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(mongoConfigDiff(sa, sb))
+}
+
+func mongoConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.MongoDBConfig{}
 	b := pc.MongoDBConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidMySqlConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(mySqlConfigDiff(sa, sb))
+}
+
+func mySqlConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.MySqlConfig{}
 	b := pc.MySqlConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidPostgresConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(postgresConfigDiff(sa, sb))
+}
+
+func postgresConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.PostgresConfig{}
 	b := pc.PostgresConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidRedisConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(redisConfigDiff(sa, sb))
+}
+
+func redisConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.RedisConfig{}
 	b := pc.RedisConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidSnowflakeConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(snowflakeConfigDiff(sa, sb))
+}
+
+func snowflakeConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.SnowflakeConfig{}
 	b := pc.SnowflakeConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidRedshiftConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(redshiftConfigDiff(sa, sb))
+}
+
+func redshiftConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.RedshiftConfig{}
 	b := pc.RedshiftConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidK8sConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(k8sConfigDiff(sa, sb))
+}
+
+func k8sConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.K8sConfig{}
 	b := pc.K8sConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
 
 func isValidSparkConfigUpdate(sa, sb pc.SerializedConfig) (bool, error) {
+	return isValidConfigUpdate(sparkConfigDiff(sa, sb))
+}
+
+func sparkConfigDiff(sa, sb pc.SerializedConfig) (differing, mutable ss.StringSet, err error) {
 	a := pc.SparkConfig{}
 	b := pc.SparkConfig{}
 	if err := a.Deserialize(sa); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	if err := b.Deserialize(sb); err != nil {
-		return false, err
+		return nil, nil, err
 	}
 	diff, err := a.DifferingFields(b)
 	if err != nil {
-		return false, err
+		return nil, nil, err
 	}
-	return a.MutableFields().Contains(diff), nil
+	return diff, a.MutableFields(), nil
 }
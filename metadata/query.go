@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"sort"
+
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/metadata/search"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QueryResources returns the resources matching a structured query over
+// their tags, properties and other fields. It's run by search if it's
+// configured and over every stored resource if not.
+func (serv *MetadataServer) QueryResources(ctx context.Context, req *pb.ResourceQuery) (*pb.ResourceQueryResult, error) {
+	serv.Logger.Infow("Querying resources", "query", req.Query)
+	query, err := search.ParseQuery(req.Query)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var docs []search.ResourceDoc
+	if wrapper, ok := serv.lookup.(*SearchWrapper); ok {
+		docs, err = wrapper.Searcher.RunQuery(query)
+	} else {
+		docs, err = queryLookup(serv.lookup, query)
+	}
+	if err != nil {
+		serv.Logger.Errorw("Could not query resources", "query", req.Query, "error", err)
+		return nil, err
+	}
+	ids := make([]ResourceID, 0, len(docs))
+	for _, doc := range docs {
+		resType, has := pb.ResourceType_value[doc.Type]
+		if !has {
+			serv.Logger.Warnw("Query matched a document of an unknown type", "type", doc.Type, "name", doc.Name)
+			continue
+		}
+		ids = append(ids, ResourceID{Name: doc.Name, Variant: doc.Variant, Type: ResourceType(resType), Namespace: doc.Namespace})
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].Type != ids[j].Type {
+			return ids[i].Type < ids[j].Type
+		}
+		if ids[i].Namespace != ids[j].Namespace {
+			return ids[i].Namespace < ids[j].Namespace
+		}
+		if ids[i].Name != ids[j].Name {
+			return ids[i].Name < ids[j].Name
+		}
+		return ids[i].Variant < ids[j].Variant
+	})
+	return &pb.ResourceQueryResult{Resources: resourceIDsToProto(ids)}, nil
+}
+
+// queryLookup runs query over the search documents of every resource in
+// lookup.
+func queryLookup(lookup ResourceLookup, query search.Query) ([]search.ResourceDoc, error) {
+	resources, err := lookup.List()
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]search.ResourceDoc, 0)
+	for _, res := range resources {
+		doc := SearchDocument(res.ID(), res)
+		if query.Matches(doc) {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+// QueryResources returns the resources matching a structured query like
+// tag:pii AND owner:alice AND provider:redis.
+func (client *Client) QueryResources(ctx context.Context, query string) ([]ResourceID, error) {
+	result, err := client.GrpcConn.QueryResources(ctx, &pb.ResourceQuery{Query: query})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ResourceID, len(result.Resources))
+	for i, id := range result.Resources {
+		ids[i] = resourceIDFromProto(id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,84 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/featureform/metadata/search"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestQueryResources(t *testing.T) {
+	defs := filledResourceDefs()
+	for i, def := range defs {
+		switch def := def.(type) {
+		case FeatureDef:
+			if def.Name == "feature" && def.Variant == "variant" {
+				def.Tags = Tags{"pii"}
+				def.Properties = Properties{"team": "fraud"}
+			}
+			defs[i] = def
+		case LabelDef:
+			def.Tags = Tags{"pii"}
+			defs[i] = def
+		}
+	}
+	ctx := testContext{
+		Defs: defs,
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+
+	feature := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	label := ResourceID{Name: "label", Variant: "variant", Type: LABEL_VARIANT}
+	queries := map[string][]ResourceID{
+		"tag:pii":                                        {feature, label},
+		"tag:pii AND provider:mockOnline":                {feature},
+		"property.team:fraud":                            {feature},
+		"tag:pii owner:Other type:label_variant":         {label},
+		"tag:pii NOT (owner:Featureform OR owner:Other)": {},
+	}
+	run := func() {
+		for q, expected := range queries {
+			ids, err := client.QueryResources(bg, q)
+			if err != nil {
+				t.Fatalf("Failed to query %q: %s", q, err)
+			}
+			if !reflect.DeepEqual(ids, expected) {
+				t.Fatalf("Expected %q to find %v, found %v", q, expected, ids)
+			}
+		}
+	}
+	run()
+	if _, err := client.QueryResources(bg, "color:red"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected an invalid query to fail: %v", err)
+	}
+
+	// Search answers the same queries when it's configured.
+	searcher := search.NewEmbeddedSearch()
+	if err := IndexResources(ctx.serv.lookup, searcher); err != nil {
+		t.Fatalf("Failed to index resources: %s", err)
+	}
+	ctx.serv.lookup = &SearchWrapper{Searcher: searcher, ResourceLookup: ctx.serv.lookup}
+	run()
+
+	// Tag edits are reindexed, so queries see them straight away.
+	if err := client.SetTags(bg, label, Tags{"gold"}); err != nil {
+		t.Fatalf("Failed to set tags: %s", err)
+	}
+	queries = map[string][]ResourceID{
+		"tag:pii":  {feature},
+		"tag:gold": {label},
+	}
+	run()
+}
@@ -0,0 +1,90 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Writes that lose a race to a concurrent one are retried this many times
+// before giving up.
+const maxRevisionAttempts = 10
+
+// RevisionConflict is returned when a resource is written at a revision it's
+// no longer at. Resources that don't exist are at revision 0.
+type RevisionConflict struct {
+	ID       ResourceID
+	Expected int64
+	Actual   int64
+}
+
+func (err *RevisionConflict) Error() string {
+	return fmt.Sprintf("%s changed concurrently: expected revision %d, found %d", err.ID, err.Expected, err.Actual)
+}
+
+func (err *RevisionConflict) GRPCStatus() *status.Status {
+	return status.New(codes.Aborted, err.Error())
+}
+
+type revisionGetter interface {
+	GetRevision() int64
+}
+
+func resourceRevision(res Resource) int64 {
+	if res == nil {
+		return 0
+	}
+	if getter, ok := res.Proto().(revisionGetter); ok {
+		return getter.GetRevision()
+	}
+	return 0
+}
+
+func setRevision(res Resource, revision int64) {
+	msg := res.Proto().ProtoReflect()
+	if !msg.IsValid() {
+		return
+	}
+	if field := msg.Descriptor().Fields().ByName("revision"); field != nil {
+		msg.Set(field, protoreflect.ValueOfInt64(revision))
+	}
+}
+
+// updateResource applies fn to a copy of the resource at id and stores it if
+// nothing else has written it since it was read. With an expected revision,
+// it fails if the resource isn't at it. Otherwise, it retries with the latest
+// version of the resource until the write goes through.
+func updateResource(lookup ResourceLookup, id ResourceID, expected int64, fn func(Resource) error) (before proto.Message, after Resource, err error) {
+	for attempt := 0; attempt < maxRevisionAttempts; attempt++ {
+		res, err := lookup.Lookup(id)
+		if err != nil {
+			return nil, nil, err
+		}
+		revision := resourceRevision(res)
+		if expected != 0 && revision != expected {
+			return nil, nil, &RevisionConflict{ID: id, Expected: expected, Actual: revision}
+		}
+		updated, err := copyResource(res)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := fn(updated); err != nil {
+			return nil, nil, err
+		}
+		err = lookup.SetIfRevision(id, updated, revision)
+		if _, isConflict := err.(*RevisionConflict); isConflict && expected == 0 {
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		return res.Proto(), updated, nil
+	}
+	return nil, nil, fmt.Errorf("could not update %s: too many concurrent writes", id)
+}
@@ -0,0 +1,172 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRevisionConflicts(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	nameVariant := NameVariant{Name: "feature", Variant: "variant"}
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+
+	feature, err := client.GetFeatureVariant(bg, nameVariant)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	read := feature.Revision()
+	if read == 0 {
+		t.Fatalf("Expected the feature to have a revision")
+	}
+	if err := client.SetStatusIfRevision(bg, id, READY, "", read); err != nil {
+		t.Fatalf("Failed to set status at the read revision: %s", err)
+	}
+	if err := client.SetStatusIfRevision(bg, id, FAILED, "", read); status.Code(err) != codes.Aborted {
+		t.Fatalf("Expected a stale revision to conflict: %v", err)
+	}
+	if err := client.SetLifecycleIfRevision(bg, id, Lifecycle{State: DEPRECATED}, read); status.Code(err) != codes.Aborted {
+		t.Fatalf("Expected a stale revision to conflict: %v", err)
+	}
+	if err := client.SetTagsIfRevision(bg, id, Tags{"stale"}, read); status.Code(err) != codes.Aborted {
+		t.Fatalf("Expected a stale revision to conflict: %v", err)
+	}
+	feature, err = client.GetFeatureVariant(bg, nameVariant)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.Status() != READY || feature.Revision() <= read {
+		t.Fatalf("Expected a ready feature at a later revision: %s %d", feature.Status(), feature.Revision())
+	}
+
+	stale := proto.Clone(feature.serialized).(*pb.FeatureVariant)
+	stale.Revision = read
+	stale.Tags = &pb.Tags{Tag: []string{"stale"}}
+	def := DefinitionDef{Definition: &pb.ResourceDefinition{Resource: &pb.ResourceDefinition_FeatureVariant{FeatureVariant: stale}}}
+	if err := client.Create(bg, def); status.Code(err) != codes.Aborted {
+		t.Fatalf("Expected an update at a stale revision to conflict: %v", err)
+	}
+	current := proto.Clone(stale).(*pb.FeatureVariant)
+	current.Revision = feature.Revision()
+	def = DefinitionDef{Definition: &pb.ResourceDefinition{Resource: &pb.ResourceDefinition_FeatureVariant{FeatureVariant: current}}}
+	if err := client.Create(bg, def); err != nil {
+		t.Fatalf("Failed to update at the current revision: %s", err)
+	}
+	feature, err = client.GetFeatureVariant(bg, nameVariant)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if !reflect.DeepEqual(feature.Tags(), Tags{"stale"}) {
+		t.Fatalf("Expected tags to be updated: %v", feature.Tags())
+	}
+}
+
+// racingLookup writes the resource in between each read and conditional
+// write, the first few times.
+type racingLookup struct {
+	LocalResourceLookup
+	races int
+	write func(Resource)
+}
+
+func (lookup *racingLookup) SetIfRevision(id ResourceID, res Resource, revision int64) error {
+	if lookup.races > 0 {
+		lookup.races--
+		raced, _ := copyResource(lookup.LocalResourceLookup[id])
+		lookup.write(raced)
+		lookup.LocalResourceLookup.Set(id, raced)
+	}
+	return lookup.LocalResourceLookup.SetIfRevision(id, res, revision)
+}
+
+func TestUpdateResourceRetries(t *testing.T) {
+	id := ResourceID{Name: "user", Type: ENTITY}
+	local := LocalResourceLookup{}
+	local.Set(id, &entityResource{&pb.Entity{Name: "user"}})
+	lookup := &racingLookup{
+		LocalResourceLookup: local,
+		races:               2,
+		write: func(res Resource) {
+			res.Proto().(*pb.Entity).Description = "concurrent"
+		},
+	}
+	_, updated, err := updateResource(lookup, id, 0, func(res Resource) error {
+		return res.UpdateStatus(&pb.ResourceStatus{Status: pb.ResourceStatus_READY})
+	})
+	if err != nil {
+		t.Fatalf("Failed to update: %s", err)
+	}
+	entity := updated.Proto().(*pb.Entity)
+	if entity.Description != "concurrent" || entity.Status.Status != pb.ResourceStatus_READY {
+		t.Fatalf("Expected both writes to be kept: %v", entity)
+	}
+	if entity.Revision != 4 {
+		t.Fatalf("Expected revision 4 found %d", entity.Revision)
+	}
+
+	lookup.races = 1
+	_, _, err = updateResource(lookup, id, entity.Revision, func(res Resource) error {
+		return nil
+	})
+	if _, isConflict := err.(*RevisionConflict); !isConflict {
+		t.Fatalf("Expected a conflict with an expected revision: %v", err)
+	}
+
+	lookup.races = maxRevisionAttempts
+	if _, _, err := updateResource(lookup, id, 0, func(res Resource) error { return nil }); err == nil {
+		t.Fatalf("Expected too many conflicts to fail")
+	}
+}
+
+func TestFileSetIfRevision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "resources.log")
+	lookup, err := OpenFileResourceLookup(path)
+	if err != nil {
+		t.Fatalf("Failed to open lookup: %s", err)
+	}
+	id := ResourceID{Name: "user", Type: USER}
+	if err := lookup.SetIfRevision(id, &userResource{&pb.User{Name: "user"}}, 1); err == nil {
+		t.Fatalf("Expected a missing resource to conflict")
+	}
+	if err := lookup.SetIfRevision(id, &userResource{&pb.User{Name: "user"}}, 0); err != nil {
+		t.Fatalf("Failed to create: %s", err)
+	}
+	if err := lookup.SetStatus(id, &pb.ResourceStatus{Status: pb.ResourceStatus_READY}); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	if err := lookup.SetIfRevision(id, &userResource{&pb.User{Name: "user"}}, 1); err == nil {
+		t.Fatalf("Expected a stale revision to conflict")
+	}
+	lookup.Close()
+
+	reopened, err := OpenFileResourceLookup(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen lookup: %s", err)
+	}
+	defer reopened.Close()
+	res, err := reopened.Lookup(id)
+	if err != nil {
+		t.Fatalf("Failed to lookup: %s", err)
+	}
+	if revision := resourceRevision(res); revision != 2 {
+		t.Fatalf("Expected revision 2 after replay, found %d", revision)
+	}
+}
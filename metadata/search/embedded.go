@@ -0,0 +1,265 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// How much a match in each field of a document counts towards its rank.
+const (
+	nameWeight        = 3
+	fieldWeight       = 2
+	descriptionWeight = 1
+)
+
+// How close a query token is to an indexed one.
+const (
+	fuzzyMatch = 1 + iota
+	prefixMatch
+	exactMatch
+)
+
+// EmbeddedSearch is an in-memory inverted index of resources, for running
+// without Meilisearch. It starts empty and has to be filled on startup.
+type EmbeddedSearch struct {
+	mu   sync.RWMutex
+	docs map[string]ResourceDoc
+	// index maps each token to the weights of the documents it's in.
+	index map[string]map[string]int
+}
+
+func NewEmbeddedSearch() *EmbeddedSearch {
+	return &EmbeddedSearch{
+		docs:  make(map[string]ResourceDoc),
+		index: make(map[string]map[string]int),
+	}
+}
+
+// tokenize splits s into lowercase words on anything that isn't a letter or
+// digit, so that names like avg_txn-amount match any of their words.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// docTokens returns the tokens in doc along with the weight of the heaviest
+// field each is in.
+func docTokens(doc ResourceDoc) map[string]int {
+	tokens := make(map[string]int)
+	add := func(s string, weight int) {
+		for _, token := range tokenize(s) {
+			if weight > tokens[token] {
+				tokens[token] = weight
+			}
+		}
+	}
+	add(doc.Name, nameWeight)
+	add(doc.Variant, fieldWeight)
+	add(doc.Type, fieldWeight)
+	for _, tag := range doc.Tags {
+		add(tag, fieldWeight)
+	}
+	add(doc.Description, descriptionWeight)
+	return tokens
+}
+
+func (s *EmbeddedSearch) Upsert(doc ResourceDoc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := documentID(doc)
+	s.remove(id)
+	s.docs[id] = doc
+	for token, weight := range docTokens(doc) {
+		if _, has := s.index[token]; !has {
+			s.index[token] = make(map[string]int)
+		}
+		s.index[token][id] = weight
+	}
+	return nil
+}
+
+func (s *EmbeddedSearch) UpsertAll(docs []ResourceDoc) error {
+	for _, doc := range docs {
+		if err := s.Upsert(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *EmbeddedSearch) Delete(doc ResourceDoc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(documentID(doc))
+	return nil
+}
+
+// remove must be called with the lock held.
+func (s *EmbeddedSearch) remove(id string) {
+	doc, has := s.docs[id]
+	if !has {
+		return
+	}
+	for token := range docTokens(doc) {
+		delete(s.index[token], id)
+		if len(s.index[token]) == 0 {
+			delete(s.index, token)
+		}
+	}
+	delete(s.docs, id)
+}
+
+func (s *EmbeddedSearch) DeleteAll() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.docs = make(map[string]ResourceDoc)
+	s.index = make(map[string]map[string]int)
+	return nil
+}
+
+// RunSearch returns the documents matching every word of q, best matches
+// first. Words match indexed words they equal, prefix or are a typo or two
+// away from. An empty query matches every document.
+func (s *EmbeddedSearch) RunSearch(q string) ([]ResourceDoc, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	queryTokens := tokenize(q)
+	scores := make(map[string]int, len(s.docs))
+	for id := range s.docs {
+		scores[id] = 0
+	}
+	for _, queryToken := range queryTokens {
+		best := make(map[string]int)
+		for token, docs := range s.index {
+			match := matchToken(queryToken, token)
+			if match == 0 {
+				continue
+			}
+			for id, weight := range docs {
+				if score := match * weight; score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+		for id := range scores {
+			score, has := best[id]
+			if !has {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += score
+		}
+	}
+	ids := make([]string, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	results := make([]ResourceDoc, len(ids))
+	for i, id := range ids {
+		results[i] = s.docs[id]
+	}
+	return results, nil
+}
+
+// RunQuery returns the documents matching q, sorted by their IDs.
+func (s *EmbeddedSearch) RunQuery(q Query) ([]ResourceDoc, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0)
+	for id, doc := range s.docs {
+		if q.Matches(doc) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	results := make([]ResourceDoc, len(ids))
+	for i, id := range ids {
+		results[i] = s.docs[id]
+	}
+	return results, nil
+}
+
+// matchToken returns how closely queryToken matches token, or zero if it
+// doesn't.
+func matchToken(queryToken, token string) int {
+	switch {
+	case queryToken == token:
+		return exactMatch
+	case strings.HasPrefix(token, queryToken):
+		return prefixMatch
+	}
+	typos := allowedTypos(queryToken)
+	if typos > 0 && prefixEditDistance(queryToken, token, typos) <= typos {
+		return fuzzyMatch
+	}
+	return 0
+}
+
+// allowedTypos grows with the length of a word, as Meilisearch's does.
+func allowedTypos(token string) int {
+	switch n := len([]rune(token)); {
+	case n >= 9:
+		return 2
+	case n >= 5:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// prefixEditDistance returns the least Levenshtein distance between query
+// and a prefix of token, since the query may stop mid-word, or max+1 if it's
+// more than max.
+func prefixEditDistance(query, token string, max int) int {
+	qr, tr := []rune(query), []rune(token)
+	if len(tr) < len(qr)-max {
+		return max + 1
+	}
+	prev := make([]int, len(tr)+1)
+	curr := make([]int, len(tr)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(qr); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(tr); j++ {
+			cost := 1
+			if qr[i-1] == tr[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev, curr = curr, prev
+	}
+	return minInt(prev[0], prev[1:]...)
+}
+
+func minInt(first int, rest ...int) int {
+	min := first
+	for _, n := range rest {
+		if n < min {
+			min = n
+		}
+	}
+	return min
+}
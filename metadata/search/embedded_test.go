@@ -0,0 +1,104 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func embeddedSearchNames(t *testing.T, searcher Searcher, q string) []string {
+	results, err := searcher.RunSearch(q)
+	if err != nil {
+		t.Fatalf("Failed to search %q: %s", q, err)
+	}
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.Name
+	}
+	return names
+}
+
+func TestEmbeddedSearch(t *testing.T) {
+	searcher := NewEmbeddedSearch()
+	docs := []ResourceDoc{
+		{
+			Name:    "avg_transaction_amount",
+			Variant: "default",
+			Type:    "FEATURE_VARIANT",
+			Tags:    []string{"fraud"},
+		}, {
+			Name:        "transactions",
+			Variant:     "kaggle",
+			Type:        "SOURCE_VARIANT",
+			Description: "Raw card payments",
+		}, {
+			Name:    "is_fraud",
+			Variant: "default",
+			Type:    "LABEL_VARIANT",
+		}, {
+			Name:      "is_fraud",
+			Variant:   "default",
+			Type:      "LABEL_VARIANT",
+			Namespace: "team-a",
+		},
+	}
+	for _, doc := range docs {
+		if err := searcher.Upsert(doc); err != nil {
+			t.Fatalf("Failed to upsert %v: %s", doc, err)
+		}
+	}
+	tests := []struct {
+		Name     string
+		Query    string
+		Expected []string
+	}{
+		{"Exact", "transactions", []string{"transactions", "avg_transaction_amount"}},
+		{"Prefix", "transac", []string{"avg_transaction_amount", "transactions"}},
+		{"Typo", "transacton", []string{"avg_transaction_amount", "transactions"}},
+		{"Word Of Name", "amount", []string{"avg_transaction_amount"}},
+		{"Every Word", "avg fraud", []string{"avg_transaction_amount"}},
+		{"Tag", "fraud", []string{"is_fraud", "is_fraud", "avg_transaction_amount"}},
+		{"Description", "payments", []string{"transactions"}},
+		{"Variant", "kaggle", []string{"transactions"}},
+		{"Case", "KAGGLE", []string{"transactions"}},
+		{"No Match", "recommendations", []string{}},
+		{"Short Words Need No Typos", "fruad", []string{}},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if names := embeddedSearchNames(t, searcher, test.Query); !reflect.DeepEqual(names, test.Expected) {
+				t.Fatalf("Expected %v for %q, got %v", test.Expected, test.Query, names)
+			}
+		})
+	}
+	if names := embeddedSearchNames(t, searcher, ""); len(names) != len(docs) {
+		t.Fatalf("Expected an empty query to match everything, got %v", names)
+	}
+
+	// Upserts replace the indexed words of a document.
+	docs[1].Description = "Card purchases"
+	if err := searcher.Upsert(docs[1]); err != nil {
+		t.Fatalf("Failed to upsert: %s", err)
+	}
+	if names := embeddedSearchNames(t, searcher, "payments"); len(names) != 0 {
+		t.Fatalf("Expected replaced description to be unindexed, got %v", names)
+	}
+	if names := embeddedSearchNames(t, searcher, "purchases"); !reflect.DeepEqual(names, []string{"transactions"}) {
+		t.Fatalf("Expected new description to be indexed, got %v", names)
+	}
+	if err := searcher.Delete(docs[3]); err != nil {
+		t.Fatalf("Failed to delete: %s", err)
+	}
+	if names := embeddedSearchNames(t, searcher, "is_fraud"); !reflect.DeepEqual(names, []string{"is_fraud"}) {
+		t.Fatalf("Expected one label left after delete, got %v", names)
+	}
+	if err := searcher.DeleteAll(); err != nil {
+		t.Fatalf("Failed to delete all: %s", err)
+	}
+	if names := embeddedSearchNames(t, searcher, ""); len(names) != 0 {
+		t.Fatalf("Expected nothing after delete all, got %v", names)
+	}
+}
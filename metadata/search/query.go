@@ -0,0 +1,408 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// The fields a query can match on. Properties are matched with
+// PropertyFieldPrefix followed by the property's key, like property.team:fraud.
+const (
+	TagField            = "tag"
+	OwnerField          = "owner"
+	ProviderField       = "provider"
+	StatusField         = "status"
+	TypeField           = "type"
+	NameField           = "name"
+	VariantField        = "variant"
+	NamespaceField      = "namespace"
+	PropertyFieldPrefix = "property."
+)
+
+// QueryFields are the fields a query can match on besides properties.
+var QueryFields = []string{TagField, OwnerField, ProviderField, StatusField, TypeField, NameField, VariantField, NamespaceField}
+
+// AnyValue matches any value of a field, as long as the resource has it.
+const AnyValue = "*"
+
+// Query is a structured search over the fields of resources, like
+//
+//	tag:pii AND (provider:redis OR provider:dynamo) AND NOT status:failed
+//
+// Terms are field:value pairs or free text, which matches words in names,
+// tags and descriptions. Terms next to each other must both match, NOT
+// binds tighter than AND, and AND binds tighter than OR. Values are
+// compared without case and can be quoted to hold spaces.
+type Query interface {
+	Matches(doc ResourceDoc) bool
+	String() string
+}
+
+// andQuery matches documents that match all of its queries.
+type andQuery []Query
+
+// orQuery matches documents that match any of its queries.
+type orQuery []Query
+
+type notQuery struct {
+	query Query
+}
+
+// fieldQuery matches documents with value in field.
+type fieldQuery struct {
+	field string
+	value string
+}
+
+// textQuery matches documents with every word of text in their name, tags
+// or description.
+type textQuery struct {
+	text string
+}
+
+func (query andQuery) Matches(doc ResourceDoc) bool {
+	for _, q := range query {
+		if !q.Matches(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+func (query orQuery) Matches(doc ResourceDoc) bool {
+	for _, q := range query {
+		if q.Matches(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+func (query notQuery) Matches(doc ResourceDoc) bool {
+	return !query.query.Matches(doc)
+}
+
+func (query fieldQuery) Matches(doc ResourceDoc) bool {
+	for _, value := range docFieldValues(doc, query.field) {
+		if query.value == AnyValue || strings.EqualFold(value, query.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (query textQuery) Matches(doc ResourceDoc) bool {
+	tokens := docTokens(doc)
+	for _, word := range tokenize(query.text) {
+		if _, has := tokens[word]; !has {
+			return false
+		}
+	}
+	return true
+}
+
+// docFieldValues returns the values doc has in a query field. Fields the
+// document doesn't have, like the owner of a provider, have no values.
+func docFieldValues(doc ResourceDoc, field string) []string {
+	nonEmpty := func(value string) []string {
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	}
+	switch field {
+	case TagField:
+		return doc.Tags
+	case OwnerField:
+		return nonEmpty(doc.Owner)
+	case ProviderField:
+		return nonEmpty(doc.Provider)
+	case StatusField:
+		return nonEmpty(doc.Status)
+	case TypeField:
+		return nonEmpty(doc.Type)
+	case NameField:
+		return nonEmpty(doc.Name)
+	case VariantField:
+		return nonEmpty(doc.Variant)
+	case NamespaceField:
+		return nonEmpty(doc.Namespace)
+	}
+	if key := strings.TrimPrefix(field, PropertyFieldPrefix); key != field {
+		if value, has := doc.Properties[key]; has {
+			return []string{value}
+		}
+	}
+	return nil
+}
+
+func (query andQuery) String() string {
+	return joinQueries(query, " AND ")
+}
+
+func (query orQuery) String() string {
+	return joinQueries(query, " OR ")
+}
+
+func joinQueries(queries []Query, sep string) string {
+	parts := make([]string, len(queries))
+	for i, q := range queries {
+		switch q.(type) {
+		case andQuery, orQuery:
+			parts[i] = fmt.Sprintf("(%s)", q)
+		default:
+			parts[i] = q.String()
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func (query notQuery) String() string {
+	switch query.query.(type) {
+	case andQuery, orQuery:
+		return fmt.Sprintf("NOT (%s)", query.query)
+	}
+	return fmt.Sprintf("NOT %s", query.query)
+}
+
+func (query fieldQuery) String() string {
+	return fmt.Sprintf("%s:%s", query.field, quoteValue(query.value))
+}
+
+func (query textQuery) String() string {
+	return quoteValue(query.text)
+}
+
+func quoteValue(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"():") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// QueryError is a query that can't be parsed.
+type QueryError struct {
+	Query   string
+	Pos     int
+	Message string
+}
+
+func (err QueryError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", err.Pos, err.Message)
+}
+
+type queryToken struct {
+	pos int
+	// field is set for field:value terms, and text is the value without any
+	// quotes.
+	field  string
+	text   string
+	quoted bool
+}
+
+func (token queryToken) is(keyword string) bool {
+	return !token.quoted && token.field == "" && token.text == keyword
+}
+
+func lexQuery(q string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(q)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{pos: i, text: string(r)})
+			i++
+		default:
+			// A word runs until a space or parenthesis, and can have quoted
+			// parts like owner:"Jane Doe". The first colon outside of quotes
+			// ends its field.
+			token := queryToken{pos: i}
+			var word strings.Builder
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				switch runes[i] {
+				case '"':
+					end := i + 1
+					for end < len(runes) && runes[end] != '"' {
+						end++
+					}
+					if end == len(runes) {
+						return nil, QueryError{q, i, "unterminated quote"}
+					}
+					token.quoted = true
+					word.WriteString(string(runes[i+1 : end]))
+					i = end + 1
+				case ':':
+					if token.field == "" && !token.quoted {
+						token.field = word.String()
+						word.Reset()
+						i++
+						continue
+					}
+					fallthrough
+				default:
+					word.WriteRune(runes[i])
+					i++
+				}
+			}
+			token.text = word.String()
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+type queryParser struct {
+	query  string
+	tokens []queryToken
+	next   int
+}
+
+// ParseQuery parses a structured query. An empty query matches everything.
+func ParseQuery(q string) (Query, error) {
+	tokens, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	parser := &queryParser{query: q, tokens: tokens}
+	if len(tokens) == 0 {
+		return andQuery{}, nil
+	}
+	query, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token, has := parser.peek(); has {
+		return nil, parser.errorAt(token, fmt.Sprintf("unexpected %q", token.text))
+	}
+	return query, nil
+}
+
+func (parser *queryParser) peek() (queryToken, bool) {
+	if parser.next == len(parser.tokens) {
+		return queryToken{}, false
+	}
+	return parser.tokens[parser.next], true
+}
+
+func (parser *queryParser) errorAt(token queryToken, message string) error {
+	return QueryError{parser.query, token.pos, message}
+}
+
+func (parser *queryParser) errorAtEnd(message string) error {
+	return QueryError{parser.query, len([]rune(parser.query)), message}
+}
+
+func (parser *queryParser) parseOr() (Query, error) {
+	var queries orQuery
+	for {
+		query, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+		token, has := parser.peek()
+		if !has || !token.is("OR") {
+			break
+		}
+		parser.next++
+	}
+	if len(queries) == 1 {
+		return queries[0], nil
+	}
+	return queries, nil
+}
+
+func (parser *queryParser) parseAnd() (Query, error) {
+	var queries andQuery
+	for {
+		query, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+		token, has := parser.peek()
+		if !has || token.is("OR") || token.is(")") {
+			break
+		}
+		if token.is("AND") {
+			parser.next++
+		}
+	}
+	if len(queries) == 1 {
+		return queries[0], nil
+	}
+	return queries, nil
+}
+
+func (parser *queryParser) parseNot() (Query, error) {
+	token, has := parser.peek()
+	if has && token.is("NOT") {
+		parser.next++
+		query, err := parser.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notQuery{query}, nil
+	}
+	return parser.parseTerm()
+}
+
+func (parser *queryParser) parseTerm() (Query, error) {
+	token, has := parser.peek()
+	if !has {
+		return nil, parser.errorAtEnd("expected a term")
+	}
+	parser.next++
+	switch {
+	case token.is("("):
+		query, err := parser.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, has := parser.peek()
+		if !has {
+			return nil, parser.errorAtEnd("expected )")
+		}
+		if !closing.is(")") {
+			return nil, parser.errorAt(closing, fmt.Sprintf("expected ) but found %q", closing.text))
+		}
+		parser.next++
+		return query, nil
+	case token.is(")"), token.is("AND"), token.is("OR"):
+		return nil, parser.errorAt(token, fmt.Sprintf("expected a term but found %q", token.text))
+	}
+	if token.field == "" {
+		return textQuery{token.text}, nil
+	}
+	field, ok := queryField(token.field)
+	if !ok {
+		return nil, parser.errorAt(token, fmt.Sprintf("unknown field %q: expected one of %v or %s<key>", token.field, QueryFields, PropertyFieldPrefix))
+	}
+	if token.text == "" {
+		return nil, parser.errorAt(token, fmt.Sprintf("expected a value for %s", field))
+	}
+	return fieldQuery{field: field, value: token.text}, nil
+}
+
+// queryField returns the canonical name of a field as written in a query.
+// Field names aren't case sensitive but property keys are.
+func queryField(field string) (string, bool) {
+	lower := strings.ToLower(field)
+	for _, known := range QueryFields {
+		if lower == known {
+			return known, true
+		}
+	}
+	if strings.HasPrefix(lower, PropertyFieldPrefix) && len(field) > len(PropertyFieldPrefix) {
+		return PropertyFieldPrefix + field[len(PropertyFieldPrefix):], true
+	}
+	return "", false
+}
@@ -0,0 +1,153 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var queryDocs = []ResourceDoc{
+	{
+		Name:        "avg_transaction_amount",
+		Variant:     "default",
+		Type:        "FEATURE_VARIANT",
+		Tags:        []string{"pii", "fraud"},
+		Owner:       "alice",
+		Provider:    "redis",
+		Status:      "READY",
+		Properties:  map[string]string{"team": "Fraud Detection"},
+		Description: "Average card payment",
+	}, {
+		Name:     "card_country",
+		Variant:  "default",
+		Type:     "FEATURE_VARIANT",
+		Tags:     []string{"pii"},
+		Owner:    "bob",
+		Provider: "dynamo",
+		Status:   "FAILED",
+	}, {
+		Name:     "transactions",
+		Variant:  "kaggle",
+		Type:     "SOURCE_VARIANT",
+		Tags:     []string{"pii"},
+		Owner:    "alice",
+		Provider: "postgres",
+		Status:   "READY",
+	}, {
+		Name: "redis",
+		Type: "PROVIDER",
+	},
+}
+
+func TestParseQuery(t *testing.T) {
+	queries := map[string]string{
+		"":                    "",
+		"tag:pii":             "tag:pii",
+		"TAG:pii owner:alice": "tag:pii AND owner:alice",
+		"tag:pii AND owner:alice OR status:failed": "(tag:pii AND owner:alice) OR status:failed",
+		"tag:pii AND (owner:alice OR owner:bob)":   "tag:pii AND (owner:alice OR owner:bob)",
+		"NOT NOT tag:pii":                          "NOT NOT tag:pii",
+		"NOT (tag:pii OR tag:phi)":                 "NOT (tag:pii OR tag:phi)",
+		`owner:"Jane Doe" property.Team:"a:b"`:     `owner:"Jane Doe" AND property.Team:"a:b"`,
+		`card "a:b"`:                               `card AND "a:b"`,
+	}
+	for q, expected := range queries {
+		parsed, err := ParseQuery(q)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", q, err)
+		}
+		if parsed.String() != expected {
+			t.Fatalf("Expected %q to parse as %q, found %q", q, expected, parsed)
+		}
+	}
+	invalid := map[string]int{
+		"color:red":            0,
+		"tag:":                 0,
+		"property.:x":          0,
+		"tag:pii AND":          11,
+		"(tag:pii":             8,
+		"tag:pii)":             7,
+		`owner:"alice`:         6,
+		"tag:pii AND OR tag:x": 12,
+	}
+	for q, pos := range invalid {
+		_, err := ParseQuery(q)
+		queryErr := QueryError{}
+		if !errors.As(err, &queryErr) || queryErr.Pos != pos {
+			t.Fatalf("Expected %q to be invalid at %d: %v", q, pos, err)
+		}
+	}
+}
+
+func TestRunQuery(t *testing.T) {
+	searcher := NewEmbeddedSearch()
+	for _, doc := range queryDocs {
+		if err := searcher.Upsert(doc); err != nil {
+			t.Fatalf("Failed to upsert %v: %s", doc, err)
+		}
+	}
+	queries := map[string][]string{
+		"":                           {"avg_transaction_amount", "card_country", "redis", "transactions"},
+		"tag:PII AND provider:redis": {"avg_transaction_amount"},
+		"tag:pii type:feature_variant NOT status:failed": {"avg_transaction_amount"},
+		"tag:pii AND (owner:bob OR provider:postgres)":   {"card_country", "transactions"},
+		`property.team:"fraud detection"`:                {"avg_transaction_amount"},
+		"property.team:*":                                {"avg_transaction_amount"},
+		"property.Team:*":                                {},
+		"owner:* NOT tag:pii":                            {},
+		"card payment":                                   {"avg_transaction_amount"},
+		"name:redis OR provider:redis":                   {"avg_transaction_amount", "redis"},
+	}
+	for q, expected := range queries {
+		parsed, err := ParseQuery(q)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", q, err)
+		}
+		results, err := searcher.RunQuery(parsed)
+		if err != nil {
+			t.Fatalf("Failed to run %q: %s", q, err)
+		}
+		names := make([]string, len(results))
+		for i, result := range results {
+			names[i] = result.Name
+		}
+		if !reflect.DeepEqual(names, expected) {
+			t.Fatalf("Expected %q to find %v, found %v", q, expected, names)
+		}
+	}
+}
+
+func TestMeilisearchFilter(t *testing.T) {
+	queries := map[string][2]string{
+		"card payment tag:pii": {"card payment", `Tags = "pii"`},
+		`tag:pii (owner:alice OR NOT status:failed) property.team:*`: {
+			"",
+			`Tags = "pii" AND (Owner = "alice" OR NOT Status = "FAILED") AND Properties.team EXISTS`,
+		},
+		`owner:a\b`: {"", `Owner = "a\\b"`},
+	}
+	for q, expected := range queries {
+		parsed, err := ParseQuery(q)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", q, err)
+		}
+		text, filter, err := meilisearchFilter(parsed)
+		if err != nil {
+			t.Fatalf("Failed to make a filter from %q: %s", q, err)
+		}
+		if text != expected[0] || filter != expected[1] {
+			t.Fatalf("Expected %q to filter %q %q, found %q %q", q, expected[0], expected[1], text, filter)
+		}
+	}
+	parsed, err := ParseQuery("tag:pii OR card")
+	if err != nil {
+		t.Fatalf("Failed to parse: %s", err)
+	}
+	if _, _, err := meilisearchFilter(parsed); err == nil {
+		t.Fatalf("Expected free text under OR to fail")
+	}
+}
@@ -14,7 +14,12 @@ import (
 
 type Searcher interface {
 	Upsert(ResourceDoc) error
+	// UpsertAll upserts documents in one batch.
+	UpsertAll([]ResourceDoc) error
+	Delete(ResourceDoc) error
 	RunSearch(q string) ([]ResourceDoc, error)
+	// RunQuery returns the documents matching a structured query.
+	RunQuery(q Query) ([]ResourceDoc, error)
 	DeleteAll() error
 }
 
@@ -76,6 +81,15 @@ type ResourceDoc struct {
 	Name    string
 	Variant string
 	Type    string
+	// Namespace is empty for the default namespace.
+	Namespace   string
+	Tags        []string
+	Description string
+	// Owner, Provider and Status are empty for resources without them.
+	Owner      string
+	Provider   string
+	Status     string
+	Properties map[string]string
 }
 
 func (s Search) waitForSync(taskUID int64) error {
@@ -105,23 +119,60 @@ func (s Search) initializeCollection() error {
 	}
 
 	err = s.waitForSync(resp.TaskUID)
-	if err != nil && err.Error() == "index_already_exists" {
-		return nil
-	} else if err != nil {
+	if err != nil && err.Error() != "index_already_exists" {
 		return fmt.Errorf("could not create index: %v", err)
 	}
 
+	// Indexes created by older versions need the fields queries filter on
+	// made filterable too. Their documents are missing those fields until
+	// the metadata server reindexes them on startup.
+	resp, err = s.client.Index("resources").UpdateFilterableAttributes(&filterableAttributes)
+	if err != nil {
+		return fmt.Errorf("filterable attributes request failed: %v", err)
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		return fmt.Errorf("could not set filterable attributes: %v", err)
+	}
+	resp, err = s.client.Index("resources").UpdatePagination(&ms.Pagination{MaxTotalHits: queryMaxTotalHits})
+	if err != nil {
+		return fmt.Errorf("pagination request failed: %v", err)
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		return fmt.Errorf("could not set pagination: %v", err)
+	}
 	return nil
 }
 
-func (s Search) Upsert(doc ResourceDoc) error {
-	document := map[string]interface{}{
-		"ID":      strings.ReplaceAll(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), " ", ""),
-		"Parsed":  strings.ReplaceAll(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), "_", " "),
-		"Name":    doc.Name,
-		"Type":    doc.Type,
-		"Variant": doc.Variant,
+// filterableAttributes are the document fields queries can filter on.
+var filterableAttributes = []string{"Name", "Type", "Variant", "Namespace", "Tags", "Owner", "Provider", "Status", "Properties"}
+
+func documentID(doc ResourceDoc) string {
+	id := fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant)
+	if doc.Namespace != "" {
+		id = fmt.Sprintf("%s__%s", doc.Namespace, id)
 	}
+	return strings.ReplaceAll(id, " ", "")
+}
+
+func meilisearchDocument(doc ResourceDoc) map[string]interface{} {
+	return map[string]interface{}{
+		"ID":          documentID(doc),
+		"Parsed":      strings.ReplaceAll(fmt.Sprintf("%s__%s__%s", doc.Type, doc.Name, doc.Variant), "_", " "),
+		"Name":        doc.Name,
+		"Type":        doc.Type,
+		"Variant":     doc.Variant,
+		"Namespace":   doc.Namespace,
+		"Tags":        doc.Tags,
+		"Description": doc.Description,
+		"Owner":       doc.Owner,
+		"Provider":    doc.Provider,
+		"Status":      doc.Status,
+		"Properties":  doc.Properties,
+	}
+}
+
+func (s Search) Upsert(doc ResourceDoc) error {
+	document := meilisearchDocument(doc)
 	resp, err := s.client.Index("resources").UpdateDocuments(document)
 	if err != nil {
 		return err
@@ -132,6 +183,33 @@ func (s Search) Upsert(doc ResourceDoc) error {
 	return nil
 }
 
+// UpsertAll sends every document in a single task and waits for it once.
+func (s Search) UpsertAll(docs []ResourceDoc) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	documents := make([]map[string]interface{}, len(docs))
+	for i, doc := range docs {
+		documents[i] = meilisearchDocument(doc)
+	}
+	resp, err := s.client.Index("resources").UpdateDocuments(documents)
+	if err != nil {
+		return err
+	}
+	if err := s.waitForSync(resp.TaskUID); err != nil {
+		return fmt.Errorf("could not upsert %d documents: %v", len(docs), err)
+	}
+	return nil
+}
+
+func (s Search) Delete(doc ResourceDoc) error {
+	resp, err := s.client.Index("resources").DeleteDocument(documentID(doc))
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %v", err)
+	}
+	return s.waitForSync(resp.TaskUID)
+}
+
 func (s Search) DeleteAll() error {
 	_, err := s.client.DeleteIndex("resources")
 	if err != nil {
@@ -145,17 +223,169 @@ func (s Search) RunSearch(q string) ([]ResourceDoc, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %v", err)
 	}
+	return parseHits(results.Hits), nil
+}
 
-	var searchResults []ResourceDoc
+// queryPageSize is how many documents RunQuery gets at a time. Meilisearch
+// returns at most its maxTotalHits setting across all pages, which the index
+// sets to queryMaxTotalHits.
+const (
+	queryPageSize     = 200
+	queryMaxTotalHits = 100000
+)
 
-	for _, hit := range results.Hits {
+// RunQuery filters on the fields of q in Meilisearch. Free text can only be
+// matched at the top level of a query, since Meilisearch can't filter on it.
+func (s Search) RunQuery(q Query) ([]ResourceDoc, error) {
+	text, filter, err := meilisearchFilter(q)
+	if err != nil {
+		return nil, err
+	}
+	var results []ResourceDoc
+	for offset := int64(0); ; offset += queryPageSize {
+		page, err := s.client.Index("resources").Search(text, &ms.SearchRequest{
+			Filter: filter,
+			Offset: offset,
+			Limit:  queryPageSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query: %v", err)
+		}
+		results = append(results, parseHits(page.Hits)...)
+		if len(page.Hits) < queryPageSize {
+			return results, nil
+		}
+		// Meilisearch stops returning hits at the cap without saying so.
+		if offset+queryPageSize >= queryMaxTotalHits {
+			return nil, fmt.Errorf("query matches more than %d resources: %s", queryMaxTotalHits, q)
+		}
+	}
+}
+
+// meilisearchFilter splits q into the free text it has to match and a
+// Meilisearch filter expression for the rest of it.
+func meilisearchFilter(q Query) (string, string, error) {
+	var text []string
+	var filters []string
+	terms := andQuery{q}
+	if and, ok := q.(andQuery); ok {
+		terms = and
+	}
+	for _, term := range terms {
+		if textTerm, ok := term.(textQuery); ok {
+			text = append(text, textTerm.text)
+			continue
+		}
+		filter, err := meilisearchFilterExpr(term)
+		if err != nil {
+			return "", "", err
+		}
+		filters = append(filters, filter)
+	}
+	return strings.Join(text, " "), strings.Join(filters, " AND "), nil
+}
+
+func meilisearchFilterExpr(q Query) (string, error) {
+	switch q := q.(type) {
+	case andQuery:
+		return meilisearchJoin(q, " AND ")
+	case orQuery:
+		return meilisearchJoin(q, " OR ")
+	case notQuery:
+		expr, err := meilisearchFilterExpr(q.query)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("NOT %s", expr), nil
+	case fieldQuery:
+		attribute, err := meilisearchAttribute(q.field)
+		if err != nil {
+			return "", err
+		}
+		if q.value == AnyValue {
+			return fmt.Sprintf("%s EXISTS", attribute), nil
+		}
+		value := q.value
+		if q.field == TypeField || q.field == StatusField {
+			// Types and statuses are indexed by their enum names.
+			value = strings.ToUpper(value)
+		}
+		return fmt.Sprintf("%s = %s", attribute, meilisearchString(value)), nil
+	case textQuery:
+		return "", fmt.Errorf("free text %q can only be matched at the top level of a query", q.text)
+	}
+	return "", fmt.Errorf("unsupported query: %s", q)
+}
+
+func meilisearchJoin(queries []Query, sep string) (string, error) {
+	exprs := make([]string, len(queries))
+	for i, query := range queries {
+		expr, err := meilisearchFilterExpr(query)
+		if err != nil {
+			return "", err
+		}
+		exprs[i] = expr
+	}
+	return fmt.Sprintf("(%s)", strings.Join(exprs, sep)), nil
+}
+
+func meilisearchAttribute(field string) (string, error) {
+	if key := strings.TrimPrefix(field, PropertyFieldPrefix); key != field {
+		if strings.ContainsAny(key, " \t\n\"'") {
+			return "", fmt.Errorf("property %q can't be queried in Meilisearch", key)
+		}
+		return fmt.Sprintf("Properties.%s", key), nil
+	}
+	if field == TagField {
+		return "Tags", nil
+	}
+	return strings.ToUpper(field[:1]) + field[1:], nil
+}
+
+func meilisearchString(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(escaped, `"`, `\"`))
+}
+
+func parseHits(hits []interface{}) []ResourceDoc {
+	var searchResults []ResourceDoc
+	for _, hit := range hits {
 		doc := hit.(map[string]interface{})
+		// Documents indexed by older versions may not have every field.
+		namespace, _ := doc["Namespace"].(string)
+		description, _ := doc["Description"].(string)
+		owner, _ := doc["Owner"].(string)
+		provider, _ := doc["Provider"].(string)
+		status, _ := doc["Status"].(string)
+		var tags []string
+		if hitTags, ok := doc["Tags"].([]interface{}); ok {
+			for _, tag := range hitTags {
+				if tag, ok := tag.(string); ok {
+					tags = append(tags, tag)
+				}
+			}
+		}
+		var properties map[string]string
+		if hitProperties, ok := doc["Properties"].(map[string]interface{}); ok {
+			properties = make(map[string]string, len(hitProperties))
+			for key, value := range hitProperties {
+				if value, ok := value.(string); ok {
+					properties[key] = value
+				}
+			}
+		}
 		searchResults = append(searchResults, ResourceDoc{
-			Name:    doc["Name"].(string),
-			Type:    doc["Type"].(string),
-			Variant: doc["Variant"].(string),
+			Name:        doc["Name"].(string),
+			Type:        doc["Type"].(string),
+			Variant:     doc["Variant"].(string),
+			Namespace:   namespace,
+			Tags:        tags,
+			Description: description,
+			Owner:       owner,
+			Provider:    provider,
+			Status:      status,
+			Properties:  properties,
 		})
-
 	}
-	return searchResults, nil
+	return searchResults
 }
@@ -7,11 +7,20 @@ package main
 import (
 	"fmt"
 	"github.com/featureform/logging"
+	"github.com/featureform/metadata/notify"
 	"github.com/featureform/metadata/search"
+	"net"
+	"net/smtp"
 	"os"
+	"strconv"
+	"time"
 
 	help "github.com/featureform/helpers"
 	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
 )
 
 func main() {
@@ -21,19 +30,50 @@ func main() {
 	logger := logging.NewLogger("metadata")
 	addr := help.GetEnv("METADATA_PORT", "8080")
 	enableSearch := help.GetEnv("ENABLE_SEARCH", "true")
-	storageProvider := metadata.EtcdStorageProvider{
+	var storageProvider metadata.StorageProvider = metadata.EtcdStorageProvider{
 		metadata.EtcdConfig{
 			Nodes: []metadata.EtcdNode{
 				{etcdHost, etcdPort},
 			},
 		},
 	}
+	// The coordinator has to be given the same METADATA_STORAGE to run the
+	// jobs kept in SQL.
+	switch storage := help.GetEnv("METADATA_STORAGE", "etcd"); storage {
+	case "etcd":
+	case "sql":
+		driver := help.GetEnv("METADATA_SQL_DRIVER", string(metadata.PostgresDriver))
+		logger.Infow("Storing metadata in SQL", "driver", driver)
+		sqlProvider, err := metadata.NewSQLStorageProvider(metadata.SQLConfig{
+			Driver:     metadata.SQLDriver(driver),
+			DataSource: help.GetEnv("METADATA_SQL_DSN", ""),
+		})
+		if err != nil {
+			logger.Panicw("Failed to open metadata database", "Err", err)
+		}
+		storageProvider = sqlProvider
+	default:
+		logger.Panicw("Unsupported metadata storage", "storage", storage)
+	}
 	config := &metadata.Config{
 		Logger:          logger,
 		Address:         fmt.Sprintf(":%s", addr),
 		StorageProvider: storageProvider,
+		ResourceCleaner: provider.MetadataCleaner{Logger: logger},
+	}
+	if policyFile := help.GetEnv("METADATA_POLICY_FILE", ""); policyFile != "" {
+		logger.Infow("Loading registration policy", "file", policyFile)
+		policy, err := metadata.LoadRulePolicy(policyFile)
+		if err != nil {
+			logger.Panicw("Failed to load policy", "Err", err)
+		}
+		config.Policy = policy
 	}
-	if enableSearch == "true" {
+	config.Notifier = notifierFromEnv(logger)
+	if enableSearch == "true" && help.GetEnv("SEARCH_BACKEND", "meilisearch") == "embedded" {
+		logger.Infow("Using embedded search")
+		config.EmbeddedSearch = true
+	} else if enableSearch == "true" {
 		logger.Infow("Connecting to search", "host", os.Getenv("MEILISEARCH_HOST"), "port", os.Getenv("MEILISEARCH_PORT"))
 		config.SearchParams = &search.MeilisearchParams{
 			Port:   help.GetEnv("MEILISEARCH_PORT", "7700"),
@@ -50,3 +90,43 @@ func main() {
 		logger.Errorw("Serve failed with error", "Err", err)
 	}
 }
+
+// notifierFromEnv configures a sink for each of the notification settings
+// that are set. It returns nil if none of them are.
+func notifierFromEnv(logger *zap.SugaredLogger) *notify.Notifier {
+	sinks := make(map[string]notify.Sink)
+	if url := help.GetEnv("NOTIFY_WEBHOOK_URL", ""); url != "" {
+		sinks[notify.WebhookSinkName] = notify.WebhookSink{URL: url}
+	}
+	if url := help.GetEnv("NOTIFY_SLACK_WEBHOOK_URL", ""); url != "" {
+		sinks[notify.SlackSinkName] = notify.SlackSink{URL: url}
+	}
+	if addr := help.GetEnv("NOTIFY_SMTP_ADDR", ""); addr != "" {
+		sink := notify.SMTPSink{Addr: addr, From: help.GetEnv("NOTIFY_SMTP_FROM", "featureform@localhost")}
+		if username := help.GetEnv("NOTIFY_SMTP_USERNAME", ""); username != "" {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				logger.Panicw("Invalid SMTP address", "Err", err)
+			}
+			sink.Auth = smtp.PlainAuth("", username, help.GetEnv("NOTIFY_SMTP_PASSWORD", ""), host)
+		}
+		sinks[notify.SMTPSinkName] = sink
+	}
+	if len(sinks) == 0 {
+		return nil
+	}
+	limit, err := strconv.Atoi(help.GetEnv("NOTIFY_RATE_LIMIT", "10"))
+	if err != nil {
+		logger.Panicw("Invalid notification rate limit", "Err", err)
+	}
+	window, err := time.ParseDuration(help.GetEnv("NOTIFY_RATE_WINDOW", "1h"))
+	if err != nil {
+		logger.Panicw("Invalid notification rate window", "Err", err)
+	}
+	logger.Infow("Sending notifications", "limit", limit, "window", window)
+	return &notify.Notifier{
+		Sinks:   sinks,
+		Logger:  logger,
+		Limiter: notify.NewRateLimiter(limit, window),
+	}
+}
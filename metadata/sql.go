@@ -0,0 +1,749 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+type SQLDriver string
+
+const (
+	PostgresDriver SQLDriver = "postgres"
+	SQLiteDriver   SQLDriver = "sqlite3"
+)
+
+// SQLConfig configures metadata storage in a SQL database. The database/sql
+// driver itself has to be registered by the binary, e.g. by importing
+// github.com/lib/pq or github.com/mattn/go-sqlite3.
+type SQLConfig struct {
+	Driver SQLDriver
+	// DataSource is a Postgres connection string or a SQLite file name.
+	DataSource string
+}
+
+// Open connects to the database and creates the metadata tables if they
+// don't exist yet.
+func (config SQLConfig) Open() (SQLStorage, error) {
+	if config.Driver != PostgresDriver && config.Driver != SQLiteDriver {
+		return SQLStorage{}, fmt.Errorf("unsupported metadata sql driver: %s", config.Driver)
+	}
+	db, err := sql.Open(string(config.Driver), config.DataSource)
+	if err != nil {
+		return SQLStorage{}, err
+	}
+	if config.Driver == SQLiteDriver {
+		// SQLite allows one writer at a time and every connection to an
+		// in-memory database gets its own database.
+		db.SetMaxOpenConns(1)
+	}
+	storage := SQLStorage{DB: db, Driver: config.Driver}
+	if err := storage.createTables(); err != nil {
+		db.Close()
+		return SQLStorage{}, errors.Wrap(err, "could not create metadata tables")
+	}
+	return storage, nil
+}
+
+// SQLStorageProvider stores metadata in Postgres or SQLite. Jobs are kept in
+// the database too, where the coordinator reads them with a SQLJobStore.
+type SQLStorageProvider struct {
+	Storage SQLStorage
+}
+
+func NewSQLStorageProvider(config SQLConfig) (*SQLStorageProvider, error) {
+	storage, err := config.Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not open metadata database: %v", err)
+	}
+	return &SQLStorageProvider{Storage: storage}, nil
+}
+
+func (sp *SQLStorageProvider) GetResourceLookup() (ResourceLookup, error) {
+	return SQLResourceLookup{Connection: sp.Storage}, nil
+}
+
+func (sp *SQLStorageProvider) GetAuditLog() (AuditLog, error) {
+	return SQLAuditLog{Connection: sp.Storage}, nil
+}
+
+// sqlQuerier is implemented by both *sql.DB and *sql.Tx.
+type sqlQuerier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+type SQLStorage struct {
+	DB     *sql.DB
+	Driver SQLDriver
+}
+
+// Keys are the same as in etcd so that a keyspace can be copied across.
+func (s SQLStorage) createTables() error {
+	binaryType := "BLOB"
+	revisionType := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.Driver == PostgresDriver {
+		binaryType = "BYTEA"
+		revisionType = "BIGSERIAL PRIMARY KEY"
+	}
+	tables := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS metadata_resources (
+			key TEXT PRIMARY KEY,
+			resource_type INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			variant TEXT NOT NULL,
+			value %s NOT NULL
+		)`, binaryType),
+		`CREATE INDEX IF NOT EXISTS metadata_resources_type ON metadata_resources (resource_type)`,
+		`CREATE TABLE IF NOT EXISTS metadata_jobs (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS metadata_job_locks (
+			key TEXT PRIMARY KEY,
+			owner TEXT NOT NULL,
+			expires BIGINT NOT NULL
+		)`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS metadata_audit_events (
+			revision %s,
+			key TEXT UNIQUE NOT NULL,
+			resource_key TEXT NOT NULL,
+			timestamp BIGINT NOT NULL,
+			value %s NOT NULL
+		)`, revisionType, binaryType),
+		`CREATE INDEX IF NOT EXISTS metadata_audit_events_resource ON metadata_audit_events (resource_key, timestamp)`,
+		`CREATE INDEX IF NOT EXISTS metadata_audit_events_timestamp ON metadata_audit_events (timestamp)`,
+	}
+	for _, table := range tables {
+		if _, err := s.DB.Exec(table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebind replaces ? placeholders with the numbered ones Postgres uses.
+func (s SQLStorage) rebind(query string) string {
+	if s.Driver != PostgresDriver {
+		return query
+	}
+	var rebound strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			rebound.WriteString("$" + strconv.Itoa(n))
+		} else {
+			rebound.WriteRune(c)
+		}
+	}
+	return rebound.String()
+}
+
+// forUpdate locks the rows a transaction reads. SQLite transactions already
+// exclude other writers.
+func (s SQLStorage) forUpdate(query string) string {
+	if s.Driver == PostgresDriver {
+		return query + " FOR UPDATE"
+	}
+	return query
+}
+
+// Transaction runs fn in a transaction, committing it if fn succeeds.
+func (s SQLStorage) Transaction(fn func(tx *sql.Tx) error) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s SQLStorage) putResource(q sqlQuerier, res Resource) error {
+	p, err := proto.Marshal(res.Proto())
+	if err != nil {
+		return err
+	}
+	id := res.ID()
+	query := s.rebind(`INSERT INTO metadata_resources (key, resource_type, name, variant, value) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`)
+	_, err = q.Exec(query, createKey(id), int32(id.Type), id.Name, id.Variant, p)
+	return err
+}
+
+func (s SQLStorage) getResource(q sqlQuerier, id ResourceID, lock bool) (Resource, error) {
+	query := "SELECT resource_type, value FROM metadata_resources WHERE key = ?"
+	if lock {
+		query = s.forUpdate(query)
+	}
+	var t int32
+	var value []byte
+	err := q.QueryRow(s.rebind(query), createKey(id)).Scan(&t, &value)
+	if err == sql.ErrNoRows {
+		return nil, &ResourceNotFound{id, nil}
+	} else if err != nil {
+		return nil, err
+	}
+	return unmarshalResource(ResourceType(t), value)
+}
+
+func (s SQLStorage) putJob(q sqlQuerier, key, value string) error {
+	query := s.rebind("INSERT INTO metadata_jobs (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value")
+	_, err := q.Exec(query, key, value)
+	return err
+}
+
+func (s SQLStorage) hasKey(q sqlQuerier, table, key string) (bool, error) {
+	var count int
+	query := s.rebind(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE key = ?", table))
+	if err := q.QueryRow(query, key).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// putAuditEvent writes an event under key, which is only overwritten when
+// it's written again, like when a migration is rerun.
+func (s SQLStorage) putAuditEvent(q sqlQuerier, key string, event *pb.AuditEvent) error {
+	id := resourceIDFromProto(event.ResourceId)
+	p, err := proto.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ts := event.Timestamp.AsTime().UnixNano()
+	query := s.rebind(`INSERT INTO metadata_audit_events (key, resource_key, timestamp, value) VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value`)
+	_, err = q.Exec(query, key, createKey(id), ts, p)
+	return err
+}
+
+type SQLResourceLookup struct {
+	Connection SQLStorage
+}
+
+func (lookup SQLResourceLookup) Lookup(id ResourceID) (Resource, error) {
+	return lookup.Connection.getResource(lookup.Connection.DB, id, false)
+}
+
+func (lookup SQLResourceLookup) Has(id ResourceID) (bool, error) {
+	return lookup.Connection.hasKey(lookup.Connection.DB, "metadata_resources", createKey(id))
+}
+
+func (lookup SQLResourceLookup) Set(id ResourceID, res Resource) error {
+	return lookup.set(id, res, nil)
+}
+
+func (lookup SQLResourceLookup) SetIfRevision(id ResourceID, res Resource, revision int64) error {
+	return lookup.set(id, res, &revision)
+}
+
+// set stores res at the revision after the stored one, checking that the
+// stored one is at revision first if it's given.
+func (lookup SQLResourceLookup) set(id ResourceID, res Resource, revision *int64) error {
+	return lookup.Connection.Transaction(func(tx *sql.Tx) error {
+		stored, err := lookup.Connection.getResource(tx, id, true)
+		if _, isNotFound := err.(*ResourceNotFound); err != nil && !isNotFound {
+			return err
+		}
+		actual := resourceRevision(stored)
+		if revision != nil && actual != *revision {
+			return &RevisionConflict{ID: id, Expected: *revision, Actual: actual}
+		}
+		setRevision(res, actual+1)
+		return lookup.Connection.putResource(tx, res)
+	})
+}
+
+func (lookup SQLResourceLookup) Delete(id ResourceID) error {
+	query := lookup.Connection.rebind("DELETE FROM metadata_resources WHERE key = ?")
+	result, err := lookup.Connection.DB.Exec(query, createKey(id))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not delete: %s", id))
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ResourceNotFound{id, nil}
+	}
+	return nil
+}
+
+func (lookup SQLResourceLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
+	resources := make(LocalResourceLookup, len(ids))
+	err := lookup.Connection.Transaction(func(tx *sql.Tx) error {
+		for _, id := range ids {
+			res, err := lookup.Connection.getResource(tx, id, false)
+			if err != nil {
+				return err
+			}
+			resources[id] = res
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+func (lookup SQLResourceLookup) list(query string, args ...interface{}) ([]Resource, error) {
+	rows, err := lookup.Connection.DB.Query(lookup.Connection.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	resources := make([]Resource, 0)
+	for rows.Next() {
+		var t int32
+		var value []byte
+		if err := rows.Scan(&t, &value); err != nil {
+			return nil, err
+		}
+		res, err := unmarshalResource(ResourceType(t), value)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not parse resource")
+		}
+		resources = append(resources, res)
+	}
+	return resources, rows.Err()
+}
+
+func (lookup SQLResourceLookup) ListForType(t ResourceType) ([]Resource, error) {
+	resources, err := lookup.list("SELECT resource_type, value FROM metadata_resources WHERE resource_type = ? ORDER BY key", int32(t))
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not list: %s", t))
+	}
+	return resources, nil
+}
+
+func (lookup SQLResourceLookup) ListRange(t ResourceType, start, end string, descending bool, limit int) ([]Resource, error) {
+	// Keys are compared byte by byte, as etcd does, whatever the database's
+	// collation.
+	collate := ""
+	if lookup.Connection.Driver == PostgresDriver {
+		collate = ` COLLATE "C"`
+	}
+	order := "ASC"
+	if descending {
+		order = "DESC"
+	}
+	query := fmt.Sprintf("SELECT resource_type, value FROM metadata_resources WHERE resource_type = ? AND key%[1]s >= ? AND key%[1]s < ? ORDER BY key%[1]s %[2]s", collate, order)
+	args := []interface{}{int32(t), start, end}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+	resources, err := lookup.list(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("could not list range: %s", t))
+	}
+	return resources, nil
+}
+
+func (lookup SQLResourceLookup) List() ([]Resource, error) {
+	resources, err := lookup.list("SELECT resource_type, value FROM metadata_resources ORDER BY key")
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list resources")
+	}
+	return resources, nil
+}
+
+func (lookup SQLResourceLookup) HasJob(id ResourceID) (bool, error) {
+	return lookup.Connection.hasKey(lookup.Connection.DB, "metadata_jobs", GetJobKey(id))
+}
+
+func (lookup SQLResourceLookup) SetJob(id ResourceID, schedule string) error {
+	coordinatorJob := CoordinatorJob{
+		Attempts: 0,
+		Resource: id,
+		Schedule: schedule,
+	}
+	serialized, err := coordinatorJob.Serialize()
+	if err != nil {
+		return err
+	}
+	return lookup.Connection.Transaction(func(tx *sql.Tx) error {
+		jobAlreadySet, err := lookup.Connection.hasKey(tx, "metadata_jobs", GetJobKey(id))
+		if err != nil {
+			return err
+		}
+		if jobAlreadySet {
+			return fmt.Errorf("Job already set")
+		}
+		return lookup.Connection.putJob(tx, GetJobKey(id), string(serialized))
+	})
+}
+
+// Removes the resource's coordinator job and schedule, if it has them
+func (lookup SQLResourceLookup) DeleteJob(id ResourceID) error {
+	query := lookup.Connection.rebind("DELETE FROM metadata_jobs WHERE key IN (?, ?)")
+	if _, err := lookup.Connection.DB.Exec(query, GetJobKey(id), GetScheduleJobKey(id)); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("could not delete jobs: %s", id))
+	}
+	return nil
+}
+
+func (lookup SQLResourceLookup) SetStatus(id ResourceID, status *pb.ResourceStatus) error {
+	return lookup.Connection.Transaction(func(tx *sql.Tx) error {
+		res, err := lookup.Connection.getResource(tx, id, true)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not lookup ID: %v", id))
+		}
+		if err := res.UpdateStatus(status); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not update ID: %v", id))
+		}
+		setRevision(res, resourceRevision(res)+1)
+		if err := lookup.Connection.putResource(tx, res); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not set ID: %v", id))
+		}
+		return nil
+	})
+}
+
+// SetSchedule updates the resource's schedule and its schedule job together.
+func (lookup SQLResourceLookup) SetSchedule(id ResourceID, schedule string) error {
+	coordinatorScheduleJob := CoordinatorScheduleJob{
+		Attempts: 0,
+		Resource: id,
+		Schedule: schedule,
+	}
+	serialized, err := coordinatorScheduleJob.Serialize()
+	if err != nil {
+		return err
+	}
+	return lookup.Connection.Transaction(func(tx *sql.Tx) error {
+		res, err := lookup.Connection.getResource(tx, id, true)
+		if err != nil {
+			return err
+		}
+		if err := res.UpdateSchedule(schedule); err != nil {
+			return err
+		}
+		setRevision(res, resourceRevision(res)+1)
+		if err := lookup.Connection.putResource(tx, res); err != nil {
+			return err
+		}
+		return lookup.Connection.putJob(tx, GetScheduleJobKey(id), string(serialized))
+	})
+}
+
+type SQLAuditLog struct {
+	Connection SQLStorage
+}
+
+func (auditLog SQLAuditLog) Append(event *pb.AuditEvent) error {
+	id := resourceIDFromProto(event.ResourceId)
+	key := auditEventKey(id, event.Timestamp.AsTime().UnixNano(), uuid.NewString())
+	return auditLog.Connection.putAuditEvent(auditLog.Connection.DB, key, event)
+}
+
+// query reads events from a query that selects their revision and value.
+func (auditLog SQLAuditLog) query(query string, args ...interface{}) ([]*pb.AuditEvent, error) {
+	rows, err := auditLog.Connection.DB.Query(auditLog.Connection.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	events := make([]*pb.AuditEvent, 0)
+	for rows.Next() {
+		var revision int64
+		var value []byte
+		if err := rows.Scan(&revision, &value); err != nil {
+			return nil, err
+		}
+		event := &pb.AuditEvent{}
+		if err := proto.Unmarshal(value, event); err != nil {
+			return nil, err
+		}
+		event.Revision = revision
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (auditLog SQLAuditLog) History(id ResourceID) ([]*pb.AuditEvent, error) {
+	return auditLog.query("SELECT revision, value FROM metadata_audit_events WHERE resource_key = ? ORDER BY timestamp, key", createKey(id))
+}
+
+func (auditLog SQLAuditLog) Range(start, end time.Time) ([]*pb.AuditEvent, error) {
+	return auditLog.query("SELECT revision, value FROM metadata_audit_events WHERE timestamp >= ? AND timestamp <= ? ORDER BY timestamp, key", start.UnixNano(), end.UnixNano())
+}
+
+// SQL watches poll for new events this often.
+var sqlWatchInterval = time.Second
+
+// sqlWatchGapTimeout is how long a watch waits for a skipped revision to
+// commit before it takes the transaction that had it to have rolled back.
+var sqlWatchGapTimeout = time.Minute
+
+// Watch polls the database, so that events appended by every metadata server
+// are seen. With Postgres, transactions can commit out of revision order, so
+// the revisions skipped over are polled for until they commit or time out,
+// and their events are passed to fn late.
+func (auditLog SQLAuditLog) Watch(ctx context.Context, startRevision int64, fn func(*pb.AuditEvent) error) error {
+	last := startRevision - 1
+	if startRevision <= 0 {
+		row := auditLog.Connection.DB.QueryRow("SELECT COALESCE(MAX(revision), 0) FROM metadata_audit_events")
+		if err := row.Scan(&last); err != nil {
+			return err
+		}
+	}
+	// gaps are the skipped revisions, and when they were first skipped.
+	gaps := make(map[int64]time.Time)
+	ticker := time.NewTicker(sqlWatchInterval)
+	defer ticker.Stop()
+	for {
+		from := last
+		for revision := range gaps {
+			if revision-1 < from {
+				from = revision - 1
+			}
+		}
+		events, err := auditLog.query("SELECT revision, value FROM metadata_audit_events WHERE revision > ? ORDER BY revision", from)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, event := range events {
+			if event.Revision <= last {
+				if _, skipped := gaps[event.Revision]; !skipped {
+					continue
+				}
+				delete(gaps, event.Revision)
+			} else {
+				for revision := last + 1; revision < event.Revision; revision++ {
+					gaps[revision] = now
+				}
+				last = event.Revision
+			}
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		for revision, skipped := range gaps {
+			if now.Sub(skipped) > sqlWatchGapTimeout {
+				delete(gaps, revision)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SQL job locks expire if they aren't renewed for this long, so that the
+// jobs of a coordinator that dies are picked up by another.
+var sqlJobLockTTL = 10 * time.Second
+
+// SQLJobStore keeps jobs in the metadata_jobs table. Watches poll it, and
+// locks are rows in metadata_job_locks that their owner keeps renewing.
+type SQLJobStore struct {
+	Connection SQLStorage
+}
+
+// jobs returns the jobs whose keys have prefix. Keys have underscores in
+// them, so they're matched with substr rather than LIKE.
+func (store SQLJobStore) jobs(prefix string) (map[string]string, error) {
+	query := store.Connection.rebind("SELECT key, value FROM metadata_jobs WHERE substr(key, 1, ?) = ?")
+	rows, err := store.Connection.DB.Query(query, len(prefix), prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	jobs := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		jobs[key] = value
+	}
+	return jobs, rows.Err()
+}
+
+// Watch polls for jobs as often as audit log watches do. A job is passed to
+// fn again when its value changes, as it would be by an etcd watch.
+func (store SQLJobStore) Watch(ctx context.Context, prefix string, fn func(key, value string)) error {
+	seen := make(map[string]string)
+	ticker := time.NewTicker(sqlWatchInterval)
+	defer ticker.Stop()
+	for {
+		jobs, err := store.jobs(prefix)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("could not get jobs: %s", prefix))
+		}
+		keys := make([]string, 0, len(jobs))
+		for key := range jobs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if value, has := seen[key]; !has || value != jobs[key] {
+				fn(key, jobs[key])
+			}
+		}
+		seen = jobs
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (store SQLJobStore) Has(prefix string) (bool, error) {
+	jobs, err := store.jobs(prefix)
+	if err != nil {
+		return false, err
+	}
+	return len(jobs) > 0, nil
+}
+
+func (store SQLJobStore) Lock(key string) (JobLock, error) {
+	lock := &sqlJobLock{
+		storage: store.Connection,
+		key:     key,
+		owner:   uuid.NewString(),
+		done:    make(chan struct{}),
+	}
+	for {
+		acquired, err := lock.acquire()
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			break
+		}
+		time.Sleep(sqlWatchInterval)
+	}
+	go lock.renew()
+	return lock, nil
+}
+
+type sqlJobLock struct {
+	storage SQLStorage
+	key     string
+	owner   string
+	done    chan struct{}
+}
+
+// acquire takes the lock if nobody has it or their claim has expired.
+func (lock *sqlJobLock) acquire() (bool, error) {
+	acquired := false
+	err := lock.storage.Transaction(func(tx *sql.Tx) error {
+		now := time.Now()
+		query := lock.storage.rebind("DELETE FROM metadata_job_locks WHERE key = ? AND expires < ?")
+		if _, err := tx.Exec(query, lock.key, now.UnixNano()); err != nil {
+			return err
+		}
+		query = lock.storage.rebind("INSERT INTO metadata_job_locks (key, owner, expires) VALUES (?, ?, ?) ON CONFLICT (key) DO NOTHING")
+		result, err := tx.Exec(query, lock.key, lock.owner, now.Add(sqlJobLockTTL).UnixNano())
+		if err != nil {
+			return err
+		}
+		inserted, err := result.RowsAffected()
+		acquired = inserted == 1
+		return err
+	})
+	return acquired, err
+}
+
+// renew pushes the lock's expiry back until it's unlocked.
+func (lock *sqlJobLock) renew() {
+	ticker := time.NewTicker(sqlJobLockTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-lock.done:
+			return
+		case <-ticker.C:
+		}
+		query := lock.storage.rebind("UPDATE metadata_job_locks SET expires = ? WHERE key = ? AND owner = ?")
+		result, err := lock.storage.DB.Exec(query, time.Now().Add(sqlJobLockTTL).UnixNano(), lock.key, lock.owner)
+		if err != nil {
+			continue
+		}
+		// Once it's lost, every use of the lock fails.
+		if renewed, err := result.RowsAffected(); err == nil && renewed == 0 {
+			return
+		}
+	}
+}
+
+// owned runs fn in a transaction that checks the lock is still held.
+func (lock *sqlJobLock) owned(fn func(tx *sql.Tx) error) error {
+	return lock.storage.Transaction(func(tx *sql.Tx) error {
+		var count int
+		query := lock.storage.rebind("SELECT COUNT(*) FROM metadata_job_locks WHERE key = ? AND owner = ? AND expires >= ?")
+		if err := tx.QueryRow(query, lock.key, lock.owner, time.Now().UnixNano()).Scan(&count); err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("was not owner of lock")
+		}
+		return fn(tx)
+	})
+}
+
+func (lock *sqlJobLock) Get() (string, bool, error) {
+	var value string
+	found := false
+	err := lock.owned(func(tx *sql.Tx) error {
+		query := lock.storage.rebind("SELECT value FROM metadata_jobs WHERE key = ?")
+		err := tx.QueryRow(query, lock.key).Scan(&value)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		found = err == nil
+		return err
+	})
+	return value, found, err
+}
+
+func (lock *sqlJobLock) Put(value string) error {
+	return lock.owned(func(tx *sql.Tx) error {
+		return lock.storage.putJob(tx, lock.key, value)
+	})
+}
+
+func (lock *sqlJobLock) Delete() (bool, error) {
+	deleted := false
+	err := lock.owned(func(tx *sql.Tx) error {
+		result, err := tx.Exec(lock.storage.rebind("DELETE FROM metadata_jobs WHERE key = ?"), lock.key)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		deleted = n == 1
+		return err
+	})
+	return deleted, err
+}
+
+func (lock *sqlJobLock) Unlock() error {
+	close(lock.done)
+	query := lock.storage.rebind("DELETE FROM metadata_job_locks WHERE key = ? AND owner = ?")
+	_, err := lock.storage.DB.Exec(query, lock.key, lock.owner)
+	return err
+}
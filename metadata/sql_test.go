@@ -0,0 +1,394 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func sqliteStorageProvider(t *testing.T) *SQLStorageProvider {
+	sp, err := NewSQLStorageProvider(SQLConfig{Driver: SQLiteDriver, DataSource: ":memory:"})
+	if err != nil {
+		t.Fatalf("Failed to open sqlite: %s", err)
+	}
+	t.Cleanup(func() { sp.Storage.DB.Close() })
+	return sp
+}
+
+func TestSQLRebind(t *testing.T) {
+	postgres := SQLStorage{Driver: PostgresDriver}
+	if rebound := postgres.rebind("SELECT a FROM b WHERE c = ? AND d = ?"); rebound != "SELECT a FROM b WHERE c = $1 AND d = $2" {
+		t.Fatalf("Wrong postgres query: %s", rebound)
+	}
+	sqlite := SQLStorage{Driver: SQLiteDriver}
+	if rebound := sqlite.rebind("SELECT a FROM b WHERE c = ?"); rebound != "SELECT a FROM b WHERE c = ?" {
+		t.Fatalf("Wrong sqlite query: %s", rebound)
+	}
+}
+
+func TestSQLUnsupportedDriver(t *testing.T) {
+	if _, err := (SQLConfig{Driver: "mysql"}).Open(); err == nil {
+		t.Fatalf("Expected unsupported driver to fail")
+	}
+}
+
+func TestSQLResourceLookup(t *testing.T) {
+	lookup, err := sqliteStorageProvider(t).GetResourceLookup()
+	if err != nil {
+		t.Fatalf("Failed to get lookup: %s", err)
+	}
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	res := &featureVariantResource{&pb.FeatureVariant{
+		Name:    "feature",
+		Variant: "variant",
+		Created: tspb.Now(),
+		Status:  &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+	}}
+	if _, err := lookup.Lookup(id); err == nil {
+		t.Fatalf("Expected missing resource to not be found")
+	} else if _, ok := err.(*ResourceNotFound); !ok {
+		t.Fatalf("Wrong error for missing resource: %T %s", err, err)
+	}
+	if err := lookup.Set(id, res); err != nil {
+		t.Fatalf("Failed to set: %s", err)
+	}
+	if has, err := lookup.Has(id); err != nil || !has {
+		t.Fatalf("Expected resource to exist: %v %v", has, err)
+	}
+	other := &userResource{&pb.User{Name: "user"}}
+	if err := lookup.Set(other.ID(), other); err != nil {
+		t.Fatalf("Failed to set: %s", err)
+	}
+	found, err := lookup.Lookup(id)
+	if err != nil {
+		t.Fatalf("Failed to lookup: %s", err)
+	}
+	if found.ID() != id {
+		t.Fatalf("Wrong resource: %v", found.ID())
+	}
+	features, err := lookup.ListForType(FEATURE_VARIANT)
+	if err != nil || len(features) != 1 {
+		t.Fatalf("Wrong features: %v %v", features, err)
+	}
+	all, err := lookup.List()
+	if err != nil || len(all) != 2 {
+		t.Fatalf("Wrong resources: %v %v", all, err)
+	}
+	submap, err := lookup.Submap([]ResourceID{id, other.ID()})
+	if err != nil {
+		t.Fatalf("Failed to get submap: %s", err)
+	}
+	if has, _ := submap.Has(other.ID()); !has {
+		t.Fatalf("Submap missing %v", other.ID())
+	}
+	if _, err := lookup.Submap([]ResourceID{{Name: "missing", Type: USER}}); err == nil {
+		t.Fatalf("Expected submap of missing resource to fail")
+	}
+
+	if err := lookup.SetStatus(id, &pb.ResourceStatus{Status: pb.ResourceStatus_READY}); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	found, _ = lookup.Lookup(id)
+	if status := found.Proto().(*pb.FeatureVariant).Status.Status; status != pb.ResourceStatus_READY {
+		t.Fatalf("Status not updated: %s", status)
+	}
+	if err := lookup.SetSchedule(id, "* * * * *"); err != nil {
+		t.Fatalf("Failed to set schedule: %s", err)
+	}
+	found, _ = lookup.Lookup(id)
+	if schedule := found.Schedule(); schedule != "* * * * *" {
+		t.Fatalf("Schedule not updated: %s", schedule)
+	}
+
+	if has, _ := lookup.HasJob(id); has {
+		t.Fatalf("Job set before SetJob")
+	}
+	if err := lookup.SetJob(id, ""); err != nil {
+		t.Fatalf("Failed to set job: %s", err)
+	}
+	if has, _ := lookup.HasJob(id); !has {
+		t.Fatalf("Job not set")
+	}
+	if err := lookup.SetJob(id, ""); err == nil {
+		t.Fatalf("Expected setting a job twice to fail")
+	}
+	if err := lookup.DeleteJob(id); err != nil {
+		t.Fatalf("Failed to delete job: %s", err)
+	}
+	if has, _ := lookup.HasJob(id); has {
+		t.Fatalf("Job not deleted")
+	}
+
+	if err := lookup.Delete(id); err != nil {
+		t.Fatalf("Failed to delete: %s", err)
+	}
+	if err := lookup.Delete(id); err == nil {
+		t.Fatalf("Expected deleting a missing resource to fail")
+	}
+	if has, _ := lookup.Has(id); has {
+		t.Fatalf("Resource not deleted")
+	}
+}
+
+func TestSQLAuditLog(t *testing.T) {
+	auditLog, err := sqliteStorageProvider(t).GetAuditLog()
+	if err != nil {
+		t.Fatalf("Failed to get audit log: %s", err)
+	}
+	id := ResourceID{Name: "user", Type: USER}
+	start := time.Now()
+	for i, action := range []pb.AuditEvent_Action{pb.AuditEvent_CREATE, pb.AuditEvent_UPDATE} {
+		event := &pb.AuditEvent{
+			ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+			Action:     action,
+			Timestamp:  tspb.New(start.Add(time.Duration(i) * time.Second)),
+		}
+		if err := auditLog.Append(event); err != nil {
+			t.Fatalf("Failed to append: %s", err)
+		}
+	}
+	history, err := auditLog.History(id)
+	if err != nil {
+		t.Fatalf("Failed to get history: %s", err)
+	}
+	if len(history) != 2 || history[0].Action != pb.AuditEvent_CREATE || history[1].Action != pb.AuditEvent_UPDATE {
+		t.Fatalf("Wrong history: %v", history)
+	}
+	events, err := auditLog.Range(start.Add(time.Millisecond), start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to get range: %s", err)
+	}
+	if len(events) != 1 || events[0].Action != pb.AuditEvent_UPDATE {
+		t.Fatalf("Wrong events in range: %v", events)
+	}
+}
+
+func TestSQLAuditLogWatch(t *testing.T) {
+	auditLog, err := sqliteStorageProvider(t).GetAuditLog()
+	if err != nil {
+		t.Fatalf("Failed to get audit log: %s", err)
+	}
+	interval := sqlWatchInterval
+	sqlWatchInterval = 10 * time.Millisecond
+	defer func() { sqlWatchInterval = interval }()
+	id := ResourceID{Name: "user", Type: USER}
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		event := &pb.AuditEvent{
+			ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+			Timestamp:  tspb.New(start.Add(time.Duration(i) * time.Second)),
+		}
+		if err := auditLog.Append(event); err != nil {
+			t.Fatalf("Failed to append: %s", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	revisions := make([]int64, 0)
+	err = auditLog.Watch(ctx, 2, func(event *pb.AuditEvent) error {
+		revisions = append(revisions, event.Revision)
+		if len(revisions) == 1 {
+			return auditLog.Append(&pb.AuditEvent{
+				ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+				Timestamp:  tspb.New(start.Add(time.Minute)),
+			})
+		}
+		cancel()
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("Unexpected watch error: %v", err)
+	}
+	if !reflect.DeepEqual(revisions, []int64{2, 3}) {
+		t.Fatalf("Wrong revisions watched: %v", revisions)
+	}
+}
+
+func TestSQLAuditLogWatchGap(t *testing.T) {
+	storage := sqliteStorageProvider(t).Storage
+	auditLog := SQLAuditLog{Connection: storage}
+	interval := sqlWatchInterval
+	sqlWatchInterval = 10 * time.Millisecond
+	defer func() { sqlWatchInterval = interval }()
+	id := ResourceID{Name: "user", Type: USER}
+	// Revision 2 is skipped, as though its transaction hadn't committed yet.
+	put := func(revision int64) {
+		event := &pb.AuditEvent{
+			ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+			Timestamp:  tspb.Now(),
+		}
+		p, err := proto.Marshal(event)
+		if err != nil {
+			t.Fatalf("Failed to marshal: %s", err)
+		}
+		query := storage.rebind("INSERT INTO metadata_audit_events (revision, key, resource_key, timestamp, value) VALUES (?, ?, ?, ?, ?)")
+		if _, err := storage.DB.Exec(query, revision, fmt.Sprintf("event-%d", revision), createKey(id), revision, p); err != nil {
+			t.Fatalf("Failed to insert event: %s", err)
+		}
+	}
+	put(1)
+	put(3)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	revisions := make([]int64, 0)
+	err := auditLog.Watch(ctx, 1, func(event *pb.AuditEvent) error {
+		revisions = append(revisions, event.Revision)
+		if event.Revision == 3 {
+			put(2)
+		}
+		if len(revisions) == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("Unexpected watch error: %v", err)
+	}
+	if !reflect.DeepEqual(revisions, []int64{1, 3, 2}) {
+		t.Fatalf("Wrong revisions watched: %v", revisions)
+	}
+}
+
+func TestSQLMetadataServer(t *testing.T) {
+	serv, err := NewMetadataServer(&Config{
+		Logger:          zaptest.NewLogger(t).Sugar(),
+		StorageProvider: sqliteStorageProvider(t),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go serv.ServeOnListener(lis)
+	defer serv.Stop()
+	client := client(t, lis.Addr().String())
+	defer client.Close()
+	ctx := context.Background()
+	if err := client.CreateAll(ctx, filledResourceDefs()); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(ctx, NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.Entity() != "user" {
+		t.Fatalf("Wrong feature entity: %s", feature.Entity())
+	}
+	entity, err := client.GetEntity(ctx, "user")
+	if err != nil {
+		t.Fatalf("Failed to get entity: %s", err)
+	}
+	if len(entity.Features()) == 0 {
+		t.Fatalf("Entity not updated with its features")
+	}
+	history, err := client.GetResourceHistory(ctx, ResourceID{Name: "user", Type: ENTITY})
+	if err != nil || len(history) == 0 {
+		t.Fatalf("Wrong entity history: %v %v", history, err)
+	}
+}
+
+func TestSQLAuditLogSameTimestamp(t *testing.T) {
+	auditLog, err := sqliteStorageProvider(t).GetAuditLog()
+	if err != nil {
+		t.Fatalf("Failed to get audit log: %s", err)
+	}
+	id := ResourceID{Name: "user", Type: USER}
+	ts := tspb.Now()
+	for _, action := range []pb.AuditEvent_Action{pb.AuditEvent_CREATE, pb.AuditEvent_UPDATE} {
+		event := &pb.AuditEvent{
+			ResourceId: &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+			Action:     action,
+			Timestamp:  ts,
+		}
+		if err := auditLog.Append(event); err != nil {
+			t.Fatalf("Failed to append: %s", err)
+		}
+	}
+	history, err := auditLog.History(id)
+	if err != nil {
+		t.Fatalf("Failed to get history: %s", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected events at the same time to both be kept: %v", history)
+	}
+}
+
+func TestSQLJobStore(t *testing.T) {
+	storage := sqliteStorageProvider(t).Storage
+	store := SQLJobStore{Connection: storage}
+	interval := sqlWatchInterval
+	sqlWatchInterval = 10 * time.Millisecond
+	defer func() { sqlWatchInterval = interval }()
+	lookup := SQLResourceLookup{Connection: storage}
+	first := ResourceID{Name: "first", Variant: "v", Type: FEATURE_VARIANT}
+	second := ResourceID{Name: "second", Variant: "v", Type: FEATURE_VARIANT}
+	if err := lookup.SetJob(first, ""); err != nil {
+		t.Fatalf("Failed to set job: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watched := make([]string, 0)
+	err := store.Watch(ctx, "JOB_", func(key, value string) {
+		watched = append(watched, key)
+		if len(watched) == 1 {
+			if err := lookup.SetJob(second, ""); err != nil {
+				t.Errorf("Failed to set job: %s", err)
+			}
+			return
+		}
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Fatalf("Unexpected watch error: %v", err)
+	}
+	if !reflect.DeepEqual(watched, []string{GetJobKey(first), GetJobKey(second)}) {
+		t.Fatalf("Wrong jobs watched: %v", watched)
+	}
+
+	key := GetJobKey(first)
+	lock, err := store.Lock(key)
+	if err != nil {
+		t.Fatalf("Failed to lock: %s", err)
+	}
+	// Nobody else gets the lock while it's held.
+	other := &sqlJobLock{storage: storage, key: key, owner: "other", done: make(chan struct{})}
+	if acquired, err := other.acquire(); err != nil || acquired {
+		t.Fatalf("Expected a held lock not to be acquired: %v %v", acquired, err)
+	}
+	if err := lock.Put("updated"); err != nil {
+		t.Fatalf("Failed to put job: %s", err)
+	}
+	if value, found, err := lock.Get(); err != nil || !found || value != "updated" {
+		t.Fatalf("Wrong job: %q %v %v", value, found, err)
+	}
+	if deleted, err := lock.Delete(); err != nil || !deleted {
+		t.Fatalf("Failed to delete job: %v %v", deleted, err)
+	}
+	if has, err := store.Has(key); err != nil || has {
+		t.Fatalf("Expected job to be deleted: %v %v", has, err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Failed to unlock: %s", err)
+	}
+	if _, _, err := lock.Get(); err == nil {
+		t.Fatalf("Expected an unlocked lock to fail")
+	}
+	if acquired, err := other.acquire(); err != nil || !acquired {
+		t.Fatalf("Expected an unlocked lock to be acquired: %v %v", acquired, err)
+	}
+}
@@ -0,0 +1,164 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// Only this many of a resource's failures are kept.
+	maxErrorHistory = 10
+	// Only the end of a failed job's logs is kept, up to this many bytes.
+	maxErrorLogBytes = 16 << 10
+)
+
+// ResourceError is why a resource's job failed.
+type ResourceError struct {
+	Message string
+	Attempt int
+	Failed  time.Time
+	Runner  string
+	Logs    string
+}
+
+func (err ResourceError) Serialize() *pb.ResourceError {
+	serialized := &pb.ResourceError{
+		Message: err.Message,
+		Attempt: int32(err.Attempt),
+		Runner:  err.Runner,
+		Logs:    err.Logs,
+	}
+	if !err.Failed.IsZero() {
+		serialized.Failed = tspb.New(err.Failed)
+	}
+	return serialized
+}
+
+func parseResourceError(serialized *pb.ResourceError) ResourceError {
+	err := ResourceError{
+		Message: serialized.GetMessage(),
+		Attempt: int(serialized.GetAttempt()),
+		Runner:  serialized.GetRunner(),
+		Logs:    serialized.GetLogs(),
+	}
+	if serialized.GetFailed() != nil {
+		err.Failed = serialized.GetFailed().AsTime()
+	}
+	return err
+}
+
+// Resource returns the error as the dashboard shows it.
+func (err ResourceError) Resource() ResourceErrorResource {
+	resource := ResourceErrorResource{
+		Message: err.Message,
+		Attempt: err.Attempt,
+		Runner:  err.Runner,
+		Logs:    err.Logs,
+	}
+	if !err.Failed.IsZero() {
+		resource.Failed = err.Failed.UTC().Format(time.RFC3339)
+	}
+	return resource
+}
+
+type statusGetter interface {
+	GetStatus() *pb.ResourceStatus
+}
+
+// resourceStatus returns a resource's status, or nil if it doesn't have one.
+func resourceStatus(res Resource) *pb.ResourceStatus {
+	if getter, ok := res.Proto().(statusGetter); ok {
+		return getter.GetStatus()
+	}
+	return nil
+}
+
+// truncateLogs keeps the end of logs, where a job's errors usually are.
+func truncateLogs(logs string) string {
+	if len(logs) <= maxErrorLogBytes {
+		return logs
+	}
+	start := len(logs) - maxErrorLogBytes
+	for start < len(logs) && !utf8.RuneStart(logs[start]) {
+		start++
+	}
+	return "..." + logs[start:]
+}
+
+// nextStatus returns the status to store when a resource at current is set
+// to next. Failures get an error if they were only given a message, and are
+// added to the error history, which is carried over from current. The last
+// failure is kept until the resource is ready.
+func nextStatus(current, next *pb.ResourceStatus) *pb.ResourceStatus {
+	status := proto.Clone(next).(*pb.ResourceStatus)
+	history := make([]*pb.ResourceError, 0, len(current.GetErrorHistory())+1)
+	for _, err := range current.GetErrorHistory() {
+		history = append(history, proto.Clone(err).(*pb.ResourceError))
+	}
+	switch status.Status {
+	case pb.ResourceStatus_READY:
+		status.Error = nil
+	case pb.ResourceStatus_FAILED:
+		if status.Error == nil {
+			status.Error = &pb.ResourceError{Message: status.ErrorMessage}
+		}
+		if status.Error.Failed == nil {
+			status.Error.Failed = tspb.Now()
+		}
+		if status.ErrorMessage == "" {
+			status.ErrorMessage = status.Error.Message
+		}
+		status.Error.Logs = truncateLogs(status.Error.Logs)
+		history = append(history, proto.Clone(status.Error).(*pb.ResourceError))
+	default:
+		status.Error = nil
+		if current.GetError() != nil {
+			status.Error = proto.Clone(current.GetError()).(*pb.ResourceError)
+		}
+	}
+	if len(history) > maxErrorHistory {
+		history = history[len(history)-maxErrorHistory:]
+	}
+	status.ErrorHistory = history
+	return status
+}
+
+// ResourceErrors are a resource's failures, oldest first.
+type ResourceErrors []ResourceError
+
+// Resource returns the errors as the dashboard shows them.
+func (errs ResourceErrors) Resource() []ResourceErrorResource {
+	resources := make([]ResourceErrorResource, len(errs))
+	for i, err := range errs {
+		resources[i] = err.Resource()
+	}
+	return resources
+}
+
+type fetchStatusErrorFn struct {
+	getter statusGetter
+}
+
+// Failure returns why the resource last failed. It's empty if it hasn't
+// failed since it was last ready.
+func (fn fetchStatusErrorFn) Failure() ResourceError {
+	return parseResourceError(fn.getter.GetStatus().GetError())
+}
+
+// ErrorHistory returns the resource's most recent failures, oldest first,
+// including the one it's failed with.
+func (fn fetchStatusErrorFn) ErrorHistory() ResourceErrors {
+	history := make(ResourceErrors, 0, len(fn.getter.GetStatus().GetErrorHistory()))
+	for _, err := range fn.getter.GetStatus().GetErrorHistory() {
+		history = append(history, parseResourceError(err))
+	}
+	return history
+}
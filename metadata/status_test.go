@@ -0,0 +1,122 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+func TestTruncateLogs(t *testing.T) {
+	if logs := truncateLogs("short"); logs != "short" {
+		t.Fatalf("Expected short logs to be kept: %s", logs)
+	}
+	long := "é" + strings.Repeat("a", maxErrorLogBytes-2) + "end"
+	truncated := truncateLogs(long)
+	if !strings.HasPrefix(truncated, "...") || !strings.HasSuffix(truncated, "end") {
+		t.Fatalf("Expected the end of the logs to be kept")
+	}
+	if len(truncated) > maxErrorLogBytes+len("...") || !utf8.ValidString(truncated) {
+		t.Fatalf("Expected at most %d valid bytes of logs, found %d", maxErrorLogBytes, len(truncated))
+	}
+}
+
+func TestNextStatusHistory(t *testing.T) {
+	current := &pb.ResourceStatus{}
+	for i := 0; i < maxErrorHistory+2; i++ {
+		current = nextStatus(current, &pb.ResourceStatus{Status: pb.ResourceStatus_FAILED, ErrorMessage: "failed"})
+	}
+	if len(current.ErrorHistory) != maxErrorHistory {
+		t.Fatalf("Expected %d errors kept, found %d", maxErrorHistory, len(current.ErrorHistory))
+	}
+	if current.Error.GetMessage() != "failed" || current.Error.Failed == nil {
+		t.Fatalf("Expected a message to be made into an error: %v", current.Error)
+	}
+}
+
+func TestSetFailed(t *testing.T) {
+	ctx := testContext{
+		Defs: filledResourceDefs(),
+	}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	nameVariant := NameVariant{Name: "feature", Variant: "variant"}
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+
+	failed := time.Now().UTC().Truncate(time.Second)
+	failure := ResourceError{
+		Message: "materialization failed",
+		Attempt: 1,
+		Failed:  failed,
+		Runner:  "Materialize",
+		Logs:    strings.Repeat("log line\n", maxErrorLogBytes),
+	}
+	if err := client.SetFailed(bg, id, failure); err != nil {
+		t.Fatalf("Failed to set failed: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(bg, nameVariant)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.Status() != FAILED || feature.Error() != failure.Message {
+		t.Fatalf("Expected failed with a message: %s %s", feature.Status(), feature.Error())
+	}
+	recorded := feature.Failure()
+	if recorded.Message != failure.Message || recorded.Attempt != 1 || !recorded.Failed.Equal(failed) || recorded.Runner != "Materialize" {
+		t.Fatalf("Expected %v found %v", failure, recorded)
+	}
+	if len(recorded.Logs) >= len(failure.Logs) || !strings.HasSuffix(failure.Logs, strings.TrimPrefix(recorded.Logs, "...")) {
+		t.Fatalf("Expected the end of the logs to be kept")
+	}
+
+	if err := client.SetStatus(bg, id, PENDING, ""); err != nil {
+		t.Fatalf("Failed to set pending: %s", err)
+	}
+	feature, err = client.GetFeatureVariant(bg, nameVariant)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.Failure().Message != failure.Message {
+		t.Fatalf("Expected a retried feature to keep its failure: %v", feature.Failure())
+	}
+	if err := client.SetStatus(bg, id, FAILED, "retry failed"); err != nil {
+		t.Fatalf("Failed to set failed: %s", err)
+	}
+	feature, err = client.GetFeatureVariant(bg, nameVariant)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.Failure().Message != "retry failed" || feature.Failure().Failed.IsZero() {
+		t.Fatalf("Expected a structured error from the message: %v", feature.Failure())
+	}
+	history := feature.ErrorHistory()
+	if len(history) != 2 || history[0].Message != failure.Message || history[1].Message != "retry failed" {
+		t.Fatalf("Expected both failures in the history: %v", history)
+	}
+
+	if err := client.SetStatus(bg, id, READY, ""); err != nil {
+		t.Fatalf("Failed to set ready: %s", err)
+	}
+	feature, err = client.GetFeatureVariant(bg, nameVariant)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if feature.Failure() != (ResourceError{}) || len(feature.ErrorHistory()) != 2 {
+		t.Fatalf("Expected a ready feature to keep only its history: %v %v", feature.Failure(), feature.ErrorHistory())
+	}
+	resource := feature.ErrorHistory().Resource()
+	if resource[0].Failed != failed.Format(time.RFC3339) || resource[0].Runner != "Materialize" {
+		t.Fatalf("Expected the dashboard to show the failure: %v", resource[0])
+	}
+}
@@ -0,0 +1,77 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SetTags replaces the tags of a resource.
+func (client *Client) SetTags(ctx context.Context, id ResourceID, tags Tags) error {
+	return client.SetTagsIfRevision(ctx, id, tags, 0)
+}
+
+// SetTagsIfRevision fails with an Aborted status if the resource isn't at
+// revision. A revision of 0 sets the tags whatever it's at.
+func (client *Client) SetTagsIfRevision(ctx context.Context, id ResourceID, tags Tags, revision int64) error {
+	_, err := client.GrpcConn.SetTags(ctx, &pb.SetTagsRequest{
+		ResourceId:       &pb.ResourceID{Resource: id.Proto(), ResourceType: id.Type.Serialized()},
+		Tags:             &pb.Tags{Tag: tags},
+		ExpectedRevision: revision,
+	})
+	return err
+}
+
+// setResourceTags replaces the tags of resources that have them.
+func setResourceTags(res Resource, tags *pb.Tags) bool {
+	switch serialized := res.Proto().(type) {
+	case *pb.User:
+		serialized.Tags = tags
+	case *pb.Provider:
+		serialized.Tags = tags
+	case *pb.Entity:
+		serialized.Tags = tags
+	case *pb.SourceVariant:
+		serialized.Tags = tags
+	case *pb.FeatureVariant:
+		serialized.Tags = tags
+	case *pb.FeatureGroupVariant:
+		serialized.Tags = tags
+	case *pb.LabelVariant:
+		serialized.Tags = tags
+	case *pb.TrainingSetVariant:
+		serialized.Tags = tags
+	case *pb.Model:
+		serialized.Tags = tags
+	case *pb.ModelVariant:
+		serialized.Tags = tags
+	default:
+		return false
+	}
+	return true
+}
+
+// SetTags replaces the tags of a resource. It goes through the lookup like
+// any other update, so the change is audited and searches see it.
+func (serv *MetadataServer) SetTags(ctx context.Context, req *pb.SetTagsRequest) (*pb.Empty, error) {
+	id := resourceIDFromProto(req.GetResourceId())
+	serv.Logger.Infow("Setting tags", "id", id.String(), "tags", req.GetTags().GetTag())
+	tags := &pb.Tags{Tag: req.GetTags().GetTag()}
+	before, res, err := updateResource(serv.lookup, id, req.GetExpectedRevision(), func(res Resource) error {
+		if !setResourceTags(res, tags) {
+			return status.Errorf(codes.InvalidArgument, "%s has no tags", id.Type)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	serv.audit(ctx, pb.AuditEvent_UPDATE, id, before, res.Proto())
+	return &pb.Empty{}, nil
+}
@@ -28,6 +28,9 @@ func unionNameVariants(destination, source []*pb.NameVariant) []*pb.NameVariant
 }
 
 func UnionTags(destination, source *pb.Tags) *pb.Tags {
+	if destination == nil {
+		destination = &pb.Tags{}
+	}
 	set := make(map[string]bool)
 
 	for _, tag := range destination.GetTag() {
@@ -55,3 +58,36 @@ func mergeProperties(destination, source *pb.Properties) *pb.Properties {
 	}
 	return destination
 }
+
+// updateNameVariants adds key to a list of related resources when it's
+// created and removes it when it's deleted.
+func updateNameVariants(op operation, list []*pb.NameVariant, key *pb.NameVariant) []*pb.NameVariant {
+	if op != delete_op {
+		return append(list, key)
+	}
+	updated := make([]*pb.NameVariant, 0, len(list))
+	for _, nameVariant := range list {
+		if nameVariant.Name != key.Name || nameVariant.Variant != key.Variant {
+			updated = append(updated, nameVariant)
+		}
+	}
+	return updated
+}
+
+// removeVariant removes a deleted variant from its parent's variants. If it
+// was the default, the most recently created remaining variant replaces it.
+func removeVariant(variants []string, defaultVariant, variant string) ([]string, string) {
+	updated := make([]string, 0, len(variants))
+	for _, v := range variants {
+		if v != variant {
+			updated = append(updated, v)
+		}
+	}
+	if defaultVariant == variant {
+		defaultVariant = ""
+		if len(updated) > 0 {
+			defaultVariant = updated[len(updated)-1]
+		}
+	}
+	return updated, defaultVariant
+}
@@ -20,6 +20,7 @@ type Observation string
 const (
 	TRAINING_ROW_SERVE Observation = "training_row_serve"
 	ONLINE_ROW_SERVE               = "online_row_serve"
+	DEPRECATED_SERVE               = "deprecated_serve"
 	ERROR                          = "error"
 	SUCCESS                        = "success"
 )
@@ -34,6 +35,8 @@ type MetricsHandler interface {
 type FeatureObserver interface {
 	SetError()
 	ServeRow()
+	// ServeDeprecated counts a request for a deprecated variant.
+	ServeDeprecated()
 	Finish()
 }
 
@@ -134,6 +137,10 @@ func (p PromFeatureObserver) ServeRow() {
 	p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(ONLINE_ROW_SERVE)).Inc()
 }
 
+func (p PromFeatureObserver) ServeDeprecated() {
+	p.Count.WithLabelValues(p.Name, p.Feature, p.Key, string(DEPRECATED_SERVE)).Inc()
+}
+
 func (p PromFeatureObserver) Finish() {
 	p.Status = string(SUCCESS)
 	p.Timer.ObserveDuration()
@@ -166,6 +173,10 @@ func (p TrainingDataObserver) ServeRow() {
 	p.Row_Count.WithLabelValues(p.Title, p.Name, p.Version, string(TRAINING_ROW_SERVE)).Inc()
 }
 
+func (p TrainingDataObserver) ServeDeprecated() {
+	p.Row_Count.WithLabelValues(p.Title, p.Name, p.Version, string(DEPRECATED_SERVE)).Inc()
+}
+
 func (p TrainingDataObserver) GetObservedRowCount() (int, error) {
 	var m = &dto.Metric{}
 	if err := p.Row_Count.WithLabelValues(p.Title, p.Name, p.Version, string(TRAINING_ROW_SERVE)).Write(m); err != nil {
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -111,6 +112,77 @@ func (pt *bqPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error)
 	return newBigQueryGenericTableIterator(it, pt.query), nil
 }
 
+func (pt *bqPrimaryTable) IterateQuery(query SegmentQuery) (GenericTableIterator, error) {
+	qry, params, err := pt.segmentQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	bqQ := pt.client.Query(qry)
+	bqQ.Parameters = params
+	it, err := bqQ.Read(pt.query.getContext())
+	if err != nil {
+		return nil, err
+	}
+	return newBigQueryGenericTableIterator(it, pt.query), nil
+}
+
+// segmentQuery builds the SQL for a SegmentQuery. Column names are quoted
+// rather than passed as parameters, so they're checked against the table's
+// schema first.
+func (pt *bqPrimaryTable) segmentQuery(query SegmentQuery) (string, []bigquery.QueryParameter, error) {
+	if err := query.check(); err != nil {
+		return "", nil, err
+	}
+	tableColumns := make([]string, len(pt.schema.Columns))
+	for i, col := range pt.schema.Columns {
+		tableColumns[i] = col.Name
+	}
+	if _, err := query.selectColumns(tableColumns); err != nil {
+		return "", nil, err
+	}
+	if _, err := (SegmentQuery{Columns: filterColumns(query.Filters)}).selectColumns(tableColumns); err != nil {
+		return "", nil, err
+	}
+	tableName := pt.query.getTableName(pt.name)
+	columns := "*"
+	if len(query.Columns) > 0 {
+		quoted := make([]string, len(query.Columns))
+		for i, col := range query.Columns {
+			quoted[i] = fmt.Sprintf("`%s`", col)
+		}
+		columns = strings.Join(quoted, ", ")
+	}
+	params := make([]bigquery.QueryParameter, 0)
+	conditions := make([]string, len(query.Filters))
+	for i, filter := range query.Filters {
+		if filter.Operator == FilterIn {
+			placeholders := make([]string, len(filter.Values))
+			for j, v := range filter.Values {
+				placeholders[j] = "?"
+				params = append(params, bigquery.QueryParameter{Value: v})
+			}
+			conditions[i] = fmt.Sprintf("`%s` IN (%s)", filter.Column, strings.Join(placeholders, ", "))
+		} else {
+			conditions[i] = fmt.Sprintf("`%s` %s ?", filter.Column, filter.Operator)
+			params = append(params, bigquery.QueryParameter{Value: filter.Values[0]})
+		}
+	}
+	qry := fmt.Sprintf("SELECT %s FROM `%s`", columns, tableName)
+	if len(conditions) > 0 {
+		qry = fmt.Sprintf("%s WHERE %s", qry, strings.Join(conditions, " AND "))
+	}
+	// BigQuery only allows an OFFSET after a LIMIT.
+	if query.Limit != -1 {
+		qry = fmt.Sprintf("%s LIMIT %d", qry, query.Limit)
+	} else if query.Offset > 0 {
+		qry = fmt.Sprintf("%s LIMIT %d", qry, int64(math.MaxInt64))
+	}
+	if query.Offset > 0 {
+		qry = fmt.Sprintf("%s OFFSET %d", qry, query.Offset)
+	}
+	return qry, params, nil
+}
+
 func (pt *bqPrimaryTable) NumRows() (int64, error) {
 	var n []bigquery.Value
 	tableName := pt.query.getTableName(pt.name)
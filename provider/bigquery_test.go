@@ -0,0 +1,42 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"testing"
+)
+
+func TestBigQuerySegmentQueryColumns(t *testing.T) {
+	table := &bqPrimaryTable{
+		name:  "primary",
+		query: &defaultBQQueries{TablePrefix: "project.dataset"},
+		schema: TableSchema{Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+		}},
+	}
+	qry, params, err := table.segmentQuery(SegmentQuery{
+		Columns: []string{"value"},
+		Filters: []ColumnFilter{{Column: "entity", Operator: FilterEqual, Values: []interface{}{"a"}}},
+		Limit:   10,
+	})
+	if err != nil {
+		t.Fatalf("Failed to build query: %s", err)
+	}
+	expected := "SELECT `value` FROM `project.dataset.primary` WHERE `entity` = ? LIMIT 10"
+	if qry != expected || len(params) != 1 {
+		t.Fatalf("Wrong query: %s %v\nExpected: %s", qry, params, expected)
+	}
+	injected := "value` FROM `other`; --"
+	invalid := map[string]SegmentQuery{
+		"Column": {Columns: []string{injected}, Limit: -1},
+		"Filter": {Filters: []ColumnFilter{{Column: injected, Operator: FilterEqual, Values: []interface{}{"a"}}}, Limit: -1},
+	}
+	for name, query := range invalid {
+		if qry, _, err := table.segmentQuery(query); err == nil {
+			t.Fatalf("Expected %s with a backtick to be rejected: %s", name, qry)
+		}
+	}
+}
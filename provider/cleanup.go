@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/metadata/proto"
+	pc "github.com/featureform/provider/provider_config"
+	pt "github.com/featureform/provider/provider_type"
+	"go.uber.org/zap"
+)
+
+// MetadataCleaner is a metadata.ResourceCleaner that drops the offline tables,
+// materializations and online tables of deleted variants. Offline stores that
+// don't implement ResourceDeleter are left as they are.
+type MetadataCleaner struct {
+	Logger *zap.SugaredLogger
+}
+
+func (cleaner MetadataCleaner) Cleanup(res metadata.Resource, lookup metadata.ResourceLookup) error {
+	switch serialized := res.Proto().(type) {
+	case *pb.SourceVariant:
+		resType := Transformation
+		if serialized.GetPrimaryData() != nil {
+			resType = Primary
+		}
+		return cleaner.deleteOffline(lookup, serialized.Provider, ResourceID{serialized.Name, serialized.Variant, resType})
+	case *pb.FeatureVariant:
+		if !metadata.PRECOMPUTED.Equals(serialized.Mode) {
+			return nil
+		}
+		sourceProvider, err := cleaner.sourceProvider(lookup, serialized.Source)
+		if err != nil {
+			return err
+		}
+		if err := cleaner.deleteOffline(lookup, sourceProvider, ResourceID{serialized.Name, serialized.Variant, Feature}); err != nil {
+			return err
+		}
+		return cleaner.deleteOnline(lookup, serialized.Provider, serialized.Name, serialized.Variant)
+	case *pb.LabelVariant:
+		sourceProvider, err := cleaner.sourceProvider(lookup, serialized.Source)
+		if err != nil {
+			return err
+		}
+		return cleaner.deleteOffline(lookup, sourceProvider, ResourceID{serialized.Name, serialized.Variant, Label})
+	case *pb.TrainingSetVariant:
+		return cleaner.deleteOffline(lookup, serialized.Provider, ResourceID{serialized.Name, serialized.Variant, TrainingSet})
+	}
+	return nil
+}
+
+func (cleaner MetadataCleaner) sourceProvider(lookup metadata.ResourceLookup, source *pb.NameVariant) (string, error) {
+	id := metadata.ResourceID{Name: source.GetName(), Variant: source.GetVariant(), Type: metadata.SOURCE_VARIANT}
+	res, err := lookup.Lookup(id)
+	if err != nil {
+		return "", err
+	}
+	variant, ok := res.Proto().(*pb.SourceVariant)
+	if !ok {
+		return "", fmt.Errorf("%s is not a source variant", id)
+	}
+	return variant.Provider, nil
+}
+
+func (cleaner MetadataCleaner) getProvider(lookup metadata.ResourceLookup, name string) (Provider, error) {
+	id := metadata.ResourceID{Name: name, Type: metadata.PROVIDER}
+	res, err := lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	serialized, ok := res.Proto().(*pb.Provider)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a provider", id)
+	}
+	return Get(pt.Type(serialized.Type), pc.SerializedConfig(serialized.SerializedConfig))
+}
+
+func (cleaner MetadataCleaner) deleteOffline(lookup metadata.ResourceLookup, providerName string, id ResourceID) error {
+	p, err := cleaner.getProvider(lookup, providerName)
+	if err != nil {
+		return err
+	}
+	store, err := p.AsOfflineStore()
+	if err != nil {
+		return err
+	}
+	deleter, ok := store.(ResourceDeleter)
+	if !ok {
+		cleaner.Logger.Infow("Offline store cannot delete resources", "provider", providerName, "resource", id)
+		return nil
+	}
+	cleaner.Logger.Infow("Deleting offline resource", "provider", providerName, "resource", id)
+	return deleter.DeleteResource(id)
+}
+
+func (cleaner MetadataCleaner) deleteOnline(lookup metadata.ResourceLookup, providerName, name, variant string) error {
+	p, err := cleaner.getProvider(lookup, providerName)
+	if err != nil {
+		return err
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		return err
+	}
+	cleaner.Logger.Infow("Deleting online table", "provider", providerName, "name", name, "variant", variant)
+	if err := store.DeleteTable(name, variant); err != nil {
+		if _, notFound := err.(*TableNotFound); !notFound {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -612,6 +613,32 @@ func (tbl *FileStorePrimaryTable) GetName() string {
 }
 
 func (tbl *FileStorePrimaryTable) IterateSegment(n int64) (GenericTableIterator, error) {
+	return tbl.iterate(n)
+}
+
+// IterateQuery filters and projects rows as they're read from the underlying
+// files. Without filters, only the rows up to the offset and limit are read.
+func (tbl *FileStorePrimaryTable) IterateQuery(query SegmentQuery) (GenericTableIterator, error) {
+	if err := query.check(); err != nil {
+		return nil, err
+	}
+	n := int64(-1)
+	if len(query.Filters) == 0 && query.Limit != -1 {
+		n = query.Offset + query.Limit
+	}
+	iter, err := tbl.iterate(n)
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := FilterTableIterator(iter, query)
+	if err != nil {
+		iter.Close()
+		return nil, err
+	}
+	return filtered, nil
+}
+
+func (tbl *FileStorePrimaryTable) iterate(n int64) (GenericTableIterator, error) {
 	sources := []filestore.Filepath{tbl.source}
 	if tbl.source.IsDir() {
 		// The key should only be a directory in the case of transformations.
@@ -638,15 +665,23 @@ func (tbl *FileStorePrimaryTable) IterateSegment(n int64) (GenericTableIterator,
 	}
 	fmt.Printf("Sources: %d found\n", len(sources))
 	fmt.Printf("Source %s extension %s\n", sources[0].ToURI(), string(sources[0].Ext()))
+	iter, err := newFileStoreTableIterator(sources, tbl.store, n)
+	if err != nil {
+		return nil, fmt.Errorf("table (%v): %w", tbl.id, err)
+	}
+	return iter, nil
+}
+
+func newFileStoreTableIterator(sources []filestore.Filepath, store FileStore, n int64) (GenericTableIterator, error) {
 	switch sources[0].Ext() {
 	case filestore.Parquet:
-		return newMultipleFileParquetIterator(sources, tbl.store, n)
+		return newMultipleFileParquetIterator(sources, store, n)
 	case filestore.CSV:
 		if len(sources) > 1 {
-			return nil, fmt.Errorf("multiple CSV files found for table (%v)", tbl.id)
+			return nil, fmt.Errorf("multiple CSV files found")
 		}
-		fmt.Printf("Reading file at key %s in file store type %s\n", sources[0].Key(), tbl.store.FilestoreType())
-		b, err := tbl.store.Read(sources[0])
+		fmt.Printf("Reading file at key %s in file store type %s\n", sources[0].Key(), store.FilestoreType())
+		b, err := store.Read(sources[0])
 		if err != nil {
 			return nil, fmt.Errorf("could not read file: %w", err)
 		}
@@ -1085,6 +1120,137 @@ func fileStoreGetResourceTable(id ResourceID, store FileStore, logger *zap.Sugar
 	return &BlobOfflineTable{schema: resourceSchema, store: store}, nil
 }
 
+func (k8s *K8sOfflineStore) GetFeatureValuesAsOf(id ResourceID, entities []string, ts time.Time) ([]interface{}, error) {
+	return fileStoreGetFeatureValuesAsOf(id, entities, ts, k8s.store, k8s.logger)
+}
+
+// fileStoreGetFeatureValuesAsOf scans a feature's source files for the last
+// value of each entity at or before ts. Rows without a timestamp column are
+// treated as always set, with later rows taking precedence.
+func fileStoreGetFeatureValuesAsOf(id ResourceID, entities []string, ts time.Time, store FileStore, logger *zap.SugaredLogger) ([]interface{}, error) {
+	if err := id.check(Feature); err != nil {
+		return nil, err
+	}
+	table, err := fileStoreGetResourceTable(id, store, logger)
+	if err != nil {
+		return nil, err
+	}
+	blobTable, ok := table.(*BlobOfflineTable)
+	if !ok {
+		return nil, fmt.Errorf("resource table for %v is not a blob offline table", id)
+	}
+	schema := blobTable.schema
+	sources, err := fileStoreResourceSources(schema.SourceTable, store)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugw("Looking up feature values as of", "id", id, "ts", ts, "sources", len(sources))
+	iter, err := newFileStoreTableIterator(sources, store, -1)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	entityIdx, valueIdx, tsIdx := -1, -1, -1
+	for i, col := range iter.Columns() {
+		switch col {
+		case schema.Entity:
+			entityIdx = i
+		case schema.Value:
+			valueIdx = i
+		case schema.TS:
+			tsIdx = i
+		}
+	}
+	if entityIdx == -1 || valueIdx == -1 || (schema.TS != "" && tsIdx == -1) {
+		return nil, fmt.Errorf("source for %v is missing columns %s, %s or %s", id, schema.Entity, schema.Value, schema.TS)
+	}
+	type lastValue struct {
+		value interface{}
+		ts    time.Time
+		found bool
+	}
+	latest := make(map[string]*lastValue, len(entities))
+	for _, entity := range entities {
+		latest[entity] = &lastValue{}
+	}
+	for iter.Next() {
+		row := iter.Values()
+		last, wanted := latest[fmt.Sprintf("%v", row[entityIdx])]
+		if !wanted {
+			continue
+		}
+		rowTS := time.UnixMilli(0).UTC()
+		if tsIdx != -1 {
+			parsed, err := asOfTimestamp(row[tsIdx])
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp in source for %v: %w", id, err)
+			}
+			rowTS = parsed
+		}
+		if rowTS.After(ts) || (last.found && rowTS.Before(last.ts)) {
+			continue
+		}
+		*last = lastValue{value: row[valueIdx], ts: rowTS, found: true}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		values[i] = latest[entity].value
+	}
+	return values, nil
+}
+
+// fileStoreResourceSources resolves a resource schema's source table, which is
+// either a single file or a directory of dated parquet files, to the files to
+// read.
+func fileStoreResourceSources(sourceTable string, store FileStore) ([]filestore.Filepath, error) {
+	var source filestore.Filepath
+	if strings.Contains(sourceTable, "://") {
+		path, err := filestore.NewEmptyFilepath(store.FilestoreType())
+		if err != nil {
+			return nil, err
+		}
+		if err := path.ParseFilePath(sourceTable); err != nil {
+			return nil, fmt.Errorf("could not parse source table %s: %w", sourceTable, err)
+		}
+		source = path
+	} else {
+		path, err := store.CreateFilePath(sourceTable)
+		if err != nil {
+			return nil, fmt.Errorf("could not create file path: %w", err)
+		}
+		source = path
+	}
+	if source.Ext() != "" {
+		return []filestore.Filepath{source}, nil
+	}
+	source.SetIsDir(true)
+	files, err := store.List(source, filestore.Parquet)
+	if err != nil {
+		return nil, fmt.Errorf("could not list source files: %w", err)
+	}
+	groups, err := filestore.NewFilePathGroup(files, filestore.DateTimeDirectoryGrouping)
+	if err != nil {
+		return nil, fmt.Errorf("could not group source files by datetime: %w", err)
+	}
+	return groups.GetFirst()
+}
+
+func asOfTimestamp(v interface{}) (time.Time, error) {
+	switch ts := v.(type) {
+	case time.Time:
+		return ts.UTC(), nil
+	case string:
+		return time.Parse(time.RFC3339, ts)
+	case nil:
+		return time.UnixMilli(0).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp type %T", v)
+	}
+}
+
 // **NOTE:** This is a temporary fix for GCS. The primary source table path is stored in the resource schema,
 // which will contain only a directory path for resources other than a primary source. For example, or a Label that was
 // created from a primary source, the value for `SourceTable` on the label schema will be:
@@ -1385,6 +1551,36 @@ func fileStoreDeleteMaterialization(id MaterializationID, store FileStore, logge
 	return store.DeleteAll(materializationPath)
 }
 
+func (k8s *K8sOfflineStore) DeleteResource(id ResourceID) error {
+	return fileStoreDeleteResource(id, k8s.store)
+}
+
+// fileStoreDeleteResource removes the files that a file store holds for a
+// resource, along with a feature's materialization.
+func fileStoreDeleteResource(id ResourceID, store FileStore) error {
+	ids := []ResourceID{id}
+	if id.Type == Feature {
+		ids = append(ids, ResourceID{id.Name, id.Variant, FeatureMaterialization})
+	}
+	for _, id := range ids {
+		path, err := store.CreateFilePath(fileStoreResourcePath(id))
+		if err != nil {
+			return fmt.Errorf("could not create file path for %v: %w", id, err)
+		}
+		exists, err := store.Exists(path)
+		if err != nil {
+			return fmt.Errorf("could not check if %v exists: %w", id, err)
+		}
+		if !exists {
+			continue
+		}
+		if err := store.DeleteAll(path); err != nil {
+			return fmt.Errorf("could not delete %v: %w", id, err)
+		}
+	}
+	return nil
+}
+
 func (k8s *K8sOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 	return k8s.trainingSet(def, false)
 }
@@ -1497,6 +1693,27 @@ func (k8s *K8sOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator,
 	return fileStoreGetTrainingSet(id, k8s.store, k8s.logger)
 }
 
+func (k8s *K8sOfflineStore) ReadTrainingSet(id ResourceID, opts TrainingSetReadOptions) (TrainingSetIterator, error) {
+	return fileStoreReadTrainingSet(id, opts, k8s.store, k8s.logger)
+}
+
+// fileStoreReadTrainingSet serves a shard of a training set. The part files
+// are read in key order so that row numbering, and therefore shard
+// assignment, is the same on every read.
+func fileStoreReadTrainingSet(id ResourceID, opts TrainingSetReadOptions, store FileStore, logger *zap.SugaredLogger) (TrainingSetIterator, error) {
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+	if opts.Shuffle.Enabled {
+		return nil, fmt.Errorf("file store training sets cannot be shuffled while reading")
+	}
+	iter, err := fileStoreGetTrainingSet(id, store, logger)
+	if err != nil {
+		return nil, err
+	}
+	return ShardTrainingSetIterator(iter, opts)
+}
+
 func fileStoreGetTrainingSet(id ResourceID, store FileStore, logger *zap.SugaredLogger) (TrainingSetIterator, error) {
 	if err := id.check(TrainingSet); err != nil {
 		logger.Errorw("Resource is not of type training set", "error", err)
@@ -1526,6 +1743,9 @@ func fileStoreGetTrainingSet(id ResourceID, store FileStore, logger *zap.Sugared
 	if err != nil {
 		return nil, fmt.Errorf("could not get newest files: %v", err)
 	}
+	sort.Slice(newestFiles, func(i, j int) bool {
+		return newestFiles[i].Key() < newestFiles[j].Key()
+	})
 	iterator, err := store.Serve(newestFiles)
 	if err != nil {
 		return nil, fmt.Errorf("could not serve training set: %w", err)
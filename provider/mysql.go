@@ -85,6 +85,20 @@ func (q mySQLQueries) materializationCreate(tableName string, sourceName string)
 	return q.primaryTableRegister(tableName, sourceName)
 }
 
+// MySQL doesn't allow an OFFSET without a LIMIT, so we use the largest
+// possible limit in its place.
+func (q mySQLQueries) primaryTableSegmentLimit(limit int64, offset int64) string {
+	if limit == -1 && offset > 0 {
+		return fmt.Sprintf("LIMIT 18446744073709551615 OFFSET %d", offset)
+	}
+	return q.defaultOfflineSQLQueries.primaryTableSegmentLimit(limit, offset)
+}
+
+// MySQL can't cast to VARCHAR, only to CHAR.
+func (q mySQLQueries) resourceValuesAsOf(tableName string, entityCount int) string {
+	return q.resourceValuesAsOfCast(tableName, "CHAR", entityCount)
+}
+
 func (q mySQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
 	query := `DROP VIEW IF EXISTS ?;` + q.primaryTableCreate(tableName, sourceName)
 	_, err := db.Exec(query, tableName)
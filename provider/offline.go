@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
 	"sort"
 	"strings"
@@ -280,6 +281,144 @@ type TrainingSetIterator interface {
 	Err() error
 }
 
+// TrainingSetShard selects a disjoint slice of a training set. Rows are
+// assigned round-robin, so row i belongs to shard i % Count.
+type TrainingSetShard struct {
+	Index int64
+	Count int64
+}
+
+func (shard TrainingSetShard) check() error {
+	if shard.Count < 0 || shard.Index < 0 {
+		return fmt.Errorf("shard index and count must be non-negative: %d/%d", shard.Index, shard.Count)
+	}
+	if shard.Count > 0 && shard.Index >= shard.Count {
+		return fmt.Errorf("shard index %d out of range for shard count %d", shard.Index, shard.Count)
+	}
+	return nil
+}
+
+// isSharded returns false when every row is in the shard.
+func (shard TrainingSetShard) isSharded() bool {
+	return shard.Count > 1
+}
+
+func (shard TrainingSetShard) contains(row int64) bool {
+	if !shard.isSharded() {
+		return true
+	}
+	return row%shard.Count == shard.Index
+}
+
+// TrainingSetShuffle requests that rows be served in a pseudo-random order
+// that is fully determined by Seed.
+type TrainingSetShuffle struct {
+	Enabled bool
+	Seed    int64
+}
+
+type TrainingSetReadOptions struct {
+	Shard TrainingSetShard
+	// Offset is the number of rows of the shard to skip before serving.
+	Offset int64
+	// Shuffle is applied before sharding, so shards partition the shuffled
+	// order. Only readers that implement GlobalShuffleReader support it.
+	Shuffle TrainingSetShuffle
+}
+
+func (opts TrainingSetReadOptions) check() error {
+	if err := opts.Shard.check(); err != nil {
+		return err
+	}
+	if opts.Offset < 0 {
+		return fmt.Errorf("training set offset must be non-negative: %d", opts.Offset)
+	}
+	return nil
+}
+
+// TrainingSetReader is implemented by offline stores that can serve a
+// deterministic shard of a training set starting at an offset. Serving the
+// same options twice yields the same rows in the same order.
+type TrainingSetReader interface {
+	ReadTrainingSet(id ResourceID, opts TrainingSetReadOptions) (TrainingSetIterator, error)
+}
+
+// GlobalShuffleReader is a TrainingSetReader that can shuffle the entire
+// training set as part of reading it, typically by ordering on a seeded hash
+// in the query.
+type GlobalShuffleReader interface {
+	TrainingSetReader
+	SupportsGlobalShuffle() bool
+}
+
+// PointInTimeLookup is implemented by offline stores that can answer what a
+// feature's value was for entities at a point in time. Like training sets, it
+// uses each entity's last value at or before the timestamp. Values are
+// returned in the order of entities, with nil for entities that had no value.
+type PointInTimeLookup interface {
+	GetFeatureValuesAsOf(id ResourceID, entities []string, ts time.Time) ([]interface{}, error)
+}
+
+// ResourceDeleter is implemented by offline stores that can drop the tables
+// they hold for a resource. Deleting a resource that doesn't exist succeeds.
+type ResourceDeleter interface {
+	DeleteResource(id ResourceID) error
+}
+
+// ShardTrainingSetIterator filters an iterator down to a shard and skips the
+// first opts.Offset rows of that shard. The underlying iterator must return
+// rows in a deterministic order for the shards to be disjoint.
+func ShardTrainingSetIterator(iter TrainingSetIterator, opts TrainingSetReadOptions) (TrainingSetIterator, error) {
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+	if opts.Shuffle.Enabled {
+		return nil, fmt.Errorf("cannot shuffle a training set iterator while sharding it")
+	}
+	return &shardedTrainingSetIterator{
+		iter: iter,
+		opts: opts,
+	}, nil
+}
+
+type shardedTrainingSetIterator struct {
+	iter TrainingSetIterator
+	opts TrainingSetReadOptions
+	// row is the index of the next row in the underlying iterator.
+	row int64
+	// served is the number of rows of the shard seen so far, including
+	// skipped ones.
+	served int64
+}
+
+func (it *shardedTrainingSetIterator) Next() bool {
+	for it.iter.Next() {
+		row := it.row
+		it.row++
+		if !it.opts.Shard.contains(row) {
+			continue
+		}
+		it.served++
+		if it.served <= it.opts.Offset {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func (it *shardedTrainingSetIterator) Features() []interface{} {
+	return it.iter.Features()
+}
+
+func (it *shardedTrainingSetIterator) Label() interface{} {
+	return it.iter.Label()
+}
+
+func (it *shardedTrainingSetIterator) Err() error {
+	return it.iter.Err()
+}
+
 type GenericTableIterator interface {
 	Next() bool
 	Values() GenericRecord
@@ -380,6 +519,102 @@ type PrimaryTable interface {
 	NumRows() (int64, error)
 }
 
+type FilterOperator string
+
+const (
+	FilterEqual              FilterOperator = "="
+	FilterGreaterThan        FilterOperator = ">"
+	FilterGreaterThanOrEqual FilterOperator = ">="
+	FilterLessThan           FilterOperator = "<"
+	FilterLessThanOrEqual    FilterOperator = "<="
+	FilterIn                 FilterOperator = "IN"
+)
+
+// ColumnFilter is a predicate on a single column. Every operator but
+// FilterIn takes exactly one value.
+type ColumnFilter struct {
+	Column   string
+	Operator FilterOperator
+	Values   []interface{}
+}
+
+func (filter ColumnFilter) check() error {
+	if filter.Column == "" {
+		return fmt.Errorf("filter column cannot be empty")
+	}
+	switch filter.Operator {
+	case FilterIn:
+		if len(filter.Values) == 0 {
+			return fmt.Errorf("filter on %s: IN requires at least one value", filter.Column)
+		}
+	case FilterEqual, FilterGreaterThan, FilterGreaterThanOrEqual, FilterLessThan, FilterLessThanOrEqual:
+		if len(filter.Values) != 1 {
+			return fmt.Errorf("filter on %s: %s requires exactly one value, got %d", filter.Column, filter.Operator, len(filter.Values))
+		}
+	default:
+		return fmt.Errorf("filter on %s: unknown operator %q", filter.Column, filter.Operator)
+	}
+	return nil
+}
+
+// SegmentQuery selects a subset of a primary table. An empty Columns serves
+// every column, Filters are ANDed together, and a Limit of -1 serves every
+// matching row.
+type SegmentQuery struct {
+	Columns []string
+	Filters []ColumnFilter
+	Offset  int64
+	Limit   int64
+}
+
+func (query SegmentQuery) check() error {
+	for _, filter := range query.Filters {
+		if err := filter.check(); err != nil {
+			return err
+		}
+	}
+	if query.Offset < 0 {
+		return fmt.Errorf("segment offset must be non-negative: %d", query.Offset)
+	}
+	if query.Limit < -1 {
+		return fmt.Errorf("segment limit must be -1 or non-negative: %d", query.Limit)
+	}
+	return nil
+}
+
+// selectColumns maps the query's columns onto their positions in the table's
+// columns, erroring on columns the table doesn't have.
+func (query SegmentQuery) selectColumns(tableColumns []string) ([]int, error) {
+	if len(query.Columns) == 0 {
+		indices := make([]int, len(tableColumns))
+		for i := range tableColumns {
+			indices[i] = i
+		}
+		return indices, nil
+	}
+	positions := make(map[string]int, len(tableColumns))
+	for i, col := range tableColumns {
+		positions[col] = i
+	}
+	indices := make([]int, len(query.Columns))
+	for i, col := range query.Columns {
+		idx, has := positions[col]
+		if !has {
+			return nil, fmt.Errorf("column %s not found in table", col)
+		}
+		indices[i] = idx
+	}
+	return indices, nil
+}
+
+// QueryablePrimaryTable is implemented by primary tables that can push a
+// SegmentQuery down into the underlying store. Tables that don't can be
+// queried with FilterTableIterator over IterateSegment(-1).
+type QueryablePrimaryTable interface {
+	PrimaryTable
+	IterateQuery(query SegmentQuery) (GenericTableIterator, error)
+}
+
 type TransformationTable interface {
 	PrimaryTable
 }
@@ -688,6 +923,17 @@ func (store *memoryOfflineStore) DeleteMaterialization(id MaterializationID) err
 	return nil
 }
 
+// DeleteResource drops a resource table or training set. Materializations
+// aren't tied to the feature they came from, so they're left in place.
+func (store *memoryOfflineStore) DeleteResource(id ResourceID) error {
+	if id.Type == TrainingSet {
+		store.trainingSets.Delete(id)
+		return nil
+	}
+	store.tables.Delete(id)
+	return nil
+}
+
 func latestRecord(recs []ResourceRecord) ResourceRecord {
 	latest := recs[0]
 	for _, rec := range recs {
@@ -715,6 +961,14 @@ func (store *memoryOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 		features[i] = feature
 	}
 	labelRecs := label.records()
+	// Rows are ordered by entity and timestamp so that shards of the training
+	// set are stable across stores built from the same data.
+	sort.Slice(labelRecs, func(i, j int) bool {
+		if labelRecs[i].Entity != labelRecs[j].Entity {
+			return labelRecs[i].Entity < labelRecs[j].Entity
+		}
+		return labelRecs[i].TS.Before(labelRecs[j].TS)
+	})
 	trainingData := make(trainingRows, len(labelRecs))
 	for i, rec := range labelRecs {
 		featureVals := make([]interface{}, len(features))
@@ -745,6 +999,43 @@ func (store *memoryOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetItera
 	}
 	return data.(trainingRows).Iterator(), nil
 }
+
+func (store *memoryOfflineStore) ReadTrainingSet(id ResourceID, opts TrainingSetReadOptions) (TrainingSetIterator, error) {
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
+	if err := id.check(TrainingSet); err != nil {
+		return nil, err
+	}
+	data, has := store.trainingSets.Load(id)
+	if !has {
+		return nil, &TrainingSetNotFound{id}
+	}
+	rows := data.(trainingRows)
+	if opts.Shuffle.Enabled {
+		shuffled := make(trainingRows, len(rows))
+		copy(shuffled, rows)
+		rand.New(rand.NewSource(opts.Shuffle.Seed)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		rows = shuffled
+	}
+	shard := make(trainingRows, 0, len(rows))
+	for i, row := range rows {
+		if opts.Shard.contains(int64(i)) {
+			shard = append(shard, row)
+		}
+	}
+	if opts.Offset >= int64(len(shard)) {
+		return trainingRows{}.Iterator(), nil
+	}
+	return shard[opts.Offset:].Iterator(), nil
+}
+
+func (store *memoryOfflineStore) SupportsGlobalShuffle() bool {
+	return true
+}
+
 func (store *memoryOfflineStore) Close() error {
 	return nil
 }
@@ -805,6 +1096,21 @@ func (it *memoryTrainingRowsIterator) Label() interface{} {
 	return it.data[it.idx].Label
 }
 
+func (store *memoryOfflineStore) GetFeatureValuesAsOf(id ResourceID, entities []string, ts time.Time) ([]interface{}, error) {
+	if err := id.check(Feature); err != nil {
+		return nil, err
+	}
+	table, err := store.getMemoryResourceTable(id)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		values[i] = table.getLastValueBefore(entity, ts)
+	}
+	return values, nil
+}
+
 type memoryOfflineTable struct {
 	entityMap syncmap.Map
 }
@@ -324,6 +324,7 @@ func TestOfflineStores(t *testing.T) {
 		"FeatureTableNotFound":   testFeatureTableNotFound,
 
 		"TrainingDefShorthand": testTrainingSetDefShorthand,
+		"DeleteResource":       testDeleteResource,
 	}
 	testSQLFns := map[string]func(*testing.T, OfflineStore){
 		"PrimaryTableCreate":                 testPrimaryCreateTable,
@@ -597,6 +598,33 @@ func testOfflineTableNotFound(t *testing.T, store OfflineStore) {
 	}
 }
 
+func testDeleteResource(t *testing.T, store OfflineStore) {
+	deleter, ok := store.(ResourceDeleter)
+	if !ok {
+		t.Skip("Store cannot delete resources")
+	}
+	id := randomID(Feature, Label)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	if _, err := store.CreateResourceTable(id, schema); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := deleter.DeleteResource(id); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, err := store.GetResourceTable(id); err == nil {
+		t.Fatalf("Succeeded in getting deleted table")
+	}
+	if err := deleter.DeleteResource(id); err != nil {
+		t.Fatalf("Failed to delete missing table: %s", err)
+	}
+}
+
 func testMaterializations(t *testing.T, store OfflineStore) {
 	type TestCase struct {
 		WriteRecords             []ResourceRecord
@@ -0,0 +1,244 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FilterTableIterator applies a SegmentQuery to an iterator in memory. It's
+// used by stores that can't push the query down, so every row of the
+// underlying iterator is still read until the limit is reached.
+func FilterTableIterator(iter GenericTableIterator, query SegmentQuery) (GenericTableIterator, error) {
+	if err := query.check(); err != nil {
+		return nil, err
+	}
+	tableColumns := iter.Columns()
+	selected, err := query.selectColumns(tableColumns)
+	if err != nil {
+		return nil, err
+	}
+	filterIndices, err := SegmentQuery{Columns: filterColumns(query.Filters)}.selectColumns(tableColumns)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]string, len(selected))
+	for i, idx := range selected {
+		columns[i] = tableColumns[idx]
+	}
+	return &filteredTableIterator{
+		iter:          iter,
+		query:         query,
+		selected:      selected,
+		filterIndices: filterIndices,
+		columns:       columns,
+	}, nil
+}
+
+func filterColumns(filters []ColumnFilter) []string {
+	columns := make([]string, len(filters))
+	for i, filter := range filters {
+		columns[i] = filter.Column
+	}
+	return columns
+}
+
+type filteredTableIterator struct {
+	iter          GenericTableIterator
+	query         SegmentQuery
+	selected      []int
+	filterIndices []int
+	columns       []string
+	skipped       int64
+	served        int64
+	current       GenericRecord
+	err           error
+}
+
+func (it *filteredTableIterator) Next() bool {
+	if it.query.Limit != -1 && it.served >= it.query.Limit {
+		return false
+	}
+	for it.iter.Next() {
+		row := it.iter.Values()
+		matches, err := it.matches(row)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if !matches {
+			continue
+		}
+		if it.skipped < it.query.Offset {
+			it.skipped++
+			continue
+		}
+		values := make(GenericRecord, len(it.selected))
+		for i, idx := range it.selected {
+			if idx < len(row) {
+				values[i] = row[idx]
+			}
+		}
+		it.current = values
+		it.served++
+		return true
+	}
+	return false
+}
+
+func (it *filteredTableIterator) matches(row GenericRecord) (bool, error) {
+	for i, filter := range it.query.Filters {
+		idx := it.filterIndices[i]
+		if idx >= len(row) {
+			return false, nil
+		}
+		matches, err := filterMatches(filter, row[idx])
+		if err != nil {
+			return false, err
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (it *filteredTableIterator) Values() GenericRecord {
+	return it.current
+}
+
+func (it *filteredTableIterator) Columns() []string {
+	return it.columns
+}
+
+func (it *filteredTableIterator) Err() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.iter.Err()
+}
+
+func (it *filteredTableIterator) Close() error {
+	return it.iter.Close()
+}
+
+// filterMatches checks a single value against a filter. Like SQL, a null
+// value never matches.
+func filterMatches(filter ColumnFilter, value interface{}) (bool, error) {
+	if value == nil {
+		return false, nil
+	}
+	if filter.Operator == FilterIn {
+		for _, candidate := range filter.Values {
+			cmp, err := compareFilterValues(value, candidate)
+			if err != nil {
+				return false, fmt.Errorf("filter on %s: %w", filter.Column, err)
+			}
+			if cmp == 0 {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	cmp, err := compareFilterValues(value, filter.Values[0])
+	if err != nil {
+		return false, fmt.Errorf("filter on %s: %w", filter.Column, err)
+	}
+	switch filter.Operator {
+	case FilterEqual:
+		return cmp == 0, nil
+	case FilterGreaterThan:
+		return cmp > 0, nil
+	case FilterGreaterThanOrEqual:
+		return cmp >= 0, nil
+	case FilterLessThan:
+		return cmp < 0, nil
+	case FilterLessThanOrEqual:
+		return cmp <= 0, nil
+	default:
+		return false, fmt.Errorf("unknown operator %q", filter.Operator)
+	}
+}
+
+// compareFilterValues returns -1, 0 or 1 as a is less than, equal to or
+// greater than b. Numbers of any type compare by value, and a string is
+// parsed as RFC 3339 when compared to a timestamp.
+func compareFilterValues(a, b interface{}) (int, error) {
+	if b == nil {
+		return 0, fmt.Errorf("cannot compare %v to null", a)
+	}
+	if af, ok := filterNumber(a); ok {
+		bf, ok := filterNumber(b)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare number %v to %T", a, b)
+		}
+		if af < bf {
+			return -1, nil
+		} else if af > bf {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare string %q to %T", av, b)
+		}
+		return strings.Compare(av, bv), nil
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, fmt.Errorf("cannot compare bool to %T", b)
+		}
+		if av == bv {
+			return 0, nil
+		} else if !av {
+			return -1, nil
+		}
+		return 1, nil
+	case time.Time:
+		var bv time.Time
+		switch t := b.(type) {
+		case time.Time:
+			bv = t
+		case string:
+			parsed, err := time.Parse(time.RFC3339, t)
+			if err != nil {
+				return 0, fmt.Errorf("cannot parse %q as a timestamp: %w", t, err)
+			}
+			bv = parsed
+		default:
+			return 0, fmt.Errorf("cannot compare timestamp to %T", b)
+		}
+		if av.Before(bv) {
+			return -1, nil
+		} else if av.After(bv) {
+			return 1, nil
+		}
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("cannot filter on values of type %T", a)
+	}
+}
+
+func filterNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
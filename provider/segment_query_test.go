@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type sliceTableIterator struct {
+	columns []string
+	rows    []GenericRecord
+	idx     int
+}
+
+func (it *sliceTableIterator) Next() bool {
+	if it.idx >= len(it.rows) {
+		return false
+	}
+	it.idx++
+	return true
+}
+
+func (it *sliceTableIterator) Values() GenericRecord {
+	return it.rows[it.idx-1]
+}
+
+func (it *sliceTableIterator) Columns() []string {
+	return it.columns
+}
+
+func (it *sliceTableIterator) Err() error {
+	return nil
+}
+
+func (it *sliceTableIterator) Close() error {
+	return nil
+}
+
+func TestFilterTableIterator(t *testing.T) {
+	type FilterTest struct {
+		Query           SegmentQuery
+		ExpectedColumns []string
+		Expected        []GenericRecord
+		ExpectErr       bool
+	}
+
+	columns := []string{"entity", "int", "flt", "ts"}
+	rows := []GenericRecord{
+		{"a", 1, 1.1, time.UnixMilli(0).UTC()},
+		{"b", 2, 1.2, time.UnixMilli(1000).UTC()},
+		{"c", nil, 1.3, time.UnixMilli(2000).UTC()},
+		{"d", 4, 1.4, time.UnixMilli(3000).UTC()},
+		{"e", 5, 1.5, time.UnixMilli(4000).UTC()},
+	}
+
+	tests := map[string]FilterTest{
+		"NoQuery": {
+			Query:           SegmentQuery{Limit: -1},
+			ExpectedColumns: columns,
+			Expected:        rows,
+		},
+		"Columns": {
+			Query:           SegmentQuery{Columns: []string{"flt", "entity"}, Limit: 2},
+			ExpectedColumns: []string{"flt", "entity"},
+			Expected:        []GenericRecord{{1.1, "a"}, {1.2, "b"}},
+		},
+		"Equal": {
+			Query: SegmentQuery{
+				Columns: []string{"entity"},
+				Filters: []ColumnFilter{{Column: "entity", Operator: FilterEqual, Values: []interface{}{"d"}}},
+				Limit:   -1,
+			},
+			ExpectedColumns: []string{"entity"},
+			Expected:        []GenericRecord{{"d"}},
+		},
+		"RangeSkipsNulls": {
+			Query: SegmentQuery{
+				Columns: []string{"entity"},
+				Filters: []ColumnFilter{
+					{Column: "int", Operator: FilterGreaterThanOrEqual, Values: []interface{}{int32(2)}},
+					{Column: "int", Operator: FilterLessThan, Values: []interface{}{int64(5)}},
+				},
+				Limit: -1,
+			},
+			ExpectedColumns: []string{"entity"},
+			Expected:        []GenericRecord{{"b"}, {"d"}},
+		},
+		"InWithOffset": {
+			Query: SegmentQuery{
+				Columns: []string{"entity"},
+				Filters: []ColumnFilter{{Column: "entity", Operator: FilterIn, Values: []interface{}{"a", "c", "e"}}},
+				Offset:  1,
+				Limit:   1,
+			},
+			ExpectedColumns: []string{"entity"},
+			Expected:        []GenericRecord{{"c"}},
+		},
+		"Timestamp": {
+			Query: SegmentQuery{
+				Columns: []string{"entity"},
+				Filters: []ColumnFilter{{Column: "ts", Operator: FilterGreaterThan, Values: []interface{}{"1970-01-01T00:00:02Z"}}},
+				Limit:   -1,
+			},
+			ExpectedColumns: []string{"entity"},
+			Expected:        []GenericRecord{{"d"}, {"e"}},
+		},
+		"UnknownColumn": {
+			Query:     SegmentQuery{Columns: []string{"missing"}, Limit: -1},
+			ExpectErr: true,
+		},
+		"UnknownFilterColumn": {
+			Query: SegmentQuery{
+				Filters: []ColumnFilter{{Column: "missing", Operator: FilterEqual, Values: []interface{}{"a"}}},
+				Limit:   -1,
+			},
+			ExpectErr: true,
+		},
+		"MissingValue": {
+			Query: SegmentQuery{
+				Filters: []ColumnFilter{{Column: "entity", Operator: FilterEqual}},
+				Limit:   -1,
+			},
+			ExpectErr: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			iter, err := FilterTableIterator(&sliceTableIterator{columns: columns, rows: rows}, test.Query)
+			if test.ExpectErr {
+				if err == nil {
+					t.Fatalf("expected error for query %v", test.Query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("could not filter iterator: %v", err)
+			}
+			if !reflect.DeepEqual(iter.Columns(), test.ExpectedColumns) {
+				t.Fatalf("wrong columns: expected %v, got %v", test.ExpectedColumns, iter.Columns())
+			}
+			actual := make([]GenericRecord, 0)
+			for iter.Next() {
+				actual = append(actual, iter.Values())
+			}
+			if err := iter.Err(); err != nil {
+				t.Fatalf("iterator error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, test.Expected) {
+				t.Fatalf("wrong rows: expected %v, got %v", test.Expected, actual)
+			}
+		})
+	}
+}
@@ -1688,6 +1688,10 @@ func (spark *SparkOfflineStore) GetResourceTable(id ResourceID) (OfflineTable, e
 	return fileStoreGetResourceTable(id, spark.Store, spark.Logger)
 }
 
+func (spark *SparkOfflineStore) GetFeatureValuesAsOf(id ResourceID, entities []string, ts time.Time) ([]interface{}, error) {
+	return fileStoreGetFeatureValuesAsOf(id, entities, ts, spark.Store, spark.Logger)
+}
+
 func blobSparkMaterialization(id ResourceID, spark *SparkOfflineStore, isUpdate bool) (Materialization, error) {
 	if err := id.check(Feature); err != nil {
 		spark.Logger.Errorw("Attempted to create a materialization of a non feature resource", "type", id.Type)
@@ -1801,6 +1805,10 @@ func (spark *SparkOfflineStore) DeleteMaterialization(id MaterializationID) erro
 	return fileStoreDeleteMaterialization(id, spark.Store, spark.Logger)
 }
 
+func (spark *SparkOfflineStore) DeleteResource(id ResourceID) error {
+	return fileStoreDeleteResource(id, spark.Store)
+}
+
 func (spark *SparkOfflineStore) getResourceSchema(id ResourceID) (ResourceSchema, error) {
 	if err := id.check(Feature, Label); err != nil {
 		return ResourceSchema{}, fmt.Errorf("ID check failed: %v", err)
@@ -1929,6 +1937,10 @@ func (spark *SparkOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterat
 	return fileStoreGetTrainingSet(id, spark.Store, spark.Logger)
 }
 
+func (spark *SparkOfflineStore) ReadTrainingSet(id ResourceID, opts TrainingSetReadOptions) (TrainingSetIterator, error) {
+	return fileStoreReadTrainingSet(id, opts, spark.Store, spark.Logger)
+}
+
 func sanitizeSparkSQL(name string) string {
 	return name
 }
@@ -35,11 +35,14 @@ type OfflineTableQueries interface {
 	setVariableBinding(b variableBindingStyle)
 	tableExists() string
 	viewExists() string
+	tableType() string
 	resourceExists(tableName string) string
 	registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error
 	primaryTableRegister(tableName string, sourceName string) string
 	primaryTableCreate(name string, columnString string) string
 	getColumns(db *sql.DB, tableName string) ([]TableColumn, error)
+	primaryTableSegmentFilter(filters []ColumnFilter) (string, []interface{})
+	primaryTableSegmentLimit(limit int64, offset int64) string
 	getValueColumnTypes(tableName string) string
 	determineColumnType(valueType ValueType) (string, error)
 	materializationCreate(tableName string, sourceName string) string
@@ -48,6 +51,7 @@ type OfflineTableQueries interface {
 	materializationDrop(tableName string) string
 	getTable() string
 	dropTable(tableName string) string
+	dropView(viewName string) string
 	materializationIterateSegment(tableName string) string
 	newSQLOfflineTable(name string, columnType string) string
 	writeUpdate(table string) string
@@ -57,6 +61,9 @@ type OfflineTableQueries interface {
 	trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error
 	trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error
 	trainingRowSelect(columns string, trainingSetName string) string
+	trainingRowSelectShard(columns string, order string, trainingSetName string, opts TrainingSetReadOptions) string
+	trainingRowShuffleOrder(columns []string, seed int64) string
+	resourceValuesAsOf(tableName string, entityCount int) string
 	castTableItemType(v interface{}, t interface{}) interface{}
 	getValueColumnType(t *sql.ColumnType) interface{}
 	numRows(n interface{}) (int64, error)
@@ -577,6 +584,40 @@ func (store *sqlOfflineStore) DeleteMaterialization(id MaterializationID) error
 	return nil
 }
 
+// DeleteResource drops the table or view that holds a resource. Materializations
+// are named after the feature alone, so they're shared by all of its variants
+// and are left in place.
+func (store *sqlOfflineStore) DeleteResource(id ResourceID) error {
+	var tableName string
+	var err error
+	switch id.Type {
+	case Feature, Label:
+		tableName, err = store.getResourceTableName(id)
+	case TrainingSet:
+		tableName, err = store.getTrainingSetName(id)
+	case Primary, Transformation:
+		tableName, err = GetPrimaryTableName(id)
+	default:
+		return fmt.Errorf("cannot delete %s resources", id.Type)
+	}
+	if err != nil {
+		return err
+	}
+	var tableType string
+	err = store.db.QueryRow(store.query.tableType(), tableName).Scan(&tableType)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("table type check: %w", err)
+	}
+	query := store.query.dropTable(tableName)
+	if strings.EqualFold(tableType, "VIEW") {
+		query = store.query.dropView(tableName)
+	}
+	_, err = store.db.Exec(query)
+	return err
+}
+
 func (store *sqlOfflineStore) materializationExists(id MaterializationID) (bool, error) {
 	tableName := store.getMaterializationTableName(id)
 	getMatQry := store.query.materializationExists()
@@ -633,11 +674,38 @@ func (store *sqlOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
 	return nil
 }
 
+// GetTrainingSet serves the rows in whatever order the database returns
+// them, which ReadTrainingSet doesn't rely on.
 func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator, error) {
+	return store.readTrainingSet(id, TrainingSetReadOptions{}, false)
+}
+
+// globalShuffleProviders are the dialects whose trainingRowShuffleOrder has
+// been tested. The others don't hash rows with the same semantics, so their
+// training sets are shuffled through a buffer instead.
+var globalShuffleProviders = map[pt.Type]bool{
+	pt.PostgresOffline: true,
+	pt.MySqlOffline:    true,
+}
+
+func (store *sqlOfflineStore) SupportsGlobalShuffle() bool {
+	return globalShuffleProviders[store.ProviderType]
+}
+
+// ReadTrainingSet orders the rows even when every row is read, so that the
+// same options always serve the same sequence.
+func (store *sqlOfflineStore) ReadTrainingSet(id ResourceID, opts TrainingSetReadOptions) (TrainingSetIterator, error) {
+	return store.readTrainingSet(id, opts, true)
+}
+
+func (store *sqlOfflineStore) readTrainingSet(id ResourceID, opts TrainingSetReadOptions, ordered bool) (TrainingSetIterator, error) {
 	fmt.Printf("Getting Training Set: %v\n", id)
 	if err := id.check(TrainingSet); err != nil {
 		return nil, err
 	}
+	if err := opts.check(); err != nil {
+		return nil, err
+	}
 	fmt.Printf("Checking if Training Set exists: %v\n", id)
 	if exists, err := store.tableExists(id); err != nil {
 		return nil, err
@@ -657,7 +725,16 @@ func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator
 		features = append(features, sanitize(name.Name))
 	}
 	columns := strings.Join(features[:], ", ")
-	trainingSetQry := store.query.trainingRowSelect(columns, trainingSetName)
+	var trainingSetQry string
+	if !ordered {
+		trainingSetQry = store.query.trainingRowSelect(columns, trainingSetName)
+	} else {
+		order := columns
+		if opts.Shuffle.Enabled {
+			order = fmt.Sprintf("%s, %s", store.query.trainingRowShuffleOrder(features, opts.Shuffle.Seed), columns)
+		}
+		trainingSetQry = store.query.trainingRowSelectShard(columns, order, trainingSetName, opts)
+	}
 	fmt.Printf("Training Set Query: %s\n", trainingSetQry)
 	rows, err := store.db.Query(trainingSetQry)
 	if err != nil {
@@ -790,6 +867,54 @@ func (store *sqlOfflineStore) getsqlResourceTable(id ResourceID) (*sqlOfflineTab
 	}, nil
 }
 
+func (store *sqlOfflineStore) GetFeatureValuesAsOf(id ResourceID, entities []string, ts time.Time) ([]interface{}, error) {
+	if err := id.check(Feature); err != nil {
+		return nil, err
+	}
+	table, err := store.getsqlResourceTable(id)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(entities))
+	if len(entities) == 0 {
+		return values, nil
+	}
+	args := make([]interface{}, 0, len(entities)+1)
+	for _, entity := range entities {
+		args = append(args, entity)
+	}
+	args = append(args, ts.UTC())
+	rows, err := store.db.Query(store.query.resourceValuesAsOf(table.name, len(entities)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	valueType := store.query.getValueColumnType(colTypes[1])
+	found := make(map[string]interface{}, len(entities))
+	for rows.Next() {
+		var entity string
+		var value interface{}
+		if err := rows.Scan(&entity, &value); err != nil {
+			return nil, err
+		}
+		if value != nil {
+			value = store.query.castTableItemType(value, valueType)
+		}
+		found[entity] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, entity := range entities {
+		values[i] = found[entity]
+	}
+	return values, nil
+}
+
 type sqlOfflineTable struct {
 	db    *sql.DB
 	query OfflineTableQueries
@@ -864,6 +989,53 @@ func (pt *sqlPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error)
 	return newsqlGenericTableIterator(rows, colTypes, columnNames, pt.query), nil
 }
 
+func (pt *sqlPrimaryTable) IterateQuery(query SegmentQuery) (GenericTableIterator, error) {
+	if err := query.check(); err != nil {
+		return nil, err
+	}
+	columns, err := pt.query.getColumns(pt.db, pt.name)
+	if err != nil {
+		return nil, err
+	}
+	tableColumns := make([]string, len(columns))
+	for i, col := range columns {
+		tableColumns[i] = col.Name
+	}
+	selected, err := query.selectColumns(tableColumns)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := (SegmentQuery{Columns: filterColumns(query.Filters)}).selectColumns(tableColumns); err != nil {
+		return nil, err
+	}
+	allTypes, err := pt.getValueColumnTypes(pt.name)
+	if err != nil {
+		return nil, err
+	}
+	columnNames := make([]string, len(selected))
+	colTypes := make([]interface{}, len(selected))
+	for i, idx := range selected {
+		columnNames[i] = sanitize(tableColumns[idx])
+		// The types are empty when the table has no rows.
+		if idx < len(allTypes) {
+			colTypes[i] = allTypes[idx]
+		}
+	}
+	qry := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columnNames, ", "), sanitize(pt.name))
+	where, args := pt.query.primaryTableSegmentFilter(query.Filters)
+	if where != "" {
+		qry = fmt.Sprintf("%s WHERE %s", qry, where)
+	}
+	if limit := pt.query.primaryTableSegmentLimit(query.Limit, query.Offset); limit != "" {
+		qry = fmt.Sprintf("%s %s", qry, limit)
+	}
+	rows, err := pt.db.Query(qry, args...)
+	if err != nil {
+		return nil, err
+	}
+	return newsqlGenericTableIterator(rows, colTypes, columnNames, pt.query), nil
+}
+
 func (pt *sqlPrimaryTable) getValueColumnTypes(table string) ([]interface{}, error) {
 	query := pt.query.getValueColumnTypes(table)
 	rows, err := pt.db.Query(query)
@@ -1142,6 +1314,37 @@ func (q *defaultOfflineSQLQueries) setVariableBinding(b variableBindingStyle) {
 	q.BindingStyle = b
 }
 
+func (q defaultOfflineSQLQueries) primaryTableSegmentFilter(filters []ColumnFilter) (string, []interface{}) {
+	bind := q.newVariableBindingIterator()
+	conditions := make([]string, len(filters))
+	args := make([]interface{}, 0)
+	for i, filter := range filters {
+		if filter.Operator == FilterIn {
+			placeholders := make([]string, len(filter.Values))
+			for j, v := range filter.Values {
+				placeholders[j] = bind.Next()
+				args = append(args, v)
+			}
+			conditions[i] = fmt.Sprintf("%s IN (%s)", sanitize(filter.Column), strings.Join(placeholders, ", "))
+		} else {
+			conditions[i] = fmt.Sprintf("%s %s %s", sanitize(filter.Column), filter.Operator, bind.Next())
+			args = append(args, filter.Values[0])
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+func (q defaultOfflineSQLQueries) primaryTableSegmentLimit(limit int64, offset int64) string {
+	clauses := make([]string, 0)
+	if limit != -1 {
+		clauses = append(clauses, fmt.Sprintf("LIMIT %d", limit))
+	}
+	if offset > 0 {
+		clauses = append(clauses, fmt.Sprintf("OFFSET %d", offset))
+	}
+	return strings.Join(clauses, " ")
+}
+
 const genericExists = `SELECT COUNT(*) FROM information_schema.tables WHERE table_name = ?`
 
 func (q defaultOfflineSQLQueries) tableExists() string {
@@ -1152,6 +1355,11 @@ func (q defaultOfflineSQLQueries) viewExists() string {
 	return genericExists
 }
 
+func (q defaultOfflineSQLQueries) tableType() string {
+	bind := q.newVariableBindingIterator()
+	return fmt.Sprintf("SELECT table_type FROM information_schema.tables WHERE table_name = %s", bind.Next())
+}
+
 func (q defaultOfflineSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
 	var query string
 	if timestamp {
@@ -1237,10 +1445,38 @@ func (q defaultOfflineSQLQueries) dropTable(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
 
+func (q defaultOfflineSQLQueries) dropView(viewName string) string {
+	return fmt.Sprintf("DROP VIEW %s", sanitize(viewName))
+}
+
 func (q defaultOfflineSQLQueries) trainingRowSelect(columns string, trainingSetName string) string {
 	return fmt.Sprintf("SELECT %s FROM %s", columns, sanitize(trainingSetName))
 }
 
+// trainingRowSelectShard numbers the rows by the given order, which must
+// include every column so that the shard assignment is stable across reads.
+// The k-th row of shard i has row number k*count+i+1, so skipping the first
+// offset rows of the shard is a lower bound on the row number.
+func (q defaultOfflineSQLQueries) trainingRowSelectShard(columns string, order string, trainingSetName string, opts TrainingSetReadOptions) string {
+	count := opts.Shard.Count
+	if !opts.Shard.isSharded() {
+		count = 1
+	}
+	return fmt.Sprintf(""+
+		"SELECT %s FROM ( "+
+		"SELECT %s, ROW_NUMBER() OVER (ORDER BY %s) AS featureform_row_number FROM %s "+
+		") t1 "+
+		"WHERE MOD(featureform_row_number - 1, %d) = %d AND featureform_row_number > %d "+
+		"ORDER BY featureform_row_number",
+		columns, columns, order, sanitize(trainingSetName), count, opts.Shard.Index%count, opts.Offset*count)
+}
+
+// trainingRowShuffleOrder returns an expression that hashes a row with the
+// seed. Ties, such as rows whose hash is NULL, are broken by the columns.
+func (q defaultOfflineSQLQueries) trainingRowShuffleOrder(columns []string, seed int64) string {
+	return fmt.Sprintf("MD5(CONCAT('%d', %s))", seed, strings.Join(columns, ", "))
+}
+
 func (q defaultOfflineSQLQueries) getValueColumnTypes(tableName string) string {
 	return fmt.Sprintf("SELECT * FROM %s", sanitize(tableName))
 }
@@ -1380,6 +1616,25 @@ func (q defaultOfflineSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def
 	return q.trainingSetQuery(store, def, tableName, labelName, true)
 }
 
+// resourceValuesAsOf selects the last value at or before a timestamp for each
+// of entityCount entities. The entities are bound first, then the timestamp.
+func (q defaultOfflineSQLQueries) resourceValuesAsOf(tableName string, entityCount int) string {
+	return q.resourceValuesAsOfCast(tableName, "VARCHAR", entityCount)
+}
+
+// resourceValuesAsOfCast is resourceValuesAsOf with entities compared as
+// stringType, which differs between dialects.
+func (q defaultOfflineSQLQueries) resourceValuesAsOfCast(tableName string, stringType string, entityCount int) string {
+	bind := q.newVariableBindingIterator()
+	placeholders := make([]string, entityCount)
+	for i := range placeholders {
+		placeholders[i] = bind.Next()
+	}
+	return fmt.Sprintf("SELECT entity, value FROM (SELECT CAST(entity AS %s) AS entity, value, ROW_NUMBER() OVER (PARTITION BY entity ORDER BY ts DESC) AS featureform_rank "+
+		"FROM %s WHERE CAST(entity AS %s) IN (%s) AND ts <= %s) AS featureform_as_of WHERE featureform_rank = 1",
+		stringType, sanitize(tableName), stringType, strings.Join(placeholders, ", "), bind.Next())
+}
+
 func (q defaultOfflineSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	switch t {
 	case sfInt, sfNumber:
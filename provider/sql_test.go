@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"testing"
+
+	pt "github.com/featureform/provider/provider_type"
+)
+
+func TestSQLSupportsGlobalShuffle(t *testing.T) {
+	tests := map[pt.Type]bool{
+		pt.PostgresOffline:  true,
+		pt.MySqlOffline:     true,
+		pt.SnowflakeOffline: false,
+		pt.RedshiftOffline:  false,
+		pt.BigQueryOffline:  false,
+	}
+	for providerType, expected := range tests {
+		store := &sqlOfflineStore{BaseProvider: BaseProvider{ProviderType: providerType}}
+		if store.SupportsGlobalShuffle() != expected {
+			t.Fatalf("Expected %s global shuffle support to be %v", providerType, expected)
+		}
+	}
+}
+
+func TestResourceValuesAsOfQuery(t *testing.T) {
+	postgres := defaultOfflineSQLQueries{}
+	postgres.setVariableBinding(PostgresBindingStyle)
+	mysql := mySQLQueries{}
+	mysql.setVariableBinding(MySQLBindingStyle)
+	tests := map[string]struct {
+		queries  OfflineTableQueries
+		expected string
+	}{
+		"Postgres": {
+			&postgres,
+			"SELECT entity, value FROM (SELECT CAST(entity AS VARCHAR) AS entity, value, ROW_NUMBER() OVER (PARTITION BY entity ORDER BY ts DESC) AS featureform_rank " +
+				"FROM \"table\" WHERE CAST(entity AS VARCHAR) IN ($1, $2) AND ts <= $3) AS featureform_as_of WHERE featureform_rank = 1",
+		},
+		"MySQL": {
+			&mysql,
+			"SELECT entity, value FROM (SELECT CAST(entity AS CHAR) AS entity, value, ROW_NUMBER() OVER (PARTITION BY entity ORDER BY ts DESC) AS featureform_rank " +
+				"FROM \"table\" WHERE CAST(entity AS CHAR) IN (?, ?) AND ts <= ?) AS featureform_as_of WHERE featureform_rank = 1",
+		},
+	}
+	for name, test := range tests {
+		if qry := test.queries.resourceValuesAsOf("table", 2); qry != test.expected {
+			t.Fatalf("Wrong %s query: %s\nExpected: %s", name, qry, test.expected)
+		}
+	}
+}
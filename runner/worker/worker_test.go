@@ -363,7 +363,7 @@ func TestBasicUpdateRunner(t *testing.T) {
 	resourceName := uuid.New().String()
 	resourceVariant := ""
 	resourceType := metadata.FEATURE_VARIANT
-	resourceID := metadata.ResourceID{resourceName, resourceVariant, resourceType}
+	resourceID := metadata.ResourceID{Name: resourceName, Variant: resourceVariant, Type: resourceType}
 	if err := registerUpdateMockRunnerFactory(resourceID); err != nil {
 		t.Fatalf("Error registering mock runner factory: %v", err)
 	}
@@ -0,0 +1,92 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+)
+
+const defaultResumeTokenInterval = 1000
+
+// resumeToken records how far into a training set shard a stream got. It is
+// handed to clients as an opaque string.
+type resumeToken struct {
+	Name       string `json:"name"`
+	Variant    string `json:"variant"`
+	ShardIndex int64  `json:"shard_index"`
+	ShardCount int64  `json:"shard_count"`
+	Offset     int64  `json:"offset"`
+	// The shuffle has to be replayed exactly for the offset to line up, so
+	// it is pinned by the token.
+	Shuffle           bool  `json:"shuffle"`
+	ShuffleSeed       int64 `json:"shuffle_seed"`
+	ShuffleBufferSize int64 `json:"shuffle_buffer_size"`
+}
+
+func (token resumeToken) Serialize() (string, error) {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return "", fmt.Errorf("serialize resume token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func parseResumeToken(serialized string) (resumeToken, error) {
+	token := resumeToken{}
+	b, err := base64.RawURLEncoding.DecodeString(serialized)
+	if err != nil {
+		return token, fmt.Errorf("invalid resume token: %w", err)
+	}
+	if err := json.Unmarshal(b, &token); err != nil {
+		return token, fmt.Errorf("invalid resume token: %w", err)
+	}
+	return token, nil
+}
+
+func (token resumeToken) ReadOptions() provider.TrainingSetReadOptions {
+	return provider.TrainingSetReadOptions{
+		Shard: provider.TrainingSetShard{
+			Index: token.ShardIndex,
+			Count: token.ShardCount,
+		},
+		Offset: token.Offset,
+		Shuffle: provider.TrainingSetShuffle{
+			Enabled: token.Shuffle,
+			Seed:    token.ShuffleSeed,
+		},
+	}
+}
+
+// trainingDataPosition determines where a TrainingData stream starts from the
+// request's shard fields or, if set, its resume token.
+func trainingDataPosition(req *pb.TrainingDataRequest) (resumeToken, error) {
+	id := req.GetId()
+	name, variant := id.GetName(), id.GetVersion()
+	serialized := req.GetResumeToken()
+	if serialized == "" {
+		return resumeToken{
+			Name:              name,
+			Variant:           variant,
+			ShardIndex:        req.GetShardIndex(),
+			ShardCount:        req.GetShardCount(),
+			Shuffle:           req.GetShuffle(),
+			ShuffleSeed:       req.GetShuffleSeed(),
+			ShuffleBufferSize: req.GetShuffleBufferSize(),
+		}, nil
+	}
+	token, err := parseResumeToken(serialized)
+	if err != nil {
+		return token, err
+	}
+	if token.Name != name || token.Variant != variant {
+		return token, fmt.Errorf("resume token is for training set %s (%s), not %s (%s)", token.Name, token.Variant, name, variant)
+	}
+	return token, nil
+}
@@ -166,6 +166,29 @@ func wrapValue(value interface{}) (proto *pb.Value, err error) {
 	return
 }
 
+// unwrapValue is the inverse of wrapValue for the scalar types a client can
+// send, such as filter values.
+func unwrapValue(value *pb.Value) (interface{}, error) {
+	switch typed := value.GetValue().(type) {
+	case *pb.Value_StrValue:
+		return typed.StrValue, nil
+	case *pb.Value_IntValue:
+		return int(typed.IntValue), nil
+	case *pb.Value_Int32Value:
+		return typed.Int32Value, nil
+	case *pb.Value_Int64Value:
+		return typed.Int64Value, nil
+	case *pb.Value_FloatValue:
+		return typed.FloatValue, nil
+	case *pb.Value_DoubleValue:
+		return typed.DoubleValue, nil
+	case *pb.Value_BoolValue:
+		return typed.BoolValue, nil
+	default:
+		return nil, InvalidValue{typed}
+	}
+}
+
 func wrapFloat(val float32) *pb.Value {
 	return &pb.Value{
 		Value: &pb.Value_FloatValue{val},
@@ -7,8 +7,14 @@ package serving
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmd "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
@@ -37,29 +43,69 @@ func NewFeatureServer(meta *metadata.Client, promMetrics metrics.MetricsHandler,
 
 func (serv *FeatureServer) TrainingData(req *pb.TrainingDataRequest, stream pb.Feature_TrainingDataServer) error {
 	id := req.GetId()
-	name, variant := id.GetName(), id.GetVersion()
+	name, variant, namespace := id.GetName(), id.GetVersion(), id.GetNamespace()
 	featureObserver := serv.Metrics.BeginObservingTrainingServe(name, variant)
 	defer featureObserver.Finish()
 	logger := serv.Logger.With("Name", name, "Variant", variant)
 	logger.Info("Serving training data")
-	if model := req.GetModel(); model != nil {
-		trainingSets := []metadata.NameVariant{{Name: name, Variant: variant}}
-		err := serv.Metadata.CreateModel(stream.Context(), metadata.ModelDef{Name: model.GetName(), Trainingsets: trainingSets})
+	ts, err := serv.Metadata.GetTrainingSetVariant(stream.Context(), metadata.NameVariant{Name: name, Variant: variant, Namespace: namespace})
+	if err != nil {
+		logger.Errorw("Failed to get training set variant", "Error", err)
+		featureObserver.SetError()
+		return errors.Wrap(err, "could not get training set variant")
+	}
+	tsID := metadata.ResourceID{Name: name, Variant: variant, Type: metadata.TRAINING_SET_VARIANT, Namespace: namespace}
+	setTrailer := func(md grpcmd.MD) error {
+		stream.SetTrailer(md)
+		return nil
+	}
+	if err := serv.checkLifecycle(tsID, ts.Lifecycle(), featureObserver, setTrailer); err != nil {
+		featureObserver.SetError()
+		return err
+	}
+	if model := req.GetModel(); model != nil && model.GetVersion() != "" {
+		trainingSet := metadata.NameVariant{Name: name, Variant: ts.Variant(), Namespace: namespace}
+		def := metadata.ModelVariantDef{Name: model.GetName(), Variant: model.GetVersion(), TrainingSet: trainingSet, Namespace: namespace}
+		if err := serv.Metadata.CreateModelVariant(stream.Context(), def); err != nil {
+			return err
+		}
+	} else if model != nil {
+		trainingSets := []metadata.NameVariant{{Name: name, Variant: variant, Namespace: namespace}}
+		err := serv.Metadata.CreateModel(stream.Context(), metadata.ModelDef{Name: model.GetName(), Namespace: namespace, Trainingsets: trainingSets})
 		if err != nil {
 			return err
 		}
 	}
-	iter, err := serv.getTrainingSetIterator(name, variant)
+	position, err := trainingDataPosition(req)
+	if err != nil {
+		logger.Errorw("Failed to parse training data position", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	tokenInterval := req.GetResumeTokenInterval()
+	if tokenInterval <= 0 {
+		tokenInterval = defaultResumeTokenInterval
+	}
+	iter, err := serv.getTrainingSetIterator(ts, position.ReadOptions(), int(position.ShuffleBufferSize))
 	if err != nil {
 		logger.Errorw("Failed to get training set iterator", "Error", err)
 		featureObserver.SetError()
 		return err
 	}
+	sent := int64(0)
 	for iter.Next() {
 		sRow, err := serializedRow(iter.Features(), iter.Label())
 		if err != nil {
 			return err
 		}
+		sent++
+		if sent%tokenInterval == 0 {
+			token := position
+			token.Offset += sent
+			if sRow.ResumeToken, err = token.Serialize(); err != nil {
+				return err
+			}
+		}
 		if err := stream.Send(sRow); err != nil {
 			logger.Errorw("Failed to write to stream", "Error", err)
 			featureObserver.SetError()
@@ -79,7 +125,7 @@ func (serv *FeatureServer) TrainingDataColumns(ctx context.Context, req *pb.Trai
 	id := req.GetId()
 	name, variant := id.GetName(), id.GetVersion()
 	serv.Logger.Infow("Getting training set columns", "Name", name, "Variant", variant)
-	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant, Namespace: id.GetNamespace()})
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get training set variant")
 	}
@@ -99,10 +145,14 @@ func (serv *FeatureServer) TrainingDataColumns(ctx context.Context, req *pb.Trai
 func (serv *FeatureServer) SourceData(req *pb.SourceDataRequest, stream pb.Feature_SourceDataServer) error {
 	id := req.GetId()
 	name, variant := id.GetName(), id.GetVersion()
-	limit := req.GetLimit()
 	logger := serv.Logger.With("Name", name, "Variant", variant)
 	logger.Info("Serving source data")
-	iter, err := serv.getSourceDataIterator(name, variant, limit)
+	query, err := sourceDataQuery(req)
+	if err != nil {
+		logger.Errorw("Invalid source data query", "Error", err)
+		return err
+	}
+	iter, err := serv.getSourceDataIterator(id.GetNamespace(), name, variant, query)
 	if err != nil {
 		logger.Errorw("Failed to get source data iterator", "Error", err)
 		return err
@@ -124,13 +174,10 @@ func (serv *FeatureServer) SourceData(req *pb.SourceDataRequest, stream pb.Featu
 	return nil
 }
 
-func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provider.TrainingSetIterator, error) {
+func (serv *FeatureServer) getTrainingSetIterator(ts *metadata.TrainingSetVariant, opts provider.TrainingSetReadOptions, shuffleBufferSize int) (provider.TrainingSetIterator, error) {
 	ctx := context.TODO()
+	namespace, name, variant := ts.Namespace(), ts.Name(), ts.Variant()
 	serv.Logger.Infow("Getting Training Set Iterator", "name", name, "variant", variant)
-	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{name, variant})
-	if err != nil {
-		return nil, errors.Wrap(err, "could not get training set variant")
-	}
 	serv.Logger.Debugw("Fetching Training Set Provider", "name", name, "variant", variant)
 	providerEntry, err := ts.FetchProvider(serv.Metadata, ctx)
 	if err != nil {
@@ -146,14 +193,97 @@ func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provide
 		// That shouldn't be possible.
 		return nil, errors.Wrap(err, "could not open as offline store")
 	}
-	serv.Logger.Debugw("Get Training Set From Store", "name", name, "variant", variant)
-	return store.GetTrainingSet(provider.ResourceID{Name: name, Variant: variant})
+	serv.Logger.Debugw("Get Training Set From Store", "name", name, "variant", variant, "options", opts)
+	id := provider.ResourceID{Name: metadata.QualifiedName(namespace, name), Variant: variant}
+	if opts.Shuffle.Enabled {
+		if shuffler, ok := store.(provider.GlobalShuffleReader); ok && shuffler.SupportsGlobalShuffle() {
+			return shuffler.ReadTrainingSet(id, opts)
+		}
+		serv.Logger.Debugw("Buffer shuffling training set", "name", name, "variant", variant, "buffer", shuffleBufferSize)
+		return bufferShuffledTrainingSet(store, id, opts, shuffleBufferSize)
+	}
+	if opts == (provider.TrainingSetReadOptions{}) {
+		return store.GetTrainingSet(id)
+	}
+	if reader, ok := store.(provider.TrainingSetReader); ok {
+		return reader.ReadTrainingSet(id, opts)
+	}
+	iter, err := store.GetTrainingSet(id)
+	if err != nil {
+		return nil, err
+	}
+	// The store can't seek, so we fall back to reading from the start and
+	// discarding rows outside of the requested position.
+	return provider.ShardTrainingSetIterator(iter, opts)
+}
+
+// bufferShuffledTrainingSet shuffles the whole training set through a buffer
+// and then shards the shuffled rows, for stores that can't shuffle. Every
+// worker has to shuffle the same rows in the same order for their shards to
+// partition the training set, and for resume tokens to replay it, so the
+// rows are read in the reader's deterministic order. Stores without one can
+// only be shuffled into a single stream that isn't resumed.
+func bufferShuffledTrainingSet(store provider.OfflineStore, id provider.ResourceID, opts provider.TrainingSetReadOptions, bufferSize int) (provider.TrainingSetIterator, error) {
+	seed := opts.Shuffle.Seed
+	opts.Shuffle = provider.TrainingSetShuffle{}
+	var iter provider.TrainingSetIterator
+	var err error
+	if reader, ok := store.(provider.TrainingSetReader); ok {
+		iter, err = reader.ReadTrainingSet(id, provider.TrainingSetReadOptions{})
+	} else if opts.Shard.Count > 1 || opts.Offset > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "training set %s can't be shuffled with a shard or resume token, its store doesn't read rows in a stable order", id.Name)
+	} else {
+		iter, err = store.GetTrainingSet(id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	shuffled := newBufferShuffleIterator(iter, seed, bufferSize)
+	return provider.ShardTrainingSetIterator(shuffled, opts)
+}
+
+var sourceFilterOperators = map[pb.SourceFilter_Operator]provider.FilterOperator{
+	pb.SourceFilter_EQUAL:                 provider.FilterEqual,
+	pb.SourceFilter_GREATER_THAN:          provider.FilterGreaterThan,
+	pb.SourceFilter_GREATER_THAN_OR_EQUAL: provider.FilterGreaterThanOrEqual,
+	pb.SourceFilter_LESS_THAN:             provider.FilterLessThan,
+	pb.SourceFilter_LESS_THAN_OR_EQUAL:    provider.FilterLessThanOrEqual,
+	pb.SourceFilter_IN:                    provider.FilterIn,
 }
 
-func (serv *FeatureServer) getSourceDataIterator(name, variant string, limit int64) (provider.GenericTableIterator, error) {
+func sourceDataQuery(req *pb.SourceDataRequest) (provider.SegmentQuery, error) {
+	filters := make([]provider.ColumnFilter, len(req.GetFilters()))
+	for i, filter := range req.GetFilters() {
+		op, has := sourceFilterOperators[filter.GetOperator()]
+		if !has {
+			return provider.SegmentQuery{}, fmt.Errorf("unknown filter operator: %v", filter.GetOperator())
+		}
+		values := make([]interface{}, len(filter.GetValues()))
+		for j, v := range filter.GetValues() {
+			unwrapped, err := unwrapValue(v)
+			if err != nil {
+				return provider.SegmentQuery{}, fmt.Errorf("invalid value for filter on %s: %w", filter.GetColumn(), err)
+			}
+			values[j] = unwrapped
+		}
+		filters[i] = provider.ColumnFilter{
+			Column:   filter.GetColumn(),
+			Operator: op,
+			Values:   values,
+		}
+	}
+	return provider.SegmentQuery{
+		Columns: req.GetColumns(),
+		Filters: filters,
+		Offset:  req.GetOffset(),
+		Limit:   req.GetLimit(),
+	}, nil
+}
+
+func (serv *FeatureServer) getSourceDataIterator(namespace, name, variant string, query provider.SegmentQuery) (provider.GenericTableIterator, error) {
 	ctx := context.TODO()
 	serv.Logger.Infow("Getting Source Variant Iterator", "name", name, "variant", variant)
-	sv, err := serv.Metadata.GetSourceVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	sv, err := serv.Metadata.GetSourceVariant(ctx, metadata.NameVariant{Name: name, Variant: variant, Namespace: namespace})
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get source variant")
 	}
@@ -178,7 +308,7 @@ func (serv *FeatureServer) getSourceDataIterator(name, variant string, limit int
 	var providerErr error
 	if sv.IsTransformation() {
 		serv.Logger.Debugw("Getting transformation table", "name", name, "variant", variant)
-		t, err := store.GetTransformationTable(provider.ResourceID{Name: name, Variant: variant, Type: provider.Transformation})
+		t, err := store.GetTransformationTable(provider.ResourceID{Name: metadata.QualifiedName(namespace, name), Variant: variant, Type: provider.Transformation})
 		if err != nil {
 			serv.Logger.Errorw("Could not get transformation table", "name", name, "variant", variant, "Error", err)
 			providerErr = err
@@ -195,40 +325,173 @@ func (serv *FeatureServer) getSourceDataIterator(name, variant string, limit int
 		}
 	} else {
 		serv.Logger.Debugw("Getting primary table", "name", name, "variant", variant)
-		primary, providerErr = store.GetPrimaryTable(provider.ResourceID{Name: name, Variant: variant, Type: provider.Primary})
+		primary, providerErr = store.GetPrimaryTable(provider.ResourceID{Name: metadata.QualifiedName(namespace, name), Variant: variant, Type: provider.Primary})
 	}
 	if providerErr != nil {
 		serv.Logger.Errorw("Could not get primary table", "name", name, "variant", variant, "Error", providerErr)
 		return nil, errors.Wrap(err, "could not get primary table")
 	}
-	serv.Logger.Debugw("Getting source data iterator", "name", name, "variant", variant)
-	return primary.IterateSegment(limit)
+	serv.Logger.Debugw("Getting source data iterator", "name", name, "variant", variant, "query", query)
+	if len(query.Columns) == 0 && len(query.Filters) == 0 && query.Offset == 0 {
+		return primary.IterateSegment(query.Limit)
+	}
+	if queryable, ok := primary.(provider.QueryablePrimaryTable); ok {
+		return queryable.IterateQuery(query)
+	}
+	// The table can't push the query down, so we filter every row in memory.
+	iter, err := primary.IterateSegment(-1)
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := provider.FilterTableIterator(iter, query)
+	if err != nil {
+		iter.Close()
+		return nil, err
+	}
+	return filtered, nil
+}
+
+func unaryTrailer(ctx context.Context) func(grpcmd.MD) error {
+	return func(md grpcmd.MD) error {
+		return grpc.SetTrailer(ctx, md)
+	}
+}
+
+// checkLifecycle refuses retired variants, and logs, counts and warns the
+// client about deprecated ones with a trailer. obs may be nil.
+func (serv *FeatureServer) checkLifecycle(id metadata.ResourceID, lifecycle metadata.Lifecycle, obs metrics.FeatureObserver, setTrailer func(grpcmd.MD) error) error {
+	switch lifecycle.StateAt(time.Now()) {
+	case metadata.RETIRED:
+		serv.Logger.Errorw("Refusing to serve retired variant", "id", id.String())
+		return status.Errorf(codes.FailedPrecondition, "%s is retired", id)
+	case metadata.DEPRECATED:
+		warning := lifecycle.Warning(id)
+		serv.Logger.Warnw("Serving deprecated variant", "id", id.String(), "warning", warning)
+		if obs != nil {
+			obs.ServeDeprecated()
+		}
+		if err := setTrailer(grpcmd.Pairs(metadata.DeprecationWarningKey, warning)); err != nil {
+			// Only fails when called outside of an RPC, where there's no one to warn.
+			serv.Logger.Debugw("Could not set deprecation trailer", "id", id.String(), "error", err)
+		}
+	}
+	return nil
+}
+
+// modelNamespace is the namespace of a model served features, which is theirs
+// since a model can only use features from its own namespace.
+func modelNamespace(features []*pb.FeatureID) string {
+	if len(features) == 0 {
+		return metadata.DefaultNamespace
+	}
+	return features[0].GetNamespace()
+}
+
+// withFeatureGroups returns features followed by the features of each group,
+// and the group variants that were used.
+func (serv *FeatureServer) withFeatureGroups(ctx context.Context, features, groups []*pb.FeatureID) ([]*pb.FeatureID, metadata.NameVariants, error) {
+	served := append([]*pb.FeatureID{}, features...)
+	used := make(metadata.NameVariants, 0, len(groups))
+	for _, group := range groups {
+		id := metadata.NameVariant{Name: group.GetName(), Variant: group.GetVersion(), Namespace: group.GetNamespace()}
+		if id.Variant == "" {
+			fetched, err := serv.Metadata.InNamespace(id.Namespace).GetFeatureGroup(ctx, id.Name)
+			if err != nil {
+				return nil, nil, err
+			}
+			id.Variant = fetched.DefaultVariant()
+		}
+		variant, err := serv.Metadata.GetFeatureGroupVariant(ctx, id)
+		if err != nil {
+			serv.Logger.Errorw("Feature group lookup failed", "Name", id.Name, "Variant", id.Variant, "Err", err)
+			return nil, nil, err
+		}
+		for _, feature := range variant.Features() {
+			served = append(served, &pb.FeatureID{Name: feature.Name, Version: feature.Variant, Namespace: id.Namespace})
+		}
+		used = append(used, id)
+	}
+	return served, used, nil
+}
+
+// modelVariantFeatures returns the features to serve for a model variant.
+// Those are the ones it was trained with, which requested features must
+// match if there are any.
+func (serv *FeatureServer) modelVariantFeatures(ctx context.Context, model *pb.Model, requested []*pb.FeatureID) ([]*pb.FeatureID, error) {
+	id := metadata.NameVariant{Name: model.GetName(), Variant: model.GetVersion(), Namespace: model.GetNamespace()}
+	variant, err := serv.Metadata.GetModelVariant(ctx, id)
+	if err != nil {
+		serv.Logger.Errorw("Model variant lookup failed", "Name", id.Name, "Variant", id.Variant, "Err", err)
+		return nil, err
+	}
+	trained := make(map[string]*pb.FeatureID)
+	features := make([]*pb.FeatureID, len(variant.Features()))
+	for i, feature := range variant.Features() {
+		features[i] = &pb.FeatureID{Name: feature.Name, Version: feature.Variant, Namespace: variant.Namespace()}
+		trained[feature.ClientString()] = features[i]
+	}
+	if len(requested) == 0 {
+		return features, nil
+	}
+	// Requests can list the features in any order, which is the order
+	// they're served in.
+	served := make([]*pb.FeatureID, 0, len(requested))
+	extra := make([]string, 0)
+	for _, feature := range requested {
+		key := metadata.NameVariant{Name: feature.GetName(), Variant: feature.GetVersion()}.ClientString()
+		if feature.GetNamespace() != variant.Namespace() {
+			return nil, status.Errorf(codes.InvalidArgument, "feature %s is in namespace %q, but model %s is in namespace %q", key, feature.GetNamespace(), id.ClientString(), variant.Namespace())
+		}
+		if trained[key] == nil {
+			extra = append(extra, key)
+			continue
+		}
+		served = append(served, trained[key])
+		delete(trained, key)
+	}
+	if len(trained) > 0 || len(extra) > 0 {
+		missing := make([]string, 0, len(trained))
+		for key := range trained {
+			missing = append(missing, key)
+		}
+		sort.Strings(missing)
+		return nil, status.Errorf(codes.InvalidArgument, "features don't match what model %s was trained with: missing %v, not trained with %v", id.ClientString(), missing, extra)
+	}
+	return served, nil
 }
 
 // TODO: test serving embedding features
 func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServeRequest) (*pb.FeatureRow, error) {
-	features := req.GetFeatures()
+	features, groups, err := serv.withFeatureGroups(ctx, req.GetFeatures(), req.GetFeatureGroups())
+	if err != nil {
+		return nil, err
+	}
 	entities := req.GetEntities()
 	entityMap := make(map[string]string)
 	for _, entity := range entities {
 		entityMap[entity.GetName()] = entity.GetValue()
 	}
-	if model := req.GetModel(); model != nil {
-		modelFeatures := make([]metadata.NameVariant, len(features))
+	if model := req.GetModel(); model != nil && model.GetVersion() != "" {
+		features, err = serv.modelVariantFeatures(ctx, model, features)
+		if err != nil {
+			return nil, err
+		}
+	} else if model != nil {
+		modelFeatures := make([]metadata.NameVariant, len(req.GetFeatures()))
 		for i, feature := range req.GetFeatures() {
-			modelFeatures[i] = metadata.NameVariant{Name: feature.Name, Variant: feature.Version}
+			modelFeatures[i] = metadata.NameVariant{Name: feature.Name, Variant: feature.Version, Namespace: feature.Namespace}
 		}
 		serv.Logger.Infow("Creating model", "Name", model.GetName())
-		err := serv.Metadata.CreateModel(ctx, metadata.ModelDef{Name: model.GetName(), Features: modelFeatures})
+		err := serv.Metadata.CreateModel(ctx, metadata.ModelDef{Name: model.GetName(), Namespace: modelNamespace(features), Features: modelFeatures, FeatureGroups: groups})
 		if err != nil {
 			return nil, err
 		}
 	}
 	vals := make([]*pb.Value, len(features))
-	for i, feature := range req.GetFeatures() {
+	for i, feature := range features {
 		name, variant := feature.GetName(), feature.GetVersion()
 		serv.Logger.Infow("Serving feature", "Name", name, "Variant", variant)
-		val, err := serv.getFeatureValue(ctx, name, variant, entityMap)
+		val, err := serv.getFeatureValue(ctx, feature.GetNamespace(), name, variant, entityMap)
 		if err != nil {
 			return nil, errors.Wrap(err, "could not get feature value")
 		}
@@ -239,17 +502,22 @@ func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServ
 	}, nil
 }
 
-func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant string, entityMap map[string]string) (*pb.Value, error) {
+func (serv *FeatureServer) getFeatureValue(ctx context.Context, namespace, name, variant string, entityMap map[string]string) (*pb.Value, error) {
 	obs := serv.Metrics.BeginObservingOnlineServe(name, variant)
 	defer obs.Finish()
 	logger := serv.Logger.With("Name", name, "Variant", variant)
 	logger.Debug("Getting metadata")
-	meta, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{name, variant})
+	meta, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant, Namespace: namespace})
 	if err != nil {
 		logger.Errorw("metadata lookup failed", "Err", err)
 		obs.SetError()
 		return nil, err
 	}
+	featureID := metadata.ResourceID{Name: name, Variant: variant, Type: metadata.FEATURE_VARIANT, Namespace: namespace}
+	if err := serv.checkLifecycle(featureID, meta.Lifecycle(), obs, unaryTrailer(ctx)); err != nil {
+		obs.SetError()
+		return nil, err
+	}
 
 	var val interface{}
 	switch meta.Mode() {
@@ -280,7 +548,7 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 			// That shouldn't be possible.
 			return nil, err
 		}
-		table, err := store.GetTable(name, variant)
+		table, err := store.GetTable(metadata.QualifiedName(namespace, name), variant)
 		if err != nil {
 			logger.Errorw("feature not found", "Error", err)
 			obs.SetError()
@@ -307,11 +575,104 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 	return f.Serialized(), nil
 }
 
+func (serv *FeatureServer) FeatureServeAsOf(ctx context.Context, req *pb.FeatureServeAsOfRequest) (*pb.FeatureRow, error) {
+	if req.GetAsOf() == nil {
+		return nil, fmt.Errorf("as_of timestamp is required")
+	}
+	asOf := req.GetAsOf().AsTime()
+	features, _, err := serv.withFeatureGroups(ctx, req.GetFeatures(), req.GetFeatureGroups())
+	if err != nil {
+		return nil, err
+	}
+	entityMap := make(map[string]string)
+	for _, entity := range req.GetEntities() {
+		entityMap[entity.GetName()] = entity.GetValue()
+	}
+	vals := make([]*pb.Value, len(features))
+	for i, feature := range features {
+		name, variant := feature.GetName(), feature.GetVersion()
+		serv.Logger.Infow("Serving feature as of", "Name", name, "Variant", variant, "AsOf", asOf)
+		val, err := serv.getFeatureValueAsOf(ctx, feature.GetNamespace(), name, variant, entityMap, asOf)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not get feature value")
+		}
+		vals[i] = val
+	}
+	return &pb.FeatureRow{
+		Values: vals,
+	}, nil
+}
+
+// getFeatureValueAsOf answers from the feature's resource table in its
+// source's offline store, since the online store only has the latest values.
+func (serv *FeatureServer) getFeatureValueAsOf(ctx context.Context, namespace, name, variant string, entityMap map[string]string, asOf time.Time) (*pb.Value, error) {
+	logger := serv.Logger.With("Name", name, "Variant", variant)
+	meta, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant, Namespace: namespace})
+	if err != nil {
+		logger.Errorw("metadata lookup failed", "Err", err)
+		return nil, err
+	}
+	featureID := metadata.ResourceID{Name: name, Variant: variant, Type: metadata.FEATURE_VARIANT, Namespace: namespace}
+	if err := serv.checkLifecycle(featureID, meta.Lifecycle(), nil, unaryTrailer(ctx)); err != nil {
+		return nil, err
+	}
+	var val interface{}
+	switch meta.Mode() {
+	case metadata.PRECOMPUTED:
+		entity, has := entityMap[meta.Entity()]
+		if !has {
+			logger.Errorw("Entity not found", "Entity", meta.Entity())
+			return nil, fmt.Errorf("No value for entity %s", meta.Entity())
+		}
+		source, err := meta.FetchSource(serv.Metadata, ctx)
+		if err != nil {
+			logger.Errorw("fetching source metadata failed", "Error", err)
+			return nil, err
+		}
+		providerEntry, err := source.FetchProvider(serv.Metadata, ctx)
+		if err != nil {
+			logger.Errorw("fetching provider metadata failed", "Error", err)
+			return nil, err
+		}
+		p, err := provider.Get(pt.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+		if err != nil {
+			logger.Errorw("failed to get provider", "Error", err)
+			return nil, err
+		}
+		store, err := p.AsOfflineStore()
+		if err != nil {
+			logger.Errorw("failed to use provider as offline store for feature", "Error", err)
+			return nil, err
+		}
+		lookup, ok := store.(provider.PointInTimeLookup)
+		if !ok {
+			return nil, fmt.Errorf("provider %s does not support point-in-time lookups", providerEntry.Name())
+		}
+		id := provider.ResourceID{Name: metadata.QualifiedName(namespace, name), Variant: variant, Type: provider.Feature}
+		values, err := lookup.GetFeatureValuesAsOf(id, []string{entity}, asOf)
+		if err != nil {
+			logger.Errorw("point-in-time lookup failed", "Error", err)
+			return nil, err
+		}
+		val = values[0]
+	case metadata.CLIENT_COMPUTED:
+		val = meta.LocationFunction()
+	default:
+		return nil, fmt.Errorf("unknown computation mode %v", meta.Mode())
+	}
+	f, err := newValue(val)
+	if err != nil {
+		logger.Errorw("invalid feature type", "Error", err)
+		return nil, err
+	}
+	return f.Serialized(), nil
+}
+
 func (serv *FeatureServer) SourceColumns(ctx context.Context, req *pb.SourceColumnRequest) (*pb.SourceDataColumns, error) {
 	id := req.GetId()
 	name, variant := id.GetName(), id.GetVersion()
 	serv.Logger.Infow("Getting source columns", "Name", name, "Variant", variant)
-	it, err := serv.getSourceDataIterator(name, variant, 0) // Set limit to zero to fetch columns only
+	it, err := serv.getSourceDataIterator(id.GetNamespace(), name, variant, provider.SegmentQuery{Limit: 0}) // Set limit to zero to fetch columns only
 	if err != nil {
 		return nil, err
 	}
@@ -329,7 +690,7 @@ func (serv *FeatureServer) Nearest(ctx context.Context, req *pb.NearestRequest)
 	id := req.GetId()
 	name, variant := id.GetName(), id.GetVersion()
 	serv.Logger.Infow("Searching nearest", "Name", name, "Variant", variant)
-	fv, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	fv, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant, Namespace: id.GetNamespace()})
 	if err != nil {
 		serv.Logger.Errorw("metadata lookup failed", "Err", err)
 		return nil, err
@@ -344,7 +705,7 @@ func (serv *FeatureServer) Nearest(ctx context.Context, req *pb.NearestRequest)
 	if searchVector == nil {
 		return nil, fmt.Errorf("no embedding provided")
 	}
-	entities, err := vectorTable.Nearest(name, variant, searchVector.Value, k)
+	entities, err := vectorTable.Nearest(metadata.QualifiedName(id.GetNamespace(), name), variant, searchVector.Value, k)
 	if err != nil {
 		serv.Logger.Errorw("nearest search failed", "Error", err)
 		return nil, err
@@ -370,7 +731,7 @@ func (serv *FeatureServer) getVectorTable(ctx context.Context, fv *metadata.Feat
 		serv.Logger.Errorw("failed to use provider as online store for feature", "Error", err)
 		return nil, err
 	}
-	table, err := store.GetTable(fv.Name(), fv.Variant())
+	table, err := store.GetTable(metadata.QualifiedName(fv.Namespace(), fv.Name()), fv.Variant())
 	if err != nil {
 		serv.Logger.Errorw("feature not found", "Error", err)
 		return nil, err
@@ -12,11 +12,15 @@ import (
 	"net"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
 	grpcmeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
@@ -203,7 +207,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "double",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -218,7 +222,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "float",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -233,7 +237,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "str",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -248,7 +252,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "int",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -263,7 +267,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "smallint",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -278,7 +282,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "bigint",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -293,7 +297,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "bool",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -308,7 +312,7 @@ func allTypesResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "proto",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -349,7 +353,7 @@ func simpleResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "variant",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -364,7 +368,7 @@ func simpleResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Variant:  "variant",
 			Provider: "mockOnline",
 			Entity:   "mockEntity",
-			Source:   metadata.NameVariant{"mockSource", "var"},
+			Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
 			Owner:    "Featureform",
 			Location: metadata.ResourceVariantColumns{
 				Entity: "col1",
@@ -376,8 +380,8 @@ func simpleResourceDefsFn(providerType string) []metadata.ResourceDef {
 			Name:     "training-set",
 			Variant:  "variant",
 			Provider: "mockOnline",
-			Label:    metadata.NameVariant{"label", "variant"},
-			Features: metadata.NameVariants{{"feature", "variant"}},
+			Label:    metadata.NameVariant{Name: "label", Variant: "variant"},
+			Features: metadata.NameVariants{{Name: "feature", Variant: "variant"}},
 			Owner:    "Featureform",
 		},
 	}
@@ -610,6 +614,59 @@ func TestFeatureServe(t *testing.T) {
 	}
 }
 
+func TestFeatureServeAsOf(t *testing.T) {
+	featureId := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	labelId := provider.ResourceID{Name: "label", Variant: "variant", Type: provider.Label}
+	recs := map[provider.ResourceID][]provider.ResourceRecord{
+		featureId: {
+			{Entity: "a", Value: 1, TS: time.UnixMilli(1000).UTC()},
+			{Entity: "a", Value: 2, TS: time.UnixMilli(2000).UTC()},
+			{Entity: "b", Value: 3, TS: time.UnixMilli(1000).UTC()},
+		},
+		labelId: {
+			{Entity: "a", Value: true},
+		},
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(recs, nil),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	type AsOfTest struct {
+		AsOf     time.Time
+		Expected interface{}
+	}
+	tests := map[string]AsOfTest{
+		"Before":  {time.UnixMilli(500), ""},
+		"Between": {time.UnixMilli(1500), 1},
+		"Exact":   {time.UnixMilli(2000), 2},
+		"After":   {time.UnixMilli(5000), 2},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := &pb.FeatureServeAsOfRequest{
+				Features: []*pb.FeatureID{{Name: "feature", Version: "variant"}},
+				Entities: []*pb.Entity{{Name: "mockEntity", Value: "a"}},
+				AsOf:     timestamppb.New(test.AsOf),
+			}
+			resp, err := serv.FeatureServeAsOf(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Failed to serve feature as of %v: %s", test.AsOf, err)
+			}
+			if val := unwrapVal(resp.Values[0]); val != test.Expected {
+				t.Fatalf("Wrong feature value: %v\nExpected: %v", val, test.Expected)
+			}
+		})
+	}
+	if _, err := serv.FeatureServeAsOf(context.Background(), &pb.FeatureServeAsOfRequest{
+		Features: []*pb.FeatureID{{Name: "feature", Version: "variant"}},
+		Entities: []*pb.Entity{{Name: "mockEntity", Value: "a"}},
+	}); err == nil {
+		t.Fatalf("Succeeded in serving feature without a timestamp")
+	}
+}
+
 func TestFeatureNotFound(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -910,6 +967,129 @@ func TestSimpleModelRegistrationFeatureServe(t *testing.T) {
 	}
 }
 
+func TestFeatureGroupServe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: func(providerType string) []metadata.ResourceDef {
+			return append(simpleResourceDefsFn(providerType), metadata.FeatureGroupDef{
+				Name:     "group",
+				Variant:  "v1",
+				Owner:    "Featureform",
+				Entity:   "mockEntity",
+				Features: metadata.NameVariants{{Name: "feature", Variant: "variant"}},
+			})
+		},
+		FactoryFn: createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{
+				Name:    "feature",
+				Version: "variant",
+			},
+		},
+		// Without a version, the group's default variant is served.
+		FeatureGroups: []*pb.FeatureID{
+			{
+				Name: "group",
+			},
+		},
+		Entities: []*pb.Entity{
+			{
+				Name:  "mockEntity",
+				Value: "a",
+			},
+		},
+		Model: &pb.Model{
+			Name: "grouped-model",
+		},
+	}
+	resp, err := serv.FeatureServe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to serve feature group: %s", err)
+	}
+	if len(resp.Values) != 2 {
+		t.Fatalf("Wrong number of values: %d\nExpected: %d", len(resp.Values), 2)
+	}
+	for _, val := range resp.Values {
+		if dblVal := unwrapVal(val); dblVal != 12.5 {
+			t.Fatalf("Wrong feature value: %v\nExpected: %v", dblVal, 12.5)
+		}
+	}
+	model, err := serv.Metadata.GetModel(context.Background(), "grouped-model")
+	if err != nil {
+		t.Fatalf("Failed to get model: %s", err)
+	}
+	expected := metadata.NameVariants{{Name: "group", Variant: "v1"}}
+	if !reflect.DeepEqual(model.FeatureGroups(), expected) {
+		t.Fatalf("Wrong feature groups associated with registered model: %v\nExpected %v", model.FeatureGroups(), expected)
+	}
+}
+
+func TestModelVariantServe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: func(providerType string) []metadata.ResourceDef {
+			return append(simpleResourceDefsFn(providerType), metadata.ModelVariantDef{
+				Name:        "model",
+				Variant:     "v1",
+				TrainingSet: metadata.NameVariant{Name: "training-set", Variant: "variant"},
+			})
+		},
+		FactoryFn: createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	entities := []*pb.Entity{
+		{
+			Name:  "mockEntity",
+			Value: "a",
+		},
+	}
+	model := &pb.Model{
+		Name:    "model",
+		Version: "v1",
+	}
+	// Without features, the ones the model was trained with are served.
+	resp, err := serv.FeatureServe(context.Background(), &pb.FeatureServeRequest{Entities: entities, Model: model})
+	if err != nil {
+		t.Fatalf("Failed to serve model variant: %s", err)
+	}
+	if len(resp.Values) != 1 {
+		t.Fatalf("Wrong number of values: %d\nExpected: %d", len(resp.Values), 1)
+	}
+	if dblVal := unwrapVal(resp.Values[0]); dblVal != 12.5 {
+		t.Fatalf("Wrong feature value: %v\nExpected: %v", dblVal, 12.5)
+	}
+	mismatched := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{
+				Name:    "feature",
+				Version: "other",
+			},
+		},
+		Entities: entities,
+		Model:    model,
+	}
+	if _, err := serv.FeatureServe(context.Background(), mismatched); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected serving features the model wasn't trained with to fail: %v", err)
+	}
+	otherNamespace := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{
+				Name:      "feature",
+				Version:   "variant",
+				Namespace: "other",
+			},
+		},
+		Entities: entities,
+		Model:    model,
+	}
+	if _, err := serv.FeatureServe(context.Background(), otherNamespace); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected serving features from another namespace than the model's to fail: %v", err)
+	}
+}
+
 func TestOnDemandFeatureServe(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: onDemandResourceDefsFn,
@@ -1265,6 +1445,54 @@ func TestSimpleModelRegistrationTrainingSetServe(t *testing.T) {
 	}
 }
 
+func TestModelVariantRegistrationTrainingSetServe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.TrainingDataRequest{
+		Id: &pb.TrainingDataID{
+			Name:    "training-set",
+			Version: "variant",
+		},
+		Model: &pb.Model{
+			Name:    "model",
+			Version: "v1",
+		},
+	}
+	stream := newMockTrainingStream()
+	errChan := make(chan error)
+	go func() {
+		if err := serv.TrainingData(req, stream); err != nil {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+	for moreVals := true; moreVals; {
+		select {
+		case <-stream.RowChan:
+		case err := <-errChan:
+			if err != nil {
+				t.Fatalf("Failed to get training data: %s", err)
+			}
+			moreVals = false
+		}
+	}
+	variant, err := serv.Metadata.GetModelVariant(context.Background(), metadata.NameVariant{Name: "model", Variant: "v1"})
+	if err != nil {
+		t.Fatalf("Failed to get model variant: %s", err)
+	}
+	expected := metadata.NameVariant{Name: "training-set", Variant: "variant"}
+	if variant.TrainingSet() != expected {
+		t.Fatalf("Wrong training set associated with registered model variant: %v\nExpected %v", variant.TrainingSet(), expected)
+	}
+	if !reflect.DeepEqual(variant.Features(), metadata.NameVariants{{Name: "feature", Variant: "variant"}}) {
+		t.Fatalf("Wrong features associated with registered model variant: %v", variant.Features())
+	}
+}
+
 func TestTrainingDataColumns(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -1290,3 +1518,314 @@ func TestTrainingDataColumns(t *testing.T) {
 		t.Fatalf("Columns aren't equal: %v\n%v", expectedColumns, resp)
 	}
 }
+
+func manyFeatureRecords(n int) map[provider.ResourceID][]provider.ResourceRecord {
+	featureId := provider.ResourceID{
+		Name:    "feature",
+		Variant: "variant",
+		Type:    provider.Feature,
+	}
+	labelId := provider.ResourceID{
+		Name:    "label",
+		Variant: "variant",
+		Type:    provider.Label,
+	}
+	featureRecs := make([]provider.ResourceRecord, n)
+	labelRecs := make([]provider.ResourceRecord, n)
+	for i := 0; i < n; i++ {
+		entity := fmt.Sprintf("entity-%03d", i)
+		featureRecs[i] = provider.ResourceRecord{Entity: entity, Value: float64(i)}
+		labelRecs[i] = provider.ResourceRecord{Entity: entity, Value: i%2 == 0}
+	}
+	return map[provider.ResourceID][]provider.ResourceRecord{
+		featureId: featureRecs,
+		labelId:   labelRecs,
+	}
+}
+
+func collectTrainingRows(t *testing.T, serv *FeatureServer, req *pb.TrainingDataRequest) []*pb.TrainingDataRow {
+	stream := newMockTrainingStream()
+	errChan := make(chan error)
+	go func() {
+		if err := serv.TrainingData(req, stream); err != nil {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+	rows := make([]*pb.TrainingDataRow, 0)
+	for {
+		select {
+		case row := <-stream.RowChan:
+			rows = append(rows, row)
+		case err := <-errChan:
+			if err != nil {
+				t.Fatalf("Failed to get training data: %s", err)
+			}
+			return rows
+		}
+	}
+}
+
+func TestShardedTrainingSetServe(t *testing.T) {
+	numRows := 10
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(manyFeatureRecords(numRows), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	id := &pb.TrainingDataID{
+		Name:    "training-set",
+		Version: "variant",
+	}
+	shardCount := int64(3)
+	seen := make(map[float64]int64)
+	for i := int64(0); i < shardCount; i++ {
+		rows := collectTrainingRows(t, serv, &pb.TrainingDataRequest{Id: id, ShardIndex: i, ShardCount: shardCount})
+		for _, row := range rows {
+			val := unwrapVal(row.Features[0]).(float64)
+			if shard, has := seen[val]; has {
+				t.Fatalf("Row %v served by shard %d and %d", val, shard, i)
+			}
+			seen[val] = i
+		}
+	}
+	if len(seen) != numRows {
+		t.Fatalf("Shards served %d rows, expected %d", len(seen), numRows)
+	}
+}
+
+func TestResumeTrainingSetServe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(manyFeatureRecords(10), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	id := &pb.TrainingDataID{
+		Name:    "training-set",
+		Version: "variant",
+	}
+	all := collectTrainingRows(t, serv, &pb.TrainingDataRequest{Id: id, ShardIndex: 1, ShardCount: 2, ResumeTokenInterval: 2})
+	if len(all) != 5 {
+		t.Fatalf("Expected 5 rows in shard, got %d", len(all))
+	}
+	if all[0].ResumeToken != "" || all[1].ResumeToken == "" {
+		t.Fatalf("Expected resume token on every second row: %v", all)
+	}
+	resumed := collectTrainingRows(t, serv, &pb.TrainingDataRequest{Id: id, ResumeToken: all[1].ResumeToken})
+	if len(resumed) != 3 {
+		t.Fatalf("Expected 3 rows after resuming, got %d", len(resumed))
+	}
+	for i, row := range resumed {
+		expected := unwrapVal(all[i+2].Features[0])
+		if actual := unwrapVal(row.Features[0]); actual != expected {
+			t.Fatalf("Resumed row %d is %v, expected %v", i, actual, expected)
+		}
+	}
+	other := &pb.TrainingDataID{
+		Name:    "other-set",
+		Version: "variant",
+	}
+	if err := serv.TrainingData(&pb.TrainingDataRequest{Id: other, ResumeToken: all[1].ResumeToken}, newMockTrainingStream()); err == nil {
+		t.Fatalf("Succeeded in resuming with a token for another training set")
+	}
+}
+
+func trainingRowValues(rows []*pb.TrainingDataRow) []interface{} {
+	vals := make([]interface{}, len(rows))
+	for i, row := range rows {
+		vals[i] = unwrapVal(row.Features[0])
+	}
+	return vals
+}
+
+func TestShuffledTrainingSetServe(t *testing.T) {
+	numRows := 50
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(manyFeatureRecords(numRows), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	id := &pb.TrainingDataID{
+		Name:    "training-set",
+		Version: "variant",
+	}
+	ordered := trainingRowValues(collectTrainingRows(t, serv, &pb.TrainingDataRequest{Id: id}))
+	first := trainingRowValues(collectTrainingRows(t, serv, &pb.TrainingDataRequest{Id: id, Shuffle: true, ShuffleSeed: 7}))
+	second := trainingRowValues(collectTrainingRows(t, serv, &pb.TrainingDataRequest{Id: id, Shuffle: true, ShuffleSeed: 7}))
+	if len(first) != numRows {
+		t.Fatalf("Expected %d shuffled rows, got %d", numRows, len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("Shuffles with the same seed differ:\n%v\n%v", first, second)
+	}
+	if reflect.DeepEqual(first, ordered) {
+		t.Fatalf("Shuffled rows are in the original order: %v", first)
+	}
+}
+
+type sliceTrainingSetIterator struct {
+	values []interface{}
+	idx    int
+}
+
+func (it *sliceTrainingSetIterator) Next() bool {
+	if it.idx >= len(it.values) {
+		return false
+	}
+	it.idx++
+	return true
+}
+
+func (it *sliceTrainingSetIterator) Features() []interface{} {
+	return []interface{}{it.values[it.idx-1]}
+}
+
+func (it *sliceTrainingSetIterator) Label() interface{} {
+	return nil
+}
+
+func (it *sliceTrainingSetIterator) Err() error {
+	return nil
+}
+
+func TestBufferShuffleIterator(t *testing.T) {
+	values := make([]interface{}, 100)
+	for i := range values {
+		values[i] = i
+	}
+	shuffle := func(seed int64) []interface{} {
+		iter := newBufferShuffleIterator(&sliceTrainingSetIterator{values: values}, seed, 10)
+		shuffled := make([]interface{}, 0, len(values))
+		for iter.Next() {
+			shuffled = append(shuffled, iter.Features()[0])
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatalf("Failed to shuffle: %s", err)
+		}
+		return shuffled
+	}
+	first, second := shuffle(1), shuffle(1)
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("Shuffles with the same seed differ:\n%v\n%v", first, second)
+	}
+	if reflect.DeepEqual(first, values) {
+		t.Fatalf("Shuffled rows are in the original order")
+	}
+	seen := make(map[interface{}]bool)
+	for _, val := range first {
+		seen[val] = true
+	}
+	if len(seen) != len(values) {
+		t.Fatalf("Shuffle served %d distinct rows, expected %d", len(seen), len(values))
+	}
+}
+
+// unorderedStore can only serve its training set in a different order each
+// time.
+type unorderedStore struct {
+	provider.OfflineStore
+	values []interface{}
+	reads  int
+}
+
+func (store *unorderedStore) GetTrainingSet(id provider.ResourceID) (provider.TrainingSetIterator, error) {
+	store.reads++
+	rotated := append(append([]interface{}{}, store.values[store.reads:]...), store.values[:store.reads]...)
+	return &sliceTrainingSetIterator{values: rotated}, nil
+}
+
+// orderedStore reads its training set in a stable order.
+type orderedStore struct {
+	unorderedStore
+}
+
+func (store *orderedStore) ReadTrainingSet(id provider.ResourceID, opts provider.TrainingSetReadOptions) (provider.TrainingSetIterator, error) {
+	if opts != (provider.TrainingSetReadOptions{}) {
+		return nil, fmt.Errorf("unexpected options: %v", opts)
+	}
+	return &sliceTrainingSetIterator{values: store.values}, nil
+}
+
+func TestBufferShuffledTrainingSet(t *testing.T) {
+	values := make([]interface{}, 50)
+	for i := range values {
+		values[i] = i
+	}
+	id := provider.ResourceID{Name: "training-set", Variant: "variant", Type: provider.TrainingSet}
+	shuffle := provider.TrainingSetShuffle{Enabled: true, Seed: 3}
+	ordered := &orderedStore{unorderedStore{values: values}}
+	seen := make(map[interface{}]bool)
+	for index := int64(0); index < 2; index++ {
+		opts := provider.TrainingSetReadOptions{Shard: provider.TrainingSetShard{Index: index, Count: 2}, Shuffle: shuffle}
+		iter, err := bufferShuffledTrainingSet(ordered, id, opts, 10)
+		if err != nil {
+			t.Fatalf("Failed to shuffle shard %d: %s", index, err)
+		}
+		for iter.Next() {
+			value := iter.Features()[0]
+			if seen[value] {
+				t.Fatalf("Row %v served by more than one shard", value)
+			}
+			seen[value] = true
+		}
+	}
+	if len(seen) != len(values) || ordered.reads != 0 {
+		t.Fatalf("Expected shards to cover %d rows read in order, got %d rows and %d unordered reads", len(values), len(seen), ordered.reads)
+	}
+
+	unordered := &unorderedStore{values: values}
+	sharded := provider.TrainingSetReadOptions{Shard: provider.TrainingSetShard{Index: 0, Count: 2}, Shuffle: shuffle}
+	if _, err := bufferShuffledTrainingSet(unordered, id, sharded, 10); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected sharding an unordered store's shuffle to fail: %v", err)
+	}
+	resumed := provider.TrainingSetReadOptions{Offset: 5, Shuffle: shuffle}
+	if _, err := bufferShuffledTrainingSet(unordered, id, resumed, 10); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected resuming an unordered store's shuffle to fail: %v", err)
+	}
+	if _, err := bufferShuffledTrainingSet(unordered, id, provider.TrainingSetReadOptions{Shuffle: shuffle}, 10); err != nil {
+		t.Fatalf("Failed to shuffle an unordered store into one stream: %s", err)
+	}
+}
+
+func TestSourceDataQuery(t *testing.T) {
+	req := &pb.SourceDataRequest{
+		Limit:   10,
+		Offset:  5,
+		Columns: []string{"entity", "value"},
+		Filters: []*pb.SourceFilter{
+			{
+				Column:   "value",
+				Operator: pb.SourceFilter_GREATER_THAN,
+				Values:   []*pb.Value{wrapInt64(3)},
+			},
+			{
+				Column:   "entity",
+				Operator: pb.SourceFilter_IN,
+				Values:   []*pb.Value{wrapStr("a"), wrapStr("b")},
+			},
+		},
+	}
+	expected := provider.SegmentQuery{
+		Columns: []string{"entity", "value"},
+		Filters: []provider.ColumnFilter{
+			{Column: "value", Operator: provider.FilterGreaterThan, Values: []interface{}{int64(3)}},
+			{Column: "entity", Operator: provider.FilterIn, Values: []interface{}{"a", "b"}},
+		},
+		Offset: 5,
+		Limit:  10,
+	}
+	query, err := sourceDataQuery(req)
+	if err != nil {
+		t.Fatalf("Failed to parse source data query: %s", err)
+	}
+	if !reflect.DeepEqual(query, expected) {
+		t.Fatalf("Wrong source data query: expected %v, got %v", expected, query)
+	}
+	req.Filters[0].Values = []*pb.Value{wrapVec32([]float32{1})}
+	if _, err := sourceDataQuery(req); err == nil {
+		t.Fatalf("Succeeded to parse filter on a vector")
+	}
+}
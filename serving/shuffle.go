@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package serving
+
+import (
+	"math/rand"
+
+	"github.com/featureform/provider"
+)
+
+const defaultShuffleBufferSize = 10000
+
+type bufferedTrainingRow struct {
+	features []interface{}
+	label    interface{}
+}
+
+// bufferShuffleIterator shuffles a training set that the offline store can't
+// shuffle itself. It keeps up to size rows in memory and serves a random one
+// each time a new row is read, so rows only move within a window of roughly
+// size rows. For a given seed and input order the output order is always the
+// same, which lets a shuffled stream be resumed.
+type bufferShuffleIterator struct {
+	iter    provider.TrainingSetIterator
+	rng     *rand.Rand
+	size    int
+	buffer  []bufferedTrainingRow
+	current bufferedTrainingRow
+	drained bool
+}
+
+func newBufferShuffleIterator(iter provider.TrainingSetIterator, seed int64, size int) *bufferShuffleIterator {
+	if size <= 0 {
+		size = defaultShuffleBufferSize
+	}
+	return &bufferShuffleIterator{
+		iter:   iter,
+		rng:    rand.New(rand.NewSource(seed)),
+		size:   size,
+		buffer: make([]bufferedTrainingRow, 0, size),
+	}
+}
+
+func (it *bufferShuffleIterator) fill() {
+	for !it.drained && len(it.buffer) < it.size {
+		if !it.iter.Next() {
+			it.drained = true
+			return
+		}
+		it.buffer = append(it.buffer, bufferedTrainingRow{
+			features: it.iter.Features(),
+			label:    it.iter.Label(),
+		})
+	}
+}
+
+func (it *bufferShuffleIterator) Next() bool {
+	it.fill()
+	if len(it.buffer) == 0 || it.iter.Err() != nil {
+		return false
+	}
+	idx := it.rng.Intn(len(it.buffer))
+	it.current = it.buffer[idx]
+	last := len(it.buffer) - 1
+	it.buffer[idx] = it.buffer[last]
+	it.buffer = it.buffer[:last]
+	return true
+}
+
+func (it *bufferShuffleIterator) Features() []interface{} {
+	return it.current.features
+}
+
+func (it *bufferShuffleIterator) Label() interface{} {
+	return it.current.label
+}
+
+func (it *bufferShuffleIterator) Err() error {
+	return it.iter.Err()
+}